@@ -3,40 +3,58 @@ package main
 import (
 	"flag"
 	"fmt"
+	"image"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/hobbiee/visualboy-go/internal/core/gb"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/input"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/serial"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/sgb"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
+	"github.com/hobbiee/visualboy-go/internal/core/rewind"
+	"github.com/hobbiee/visualboy-go/internal/gui"
 	"github.com/hobbiee/visualboy-go/internal/gui/audio"
 	"github.com/hobbiee/visualboy-go/internal/gui/display"
 )
 
 // Configurações da aplicação GUI
 type GUIConfig struct {
-	ROMFile     string
-	Scale       int
-	Fullscreen  bool
-	EnableSound bool
-	Volume      float64
-	Debug       bool
-	FPS         float64
-	ShowFPS     bool
-	Palette     string
+	ROMFile       string
+	Scale         int
+	Fullscreen    bool
+	EnableSound   bool
+	Volume        float64
+	Debug         bool
+	FPS           float64
+	ShowFPS       bool
+	Palette       string
+	BootROM       string
+	LinkHost      string
+	LinkJoin      string
+	Printer       bool
+	ScreenshotDir string
+	PPU           string
+	Camera        string
+	Model         string
+	RewindMB      int
 }
 
 // Aplicação GUI principal
 type GUIApp struct {
-	config  GUIConfig
-	gameboy *gb.GameBoy
-	display *display.Display
-	audio   *audio.AudioSystem
-	running bool
-	paused  bool
+	config   GUIConfig
+	settings *gui.Config // Configurações persistentes (diretório dos saves...)
+	gameboy  *gb.GameBoy
+	display  *display.Display
+	audio    *audio.AudioSystem
+	running  bool
+	paused   bool
 
 	// Estado dos botões
 	keyStates map[string]bool
@@ -81,12 +99,15 @@ func main() {
 // parseGUIFlags analisa argumentos da linha de comando para GUI
 func parseGUIFlags() GUIConfig {
 	config := GUIConfig{
-		Scale:       3,
-		EnableSound: true,
-		Volume:      0.7,
-		FPS:         59.7,
-		ShowFPS:     true,
-		Palette:     "gameboy",
+		Scale:         3,
+		EnableSound:   true,
+		Volume:        0.7,
+		FPS:           59.7,
+		ShowFPS:       true,
+		Palette:       "gameboy",
+		PPU:           "scanline",
+		ScreenshotDir: "screenshots", // Mesmo padrão de gui.Config.ScreenshotDir
+		RewindMB:      rewind.DefaultBudget >> 20,
 	}
 
 	flag.StringVar(&config.ROMFile, "rom", "", "Arquivo ROM para carregar (.gb)")
@@ -97,7 +118,16 @@ func parseGUIFlags() GUIConfig {
 	flag.BoolVar(&config.Debug, "debug", config.Debug, "Modo debug")
 	flag.Float64Var(&config.FPS, "fps", config.FPS, "FPS alvo")
 	flag.BoolVar(&config.ShowFPS, "show-fps", config.ShowFPS, "Mostrar FPS no título")
+	flag.StringVar(&config.PPU, "ppu", config.PPU, "Renderizador do vídeo (scanline, fifo)")
 	flag.StringVar(&config.Palette, "palette", config.Palette, "Paleta de cores (gameboy, grayscale, custom)")
+	flag.StringVar(&config.LinkHost, "link-host", config.LinkHost, "Aguarda outra instância no cabo link TCP (ex.: 127.0.0.1:5739)")
+	flag.StringVar(&config.LinkJoin, "link-join", config.LinkJoin, "Conecta ao cabo link TCP de outra instância (ex.: 127.0.0.1:5739)")
+	flag.BoolVar(&config.Printer, "printer", config.Printer, "Conecta um Game Boy Printer virtual à porta serial")
+	flag.StringVar(&config.ScreenshotDir, "screenshots", config.ScreenshotDir, "Diretório das capturas e impressões do Game Boy Printer")
+	flag.StringVar(&config.Camera, "camera", config.Camera, "PNG/JPEG ou diretório de quadros vistos pela Pocket Camera")
+	flag.StringVar(&config.Model, "model", config.Model, "Hardware emulado (auto, dmg, cgb, sgb); sgb mostra moldura e paletas em jogos com suporte")
+	flag.IntVar(&config.RewindMB, "rewind", config.RewindMB, "Memória do histórico de rewind em MB (0 desliga); segure Backspace para voltar no tempo")
+	flag.StringVar(&config.BootROM, "bootrom", config.BootROM, "Boot ROM DMG (256 bytes) ou CGB (2304 bytes) para executar antes do jogo")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "VisualBoy Go - Game Boy Emulator (GUI)\n\n")
@@ -138,12 +168,28 @@ func parseGUIFlags() GUIConfig {
 func NewGUIApp(config GUIConfig) *GUIApp {
 	return &GUIApp{
 		config:    config,
+		settings:  loadGUIConfig(),
 		running:   true,
 		keyStates: make(map[string]bool),
 		lastFPS:   time.Now(),
 	}
 }
 
+// loadGUIConfig lê as configurações persistentes da interface, usando os
+// valores padrão se o arquivo não puder ser lido
+func loadGUIConfig() *gui.Config {
+	dir, err := os.UserConfigDir()
+	if err == nil {
+		var config *gui.Config
+		config, err = gui.LoadConfig(filepath.Join(dir, "visualboy-go", "config.json"))
+		if err == nil {
+			return config
+		}
+	}
+	log.Printf("Aviso: usando configurações padrão: %v", err)
+	return gui.DefaultConfig()
+}
+
 // Initialize inicializa a aplicação GUI
 func (app *GUIApp) Initialize() error {
 	fmt.Println("VisualBoy Go - Game Boy Emulator (GUI Mode)")
@@ -176,9 +222,40 @@ func (app *GUIApp) Initialize() error {
 	gbConfig.EnableSound = app.config.EnableSound
 	gbConfig.EnableDebug = app.config.Debug
 	gbConfig.EnableVSync = false // Controlamos o timing manualmente
+	gbConfig.SavesDir = app.settings.SavesDir
+	gbConfig.EnableBootROM = app.config.BootROM != ""
+	gbConfig.EnableRewind = app.config.RewindMB > 0
+	gbConfig.RewindBudget = app.config.RewindMB << 20
+	model, err := gb.ParseModel(app.config.Model)
+	if err != nil {
+		return err
+	}
+	gbConfig.Model = model
+	if strings.ToLower(app.config.PPU) == "fifo" {
+		gbConfig.Renderer = video.RendererFIFO
+	}
 
 	app.gameboy = gb.NewGameBoy(gbConfig)
 
+	// Carrega a boot ROM, se informada
+	if app.config.BootROM != "" {
+		if err := app.gameboy.LoadBootROMFile(app.config.BootROM); err != nil {
+			return fmt.Errorf("erro ao carregar boot ROM: %w", err)
+		}
+	}
+
+	// Imagens da Pocket Camera
+	if app.config.Camera != "" {
+		if err := app.gameboy.LoadCameraImages(app.config.Camera); err != nil {
+			return fmt.Errorf("erro ao carregar imagens da câmera: %w", err)
+		}
+	}
+
+	// Conecta o cabo link, se solicitado
+	if err := app.setupLink(); err != nil {
+		return err
+	}
+
 	// Configura callbacks
 	app.setupCallbacks()
 
@@ -188,6 +265,38 @@ func (app *GUIApp) Initialize() error {
 	return nil
 }
 
+// setupLink conecta a porta serial a outra instância via TCP ou ao
+// Game Boy Printer
+func (app *GUIApp) setupLink() error {
+	switch {
+	case app.config.LinkHost != "":
+		peer, err := serial.ListenTCP(app.config.LinkHost)
+		if err != nil {
+			return fmt.Errorf("erro ao iniciar cabo link: %w", err)
+		}
+		app.gameboy.SetSerialPeer(peer)
+		fmt.Printf("Cabo link aguardando conexão em %s\n", peer.Addr())
+	case app.config.LinkJoin != "":
+		peer, err := serial.DialTCP(app.config.LinkJoin)
+		if err != nil {
+			return fmt.Errorf("erro ao conectar cabo link: %w", err)
+		}
+		app.gameboy.SetSerialPeer(peer)
+		fmt.Printf("Cabo link conectado a %s\n", app.config.LinkJoin)
+	case app.config.Printer:
+		p := app.gameboy.ConnectPrinter(app.config.ScreenshotDir)
+		p.SetPrintCallback(func(img *image.Gray, path string) {
+			if path != "" {
+				fmt.Printf("Impressão salva em %s\n", path)
+			} else if err := p.LastError(); err != nil {
+				log.Printf("Aviso: %v", err)
+			}
+		})
+		fmt.Printf("Game Boy Printer conectado (impressões em %s)\n", app.config.ScreenshotDir)
+	}
+	return nil
+}
+
 // setPalette configura a paleta de cores
 func (app *GUIApp) setPalette() {
 	switch strings.ToLower(app.config.Palette) {
@@ -208,9 +317,11 @@ func (app *GUIApp) setupCallbacks() {
 		app.frameCount++
 		app.fpsCounter++
 
-		// Atualiza display
-		if err := app.display.UpdateFrame(frame); err != nil {
-			log.Printf("Erro ao atualizar display: %v", err)
+		// Atualiza display (jogos CGB e SGB usam os callbacks coloridos)
+		if !app.gameboy.IsCGB() && !app.gameboy.IsSGB() {
+			if err := app.display.UpdateFrame(frame); err != nil {
+				log.Printf("Erro ao atualizar display: %v", err)
+			}
 		}
 
 		// Atualiza FPS no título
@@ -223,6 +334,26 @@ func (app *GUIApp) setupCallbacks() {
 		}
 	})
 
+	// Callback de frame colorido (Game Boy Color)
+	app.gameboy.SetColorFrameCallback(func(frame [144][160]uint16) {
+		if !app.gameboy.IsCGB() {
+			return
+		}
+		if err := app.display.UpdateColorFrame(frame); err != nil {
+			log.Printf("Erro ao atualizar display: %v", err)
+		}
+	})
+
+	// Callback da imagem com moldura (Super Game Boy)
+	app.gameboy.SetSGBFrameCallback(func(frame [sgb.Height][sgb.Width]uint16) {
+		if err := app.display.UpdateSGBFrame(frame); err != nil {
+			log.Printf("Erro ao atualizar display: %v", err)
+		}
+	})
+
+	// Motor de vibração (MBC5+RUMBLE) no gamepad
+	app.gameboy.SetRumbleListener(app.display.Rumble)
+
 	// Callback de áudio
 	if app.config.EnableSound && app.audio != nil {
 		app.gameboy.SetAudioCallback(func(samples []int16) {
@@ -234,6 +365,9 @@ func (app *GUIApp) setupCallbacks() {
 // updateTitle atualiza o título da janela
 func (app *GUIApp) updateTitle() {
 	title := fmt.Sprintf("VisualBoy Go - %s", app.gameboy.GetROMTitle())
+	if header := app.gameboy.GetROMHeader(); header != nil {
+		title += fmt.Sprintf(" (%s)", header.MapperName())
+	}
 
 	if app.config.ShowFPS {
 		title += fmt.Sprintf(" - %.1f FPS", app.currentFPS)
@@ -279,9 +413,19 @@ func (app *GUIApp) LoadROM(filename string) error {
 		return fmt.Errorf("erro ao carregar ROM no emulador: %w", err)
 	}
 
+	// Carrega a RAM do cartucho salva em disco
+	app.gameboy.SetSavePath(gb.SavePathFor(filename, app.settings.SavesDir))
+	if err := app.gameboy.LoadBattery(); err != nil {
+		log.Printf("Aviso: %v", err)
+	} else if app.gameboy.HasBattery() {
+		fmt.Printf("Save: %s\n", app.gameboy.GetSavePath())
+	}
+
 	fmt.Printf("ROM carregada: %s\n", filepath.Base(filename))
-	fmt.Printf("Título: %s\n", app.gameboy.GetROMTitle())
-	fmt.Printf("Tipo: 0x%02X\n", app.gameboy.GetCartridgeType())
+	fmt.Print(app.gameboy.GetROMHeader().Details())
+	for _, warning := range app.gameboy.GetROMWarnings() {
+		log.Printf("Aviso: %s", warning)
+	}
 
 	app.updateTitle()
 
@@ -408,13 +552,25 @@ func (app *GUIApp) LoadTestROM() {
 // Run executa o loop principal da aplicação GUI
 func (app *GUIApp) Run() {
 	fmt.Println("Iniciando emulação GUI...")
-	fmt.Println("Use ESC para sair, Space para pausar, R para reset")
+	fmt.Println("Use ESC para sair, Space para pausar, R para reset, Backspace para voltar no tempo")
 
 	app.gameboy.Start()
 	app.updateTitle()
 
+	// Encerra de forma limpa (gravando o .sav) em SIGINT/SIGTERM
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
 	// Loop principal
 	for app.running && app.display.IsRunning() {
+		select {
+		case <-signals:
+			app.running = false
+			continue
+		default:
+		}
+
 		// Processa eventos SDL
 		keys, shouldContinue := app.display.HandleEvents()
 		if !shouldContinue {
@@ -426,10 +582,15 @@ func (app *GUIApp) Run() {
 
 		// Atualiza input do Game Boy se não pausado
 		if !app.paused {
-			app.updateGameBoyInput(keys)
-
-			// Executa um step do emulador
-			app.gameboy.Step()
+			if app.keyStates["Rewind"] && app.config.RewindMB > 0 {
+				// Backspace segurado volta a emulação no tempo
+				app.gameboy.Rewind(app.gameboy.GetConfig().RewindInterval)
+			} else {
+				app.updateGameBoyInput(keys)
+
+				// Executa um step do emulador
+				app.gameboy.Step()
+			}
 		}
 
 		// Controle de timing (60 FPS)
@@ -522,6 +683,17 @@ func (app *GUIApp) updateGameBoyInput(keys map[string]bool) {
 			inputSystem.SetButtonState(button, pressed)
 		}
 	}
+
+	// Cartuchos com acelerômetro: mouse arrastado ou analógico do gamepad,
+	// ou as setas quando nenhum dos dois está em uso
+	if app.gameboy.HasTiltSensor() {
+		x, y, analog := app.display.GetTilt()
+		if !analog {
+			dx, dy := inputSystem.GetDirectionVector()
+			x, y = float64(dx), float64(dy)
+		}
+		inputSystem.SetTilt(x, y)
+	}
 }
 
 // Cleanup limpa recursos da aplicação
@@ -529,7 +701,11 @@ func (app *GUIApp) Cleanup() {
 	fmt.Println("Limpando recursos GUI...")
 
 	if app.gameboy != nil {
+		if err := app.gameboy.FlushBattery(); err != nil {
+			log.Printf("Erro ao gravar save: %v", err)
+		}
 		app.gameboy.Stop()
+		app.gameboy.GetSerialPeer().Close()
 	}
 
 	if app.audio != nil {
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 	
 	"github.com/hobbiee/visualboy-go/internal/core/gb"
@@ -28,6 +30,7 @@ func main() {
 	debug := flag.Bool("debug", false, "Modo debug")
 	duration := flag.Int("duration", 0, "Duração em segundos (0 = infinito)")
 	fps := flag.Float64("fps", 59.7, "FPS alvo")
+	savesDir := flag.String("saves", "", "Diretório dos arquivos .sav (padrão: ao lado da ROM)")
 	
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "VisualBoy Go - Simple GUI\n\n")
@@ -55,7 +58,7 @@ func main() {
 	}
 	
 	// Inicializa
-	if err := gui.Initialize(*debug, *fps); err != nil {
+	if err := gui.Initialize(*debug, *fps, *savesDir); err != nil {
 		log.Fatalf("Erro ao inicializar: %v", err)
 	}
 	
@@ -74,7 +77,7 @@ func main() {
 }
 
 // Initialize inicializa a GUI simples
-func (gui *SimpleGUI) Initialize(debug bool, fps float64) error {
+func (gui *SimpleGUI) Initialize(debug bool, fps float64, savesDir string) error {
 	fmt.Println("VisualBoy Go - Simple GUI")
 	fmt.Println("=========================")
 	
@@ -84,6 +87,7 @@ func (gui *SimpleGUI) Initialize(debug bool, fps float64) error {
 	config.EnableSound = false
 	config.TargetFPS = fps
 	config.EnableVSync = false
+	config.SavesDir = savesDir
 	
 	gui.gameboy = gb.NewGameBoy(config)
 	
@@ -125,9 +129,17 @@ func (gui *SimpleGUI) LoadROM(filename string) error {
 		return fmt.Errorf("erro ao carregar ROM: %w", err)
 	}
 	
+	// Carrega a RAM do cartucho salva em disco
+	gui.gameboy.SetSavePath(gb.SavePathFor(filename, gui.gameboy.GetConfig().SavesDir))
+	if err := gui.gameboy.LoadBattery(); err != nil {
+		log.Printf("Aviso: %v", err)
+	}
+	
 	fmt.Printf("ROM carregada: %s\n", filepath.Base(filename))
-	fmt.Printf("Título: %s\n", gui.gameboy.GetROMTitle())
-	fmt.Printf("Tipo: 0x%02X\n", gui.gameboy.GetCartridgeType())
+	fmt.Print(gui.gameboy.GetROMHeader().Details())
+	for _, warning := range gui.gameboy.GetROMWarnings() {
+		log.Printf("Aviso: %s", warning)
+	}
 	
 	return nil
 }
@@ -257,11 +269,28 @@ func (gui *SimpleGUI) Run(duration int) {
 	startTime := time.Now()
 	targetDuration := time.Duration(duration) * time.Second
 	
+	// Ctrl+C encerra o loop para que o .sav seja gravado
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	
 	for gui.running && (duration == 0 || time.Since(startTime) < targetDuration) {
+		select {
+		case <-signals:
+			gui.running = false
+			continue
+		default:
+		}
+		
 		gui.gameboy.Step()
 		time.Sleep(time.Second / 60) // 60 FPS
 	}
 	
+	if err := gui.gameboy.FlushBattery(); err != nil {
+		log.Printf("Erro ao gravar save: %v", err)
+	}
+	gui.gameboy.Stop()
+	
 	// Estatísticas finais
 	elapsed := time.Since(startTime)
 	fmt.Printf("\n\nEstatísticas Finais:\n")
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/cartridge"
+)
+
+// cliEnv faz o binário de teste executar main() no lugar dos testes
+const cliEnv = "VISUALBOYGO_TEST_CLI"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(cliEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runCLI executa o comando com os argumentos e retorna a saída padrão e o
+// código de saída
+func runCLI(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), cliEnv+"=1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return stdout.String(), 0
+	case errors.As(err, &exitErr):
+		return stdout.String() + stderr.String(), exitErr.ExitCode()
+	}
+	t.Fatalf("erro ao executar o comando: %v", err)
+	return "", 0
+}
+
+// writeTestROM grava uma ROM mínima, com header válido, que liga o LCD e
+// copia o DIV para o scroll em loop
+func writeTestROM(t *testing.T) string {
+	rom := make([]uint8, 0x8000)
+	copy(rom[cartridge.HeaderTitle:], "CLI TEST")
+	copy(rom[0x100:], []uint8{
+		0x00, 0xC3, 0x50, 0x01, // NOP; JP 0x0150
+	})
+	copy(rom[0x150:], []uint8{
+		0x3E, 0x91, 0xE0, 0x40, // LCDC = 0x91
+		// loop (0x0154):
+		0xF0, 0x04, // LDH A, (DIV)
+		0xE0, 0x43, // LDH (SCX), A
+		0x18, 0xFA, // JR loop
+	})
+	rom[cartridge.HeaderChecksum] = cartridge.ComputeHeaderChecksum(rom)
+
+	path := filepath.Join(t.TempDir(), "test.gb")
+	if err := os.WriteFile(path, rom, 0644); err != nil {
+		t.Fatalf("erro ao gravar ROM: %v", err)
+	}
+	return path
+}
+
+func TestInfo(t *testing.T) {
+	rom := writeTestROM(t)
+	out, code := runCLI(t, "info", rom)
+	if code != 0 {
+		t.Fatalf("código de saída %d:\n%s", code, out)
+	}
+
+	data, _ := os.ReadFile(rom)
+	sum := sha1.Sum(data)
+	for _, want := range []string{"Título: CLI TEST", "SHA-1: " + hex.EncodeToString(sum[:]), "Checksum do header: 0x"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("saída sem %q:\n%s", want, out)
+		}
+	}
+
+	if out, code := runCLI(t, "info", filepath.Join(t.TempDir(), "inexistente.gb")); code != 1 {
+		t.Errorf("ROM inexistente: esperado código 1, obtido %d:\n%s", code, out)
+	}
+}
+
+func TestHash(t *testing.T) {
+	rom := writeTestROM(t)
+	out, code := runCLI(t, "hash", rom, "--frames", "30")
+	if code != 0 {
+		t.Fatalf("código de saída %d:\n%s", code, out)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 || lines[0] != "frames 30" {
+		t.Fatalf("saída inesperada:\n%s", out)
+	}
+	for i, prefix := range []string{"video  ", "frame  ", "audio  "} {
+		fields := strings.Fields(strings.TrimPrefix(lines[i+1], prefix))
+		if !strings.HasPrefix(lines[i+1], prefix) || len(fields) == 0 || len(fields[0]) != 64 {
+			t.Errorf("linha sem digest SHA-256: %q", lines[i+1])
+		}
+	}
+
+	// Execuções repetidas produzem os mesmos digests
+	if again, _ := runCLI(t, "hash", "--frames", "30", rom); again != out {
+		t.Errorf("digests diferentes entre execuções:\n%s\n%s", out, again)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	rom := writeTestROM(t)
+	for _, args := range [][]string{
+		{},
+		{"desconhecido"},
+		{"info"},
+		{"hash", rom, "--frames", "0"},
+		{"hash", rom, "--opcao-invalida"},
+	} {
+		if out, code := runCLI(t, args...); code != 2 {
+			t.Errorf("%v: esperado código 2, obtido %d:\n%s", args, code, out)
+		}
+	}
+}
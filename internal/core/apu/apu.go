@@ -2,6 +2,14 @@ package apu
 
 import (
 	"sync"
+
+	"github.com/hobbiee/visualboy-go/internal/core/blip"
+)
+
+// Clock do GBA e taxa de saída padrão
+const (
+	ClockRate         = 16777216 // Ciclos por segundo
+	DefaultSampleRate = 44100
 )
 
 // Registradores do APU
@@ -107,6 +115,12 @@ type APU struct {
 	// Estado
 	enabled bool    // Master enable
 	samples []int16 // Buffer de amostras
+
+	// Resampler band-limited (ciclos do GBA -> taxa de saída)
+	output    *blip.StereoBuffer
+	clock     int   // Ciclos desde o último fechamento de frame
+	lastLeft  int16 // Última amostra enviada ao resampler
+	lastRight int16
 }
 
 // NewAPU cria uma nova instância do APU
@@ -119,9 +133,28 @@ func NewAPU() *APU {
 		dmaA:    NewDirectSoundChannel(),
 		dmaB:    NewDirectSoundChannel(),
 		samples: make([]int16, 512), // Buffer para ~10ms @ 44.1kHz
+		output:  blip.NewStereoBuffer(ClockRate, DefaultSampleRate),
 	}
 }
 
+// SetSampleRate define a taxa de saída usada por Render/ReadSamples
+func (a *APU) SetSampleRate(rate int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.output.SetRates(ClockRate, rate)
+	a.clock = 0
+	a.lastLeft, a.lastRight = 0, 0
+}
+
+// GetSampleRate retorna a taxa de saída usada por Render/ReadSamples
+func (a *APU) GetSampleRate() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.output.SampleRate()
+}
+
 // SetEnabled define se o APU está habilitado
 func (a *APU) SetEnabled(enabled bool) {
 	a.mu.Lock()
@@ -244,6 +277,11 @@ func (a *APU) ProcessAudio() (int16, int16) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	return a.mix()
+}
+
+// mix combina todos os canais na amostra atual
+func (a *APU) mix() (int16, int16) {
 	if !a.enabled {
 		return 0, 0
 	}
@@ -374,6 +412,59 @@ func (a *APU) Step() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	a.step()
+}
+
+// Render avança o APU por cycles ciclos, enviando ao resampler cada mudança
+// da saída mixada no ciclo em que ela ocorre
+func (a *APU) Render(cycles int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i := 0; i < cycles; i++ {
+		a.step()
+
+		left, right := a.mix()
+		if left != a.lastLeft || right != a.lastRight {
+			a.output.AddDelta(a.clock, float64(left)-float64(a.lastLeft), float64(right)-float64(a.lastRight))
+			a.lastLeft, a.lastRight = left, right
+		}
+		a.clock++
+	}
+
+	// Fecha o frame periodicamente para manter os clocks pequenos
+	if a.clock >= ClockRate/64 {
+		a.endFrame()
+	}
+}
+
+// ReadSamples lê até len(out)/2 amostras estéreo intercaladas (L, R...) na
+// taxa configurada, retornando o número de amostras estéreo lidas
+func (a *APU) ReadSamples(out []int16) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.endFrame()
+	return a.output.ReadInterleaved(out, 1)
+}
+
+// SamplesAvailable retorna quantas amostras estéreo estão prontas
+func (a *APU) SamplesAvailable() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.endFrame()
+	return a.output.SamplesAvailable()
+}
+
+// endFrame fecha o frame atual do resampler
+func (a *APU) endFrame() {
+	a.output.EndFrame(a.clock)
+	a.clock = 0
+}
+
+// step avança todos os canais por um ciclo
+func (a *APU) step() {
 	if !a.enabled {
 		return
 	}
@@ -402,6 +493,11 @@ func (a *APU) Reset() {
 	// Reseta Direct Sound
 	a.dmaA.Reset()
 	a.dmaB.Reset()
+
+	// Descarta amostras pendentes
+	a.output.Clear()
+	a.clock = 0
+	a.lastLeft, a.lastRight = 0, 0
 }
 
 // DirectSoundChannel representa um canal de Direct Sound
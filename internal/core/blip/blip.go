@@ -0,0 +1,280 @@
+package blip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Parâmetros do kernel de síntese band-limited
+const (
+	// HalfWidth é a metade do número de taps do kernel (16 taps no total)
+	HalfWidth = 8
+
+	// Phases é o número de fases pré-calculadas entre duas amostras de saída
+	Phases = 64
+
+	// cutoff é a frequência de corte relativa a Nyquist da taxa de saída
+	cutoff = 0.9
+
+	taps = HalfWidth * 2
+)
+
+// kernel contém a resposta ao impulso (sinc com janela Blackman) para cada
+// fase. A fase Phases é incluída para interpolar a última fase.
+var kernel = buildKernel()
+
+// buildKernel calcula os kernels normalizados (soma 1) de todas as fases
+func buildKernel() [Phases + 1][taps]float64 {
+	var k [Phases + 1][taps]float64
+
+	for phase := 0; phase <= Phases; phase++ {
+		fraction := float64(phase) / Phases
+		sum := 0.0
+
+		for i := 0; i < taps; i++ {
+			// Distância (em amostras de saída) entre o tap e o instante do delta
+			x := float64(i-HalfWidth+1) - fraction
+			window := 0.42 + 0.5*math.Cos(math.Pi*x/HalfWidth) + 0.08*math.Cos(2*math.Pi*x/HalfWidth)
+			if math.Abs(x) >= HalfWidth {
+				window = 0
+			}
+
+			k[phase][i] = sinc(x*cutoff) * window
+			sum += k[phase][i]
+		}
+
+		for i := range k[phase] {
+			k[phase][i] /= sum
+		}
+	}
+
+	return k
+}
+
+// sinc calcula sin(pi*x)/(pi*x)
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// Buffer converte mudanças de amplitude marcadas em ciclos do clock emulado
+// em amostras na taxa de saída, sem aliasing. As posições são mantidas como
+// frações exatas (ciclos * sampleRate / clockRate), portanto não há deriva
+// entre o clock emulado e a taxa de saída, mesmo com passos muito curtos.
+//
+// Uso: AddDelta para cada mudança de amplitude dentro de um frame (clock
+// relativo ao início do frame), EndFrame para fechar o frame e ReadSamples
+// para consumir as amostras prontas.
+type Buffer struct {
+	clockRate  uint64
+	sampleRate uint64
+
+	offset     uint64    // Posição do início do frame, em 1/clockRate amostra
+	buf        []float64 // Diferenças (derivada) das amostras de saída
+	avail      int       // Amostras prontas para leitura
+	integrator float64   // Amplitude acumulada das amostras já lidas
+}
+
+// NewBuffer cria um novo Buffer para o clock e a taxa de saída informados
+func NewBuffer(clockRate, sampleRate int) *Buffer {
+	b := &Buffer{}
+	b.SetRates(clockRate, sampleRate)
+	return b
+}
+
+// SetRates define o clock emulado e a taxa de saída e limpa o buffer
+func (b *Buffer) SetRates(clockRate, sampleRate int) {
+	if clockRate <= 0 || sampleRate <= 0 {
+		panic("blip: taxas devem ser positivas")
+	}
+
+	b.clockRate = uint64(clockRate)
+	b.sampleRate = uint64(sampleRate)
+	b.Clear()
+}
+
+// SampleRate retorna a taxa de saída em Hz
+func (b *Buffer) SampleRate() int {
+	return int(b.sampleRate)
+}
+
+// ClockRate retorna o clock emulado em Hz
+func (b *Buffer) ClockRate() int {
+	return int(b.clockRate)
+}
+
+// Clear descarta todas as amostras e deltas pendentes
+func (b *Buffer) Clear() {
+	b.offset = 0
+	b.avail = 0
+	b.integrator = 0
+	b.buf = make([]float64, 1024+taps)
+}
+
+// AddDelta registra uma mudança de amplitude no ciclo clock do frame atual
+func (b *Buffer) AddDelta(clock int, delta float64) {
+	if delta == 0 {
+		return
+	}
+
+	position := b.offset + uint64(clock)*b.sampleRate
+	index := b.avail + int(position/b.clockRate)
+	b.ensure(index + taps)
+
+	// Interpola entre as duas fases mais próximas
+	phase := float64(position%b.clockRate) * Phases / float64(b.clockRate)
+	p := int(phase)
+	weight := phase - float64(p)
+
+	k0 := &kernel[p]
+	k1 := &kernel[p+1]
+	out := b.buf[index : index+taps]
+	for i := range out {
+		out[i] += delta * (k0[i] + (k1[i]-k0[i])*weight)
+	}
+}
+
+// EndFrame fecha um frame de clocks ciclos, tornando suas amostras
+// disponíveis. Deltas posteriores usam clocks relativos ao novo frame.
+func (b *Buffer) EndFrame(clocks int) {
+	b.offset += uint64(clocks) * b.sampleRate
+	b.avail += int(b.offset / b.clockRate)
+	b.offset %= b.clockRate
+	b.ensure(b.avail + taps)
+}
+
+// SamplesAvailable retorna o número de amostras prontas para leitura
+func (b *Buffer) SamplesAvailable() int {
+	return b.avail
+}
+
+// ClocksNeeded retorna quantos ciclos precisam ser emulados para que
+// samples amostras fiquem disponíveis
+func (b *Buffer) ClocksNeeded(samples int) int {
+	if samples <= b.avail {
+		return 0
+	}
+
+	needed := uint64(samples-b.avail)*b.clockRate - b.offset
+	return int((needed + b.sampleRate - 1) / b.sampleRate)
+}
+
+// ReadSamples copia até len(out) amostras prontas para out e as remove do
+// buffer, retornando quantas foram lidas
+func (b *Buffer) ReadSamples(out []float64) int {
+	count := len(out)
+	if count > b.avail {
+		count = b.avail
+	}
+
+	for i := 0; i < count; i++ {
+		b.integrator += b.buf[i]
+		out[i] = b.integrator
+	}
+
+	b.shift(count)
+	return count
+}
+
+// RemoveSamples descarta as count amostras mais antigas sem lê-las
+func (b *Buffer) RemoveSamples(count int) {
+	if count > b.avail {
+		count = b.avail
+	}
+
+	// Amostras descartadas ainda contam para a amplitude acumulada
+	for i := 0; i < count; i++ {
+		b.integrator += b.buf[i]
+	}
+	b.shift(count)
+}
+
+// shift remove as count primeiras posições do buffer
+func (b *Buffer) shift(count int) {
+	if count <= 0 {
+		return
+	}
+
+	remaining := copy(b.buf, b.buf[count:])
+	for i := remaining; i < len(b.buf); i++ {
+		b.buf[i] = 0
+	}
+	b.avail -= count
+}
+
+// ensure garante que o buffer comporta size posições
+func (b *Buffer) ensure(size int) {
+	if size <= len(b.buf) {
+		return
+	}
+
+	grown := make([]float64, size*2)
+	copy(grown, b.buf)
+	b.buf = grown
+}
+
+// bufferState é o cabeçalho do estado serializado de um Buffer
+type bufferState struct {
+	ClockRate  uint64
+	SampleRate uint64
+	Offset     uint64
+	Avail      int64
+	Integrator float64
+	Count      int64 // Posições de buf gravadas em seguida
+}
+
+// SaveState serializa as amostras pendentes e a posição do frame atual
+func (b *Buffer) SaveState() ([]byte, error) {
+	// Posições após a última diferença não nula são sempre zero
+	count := len(b.buf)
+	for count > 0 && b.buf[count-1] == 0 {
+		count--
+	}
+
+	var buf bytes.Buffer
+	state := bufferState{
+		ClockRate:  b.clockRate,
+		SampleRate: b.sampleRate,
+		Offset:     b.offset,
+		Avail:      int64(b.avail),
+		Integrator: b.integrator,
+		Count:      int64(count),
+	}
+	binary.Write(&buf, binary.LittleEndian, &state)
+	binary.Write(&buf, binary.LittleEndian, b.buf[:count])
+	return buf.Bytes(), nil
+}
+
+// LoadState restaura o estado gerado por SaveState. Se as taxas mudaram
+// desde o salvamento, as amostras pendentes são descartadas.
+func (b *Buffer) LoadState(data []byte) error {
+	r := bytes.NewReader(data)
+	var state bufferState
+	if err := binary.Read(r, binary.LittleEndian, &state); err != nil {
+		return fmt.Errorf("estado do resampler inválido: %w", err)
+	}
+	if state.Count < 0 || state.Avail < 0 || int64(r.Len()) != state.Count*8 {
+		return fmt.Errorf("estado do resampler inválido: %d posições em %d bytes", state.Count, r.Len())
+	}
+
+	if state.ClockRate != b.clockRate || state.SampleRate != b.sampleRate {
+		b.Clear()
+		return nil
+	}
+
+	samples := make([]float64, state.Count)
+	binary.Read(r, binary.LittleEndian, samples)
+
+	b.Clear()
+	b.ensure(int(state.Count))
+	copy(b.buf, samples)
+	b.offset = state.Offset
+	b.avail = int(state.Avail)
+	b.integrator = state.Integrator
+	b.ensure(b.avail + taps)
+	return nil
+}
@@ -0,0 +1,181 @@
+package blip
+
+import (
+	"math"
+	"testing"
+)
+
+const gbClock = 4194304
+
+func TestBufferStepSettles(t *testing.T) {
+	b := NewBuffer(gbClock, 44100)
+
+	b.AddDelta(100, 1000)
+	b.EndFrame(gbClock / 64)
+
+	out := make([]float64, b.SamplesAvailable())
+	n := b.ReadSamples(out)
+	if n != 44100/64 {
+		t.Fatalf("esperado %d amostras em 1/64s, obtido %d", 44100/64, n)
+	}
+
+	// Após a transição o degrau deve se estabilizar na nova amplitude
+	for _, sample := range out[HalfWidth*2:] {
+		if math.Abs(sample-1000) > 1e-6 {
+			t.Fatalf("amplitude deveria estabilizar em 1000, obtido %f", sample)
+		}
+	}
+}
+
+func TestBufferNoDriftWithShortFrames(t *testing.T) {
+	rates := []int{32000, 44100, 48000, 96000}
+
+	for _, rate := range rates {
+		b := NewBuffer(gbClock, rate)
+
+		// Um segundo emulado em passos curtos e irregulares (como instruções)
+		total := 0
+		steps := []int{4, 8, 12, 20, 24}
+		for i := 0; total < gbClock; i++ {
+			cycles := steps[i%len(steps)]
+			if total+cycles > gbClock {
+				cycles = gbClock - total
+			}
+			b.EndFrame(cycles)
+			total += cycles
+		}
+
+		if got := b.SamplesAvailable(); got != rate {
+			t.Errorf("%d Hz: esperado %d amostras em 1s, obtido %d", rate, rate, got)
+		}
+	}
+}
+
+func TestBufferClocksNeeded(t *testing.T) {
+	b := NewBuffer(gbClock, 48000)
+
+	clocks := b.ClocksNeeded(480)
+	b.EndFrame(clocks)
+	if got := b.SamplesAvailable(); got < 480 {
+		t.Errorf("ClocksNeeded(480)=%d gerou apenas %d amostras", clocks, got)
+	}
+}
+
+// squareRMS gera uma onda quadrada de frequência freq e retorna o RMS da saída
+func squareRMS(freq float64, rate int) float64 {
+	b := NewBuffer(gbClock, rate)
+
+	halfPeriod := float64(gbClock) / freq / 2
+	amplitude := 1000.0
+	level := -amplitude
+	b.AddDelta(0, level)
+
+	next := halfPeriod
+	for next < gbClock/10 {
+		b.AddDelta(int(next), -2*level)
+		level = -level
+		next += halfPeriod
+	}
+	b.EndFrame(gbClock / 10)
+
+	out := make([]float64, b.SamplesAvailable())
+	n := b.ReadSamples(out)
+
+	// Remove o nível DC antes de medir
+	mean := 0.0
+	for _, sample := range out[:n] {
+		mean += sample
+	}
+	mean /= float64(n)
+
+	sum := 0.0
+	for _, sample := range out[:n] {
+		sum += (sample - mean) * (sample - mean)
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+func TestBufferBandLimited(t *testing.T) {
+	audible := squareRMS(1000, 44100)
+	ultrasonic := squareRMS(40000, 44100)
+
+	if audible < 900 {
+		t.Errorf("onda de 1kHz deveria passar, RMS %f", audible)
+	}
+	// Sem filtragem, 40kHz geraria um alias de ~4kHz com amplitude cheia
+	if ultrasonic > audible*0.1 {
+		t.Errorf("onda de 40kHz deveria ser atenuada: RMS %f (1kHz: %f)", ultrasonic, audible)
+	}
+}
+
+func TestBufferRemoveSamplesKeepsLevel(t *testing.T) {
+	b := NewBuffer(gbClock, 44100)
+
+	b.AddDelta(0, 500)
+	b.EndFrame(gbClock / 100)
+	b.RemoveSamples(b.SamplesAvailable() - 1)
+
+	out := make([]float64, 1)
+	b.ReadSamples(out)
+	if math.Abs(out[0]-500) > 1e-6 {
+		t.Errorf("amplitude deveria continuar 500 após descartar amostras, obtido %f", out[0])
+	}
+}
+
+func TestStereoBufferReadInterleaved(t *testing.T) {
+	s := NewStereoBuffer(gbClock, 48000)
+
+	s.AddDelta(0, 100, -100)
+	s.EndFrame(gbClock / 64)
+
+	out := make([]int16, 2*s.SamplesAvailable())
+	n := s.ReadInterleaved(out, 2)
+	if n != 750 {
+		t.Fatalf("esperado 750 amostras estéreo, obtido %d", n)
+	}
+
+	last := out[len(out)-2:]
+	if last[0] != 200 || last[1] != -200 {
+		t.Errorf("esperado (200, -200), obtido (%d, %d)", last[0], last[1])
+	}
+}
+
+func TestBufferSaveState(t *testing.T) {
+	b := NewBuffer(gbClock, 44100)
+	b.AddDelta(10, 300)
+	b.EndFrame(gbClock / 100)
+	b.ReadSamples(make([]float64, 100))
+	b.AddDelta(50, -200) // Frame ainda aberto
+
+	state, err := b.SaveState()
+	if err != nil {
+		t.Fatalf("erro ao salvar: %v", err)
+	}
+
+	// Continua o original e a cópia restaurada da mesma forma
+	restored := NewBuffer(gbClock, 44100)
+	if err := restored.LoadState(state); err != nil {
+		t.Fatalf("erro ao restaurar: %v", err)
+	}
+	for _, buffer := range []*Buffer{b, restored} {
+		buffer.AddDelta(80, 50)
+		buffer.EndFrame(gbClock / 100)
+	}
+
+	want := make([]float64, b.SamplesAvailable())
+	got := make([]float64, restored.SamplesAvailable())
+	b.ReadSamples(want)
+	restored.ReadSamples(got)
+	if len(got) != len(want) {
+		t.Fatalf("esperado %d amostras, obtido %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("amostra %d: esperado %f, obtido %f", i, want[i], got[i])
+		}
+	}
+
+	if err := restored.LoadState(state[:len(state)-1]); err == nil {
+		t.Error("estado truncado deveria retornar erro")
+	}
+}
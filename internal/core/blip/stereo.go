@@ -0,0 +1,133 @@
+package blip
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// StereoBuffer agrupa dois Buffers (esquerdo e direito) com as mesmas taxas
+type StereoBuffer struct {
+	Left  *Buffer
+	Right *Buffer
+
+	scratch [2][]float64 // Buffers temporários de leitura
+}
+
+// NewStereoBuffer cria um novo StereoBuffer
+func NewStereoBuffer(clockRate, sampleRate int) *StereoBuffer {
+	return &StereoBuffer{
+		Left:  NewBuffer(clockRate, sampleRate),
+		Right: NewBuffer(clockRate, sampleRate),
+	}
+}
+
+// SetRates define o clock emulado e a taxa de saída dos dois canais
+func (s *StereoBuffer) SetRates(clockRate, sampleRate int) {
+	s.Left.SetRates(clockRate, sampleRate)
+	s.Right.SetRates(clockRate, sampleRate)
+}
+
+// SampleRate retorna a taxa de saída em Hz
+func (s *StereoBuffer) SampleRate() int {
+	return s.Left.SampleRate()
+}
+
+// Clear descarta todas as amostras dos dois canais
+func (s *StereoBuffer) Clear() {
+	s.Left.Clear()
+	s.Right.Clear()
+}
+
+// AddDelta registra mudanças de amplitude nos dois canais no ciclo clock
+func (s *StereoBuffer) AddDelta(clock int, left, right float64) {
+	s.Left.AddDelta(clock, left)
+	s.Right.AddDelta(clock, right)
+}
+
+// EndFrame fecha um frame de clocks ciclos nos dois canais
+func (s *StereoBuffer) EndFrame(clocks int) {
+	s.Left.EndFrame(clocks)
+	s.Right.EndFrame(clocks)
+}
+
+// SamplesAvailable retorna o número de amostras estéreo prontas
+func (s *StereoBuffer) SamplesAvailable() int {
+	return s.Left.SamplesAvailable()
+}
+
+// RemoveSamples descarta as count amostras estéreo mais antigas
+func (s *StereoBuffer) RemoveSamples(count int) {
+	s.Left.RemoveSamples(count)
+	s.Right.RemoveSamples(count)
+}
+
+// ReadStereo lê até len(left) amostras de cada canal, retornando quantas
+// foram lidas
+func (s *StereoBuffer) ReadStereo(left, right []float64) int {
+	count := s.Left.ReadSamples(left)
+	s.Right.ReadSamples(right[:count])
+	return count
+}
+
+// ReadInterleaved lê até len(out)/2 amostras estéreo em out, intercaladas
+// (L, R, L, R...), multiplicadas por gain e limitadas à faixa de int16.
+// Retorna o número de amostras estéreo lidas.
+func (s *StereoBuffer) ReadInterleaved(out []int16, gain float64) int {
+	frames := len(out) / 2
+	if frames > s.SamplesAvailable() {
+		frames = s.SamplesAvailable()
+	}
+
+	for i := range s.scratch {
+		if len(s.scratch[i]) < frames {
+			s.scratch[i] = make([]float64, frames)
+		}
+	}
+
+	left := s.scratch[0][:frames]
+	right := s.scratch[1][:frames]
+	s.ReadStereo(left, right)
+
+	for i := 0; i < frames; i++ {
+		out[i*2] = Clamp(left[i] * gain)
+		out[i*2+1] = Clamp(right[i] * gain)
+	}
+	return frames
+}
+
+// SaveState serializa os dois canais: o tamanho do esquerdo (uint32), o
+// esquerdo e o direito
+func (s *StereoBuffer) SaveState() ([]byte, error) {
+	left, _ := s.Left.SaveState()
+	right, _ := s.Right.SaveState()
+
+	data := binary.LittleEndian.AppendUint32(nil, uint32(len(left)))
+	data = append(data, left...)
+	return append(data, right...), nil
+}
+
+// LoadState restaura o estado gerado por SaveState
+func (s *StereoBuffer) LoadState(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("estado do resampler estéreo truncado")
+	}
+	size := int(binary.LittleEndian.Uint32(data))
+	if size > len(data)-4 {
+		return fmt.Errorf("estado do resampler estéreo truncado")
+	}
+	if err := s.Left.LoadState(data[4 : 4+size]); err != nil {
+		return err
+	}
+	return s.Right.LoadState(data[4+size:])
+}
+
+// Clamp converte uma amostra para int16, limitando à faixa válida
+func Clamp(value float64) int16 {
+	if value > 32767 {
+		return 32767
+	}
+	if value < -32768 {
+		return -32768
+	}
+	return int16(value)
+}
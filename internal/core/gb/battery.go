@@ -0,0 +1,154 @@
+package gb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SaveExtension é a extensão dos arquivos de RAM do cartucho
+const SaveExtension = ".sav"
+
+// SavePathFor retorna o caminho do arquivo .sav de uma ROM. Se savesDir
+// estiver vazio o arquivo fica ao lado da ROM.
+func SavePathFor(romPath string, savesDir string) string {
+	base := strings.TrimSuffix(filepath.Base(romPath), filepath.Ext(romPath)) + SaveExtension
+	if savesDir == "" {
+		return filepath.Join(filepath.Dir(romPath), base)
+	}
+	return filepath.Join(savesDir, base)
+}
+
+// LoadROMFile carrega uma ROM de arquivo e a RAM do cartucho salva em disco
+func (gb *GameBoy) LoadROMFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read ROM: %w", err)
+	}
+
+	if err := gb.LoadROM(data); err != nil {
+		return err
+	}
+
+	gb.savePath = SavePathFor(path, gb.config.SavesDir)
+	return gb.LoadBattery()
+}
+
+// SetSavePath define o arquivo .sav usado pela ROM carregada
+func (gb *GameBoy) SetSavePath(path string) {
+	gb.savePath = path
+}
+
+// GetSavePath retorna o arquivo .sav usado pela ROM carregada
+func (gb *GameBoy) GetSavePath() string {
+	return gb.savePath
+}
+
+// HasBattery retorna se o cartucho carregado possui RAM mantida por bateria
+func (gb *GameBoy) HasBattery() bool {
+	return gb.mmu.HasBattery()
+}
+
+// LoadBattery lê a RAM do cartucho do arquivo .sav, se existir
+func (gb *GameBoy) LoadBattery() error {
+	if !gb.mmu.HasBattery() || gb.savePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(gb.savePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read save file: %w", err)
+	}
+
+	if err := gb.mmu.LoadBatteryRAM(data); err != nil {
+		return fmt.Errorf("failed to load save file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadBatteryRAM carrega a RAM do cartucho a partir de dados no formato
+// do arquivo .sav, sem associar um arquivo à ROM
+func (gb *GameBoy) LoadBatteryRAM(data []byte) error {
+	if err := gb.mmu.LoadBatteryRAM(data); err != nil {
+		return fmt.Errorf("failed to load save data: %w", err)
+	}
+	return nil
+}
+
+// FlushBattery grava a RAM do cartucho no arquivo .sav
+func (gb *GameBoy) FlushBattery() error {
+	gb.framesSinceFlush = 0
+
+	if !gb.mmu.HasBattery() || gb.savePath == "" {
+		return nil
+	}
+
+	if err := writeFileAtomic(gb.savePath, gb.mmu.GetBatteryRAM()); err != nil {
+		return fmt.Errorf("failed to write save file: %w", err)
+	}
+
+	gb.mmu.ClearRAMDirty()
+	return nil
+}
+
+// checkBatteryFlush grava a RAM periodicamente quando o jogo a alterou
+func (gb *GameBoy) checkBatteryFlush() {
+	if gb.config.BatteryFlushFrames <= 0 {
+		return
+	}
+
+	gb.framesSinceFlush++
+	if gb.framesSinceFlush < gb.config.BatteryFlushFrames {
+		return
+	}
+	gb.framesSinceFlush = 0
+
+	if gb.mmu.IsRAMDirty() {
+		// Em caso de erro a RAM continua marcada e nova tentativa é feita
+		// no próximo intervalo
+		gb.FlushBattery()
+	}
+}
+
+// writeFileAtomic grava em um arquivo temporário e o renomeia, de modo que
+// uma interrupção no meio da escrita nunca trunca o arquivo original
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	return nil
+}
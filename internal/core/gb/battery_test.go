@@ -0,0 +1,150 @@
+package gb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestROM grava uma ROM mínima com o tipo de cartucho informado
+func writeTestROM(t *testing.T, dir string, cartridgeType, ramSize uint8) string {
+	rom := make([]uint8, 0x8000)
+	copy(rom[0x134:0x144], []byte("BATTERY TEST"))
+	rom[0x147] = cartridgeType
+	rom[0x149] = ramSize
+
+	path := filepath.Join(dir, "game.gb")
+	if err := os.WriteFile(path, rom, 0644); err != nil {
+		t.Fatalf("erro ao gravar ROM: %v", err)
+	}
+	return path
+}
+
+func TestBatterySaveRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	romPath := writeTestROM(t, dir, 0x03, 0x03) // MBC1+RAM+BATTERY, 32KB
+
+	gameboy := NewGameBoy(DefaultConfig())
+	if err := gameboy.LoadROMFile(romPath); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+	if !gameboy.HasBattery() {
+		t.Fatal("cartucho 0x03 deveria possuir bateria")
+	}
+
+	gameboy.mmu.Write(0x0000, 0x0A) // Habilita RAM
+	gameboy.mmu.Write(0xA123, 0x5A)
+	gameboy.Stop()
+
+	savePath := filepath.Join(dir, "game.sav")
+	if _, err := os.Stat(savePath); err != nil {
+		t.Fatalf("arquivo .sav não foi gravado: %v", err)
+	}
+
+	restored := NewGameBoy(DefaultConfig())
+	if err := restored.LoadROMFile(romPath); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+	restored.mmu.Write(0x0000, 0x0A)
+	if got := restored.mmu.Read(0xA123); got != 0x5A {
+		t.Errorf("RAM restaurada: esperado 0x5A, obtido 0x%02X", got)
+	}
+}
+
+func TestBatterySavesDir(t *testing.T) {
+	dir := t.TempDir()
+	savesDir := filepath.Join(dir, "saves")
+	romPath := writeTestROM(t, dir, 0x1B, 0x02) // MBC5+RAM+BATTERY
+
+	config := DefaultConfig()
+	config.SavesDir = savesDir
+
+	gameboy := NewGameBoy(config)
+	if err := gameboy.LoadROMFile(romPath); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+	if err := gameboy.FlushBattery(); err != nil {
+		t.Fatalf("erro ao gravar save: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(savesDir, "game.sav")); err != nil {
+		t.Errorf("arquivo .sav deveria estar em SavesDir: %v", err)
+	}
+}
+
+func TestBatteryPeriodicFlush(t *testing.T) {
+	dir := t.TempDir()
+	romPath := writeTestROM(t, dir, 0x03, 0x02)
+
+	config := DefaultConfig()
+	config.EnableVSync = false
+	config.BatteryFlushFrames = 2
+
+	gameboy := NewGameBoy(config)
+	if err := gameboy.LoadROMFile(romPath); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+
+	gameboy.mmu.Write(0x0000, 0x0A)
+	gameboy.mmu.Write(0xA000, 0x77)
+
+	gameboy.Start()
+	gameboy.checkBatteryFlush()
+	gameboy.checkBatteryFlush()
+
+	data, err := os.ReadFile(filepath.Join(dir, "game.sav"))
+	if err != nil {
+		t.Fatalf("RAM alterada deveria ter sido gravada: %v", err)
+	}
+	if data[0] != 0x77 {
+		t.Errorf("conteúdo do save: esperado 0x77, obtido 0x%02X", data[0])
+	}
+	if gameboy.mmu.IsRAMDirty() {
+		t.Error("RAM deveria estar marcada como salva")
+	}
+}
+
+func TestBatteryMBC2RAM(t *testing.T) {
+	dir := t.TempDir()
+	romPath := writeTestROM(t, dir, 0x06, 0x00) // MBC2+BATTERY
+
+	gameboy := NewGameBoy(DefaultConfig())
+	if err := gameboy.LoadROMFile(romPath); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+
+	gameboy.mmu.Write(0x0000, 0x0A)
+	gameboy.mmu.Write(0xA010, 0xAB)
+
+	// Apenas 4 bits são armazenados e a RAM é espelhada a cada 512 bytes
+	if got := gameboy.mmu.Read(0xA210); got != 0xFB {
+		t.Errorf("RAM MBC2: esperado 0xFB, obtido 0x%02X", got)
+	}
+
+	if err := gameboy.FlushBattery(); err != nil {
+		t.Fatalf("erro ao gravar save: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "game.sav"))
+	if err != nil {
+		t.Fatalf("erro ao ler save: %v", err)
+	}
+	if len(data) != 512 {
+		t.Errorf("tamanho do save MBC2: esperado 512, obtido %d", len(data))
+	}
+}
+
+func TestBatteryNoBatteryNoFile(t *testing.T) {
+	dir := t.TempDir()
+	romPath := writeTestROM(t, dir, 0x02, 0x02) // MBC1+RAM, sem bateria
+
+	gameboy := NewGameBoy(DefaultConfig())
+	if err := gameboy.LoadROMFile(romPath); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+	gameboy.Stop()
+
+	if _, err := os.Stat(filepath.Join(dir, "game.sav")); !os.IsNotExist(err) {
+		t.Error("cartucho sem bateria não deveria gerar arquivo .sav")
+	}
+}
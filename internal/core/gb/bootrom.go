@@ -0,0 +1,63 @@
+package gb
+
+import (
+	"fmt"
+	"os"
+)
+
+// LoadBootROM carrega uma boot ROM DMG (256 bytes) ou CGB (2304 bytes).
+// Com Config.EnableBootROM, o próximo Reset começa a execução em 0x0000 com a
+// boot ROM sobreposta à ROM do cartucho, até o jogo escrever em 0xFF50.
+// Se uma ROM já estiver carregada, o sistema é reiniciado.
+func (gb *GameBoy) LoadBootROM(data []uint8) error {
+	if err := gb.mmu.LoadBootROM(data); err != nil {
+		return fmt.Errorf("failed to load boot ROM: %w", err)
+	}
+
+	if gb.mmu.GetROMSize() > 0 {
+		gb.Reset()
+	}
+	return nil
+}
+
+// LoadBootROMFile carrega uma boot ROM a partir de um arquivo
+func (gb *GameBoy) LoadBootROMFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read boot ROM: %w", err)
+	}
+	return gb.LoadBootROM(data)
+}
+
+// SetBootROMEnabled habilita ou desabilita a execução da boot ROM no Reset
+func (gb *GameBoy) SetBootROMEnabled(enabled bool) {
+	gb.config.EnableBootROM = enabled
+}
+
+// IsBootROMMapped retorna se a boot ROM ainda está em execução (não
+// desmapeada por 0xFF50)
+func (gb *GameBoy) IsBootROMMapped() bool {
+	return gb.mmu.IsBootROMMapped()
+}
+
+// usesBootROM retorna se o Reset deve executar a boot ROM
+func (gb *GameBoy) usesBootROM() bool {
+	return gb.config.EnableBootROM && gb.mmu.HasBootROM()
+}
+
+// powerOn coloca o sistema no estado de power-on esperado pela boot ROM: CPU
+// em 0x0000 e LCD e APU desligados, que a própria boot ROM liga ao rodar o
+// logo e o som de inicialização.
+func (gb *GameBoy) powerOn() {
+	gb.cpu.SetPC(0x0000)
+	gb.cpu.SetSP(0x0000)
+	gb.cpu.SetAF(0x0000)
+	gb.cpu.SetBC(0x0000)
+	gb.cpu.SetDE(0x0000)
+	gb.cpu.SetHL(0x0000)
+
+	gb.mmu.Write(0xFF26, 0x00) // NR52: APU desligado
+	gb.mmu.Write(0xFF40, 0x00) // LCDC: LCD desligado
+	gb.mmu.Write(0xFF47, 0x00) // BGP
+	gb.mmu.Write(0xFFFF, 0x00) // IE
+}
@@ -0,0 +1,81 @@
+package gb
+
+import "testing"
+
+// newTestBootROM cria uma boot ROM DMG que apenas desmapeia a si mesma,
+// terminando em 0x00FF como a original
+func newTestBootROM() []uint8 {
+	boot := make([]uint8, 0x100) // NOPs
+	boot[0xFC] = 0x3E            // LD A, 0x01
+	boot[0xFD] = 0x01
+	boot[0xFE] = 0xE0 // LDH (0x50), A
+	boot[0xFF] = 0x50
+	return boot
+}
+
+func TestGameBoyLoadBootROMValidation(t *testing.T) {
+	gameboy := NewGameBoy(DefaultConfig())
+
+	if err := gameboy.LoadBootROM(make([]uint8, 300)); err == nil {
+		t.Error("Expected error for 300-byte boot ROM")
+	}
+	if err := gameboy.LoadBootROM(newTestBootROM()); err != nil {
+		t.Errorf("Failed to load boot ROM: %v", err)
+	}
+}
+
+func TestGameBoyBootROMExecution(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableBootROM = true
+	config.EnableVSync = false
+	gameboy := NewGameBoy(config)
+
+	if err := gameboy.LoadBootROM(newTestBootROM()); err != nil {
+		t.Fatalf("Failed to load boot ROM: %v", err)
+	}
+
+	rom := newLoopROM(0x00)
+	rom[0x0000] = 0xAA
+	if err := gameboy.LoadROM(rom); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+
+	if gameboy.cpu.GetPC() != 0x0000 {
+		t.Errorf("Expected PC=0x0000 with boot ROM, got 0x%04X", gameboy.cpu.GetPC())
+	}
+	if !gameboy.IsBootROMMapped() {
+		t.Fatal("Boot ROM should be mapped after reset")
+	}
+	if got := gameboy.mmu.Read(0x0000); got != 0x00 {
+		t.Errorf("Expected boot ROM byte at 0x0000, got 0x%02X", got)
+	}
+
+	gameboy.Start()
+	gameboy.Step()
+
+	if gameboy.IsBootROMMapped() {
+		t.Error("Boot ROM should be unmapped after writing 0xFF50")
+	}
+	if got := gameboy.mmu.Read(0x0000); got != 0xAA {
+		t.Errorf("Expected cartridge byte 0xAA at 0x0000, got 0x%02X", got)
+	}
+	if pc := gameboy.cpu.GetPC(); pc < 0x0100 || pc > 0x0101 {
+		t.Errorf("Expected PC in cartridge loop at 0x0100, got 0x%04X", pc)
+	}
+}
+
+func TestGameBoyBootROMDisabledSkipsBoot(t *testing.T) {
+	gameboy := NewGameBoy(DefaultConfig())
+	gameboy.LoadBootROM(newTestBootROM())
+
+	if err := gameboy.LoadROM(newLoopROM(0x00)); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+
+	if gameboy.cpu.GetPC() != 0x0100 {
+		t.Errorf("Expected PC=0x0100 without EnableBootROM, got 0x%04X", gameboy.cpu.GetPC())
+	}
+	if gameboy.IsBootROMMapped() {
+		t.Error("Boot ROM should not be mapped when disabled")
+	}
+}
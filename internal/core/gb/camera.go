@@ -0,0 +1,33 @@
+package gb
+
+import (
+	"github.com/hobbiee/visualboy-go/internal/core/gb/cartridge"
+)
+
+// ImageSource fornece as imagens vistas pelo sensor da Pocket Camera (ver
+// cartridge.ImageSource)
+type ImageSource = cartridge.ImageSource
+
+// SetCameraSource define as imagens capturadas pela Pocket Camera. A fonte
+// continua valendo para ROMs carregadas depois; nil faz o sensor ver um
+// cinza uniforme.
+func (gb *GameBoy) SetCameraSource(source ImageSource) {
+	gb.mmu.SetCameraSource(source)
+}
+
+// LoadCameraImages usa um PNG/JPEG (imagem fixa) ou um diretório de quadros
+// como imagem da Pocket Camera
+func (gb *GameBoy) LoadCameraImages(path string) error {
+	source, err := cartridge.LoadImageSource(path)
+	if err != nil {
+		return err
+	}
+	gb.SetCameraSource(source)
+	return nil
+}
+
+// HasCamera retorna se o cartucho carregado é uma Pocket Camera
+func (gb *GameBoy) HasCamera() bool {
+	_, ok := gb.mmu.GetCartridge().(*cartridge.Camera)
+	return ok
+}
@@ -0,0 +1,287 @@
+package cartridge
+
+import (
+	"fmt"
+	"image"
+)
+
+// Constantes da Pocket Camera
+const (
+	// 16 bancos de 8KB de SRAM
+	CameraRAMSize = 0x20000
+
+	// Resolução da imagem entregue ao jogo (16x14 tiles)
+	CameraWidth  = 128
+	CameraHeight = 112
+
+	// Posição da imagem capturada no banco 0 da SRAM
+	CameraImageOffset = 0x100
+
+	// Registradores do M64282FP (mapeados em 0xA000-0xA035 com o bit 4 de
+	// 0x4000-0x5FFF ligado, espelhados a cada 0x80 bytes)
+	CameraRegCount      = 0x36
+	CameraRegControl    = 0x00 // Bit 0: inicia a captura / ocupado
+	CameraRegParams     = 0x01 // Bit 7: N, bits 5-6: VH (realce), bits 0-4: ganho
+	CameraRegExposureHi = 0x02
+	CameraRegExposureLo = 0x03
+	CameraRegEdge       = 0x04 // Bits 4-6: intensidade do realce, bit 3: inverte
+	CameraRegVoltage    = 0x05
+	CameraRegMatrix     = 0x06 // Matriz de dither/contraste 4x4x3
+
+	// Exposição que mantém o brilho da imagem de entrada
+	CameraExposureUnit = 0x0800
+)
+
+// cameraEdgeRatios são as intensidades de realce de bordas (bits 4-6)
+var cameraEdgeRatios = [8]float64{0.50, 0.75, 1.00, 1.25, 2.00, 3.00, 4.00, 5.00}
+
+// Camera é o mapeador da Pocket Camera (0xFC) com o sensor M64282FP. As
+// imagens vêm de um ImageSource e passam por exposição, realce de bordas e
+// pela matriz de dither/contraste antes de serem gravadas na SRAM.
+type Camera struct {
+	base
+	regs   cameraRegisters
+	source ImageSource
+}
+
+// cameraRegisters guarda os registradores do mapeador e do sensor
+type cameraRegisters struct {
+	RAMWriteEnabled bool
+	ROMBank         uint8 // 6 bits
+	RAMBank         uint8 // 4 bits
+	SensorMapped    bool  // Bit 4 de 0x4000-0x5FFF
+	Sensor          [CameraRegCount]uint8
+	CaptureCycles   int32 // Ciclos restantes da captura em andamento
+}
+
+// newCamera cria um cartucho Pocket Camera; a SRAM é sempre de 128KB
+func newCamera(rom []uint8, ramSize int, features Features) Cartridge {
+	c := &Camera{base: newBase(rom, CameraRAMSize, features)}
+	c.Reset()
+	return c
+}
+
+// SetImageSource define as imagens vistas pelo sensor (nil = cinza médio)
+func (c *Camera) SetImageSource(source ImageSource) {
+	c.source = source
+}
+
+// Reset reinicia os registradores da câmera
+func (c *Camera) Reset() {
+	c.regs = cameraRegisters{ROMBank: 1}
+}
+
+// IsCapturing retorna se há uma captura em andamento
+func (c *Camera) IsCapturing() bool {
+	return c.regs.CaptureCycles > 0
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *Camera) ReadROM(addr uint16) uint8 {
+	if addr < 0x4000 {
+		return c.readROMBank(0, addr)
+	}
+	return c.readROMBank(int(c.regs.ROMBank), addr)
+}
+
+// ReadRAM lê a SRAM ou, com o sensor mapeado, o registrador de controle.
+// Os demais registradores do sensor são somente escrita e leem 0x00.
+func (c *Camera) ReadRAM(addr uint16) uint8 {
+	if !c.regs.SensorMapped {
+		return c.readRAMBank(int(c.regs.RAMBank), addr)
+	}
+
+	if addr&0x7F == CameraRegControl {
+		value := c.regs.Sensor[CameraRegControl] &^ 0x01
+		if c.IsCapturing() {
+			value |= 0x01
+		}
+		return value
+	}
+	return 0x00
+}
+
+// WriteControl escreve nos registradores do mapeador
+func (c *Camera) WriteControl(addr uint16, value uint8) {
+	switch {
+	case addr <= 0x1FFF:
+		c.regs.RAMWriteEnabled = ramEnableValue(value)
+	case addr <= 0x3FFF:
+		c.regs.ROMBank = value & 0x3F
+	case addr <= 0x5FFF:
+		c.regs.SensorMapped = value&0x10 != 0
+		if !c.regs.SensorMapped {
+			c.regs.RAMBank = value & 0x0F
+		}
+	}
+}
+
+// WriteRAM escreve na SRAM ou em um registrador do sensor
+func (c *Camera) WriteRAM(addr uint16, value uint8) {
+	if !c.regs.SensorMapped {
+		// A SRAM não pode ser escrita durante uma captura
+		if c.regs.RAMWriteEnabled && !c.IsCapturing() {
+			c.writeRAMBank(int(c.regs.RAMBank), addr, value)
+		}
+		return
+	}
+
+	reg := addr & 0x7F
+	if reg >= CameraRegCount {
+		return
+	}
+
+	if reg == CameraRegControl {
+		c.regs.Sensor[CameraRegControl] = value & 0x07
+		if value&0x01 != 0 && !c.IsCapturing() {
+			c.regs.CaptureCycles = int32(c.captureDuration())
+		}
+		return
+	}
+	c.regs.Sensor[reg] = value
+}
+
+// exposure retorna o tempo de exposição (registradores 2-3)
+func (c *Camera) exposure() int {
+	return int(c.regs.Sensor[CameraRegExposureHi])<<8 | int(c.regs.Sensor[CameraRegExposureLo])
+}
+
+// captureDuration retorna a duração da captura em ciclos de CPU:
+// 32446 + (N ? 0 : 512) + 16 * exposição, em M-cycles
+func (c *Camera) captureDuration() int {
+	mcycles := 32446 + 16*c.exposure()
+	if c.regs.Sensor[CameraRegParams]&0x80 == 0 {
+		mcycles += 512
+	}
+	return mcycles * 4
+}
+
+// Step avança a captura; ao terminar, a imagem é gravada na SRAM
+func (c *Camera) Step(cycles int) {
+	if !c.IsCapturing() {
+		return
+	}
+
+	c.regs.CaptureCycles -= int32(cycles)
+	if c.regs.CaptureCycles <= 0 {
+		c.regs.CaptureCycles = 0
+		c.capture()
+	}
+}
+
+// capture lê um quadro do ImageSource, processa como o M64282FP e grava o
+// resultado em 2bpp no banco 0 da SRAM
+func (c *Camera) capture() {
+	var img image.Image
+	if c.source != nil {
+		img = c.source.NextFrame()
+	}
+	frame := sampleSensor(img)
+
+	var exposed [CameraHeight][CameraWidth]float64
+	scale := float64(c.exposure()) / CameraExposureUnit
+	for y := range exposed {
+		for x := range exposed[y] {
+			exposed[y][x] = float64(frame[y][x]) * scale
+		}
+	}
+
+	edgeMode := (c.regs.Sensor[CameraRegParams] >> 5) & 0x03
+	ratio := cameraEdgeRatios[(c.regs.Sensor[CameraRegEdge]>>4)&0x07]
+	invert := c.regs.Sensor[CameraRegEdge]&0x08 != 0
+
+	for y := 0; y < CameraHeight; y++ {
+		for x := 0; x < CameraWidth; x++ {
+			value := exposed[y][x] + ratio*edge(&exposed, x, y, edgeMode)
+			if invert {
+				value = 255 - value
+			}
+			c.writeImagePixel(x, y, c.dither(x, y, value))
+		}
+	}
+	c.ramDirty = true
+}
+
+// edge calcula o laplaciano usado no realce de bordas: horizontal (VH=1),
+// vertical (VH=2) ou 2D (VH=3)
+func edge(img *[CameraHeight][CameraWidth]float64, x, y int, mode uint8) float64 {
+	at := func(x, y int) float64 {
+		x = clampInt(x, 0, CameraWidth-1)
+		y = clampInt(y, 0, CameraHeight-1)
+		return img[y][x]
+	}
+
+	center := img[y][x]
+	switch mode {
+	case 1:
+		return (2*center - at(x-1, y) - at(x+1, y)) / 2
+	case 2:
+		return (2*center - at(x, y-1) - at(x, y+1)) / 2
+	case 3:
+		return (4*center - at(x-1, y) - at(x+1, y) - at(x, y-1) - at(x, y+1)) / 4
+	default:
+		return 0
+	}
+}
+
+// dither converte o valor analógico em uma das 4 cores usando os três
+// limiares da posição (x, y) na matriz 4x4. Valores abaixo do primeiro
+// limiar são pretos (3); acima do último, brancos (0).
+func (c *Camera) dither(x, y int, value float64) uint8 {
+	offset := CameraRegMatrix + ((y&3)*4+(x&3))*3
+	thresholds := c.regs.Sensor[offset : offset+3]
+
+	switch {
+	case value < float64(thresholds[0]):
+		return 3
+	case value < float64(thresholds[1]):
+		return 2
+	case value < float64(thresholds[2]):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// writeImagePixel grava uma cor no tile correspondente da imagem em SRAM
+func (c *Camera) writeImagePixel(x, y int, color uint8) {
+	tile := (y/8)*(CameraWidth/8) + x/8
+	index := CameraImageOffset + tile*16 + (y&7)*2
+	bit := uint8(0x80) >> (x & 7)
+
+	c.ram[index] &^= bit
+	c.ram[index+1] &^= bit
+	if color&0x01 != 0 {
+		c.ram[index] |= bit
+	}
+	if color&0x02 != 0 {
+		c.ram[index+1] |= bit
+	}
+}
+
+// clampInt limita value ao intervalo [lo, hi]
+func clampInt(value, lo, hi int) int {
+	if value < lo {
+		return lo
+	}
+	if value > hi {
+		return hi
+	}
+	return value
+}
+
+// SaveState serializa os registradores e a SRAM
+func (c *Camera) SaveState() ([]byte, error) {
+	return c.marshalState(&c.regs)
+}
+
+// LoadState restaura os registradores e a SRAM
+func (c *Camera) LoadState(data []byte) error {
+	return c.unmarshalState(data, &c.regs)
+}
+
+// String retorna uma representação em string do cartucho
+func (c *Camera) String() string {
+	return fmt.Sprintf("Pocket Camera: ROM=%dKB ROMBank=%d RAMBank=%d Sensor=%v Capturing=%v",
+		len(c.rom)/1024, int(c.regs.ROMBank)%c.romBanks, c.regs.RAMBank, c.regs.SensorMapped, c.IsCapturing())
+}
@@ -0,0 +1,141 @@
+package cartridge
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // Registra o decodificador JPEG
+	_ "image/png"  // Registra o decodificador PNG
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ImageSource fornece as imagens vistas pelo sensor da Pocket Camera. Cada
+// captura solicitada pelo jogo consome um quadro.
+type ImageSource interface {
+	NextFrame() image.Image
+}
+
+// StaticImageSource retorna sempre a mesma imagem
+type StaticImageSource struct {
+	img image.Image
+}
+
+// NewStaticImageSource cria uma fonte com uma imagem fixa
+func NewStaticImageSource(img image.Image) *StaticImageSource {
+	return &StaticImageSource{img: img}
+}
+
+// NextFrame retorna a imagem fixa
+func (s *StaticImageSource) NextFrame() image.Image {
+	return s.img
+}
+
+// FrameSequenceSource percorre uma lista de quadros, voltando ao primeiro
+// após o último
+type FrameSequenceSource struct {
+	frames []image.Image
+	next   int
+}
+
+// NewFrameSequenceSource cria uma fonte com os quadros informados
+func NewFrameSequenceSource(frames []image.Image) *FrameSequenceSource {
+	return &FrameSequenceSource{frames: frames}
+}
+
+// NextFrame retorna o próximo quadro da sequência
+func (s *FrameSequenceSource) NextFrame() image.Image {
+	if len(s.frames) == 0 {
+		return nil
+	}
+	frame := s.frames[s.next]
+	s.next = (s.next + 1) % len(s.frames)
+	return frame
+}
+
+// LoadImageSource abre um PNG/JPEG como imagem fixa ou, se path for um
+// diretório, os PNG/JPEG dele em ordem alfabética como sequência de quadros
+func LoadImageSource(path string) (ImageSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir imagem da câmera: %w", err)
+	}
+
+	if !info.IsDir() {
+		img, err := decodeImageFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return NewStaticImageSource(img), nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar quadros da câmera: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".png", ".jpg", ".jpeg":
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("nenhuma imagem PNG/JPEG em %s", path)
+	}
+	sort.Strings(names)
+
+	frames := make([]image.Image, 0, len(names))
+	for _, name := range names {
+		img, err := decodeImageFile(filepath.Join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, img)
+	}
+	return NewFrameSequenceSource(frames), nil
+}
+
+// decodeImageFile decodifica um arquivo PNG ou JPEG
+func decodeImageFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir imagem da câmera: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao decodificar %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// sampleSensor reduz a imagem à resolução do sensor em tons de cinza
+// (vizinho mais próximo). Sem imagem, o sensor vê um cinza médio.
+func sampleSensor(img image.Image) *[CameraHeight][CameraWidth]uint8 {
+	var pixels [CameraHeight][CameraWidth]uint8
+	if img == nil {
+		for y := range pixels {
+			for x := range pixels[y] {
+				pixels[y][x] = 0x80
+			}
+		}
+		return &pixels
+	}
+
+	bounds := img.Bounds()
+	for y := 0; y < CameraHeight; y++ {
+		sy := bounds.Min.Y + y*bounds.Dy()/CameraHeight
+		for x := 0; x < CameraWidth; x++ {
+			sx := bounds.Min.X + x*bounds.Dx()/CameraWidth
+			pixels[y][x] = color.GrayModel.Convert(img.At(sx, sy)).(color.Gray).Y
+		}
+	}
+	return &pixels
+}
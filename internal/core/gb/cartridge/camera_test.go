@@ -0,0 +1,215 @@
+package cartridge
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestCamera cria uma Pocket Camera com o sensor mapeado, exposição
+// neutra e a matriz com limiares 0x40/0x80/0xC0 em todas as posições
+func newTestCamera(t *testing.T, source ImageSource) *Camera {
+	cart := newCartridge(t, newBankedROM(0xFC, 64, 0x04)).(*Camera)
+	cart.SetImageSource(source)
+
+	cart.WriteControl(0x4000, 0x10)
+	cart.WriteRAM(0xA000+CameraRegExposureHi, CameraExposureUnit>>8)
+	cart.WriteRAM(0xA000+CameraRegExposureLo, CameraExposureUnit&0xFF)
+	for i := 0; i < 16; i++ {
+		cart.WriteRAM(0xA000+CameraRegMatrix+uint16(i*3), 0x40)
+		cart.WriteRAM(0xA000+CameraRegMatrix+uint16(i*3+1), 0x80)
+		cart.WriteRAM(0xA000+CameraRegMatrix+uint16(i*3+2), 0xC0)
+	}
+	return cart
+}
+
+// uniformImage cria uma imagem de um único tom de cinza
+func uniformImage(gray uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, 256, 224))
+	for i := range img.Pix {
+		img.Pix[i] = gray
+	}
+	return img
+}
+
+// captureCamera dispara uma captura e avança até o fim
+func captureCamera(t *testing.T, cart *Camera) {
+	cart.WriteControl(0x4000, 0x10)
+	cart.WriteRAM(0xA000, 0x01)
+	if cart.ReadRAM(0xA000)&0x01 == 0 {
+		t.Fatal("registrador de controle deveria indicar captura em andamento")
+	}
+
+	cart.Step(cart.captureDuration() - 4)
+	if !cart.IsCapturing() {
+		t.Fatal("captura terminou antes do tempo")
+	}
+	cart.Step(4)
+	if cart.ReadRAM(0xA000)&0x01 != 0 {
+		t.Fatal("captura deveria ter terminado")
+	}
+	cart.WriteControl(0x4000, 0x00)
+}
+
+// cameraPixel lê a cor de um pixel da imagem capturada
+func cameraPixel(cart *Camera, x, y int) uint8 {
+	tile := (y/8)*(CameraWidth/8) + x/8
+	index := CameraImageOffset + tile*16 + (y&7)*2
+	bit := 7 - uint(x&7)
+	return (cart.ram[index]>>bit)&1 | ((cart.ram[index+1]>>bit)&1)<<1
+}
+
+func TestCameraRegisters(t *testing.T) {
+	cart := newCartridge(t, newBankedROM(0xFC, 64, 0x00))
+	if cart.RAMSize() != CameraRAMSize {
+		t.Errorf("SRAM: esperado %d bytes, obtido %d", CameraRAMSize, cart.RAMSize())
+	}
+
+	// Banco 15 da SRAM
+	cart.WriteControl(0x0000, 0x0A)
+	cart.WriteControl(0x4000, 0x0F)
+	cart.WriteRAM(0xA000, 0x42)
+	if data := cart.BatteryData(); data[15*RAMBankSize] != 0x42 {
+		t.Error("banco 15 da SRAM gravado no lugar errado")
+	}
+
+	// Registradores do sensor: somente escrita, exceto o controle
+	cart.WriteControl(0x4000, 0x10)
+	cart.WriteRAM(0xA001, 0xE0)
+	if got := cart.ReadRAM(0xA001); got != 0x00 {
+		t.Errorf("registrador somente escrita: esperado 0x00, obtido 0x%02X", got)
+	}
+	cart.WriteRAM(0xA000, 0x06)
+	if got := cart.ReadRAM(0xA080); got != 0x06 {
+		t.Errorf("controle espelhado em 0xA080: esperado 0x06, obtido 0x%02X", got)
+	}
+
+	cart.WriteControl(0x4000, 0x0F)
+	if got := cart.ReadRAM(0xA000); got != 0x42 {
+		t.Errorf("SRAM após desmapear o sensor: esperado 0x42, obtido 0x%02X", got)
+	}
+}
+
+func TestCameraDithering(t *testing.T) {
+	testCases := []struct {
+		gray uint8
+		want uint8
+	}{
+		{0x20, 3},
+		{0x60, 2},
+		{0xA0, 1},
+		{0xE0, 0},
+	}
+
+	for _, tc := range testCases {
+		cart := newTestCamera(t, NewStaticImageSource(uniformImage(tc.gray)))
+		captureCamera(t, cart)
+		for _, p := range [][2]int{{0, 0}, {127, 111}, {64, 57}} {
+			if got := cameraPixel(cart, p[0], p[1]); got != tc.want {
+				t.Errorf("cinza 0x%02X em %v: esperado cor %d, obtido %d", tc.gray, p, tc.want, got)
+			}
+		}
+	}
+}
+
+func TestCameraExposureAndInvert(t *testing.T) {
+	cart := newTestCamera(t, NewStaticImageSource(uniformImage(0x60)))
+
+	// Dobrar a exposição clareia a imagem
+	cart.WriteRAM(0xA000+CameraRegExposureHi, (2*CameraExposureUnit)>>8)
+	captureCamera(t, cart)
+	if got := cameraPixel(cart, 10, 10); got != 0 {
+		t.Errorf("exposição dobrada: esperado cor 0, obtido %d", got)
+	}
+
+	cart.WriteControl(0x4000, 0x10)
+	cart.WriteRAM(0xA000+CameraRegExposureHi, CameraExposureUnit>>8)
+	cart.WriteRAM(0xA000+CameraRegEdge, 0x08)
+	captureCamera(t, cart)
+	if got := cameraPixel(cart, 10, 10); got != 1 {
+		t.Errorf("imagem invertida: esperado cor 1, obtido %d", got)
+	}
+}
+
+func TestCameraEdgeEnhancement(t *testing.T) {
+	// Metade esquerda escura, direita clara
+	img := image.NewGray(image.Rect(0, 0, CameraWidth, CameraHeight))
+	for y := 0; y < CameraHeight; y++ {
+		for x := 0; x < CameraWidth; x++ {
+			if x >= CameraWidth/2 {
+				img.SetGray(x, y, color.Gray{Y: 0xB0})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: 0x50})
+			}
+		}
+	}
+
+	cart := newTestCamera(t, NewStaticImageSource(img))
+	captureCamera(t, cart)
+	if cameraPixel(cart, 63, 50) != 2 || cameraPixel(cart, 64, 50) != 1 {
+		t.Fatalf("sem realce: obtido %d e %d", cameraPixel(cart, 63, 50), cameraPixel(cart, 64, 50))
+	}
+
+	// Realce horizontal com intensidade 2 acentua a borda
+	cart.WriteControl(0x4000, 0x10)
+	cart.WriteRAM(0xA000+CameraRegParams, 0x20)
+	cart.WriteRAM(0xA000+CameraRegEdge, 0x40)
+	captureCamera(t, cart)
+	if got := cameraPixel(cart, 63, 50); got != 3 {
+		t.Errorf("lado escuro da borda: esperado cor 3, obtido %d", got)
+	}
+	if got := cameraPixel(cart, 64, 50); got != 0 {
+		t.Errorf("lado claro da borda: esperado cor 0, obtido %d", got)
+	}
+	if got := cameraPixel(cart, 10, 50); got != 2 {
+		t.Errorf("longe da borda: esperado cor 2, obtido %d", got)
+	}
+}
+
+// writePNG grava uma imagem uniforme como PNG
+func writePNG(t *testing.T, path string, gray uint8) {
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("erro ao criar %s: %v", path, err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, uniformImage(gray)); err != nil {
+		t.Fatalf("erro ao gravar PNG: %v", err)
+	}
+}
+
+func TestCameraImageSources(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "1.png"), 0x20)
+	writePNG(t, filepath.Join(dir, "2.png"), 0xE0)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignorado"), 0644)
+
+	static, err := LoadImageSource(filepath.Join(dir, "2.png"))
+	if err != nil {
+		t.Fatalf("erro ao abrir PNG: %v", err)
+	}
+	if _, ok := static.(*StaticImageSource); !ok {
+		t.Errorf("arquivo deveria gerar StaticImageSource, obtido %T", static)
+	}
+
+	frames, err := LoadImageSource(dir)
+	if err != nil {
+		t.Fatalf("erro ao abrir diretório: %v", err)
+	}
+
+	// Cada captura consome um quadro, voltando ao primeiro
+	cart := newTestCamera(t, frames)
+	for i, want := range []uint8{3, 0, 3} {
+		captureCamera(t, cart)
+		if got := cameraPixel(cart, 0, 0); got != want {
+			t.Errorf("captura %d: esperado cor %d, obtido %d", i, want, got)
+		}
+	}
+
+	if _, err := LoadImageSource(t.TempDir()); err == nil {
+		t.Error("diretório sem imagens deveria retornar erro")
+	}
+}
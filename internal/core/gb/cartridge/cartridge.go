@@ -0,0 +1,337 @@
+package cartridge
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Constantes do cartucho
+const (
+	// Tamanhos dos bancos
+	ROMBankSize = 0x4000 // 16KB
+	RAMBankSize = 0x2000 // 8KB
+
+	// RAM interna do MBC2 (512 x 4 bits)
+	MBC2RAMSize = 0x200
+
+	// Tamanho mínimo de uma ROM (dois bancos)
+	MinROMSize = 2 * ROMBankSize
+)
+
+// NintendoLogo é o bitmap verificado pela boot ROM em 0x104-0x133
+var NintendoLogo = [48]uint8{
+	0xCE, 0xED, 0x66, 0x66, 0xCC, 0x0D, 0x00, 0x0B, 0x03, 0x73, 0x00, 0x83,
+	0x00, 0x0C, 0x00, 0x0D, 0x00, 0x08, 0x11, 0x1F, 0x88, 0x89, 0x00, 0x0E,
+	0xDC, 0xCC, 0x6E, 0xE6, 0xDD, 0xDD, 0xD9, 0x99, 0xBB, 0xBB, 0x67, 0x63,
+	0x6E, 0x0E, 0xEC, 0xCC, 0xDD, 0xDC, 0x99, 0x9F, 0xBB, 0xB9, 0x33, 0x3E,
+}
+
+// ErrUnsupportedMapper é a causa dos erros de tipos de cartucho sem mapeador
+var ErrUnsupportedMapper = errors.New("mapeador não suportado")
+
+// UnsupportedMapperError informa o byte 0x147 de um cartucho que não pode
+// ser emulado
+type UnsupportedMapperError struct {
+	Type uint8
+}
+
+// Error implementa a interface error
+func (e *UnsupportedMapperError) Error() string {
+	if name, ok := unsupportedNames[e.Type]; ok {
+		return fmt.Sprintf("%v: tipo de cartucho 0x%02X (%s)", ErrUnsupportedMapper, e.Type, name)
+	}
+	return fmt.Sprintf("%v: tipo de cartucho 0x%02X", ErrUnsupportedMapper, e.Type)
+}
+
+// Unwrap permite comparar com errors.Is(err, ErrUnsupportedMapper)
+func (e *UnsupportedMapperError) Unwrap() error {
+	return ErrUnsupportedMapper
+}
+
+// unsupportedNames nomeia tipos conhecidos que ainda não possuem mapeador
+var unsupportedNames = map[uint8]string{
+	0xFD: "BANDAI TAMA5",
+}
+
+// Cartridge é implementado por cada mapeador (MBC). O MMU encaminha os
+// acessos de 0x0000-0x7FFF e 0xA000-0xBFFF para o cartucho.
+type Cartridge interface {
+	// ReadROM lê um byte de 0x0000-0x7FFF
+	ReadROM(addr uint16) uint8
+
+	// ReadRAM lê um byte da janela de RAM externa (0xA000-0xBFFF)
+	ReadRAM(addr uint16) uint8
+
+	// WriteControl escreve nos registradores do mapeador (0x0000-0x7FFF)
+	WriteControl(addr uint16, value uint8)
+
+	// WriteRAM escreve na janela de RAM externa (0xA000-0xBFFF)
+	WriteRAM(addr uint16, value uint8)
+
+	// Step avança o hardware do cartucho (RTC, sensores...) em ciclos do
+	// clock fixo de 4,19 MHz, que não muda em velocidade dupla
+	Step(cycles int)
+
+	// Reset reinicia os registradores do mapeador
+	Reset()
+
+	// HasBattery retorna se a RAM/RTC é mantida por bateria
+	HasBattery() bool
+
+	// RAMSize retorna o tamanho da RAM externa em bytes
+	RAMSize() int
+
+	// IsRAMDirty retorna se a RAM foi alterada desde o último salvamento
+	IsRAMDirty() bool
+
+	// ClearRAMDirty marca a RAM como salva
+	ClearRAMDirty()
+
+	// BatteryData retorna o conteúdo persistente do arquivo .sav
+	BatteryData() []byte
+
+	// LoadBatteryData restaura o conteúdo gerado por BatteryData
+	LoadBatteryData(data []byte) error
+
+	// SaveState serializa os registradores do mapeador e a RAM
+	SaveState() ([]byte, error)
+
+	// LoadState restaura o estado gerado por SaveState
+	LoadState(data []byte) error
+
+	fmt.Stringer
+}
+
+// Rumbler é implementado por cartuchos com motor de vibração
+type Rumbler interface {
+	// HasRumble retorna se o motor está presente
+	HasRumble() bool
+
+	// MotorOn retorna se o motor está ligado
+	MotorOn() bool
+
+	// RumbleFrame retorna o estado do motor e a fração dos ciclos em que
+	// ele esteve ligado desde a chamada anterior
+	RumbleFrame() (on bool, duty float64)
+}
+
+// Features descreve o hardware extra indicado pelo byte 0x147
+type Features struct {
+	RAM     bool
+	Battery bool
+	Timer   bool
+	Rumble  bool
+}
+
+// Mapper descreve um tipo de cartucho do registro
+type Mapper struct {
+	Name     string
+	Features Features
+	New      func(rom []uint8, ramSize int, features Features) Cartridge
+}
+
+// registry associa o byte 0x147 do header ao mapeador
+var registry = map[uint8]Mapper{
+	0x00: {"ROM ONLY", Features{}, newROMOnly},
+	0x01: {"MBC1", Features{}, newMBC1},
+	0x02: {"MBC1+RAM", Features{RAM: true}, newMBC1},
+	0x03: {"MBC1+RAM+BATTERY", Features{RAM: true, Battery: true}, newMBC1},
+	0x05: {"MBC2", Features{RAM: true}, newMBC2},
+	0x06: {"MBC2+BATTERY", Features{RAM: true, Battery: true}, newMBC2},
+	0x08: {"ROM+RAM", Features{RAM: true}, newROMOnly},
+	0x09: {"ROM+RAM+BATTERY", Features{RAM: true, Battery: true}, newROMOnly},
+	0x0B: {"MMM01", Features{}, newMMM01},
+	0x0C: {"MMM01+RAM", Features{RAM: true}, newMMM01},
+	0x0D: {"MMM01+RAM+BATTERY", Features{RAM: true, Battery: true}, newMMM01},
+	0x0F: {"MBC3+TIMER+BATTERY", Features{Timer: true, Battery: true}, newMBC3},
+	0x10: {"MBC3+TIMER+RAM+BATTERY", Features{Timer: true, RAM: true, Battery: true}, newMBC3},
+	0x11: {"MBC3", Features{}, newMBC3},
+	0x12: {"MBC3+RAM", Features{RAM: true}, newMBC3},
+	0x13: {"MBC3+RAM+BATTERY", Features{RAM: true, Battery: true}, newMBC3},
+	0x19: {"MBC5", Features{}, newMBC5},
+	0x1A: {"MBC5+RAM", Features{RAM: true}, newMBC5},
+	0x1B: {"MBC5+RAM+BATTERY", Features{RAM: true, Battery: true}, newMBC5},
+	0x1C: {"MBC5+RUMBLE", Features{Rumble: true}, newMBC5},
+	0x1D: {"MBC5+RUMBLE+RAM", Features{Rumble: true, RAM: true}, newMBC5},
+	0x1E: {"MBC5+RUMBLE+RAM+BATTERY", Features{Rumble: true, RAM: true, Battery: true}, newMBC5},
+	0x20: {"MBC6", Features{RAM: true, Battery: true}, newMBC6},
+	0x22: {"MBC7+SENSOR+RUMBLE+RAM+BATTERY", Features{Rumble: true, RAM: true, Battery: true}, newMBC7},
+	0xFC: {"POCKET CAMERA", Features{RAM: true, Battery: true}, newCamera},
+	0xFE: {"HuC3", Features{Timer: true, RAM: true, Battery: true}, newHuC3},
+	0xFF: {"HuC1+RAM+BATTERY", Features{RAM: true, Battery: true}, newHuC1},
+}
+
+// Register adiciona (ou substitui) o mapeador de um tipo de cartucho
+func Register(cartridgeType uint8, mapper Mapper) {
+	registry[cartridgeType] = mapper
+}
+
+// Lookup retorna o mapeador registrado para o byte 0x147
+func Lookup(cartridgeType uint8) (Mapper, bool) {
+	mapper, ok := registry[cartridgeType]
+	return mapper, ok
+}
+
+// New cria o cartucho indicado pelo header da ROM
+func New(rom []uint8) (Cartridge, error) {
+	if len(rom) < MinROMSize {
+		return nil, fmt.Errorf("ROM muito pequena: %d bytes", len(rom))
+	}
+
+	mapper, ok := Lookup(rom[HeaderCartridgeType])
+	if !ok {
+		return nil, &UnsupportedMapperError{Type: rom[HeaderCartridgeType]}
+	}
+
+	return mapper.New(rom, RAMSizeFromHeader(rom[HeaderRAMSize]), mapper.Features), nil
+}
+
+// RAMSizeFromHeader decodifica o byte 0x149 em bytes
+func RAMSizeFromHeader(code uint8) int {
+	switch code {
+	case 0x01:
+		return 0x800 // 2KB
+	case 0x02:
+		return 0x2000 // 8KB
+	case 0x03:
+		return 0x8000 // 32KB
+	case 0x04:
+		return 0x20000 // 128KB
+	case 0x05:
+		return 0x10000 // 64KB
+	default:
+		return 0
+	}
+}
+
+// base reúne o estado comum aos mapeadores: ROM, RAM externa e bateria
+type base struct {
+	rom      []uint8
+	ram      []uint8
+	romBanks int
+	battery  bool
+	ramDirty bool
+}
+
+// newBase cria o estado comum com a RAM do tamanho informado
+func newBase(rom []uint8, ramSize int, features Features) base {
+	romBanks := len(rom) / ROMBankSize
+	if romBanks == 0 {
+		romBanks = 1
+	}
+
+	var ram []uint8
+	if ramSize > 0 {
+		ram = make([]uint8, ramSize)
+	}
+
+	return base{
+		rom:      rom,
+		ram:      ram,
+		romBanks: romBanks,
+		battery:  features.Battery,
+	}
+}
+
+// readROMBank lê o offset (0-0x3FFF) do banco de ROM informado. Bancos
+// além do tamanho da ROM são espelhados, como nos chips reais.
+func (b *base) readROMBank(bank int, offset uint16) uint8 {
+	index := (bank%b.romBanks)*ROMBankSize + int(offset&0x3FFF)
+	if index < len(b.rom) {
+		return b.rom[index]
+	}
+	return 0xFF
+}
+
+// ramIndex converte banco e endereço 0xA000-0xBFFF em um índice da RAM
+func (b *base) ramIndex(bank int, addr uint16) int {
+	return (bank*RAMBankSize + int(addr&0x1FFF)) % len(b.ram)
+}
+
+// readRAMBank lê da RAM externa; sem RAM o barramento flutua em 0xFF
+func (b *base) readRAMBank(bank int, addr uint16) uint8 {
+	if len(b.ram) == 0 {
+		return 0xFF
+	}
+	return b.ram[b.ramIndex(bank, addr)]
+}
+
+// writeRAMBank escreve na RAM externa
+func (b *base) writeRAMBank(bank int, addr uint16, value uint8) {
+	if len(b.ram) == 0 {
+		return
+	}
+	b.ram[b.ramIndex(bank, addr)] = value
+	b.ramDirty = true
+}
+
+// Step não faz nada para cartuchos sem hardware extra
+func (b *base) Step(cycles int) {}
+
+// HasBattery retorna se a RAM/RTC é mantida por bateria
+func (b *base) HasBattery() bool {
+	return b.battery
+}
+
+// RAMSize retorna o tamanho da RAM externa em bytes
+func (b *base) RAMSize() int {
+	return len(b.ram)
+}
+
+// IsRAMDirty retorna se a RAM foi alterada desde o último salvamento
+func (b *base) IsRAMDirty() bool {
+	return b.ramDirty
+}
+
+// ClearRAMDirty marca a RAM como salva
+func (b *base) ClearRAMDirty() {
+	b.ramDirty = false
+}
+
+// BatteryData retorna uma cópia da RAM externa
+func (b *base) BatteryData() []byte {
+	data := make([]byte, len(b.ram))
+	copy(data, b.ram)
+	return data
+}
+
+// LoadBatteryData restaura a RAM externa; bytes extras são ignorados
+func (b *base) LoadBatteryData(data []byte) error {
+	if len(data) < len(b.ram) {
+		return fmt.Errorf("save muito pequeno: %d bytes (esperado %d)", len(data), len(b.ram))
+	}
+	copy(b.ram, data)
+	b.ramDirty = false
+	return nil
+}
+
+// marshalState serializa os registradores do mapeador (struct de tamanho
+// fixo) seguidos da RAM externa
+func (b *base) marshalState(regs any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, regs); err != nil {
+		return nil, fmt.Errorf("erro ao serializar o mapeador: %w", err)
+	}
+	buf.Write(b.ram)
+	return buf.Bytes(), nil
+}
+
+// unmarshalState restaura o estado gerado por marshalState
+func (b *base) unmarshalState(data []byte, regs any) error {
+	r := bytes.NewReader(data)
+	if err := binary.Read(r, binary.LittleEndian, regs); err != nil {
+		return fmt.Errorf("estado do mapeador inválido: %w", err)
+	}
+	if r.Len() != len(b.ram) {
+		return fmt.Errorf("estado do mapeador: RAM de %d bytes (esperado %d)", r.Len(), len(b.ram))
+	}
+	r.Read(b.ram)
+	return nil
+}
+
+// ramEnableValue retorna se o valor escrito em 0x0000-0x1FFF habilita a RAM
+func ramEnableValue(value uint8) bool {
+	return value&0x0F == 0x0A
+}
@@ -0,0 +1,671 @@
+package cartridge
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newBankedROM cria uma ROM em que o primeiro byte de cada banco é o número
+// do banco
+func newBankedROM(cartridgeType uint8, banks int, ramCode uint8) []uint8 {
+	rom := make([]uint8, banks*ROMBankSize)
+	for bank := 0; bank < banks; bank++ {
+		rom[bank*ROMBankSize] = uint8(bank)
+	}
+	rom[HeaderCartridgeType] = cartridgeType
+	rom[HeaderRAMSize] = ramCode
+	return rom
+}
+
+func newCartridge(t *testing.T, rom []uint8) Cartridge {
+	cart, err := New(rom)
+	if err != nil {
+		t.Fatalf("erro ao criar cartucho: %v", err)
+	}
+	return cart
+}
+
+func TestCartridgeROMOnly(t *testing.T) {
+	rom := make([]uint8, 0x8000)
+	for i := 0x150; i < len(rom); i++ {
+		rom[i] = uint8(i)
+	}
+	cart := newCartridge(t, rom)
+
+	for _, addr := range []uint16{0x0150, 0x3FFF, 0x4000, 0x7FFF} {
+		if got := cart.ReadROM(addr); got != uint8(addr) {
+			t.Errorf("ROM[0x%04X]: esperado 0x%02X, obtido 0x%02X", addr, uint8(addr), got)
+		}
+	}
+
+	// Escritas de controle não trocam bancos
+	cart.WriteControl(0x2000, 0x02)
+	if got := cart.ReadROM(0x4000); got != 0x00 {
+		t.Errorf("ROM sem MBC não deveria trocar de banco, obtido 0x%02X", got)
+	}
+
+	if got := cart.ReadRAM(0xA000); got != 0xFF {
+		t.Errorf("sem RAM externa deveria ler 0xFF, obtido 0x%02X", got)
+	}
+}
+
+func TestCartridgeTypes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cartridgeType uint8
+		ramCode       uint8
+		ramSize       int
+		hasBattery    bool
+	}{
+		{"ROM Only", 0x00, 0x00, 0, false},
+		{"ROM+RAM+Battery", 0x09, 0x02, 0x2000, true},
+		{"MBC1", 0x01, 0x00, 0, false},
+		{"MBC1+RAM", 0x02, 0x03, 0x8000, false},
+		{"MBC1+RAM+Battery", 0x03, 0x02, 0x2000, true},
+		{"MBC2", 0x05, 0x00, MBC2RAMSize, false},
+		{"MBC2+Battery", 0x06, 0x00, MBC2RAMSize, true},
+		{"MBC3+Timer+Battery", 0x0F, 0x00, 0, true},
+		{"MBC3+RAM+Battery", 0x13, 0x03, 0x8000, true},
+		{"MBC5", 0x19, 0x00, 0, false},
+		{"MBC5+RAM", 0x1A, 0x04, 0x20000, false},
+		{"MBC5+Rumble+RAM+Battery", 0x1E, 0x03, 0x8000, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mapper, ok := Lookup(tc.cartridgeType)
+			if !ok {
+				t.Fatalf("tipo 0x%02X deveria estar registrado", tc.cartridgeType)
+			}
+			if mapper.Features.Battery != tc.hasBattery {
+				t.Errorf("Features.Battery: esperado %v, obtido %v", tc.hasBattery, mapper.Features.Battery)
+			}
+
+			cart := newCartridge(t, newBankedROM(tc.cartridgeType, 2, tc.ramCode))
+			if cart.RAMSize() != tc.ramSize {
+				t.Errorf("RAM: esperado %d bytes, obtido %d", tc.ramSize, cart.RAMSize())
+			}
+			if cart.HasBattery() != tc.hasBattery {
+				t.Errorf("HasBattery: esperado %v, obtido %v", tc.hasBattery, cart.HasBattery())
+			}
+		})
+	}
+}
+
+func TestInvalidCartridge(t *testing.T) {
+	if _, err := New(make([]uint8, 0x4000)); err == nil {
+		t.Error("Deveria retornar erro para ROM muito pequena")
+	}
+
+	rom := make([]uint8, 0x8000)
+	rom[HeaderCartridgeType] = 0x04 // Não usado
+	if _, err := New(rom); err == nil {
+		t.Error("Deveria retornar erro para tipo não suportado")
+	}
+
+	rom[HeaderCartridgeType] = 0xFD // TAMA5
+	_, err := New(rom)
+	if !errors.Is(err, ErrUnsupportedMapper) {
+		t.Fatalf("esperado ErrUnsupportedMapper, obtido %v", err)
+	}
+	var unsupported *UnsupportedMapperError
+	if !errors.As(err, &unsupported) || unsupported.Type != 0xFD {
+		t.Errorf("erro deveria informar o tipo 0xFD: %v", err)
+	}
+}
+
+func TestMBC1Mode1RemapsBank0(t *testing.T) {
+	// 1MB: BANK2 seleciona os bancos 0x20/0x40/0x60
+	cart := newCartridge(t, newBankedROM(0x01, 64, 0x00))
+
+	cart.WriteControl(0x4000, 0x01)
+	cart.WriteControl(0x2000, 0x02)
+	if got := cart.ReadROM(0x4000); got != 0x22 {
+		t.Errorf("0x4000 em modo 0: esperado banco 0x22, obtido 0x%02X", got)
+	}
+	if got := cart.ReadROM(0x0000); got != 0x00 {
+		t.Errorf("0x0000 em modo 0: esperado banco 0, obtido 0x%02X", got)
+	}
+
+	cart.WriteControl(0x6000, 0x01)
+	if got := cart.ReadROM(0x0000); got != 0x20 {
+		t.Errorf("0x0000 em modo 1: esperado banco 0x20, obtido 0x%02X", got)
+	}
+
+	// BANK1 = 0 vira 1, mas 0x20 é acessível por 0x0000 em modo 1
+	cart.WriteControl(0x2000, 0x00)
+	if got := cart.ReadROM(0x4000); got != 0x21 {
+		t.Errorf("BANK1=0: esperado banco 0x21, obtido 0x%02X", got)
+	}
+}
+
+func TestMBC1ROMBankMasking(t *testing.T) {
+	// 256KB (16 bancos): bits além do tamanho da ROM são ignorados
+	cart := newCartridge(t, newBankedROM(0x01, 16, 0x00))
+
+	cart.WriteControl(0x2000, 0x13)
+	if got := cart.ReadROM(0x4000); got != 0x03 {
+		t.Errorf("banco 0x13 em ROM de 16 bancos: esperado 0x03, obtido 0x%02X", got)
+	}
+
+	// O teste de zero usa os 5 bits: 0x10 mapeia o banco 0 em 0x4000
+	cart.WriteControl(0x2000, 0x10)
+	if got := cart.ReadROM(0x4000); got != 0x00 {
+		t.Errorf("banco 0x10 em ROM de 16 bancos: esperado 0x00, obtido 0x%02X", got)
+	}
+}
+
+func TestMBC1RAMBankingMode(t *testing.T) {
+	cart := newCartridge(t, newBankedROM(0x03, 4, 0x03)) // 32KB de RAM
+	cart.WriteControl(0x0000, 0x0A)
+
+	// Modo 0: sempre o banco 0 de RAM
+	cart.WriteControl(0x4000, 0x02)
+	cart.WriteRAM(0xA000, 0x11)
+
+	// Modo 1: BANK2 seleciona o banco de RAM
+	cart.WriteControl(0x6000, 0x01)
+	cart.WriteRAM(0xA000, 0x22)
+	if got := cart.ReadRAM(0xA000); got != 0x22 {
+		t.Errorf("RAM banco 2: esperado 0x22, obtido 0x%02X", got)
+	}
+
+	cart.WriteControl(0x6000, 0x00)
+	if got := cart.ReadRAM(0xA000); got != 0x11 {
+		t.Errorf("RAM banco 0: esperado 0x11, obtido 0x%02X", got)
+	}
+
+	data := cart.BatteryData()
+	if data[0] != 0x11 || data[2*RAMBankSize] != 0x22 {
+		t.Errorf("bancos de RAM gravados no lugar errado: 0x%02X 0x%02X", data[0], data[2*RAMBankSize])
+	}
+}
+
+// newMulticartROM cria uma ROM MBC1M de 1MB com o logo em cada jogo de 256KB
+func newMulticartROM() []uint8 {
+	rom := newBankedROM(0x01, 64, 0x00)
+	for game := 0; game < 4; game++ {
+		copy(rom[game*0x10*ROMBankSize+HeaderLogo:], NintendoLogo[:])
+	}
+	return rom
+}
+
+func TestMBC1Multicart(t *testing.T) {
+	if isMBC1Multicart(newBankedROM(0x01, 64, 0x00)) {
+		t.Error("ROM de 1MB sem logos não deveria ser detectada como MBC1M")
+	}
+
+	cart := newCartridge(t, newMulticartROM())
+	if !cart.(*MBC1).IsMulticart() {
+		t.Fatal("ROM com logos repetidos deveria ser detectada como MBC1M")
+	}
+
+	// BANK2 seleciona o jogo a partir do bit 4 do banco
+	cart.WriteControl(0x4000, 0x02)
+	cart.WriteControl(0x2000, 0x03)
+	if got := cart.ReadROM(0x4000); got != 0x23 {
+		t.Errorf("jogo 2, banco 3: esperado 0x23, obtido 0x%02X", got)
+	}
+
+	// O bit 4 de BANK1 não é ligado, mas conta no teste de zero
+	cart.WriteControl(0x2000, 0x10)
+	if got := cart.ReadROM(0x4000); got != 0x20 {
+		t.Errorf("BANK1=0x10: esperado banco 0x20, obtido 0x%02X", got)
+	}
+
+	cart.WriteControl(0x6000, 0x01)
+	if got := cart.ReadROM(0x0000); got != 0x20 {
+		t.Errorf("0x0000 em modo 1: esperado banco 0x20, obtido 0x%02X", got)
+	}
+}
+
+func TestMMM01Mapping(t *testing.T) {
+	// 512KB: o menu fica nos dois últimos bancos
+	cart := newCartridge(t, newBankedROM(0x0D, 32, 0x03))
+	if got := cart.ReadROM(0x0000); got != 30 {
+		t.Errorf("menu em 0x0000: esperado banco 30, obtido %d", got)
+	}
+	if got := cart.ReadROM(0x4000); got != 31 {
+		t.Errorf("menu em 0x4000: esperado banco 31, obtido %d", got)
+	}
+
+	// O menu escolhe o jogo nos bancos 0x10-0x17 (mid=0, low=0x10,
+	// máscara fixa os bits 3-4) e trava a configuração
+	cart.WriteControl(0x2000, 0x10)
+	cart.WriteControl(0x6000, 0x18<<1)
+	cart.WriteControl(0x0000, 0x40|0x0A)
+
+	if got := cart.ReadROM(0x0000); got != 0x10 {
+		t.Errorf("banco 0 do jogo: esperado 0x10, obtido 0x%02X", got)
+	}
+	if got := cart.ReadROM(0x4000); got != 0x11 {
+		t.Errorf("banco 1 do jogo: esperado 0x11, obtido 0x%02X", got)
+	}
+
+	// Após travar, o jogo só altera os bits não mascarados
+	cart.WriteControl(0x2000, 0x05)
+	if got := cart.ReadROM(0x4000); got != 0x15 {
+		t.Errorf("banco 5 do jogo: esperado 0x15, obtido 0x%02X", got)
+	}
+	cart.WriteControl(0x0000, 0x00)
+	cart.WriteControl(0x2000, 0x03)
+	if got := cart.ReadROM(0x4000); got != 0x13 {
+		t.Errorf("bit 6 de 0x0000 não deveria destravar: obtido 0x%02X", got)
+	}
+
+	cart.Reset()
+	if got := cart.ReadROM(0x4000); got != 31 {
+		t.Errorf("reset deveria voltar ao menu, obtido banco %d", got)
+	}
+}
+
+func TestHuC1IR(t *testing.T) {
+	cart := newCartridge(t, newBankedROM(0xFF, 8, 0x02))
+	huc1 := cart.(*HuC1)
+
+	cart.WriteControl(0x2000, 0x05)
+	if got := cart.ReadROM(0x4000); got != 0x05 {
+		t.Errorf("banco de ROM: esperado 5, obtido %d", got)
+	}
+
+	cart.WriteRAM(0xA000, 0x42)
+	if got := cart.ReadRAM(0xA000); got != 0x42 {
+		t.Errorf("RAM: esperado 0x42, obtido 0x%02X", got)
+	}
+
+	cart.WriteControl(0x0000, 0x0E)
+	if got := cart.ReadRAM(0xA000); got != HuC1IRNoLight {
+		t.Errorf("porta IR: esperado 0x%02X, obtido 0x%02X", HuC1IRNoLight, got)
+	}
+	cart.WriteRAM(0xA000, 0x01)
+	if !huc1.IRLED() {
+		t.Error("escrita na porta IR deveria acender o LED")
+	}
+
+	cart.WriteControl(0x0000, 0x00)
+	if got := cart.ReadRAM(0xA000); got != 0x42 {
+		t.Errorf("escrita IR não deveria alterar a RAM, obtido 0x%02X", got)
+	}
+}
+
+// huc3Command escreve um comando do RTC e retorna a resposta
+func huc3Command(cart Cartridge, command uint8) uint8 {
+	cart.WriteControl(0x0000, HuC3ModeCommand)
+	cart.WriteRAM(0xA000, command)
+	cart.WriteControl(0x0000, HuC3ModeResponse)
+	return cart.ReadRAM(0xA000)
+}
+
+func TestHuC3Clock(t *testing.T) {
+	cart := newCartridge(t, newBankedROM(0xFE, 8, 0x03))
+	huc3 := cart.(*HuC3)
+
+	// 2 dias, 10:05
+	huc3.Advance((2*24*60 + 10*60 + 5) * 60)
+	cart.Step(59 * RTCCyclesPerSecond)
+	if minutes, days := huc3.Clock(); minutes != 605 || days != 2 {
+		t.Fatalf("relógio: esperado 605 minutos e 2 dias, obtido %d e %d", minutes, days)
+	}
+
+	// Latch, endereço 0 e leitura dos 6 nibbles
+	huc3Command(cart, 0x60)
+	huc3Command(cart, 0x40)
+	huc3Command(cart, 0x50)
+	var nibbles [6]uint8
+	for i := range nibbles {
+		response := huc3Command(cart, 0x10)
+		if response&0xF0 != 0x90 {
+			t.Fatalf("resposta deveria repetir o comando: 0x%02X", response)
+		}
+		nibbles[i] = response & 0x0F
+	}
+	if nibbles != [6]uint8{0xD, 0x5, 0x2, 0x2, 0x0, 0x0} {
+		t.Errorf("nibbles do relógio: obtido %v", nibbles)
+	}
+
+	// Escrita de 1 dia e 00:01 e commit
+	huc3Command(cart, 0x40)
+	for _, nibble := range []uint8{0x1, 0x0, 0x0, 0x1, 0x0, 0x0} {
+		huc3Command(cart, 0x30|nibble)
+	}
+	huc3Command(cart, 0x61)
+	if minutes, days := huc3.Clock(); minutes != 1 || days != 1 {
+		t.Errorf("commit: esperado 1 minuto e 1 dia, obtido %d e %d", minutes, days)
+	}
+
+	cart.WriteControl(0x0000, HuC3ModeReady)
+	if cart.ReadRAM(0xA000)&0x01 == 0 {
+		t.Error("semáforo deveria indicar comando concluído")
+	}
+}
+
+func TestHuC3BatteryAdvancesClock(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	cart := newCartridge(t, newBankedROM(0xFE, 8, 0x03))
+	huc3 := cart.(*HuC3)
+	huc3.now = func() time.Time { return start }
+	data := cart.BatteryData()
+	if len(data) != 0x8000+HuC3FooterSize {
+		t.Fatalf("save: esperado %d bytes, obtido %d", 0x8000+HuC3FooterSize, len(data))
+	}
+
+	restored := newCartridge(t, newBankedROM(0xFE, 8, 0x03)).(*HuC3)
+	restored.now = func() time.Time { return start.Add(25 * time.Hour) }
+	if err := restored.LoadBatteryData(data); err != nil {
+		t.Fatalf("erro ao carregar save: %v", err)
+	}
+	if minutes, days := restored.Clock(); minutes != 60 || days != 1 {
+		t.Errorf("esperado 1 dia e 60 minutos, obtido %d dias e %d minutos", days, minutes)
+	}
+}
+
+func TestMBC6Banking(t *testing.T) {
+	// 64 bancos de 8KB; newBankedROM marca cada banco de 16KB
+	cart := newCartridge(t, newBankedROM(0x20, 32, 0x00))
+	if cart.RAMSize() != MBC6RAMSize {
+		t.Errorf("RAM: esperado %d bytes, obtido %d", MBC6RAMSize, cart.RAMSize())
+	}
+
+	// As janelas A e B são independentes
+	cart.WriteControl(0x2000, 0x08) // Banco 8 de 8KB = banco 4 de 16KB
+	cart.WriteControl(0x3000, 0x0E)
+	if got := cart.ReadROM(0x4000); got != 4 {
+		t.Errorf("janela A: esperado 4, obtido %d", got)
+	}
+	if got := cart.ReadROM(0x6000); got != 7 {
+		t.Errorf("janela B: esperado 7, obtido %d", got)
+	}
+
+	// RAM em janelas de 4KB
+	cart.WriteControl(0x0000, 0x0A)
+	cart.WriteControl(0x0400, 0x01)
+	cart.WriteControl(0x0800, 0x01)
+	cart.WriteRAM(0xA000, 0x11)
+	if got := cart.ReadRAM(0xB000); got != 0x11 {
+		t.Errorf("janelas de RAM no mesmo banco: esperado 0x11, obtido 0x%02X", got)
+	}
+	if data := cart.BatteryData(); data[MBC6RAMBankSize] != 0x11 {
+		t.Error("banco 1 de RAM gravado no lugar errado")
+	}
+}
+
+func TestMBC6Flash(t *testing.T) {
+	cart := newCartridge(t, newBankedROM(0x20, 32, 0x03))
+	mbc6 := cart.(*MBC6)
+
+	cart.WriteControl(0x0C00, 0x01) // Habilita flash
+	cart.WriteControl(0x1000, 0x01) // Habilita escrita
+	cart.WriteControl(0x2800, 0x08) // Janela A na flash
+	cart.WriteControl(0x3800, 0x08) // Janela B na flash
+
+	// unlock escreve 0xAA em 2:5555 e 0x55 em 1:4AAA
+	unlock := func(command uint8) {
+		cart.WriteControl(0x2000, 0x02)
+		cart.WriteControl(0x5555, 0xAA)
+		cart.WriteControl(0x2000, 0x01)
+		cart.WriteControl(0x4AAA, 0x55)
+		cart.WriteControl(0x2000, 0x02)
+		cart.WriteControl(0x5555, command)
+	}
+
+	// Identificação
+	unlock(0x90)
+	if got := cart.ReadROM(0x4000); got != MBC6FlashManufacturer {
+		t.Errorf("fabricante: esperado 0x%02X, obtido 0x%02X", MBC6FlashManufacturer, got)
+	}
+	cart.WriteControl(0x4000, 0xF0)
+
+	// Programação no banco 3 via janela B
+	unlock(0xA0)
+	cart.WriteControl(0x3000, 0x03)
+	cart.WriteControl(0x6010, 0x5A)
+	if got := cart.ReadROM(0x6010); got != 0x5A {
+		t.Errorf("flash programada: esperado 0x5A, obtido 0x%02X", got)
+	}
+	if mbc6.Flash()[3*MBC6ROMBankSize+0x10] != 0x5A {
+		t.Error("byte programado no offset errado da flash")
+	}
+
+	// Sem comando, escritas são ignoradas
+	cart.WriteControl(0x6010, 0x00)
+	if got := cart.ReadROM(0x6010); got != 0x5A {
+		t.Errorf("escrita sem comando não deveria programar, obtido 0x%02X", got)
+	}
+
+	// Apagar o setor volta para 0xFF
+	unlock(0x80)
+	cart.WriteControl(0x2000, 0x02)
+	cart.WriteControl(0x5555, 0xAA)
+	cart.WriteControl(0x2000, 0x01)
+	cart.WriteControl(0x4AAA, 0x55)
+	cart.WriteControl(0x6000, 0x30)
+	if got := cart.ReadROM(0x6010); got != 0xFF {
+		t.Errorf("setor apagado: esperado 0xFF, obtido 0x%02X", got)
+	}
+
+	if len(cart.BatteryData()) != 0x8000+MBC6FlashSize {
+		t.Errorf("save deveria conter RAM e flash, obtido %d bytes", len(cart.BatteryData()))
+	}
+}
+
+// fixedTilt é uma TiltSource de valor constante
+type fixedTilt struct{ x, y float64 }
+
+func (f fixedTilt) Tilt() (float64, float64) { return f.x, f.y }
+
+// newEnabledMBC7 cria um MBC7 com os registradores habilitados
+func newEnabledMBC7(t *testing.T) *MBC7 {
+	cart := newCartridge(t, newBankedROM(0x22, 8, 0x00)).(*MBC7)
+	cart.WriteControl(0x0000, 0x0A)
+	cart.WriteControl(0x4000, 0x40)
+	return cart
+}
+
+// eepromSend envia bits pela EEPROM com CS alto e retorna DO após cada bit
+func eepromSend(cart *MBC7, bits uint32, count int) uint32 {
+	var out uint32
+	for i := count - 1; i >= 0; i-- {
+		di := uint8(bits>>i&1) << 1
+		cart.WriteRAM(0xA080, MBC7EEPROMCS|di)
+		cart.WriteRAM(0xA080, MBC7EEPROMCS|MBC7EEPROMCLK|di)
+		out = out<<1 | uint32(cart.ReadRAM(0xA080)&MBC7EEPROMDO)
+	}
+	return out
+}
+
+// sendEEPROMCommand envia start bit, opcode e endereço e solta CS ao final
+func sendEEPROMCommand(cart *MBC7, command uint32, extra int) uint32 {
+	cart.WriteRAM(0xA080, 0x00)
+	eepromSend(cart, 1<<10|command, 11)
+	out := eepromSend(cart, 0, extra)
+	cart.WriteRAM(0xA080, 0x00)
+	return out
+}
+
+func TestMBC7Accelerometer(t *testing.T) {
+	cart := newEnabledMBC7(t)
+	cart.SetTiltSource(fixedTilt{x: 1, y: -0.5})
+
+	// Sem apagar o latch, 0xAA não atualiza as leituras
+	cart.WriteRAM(0xA010, 0xAA)
+	if got := cart.ReadRAM(0xA020); got != 0x00 {
+		t.Errorf("latch sem apagar: esperado 0x00, obtido 0x%02X", got)
+	}
+
+	cart.WriteRAM(0xA000, 0x55)
+	if x := uint16(cart.ReadRAM(0xA030))<<8 | uint16(cart.ReadRAM(0xA020)); x != MBC7AccelErased {
+		t.Errorf("latch apagado: esperado 0x%04X, obtido 0x%04X", MBC7AccelErased, x)
+	}
+
+	cart.WriteRAM(0xA010, 0xAA)
+	x := uint16(cart.ReadRAM(0xA030))<<8 | uint16(cart.ReadRAM(0xA020))
+	y := uint16(cart.ReadRAM(0xA050))<<8 | uint16(cart.ReadRAM(0xA040))
+	if x != MBC7AccelCenter+MBC7AccelGain || y != MBC7AccelCenter-MBC7AccelGain/2 {
+		t.Errorf("acelerômetro: obtido X=0x%04X Y=0x%04X", x, y)
+	}
+
+	// Sem a segunda habilitação os registradores não aparecem
+	cart.WriteControl(0x4000, 0x00)
+	if got := cart.ReadRAM(0xA020); got != 0xFF {
+		t.Errorf("registradores desabilitados: esperado 0xFF, obtido 0x%02X", got)
+	}
+}
+
+func TestMBC7EEPROM(t *testing.T) {
+	cart := newEnabledMBC7(t)
+
+	// Sem EWEN a escrita é ignorada
+	sendEEPROMCommand(cart, 0x1<<8|0x05, 16)
+	if cart.IsRAMDirty() {
+		t.Error("escrita sem EWEN não deveria alterar a EEPROM")
+	}
+
+	sendEEPROMCommand(cart, 0x0<<8|0xC0, 0) // EWEN
+	cart.WriteRAM(0xA080, 0x00)
+	eepromSend(cart, 1<<10|0x1<<8|0x05, 11) // WRITE 0x05
+	eepromSend(cart, 0xBEEF, 16)
+	cart.WriteRAM(0xA080, 0x00)
+
+	// READ: preâmbulo 0 seguido dos 16 bits
+	if got := sendEEPROMCommand(cart, 0x2<<8|0x05, 16); got != 0xBEEF {
+		t.Errorf("leitura da EEPROM: esperado 0xBEEF, obtido 0x%04X", got)
+	}
+
+	data := cart.BatteryData()
+	if len(data) != MBC7EEPROMSize || data[10] != 0xEF || data[11] != 0xBE {
+		t.Errorf("EEPROM no save: %d bytes, palavra 0x%02X%02X", len(data), data[11], data[10])
+	}
+
+	restored := newEnabledMBC7(t)
+	if err := restored.LoadBatteryData(data); err != nil {
+		t.Fatalf("erro ao carregar save: %v", err)
+	}
+	if got := sendEEPROMCommand(restored, 0x2<<8|0x05, 16); got != 0xBEEF {
+		t.Errorf("EEPROM restaurada: esperado 0xBEEF, obtido 0x%04X", got)
+	}
+
+	// ERASE volta a palavra para 0xFFFF
+	sendEEPROMCommand(cart, 0x3<<8|0x05, 0)
+	if got := sendEEPROMCommand(cart, 0x2<<8|0x05, 16); got != 0xFFFF {
+		t.Errorf("palavra apagada: esperado 0xFFFF, obtido 0x%04X", got)
+	}
+}
+
+func TestMBC2RAM(t *testing.T) {
+	cart := newCartridge(t, newBankedROM(0x06, 16, 0x00))
+
+	// Bit 8 do endereço escolhe o registrador
+	cart.WriteControl(0x0100, 0x05)
+	if got := cart.ReadROM(0x4000); got != 0x05 {
+		t.Errorf("banco de ROM: esperado 5, obtido %d", got)
+	}
+	cart.WriteControl(0x0000, 0x0A)
+
+	cart.WriteRAM(0xA000, 0xAB)
+	if got := cart.ReadRAM(0xA000); got != 0xFB {
+		t.Errorf("RAM de 4 bits: esperado 0xFB, obtido 0x%02X", got)
+	}
+	if got := cart.ReadRAM(0xA200); got != 0xFB {
+		t.Errorf("RAM espelhada a cada 512 bytes: esperado 0xFB, obtido 0x%02X", got)
+	}
+}
+
+func TestMBC5Banking(t *testing.T) {
+	cart := newCartridge(t, newBankedROM(0x19, 512, 0x00))
+
+	// Banco 0 é permitido em 0x4000-0x7FFF
+	cart.WriteControl(0x2000, 0x00)
+	if got := cart.ReadROM(0x4000); got != 0x00 {
+		t.Errorf("banco 0: esperado 0, obtido %d", got)
+	}
+
+	// Nono bit do banco
+	cart.WriteControl(0x2000, 0x05)
+	cart.WriteControl(0x3000, 0x01)
+	if got := cart.ReadROM(0x4000); got != 0x05 {
+		t.Errorf("banco 0x105: esperado byte 0x05, obtido 0x%02X", got)
+	}
+	if got := cart.ReadROM(0x4001); got != 0 {
+		t.Errorf("banco 0x105 deveria estar mapeado, obtido 0x%02X", got)
+	}
+}
+
+func TestMBC5Rumble(t *testing.T) {
+	cart := newCartridge(t, newBankedROM(0x1E, 8, 0x04))
+	rumbler, ok := cart.(Rumbler)
+	if !ok || !rumbler.HasRumble() {
+		t.Fatal("MBC5+RUMBLE deveria ter motor")
+	}
+
+	cart.WriteControl(0x0000, 0x0A)
+	cart.WriteControl(0x4000, 0x01)
+	cart.WriteRAM(0xA000, 0x11)
+
+	// Bit 3 liga o motor sem trocar o banco de RAM
+	cart.WriteControl(0x4000, 0x09)
+	if !rumbler.MotorOn() {
+		t.Error("bit 3 deveria ligar o motor")
+	}
+	if got := cart.ReadRAM(0xA000); got != 0x11 {
+		t.Errorf("banco de RAM com o motor ligado: esperado 0x11, obtido 0x%02X", got)
+	}
+
+	// Motor ligado em 1/4 do frame
+	cart.Step(1000)
+	cart.WriteControl(0x4000, 0x01)
+	cart.Step(3000)
+	on, duty := rumbler.RumbleFrame()
+	if on || duty != 0.25 {
+		t.Errorf("esperado (false, 0.25), obtido (%v, %v)", on, duty)
+	}
+	if _, duty := rumbler.RumbleFrame(); duty != 0 {
+		t.Errorf("RumbleFrame deveria zerar a contagem, obtido %v", duty)
+	}
+
+	// Sem rumble, o bit 3 faz parte do banco de RAM
+	plain := newCartridge(t, newBankedROM(0x1B, 8, 0x04))
+	if plain.(Rumbler).HasRumble() {
+		t.Error("MBC5 comum não deveria ter motor")
+	}
+	plain.WriteControl(0x0000, 0x0A)
+	plain.WriteControl(0x4000, 0x09)
+	plain.WriteRAM(0xA000, 0x22)
+	plain.WriteControl(0x4000, 0x01)
+	if got := plain.ReadRAM(0xA000); got == 0x22 {
+		t.Error("bancos 1 e 9 deveriam ser distintos sem rumble")
+	}
+}
+
+func TestCartridgeStateRoundTrip(t *testing.T) {
+	for _, cartridgeType := range []uint8{0x00, 0x03, 0x06, 0x0D, 0x10, 0x1B, 0x1E, 0x20, 0x22, 0xFC, 0xFE, 0xFF} {
+		rom := newBankedROM(cartridgeType, 8, 0x03)
+		cart := newCartridge(t, rom)
+		cart.WriteControl(0x0000, 0x0A)
+		cart.WriteControl(0x2100, 0x03)
+		cart.WriteRAM(0xA123, 0x5A)
+
+		state, err := cart.SaveState()
+		if err != nil {
+			t.Fatalf("0x%02X: erro ao salvar estado: %v", cartridgeType, err)
+		}
+
+		restored := newCartridge(t, rom)
+		if err := restored.LoadState(state); err != nil {
+			t.Fatalf("0x%02X: erro ao carregar estado: %v", cartridgeType, err)
+		}
+
+		again, _ := restored.SaveState()
+		if !bytes.Equal(state, again) {
+			t.Errorf("0x%02X: estado restaurado difere do original", cartridgeType)
+		}
+		if restored.ReadROM(0x4000) != cart.ReadROM(0x4000) || restored.ReadRAM(0xA123) != cart.ReadRAM(0xA123) {
+			t.Errorf("0x%02X: banco ou RAM não restaurados", cartridgeType)
+		}
+
+		if err := restored.LoadState(state[:len(state)-1]); err == nil {
+			t.Errorf("0x%02X: estado truncado deveria retornar erro", cartridgeType)
+		}
+	}
+}
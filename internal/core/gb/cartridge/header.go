@@ -0,0 +1,356 @@
+package cartridge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Endereços do header (0x100-0x14F)
+const (
+	HeaderLogo           = 0x104 // Logo da Nintendo (48 bytes)
+	HeaderTitle          = 0x134 // 16 bytes (15 ou 11 em cartuchos CGB)
+	HeaderManufacturer   = 0x13F // 4 bytes, apenas em cartuchos CGB recentes
+	HeaderCGBFlag        = 0x143
+	HeaderNewLicensee    = 0x144 // 2 caracteres ASCII
+	HeaderSGBFlag        = 0x146
+	HeaderCartridgeType  = 0x147
+	HeaderROMSize        = 0x148
+	HeaderRAMSize        = 0x149
+	HeaderDestination    = 0x14A
+	HeaderOldLicensee    = 0x14B
+	HeaderVersion        = 0x14C
+	HeaderChecksum       = 0x14D // Soma de 0x134-0x14C
+	HeaderGlobalChecksum = 0x14E // Soma de toda a ROM, big-endian
+
+	// Tamanho mínimo para ler o header
+	HeaderSize = 0x150
+)
+
+// Valores do byte 0x143
+const (
+	CGBFlagCompatible = 0x80 // Funciona em DMG e CGB
+	CGBFlagOnly       = 0xC0 // Exclusivo do CGB
+)
+
+// ROMHeader contém os campos decodificados do header do cartucho
+type ROMHeader struct {
+	Title         string
+	Manufacturer  string // Código de 4 letras (vazio em cartuchos antigos)
+	CGBFlag       uint8
+	NewLicensee   string // Usado quando OldLicensee == 0x33
+	OldLicensee   uint8
+	SGBFlag       uint8
+	CartridgeType uint8
+	ROMSize       int // Em bytes (0 = código desconhecido)
+	RAMSize       int // Em bytes
+	Destination   uint8
+	Version       uint8
+
+	HeaderChecksum      uint8
+	HeaderChecksumValid bool
+	GlobalChecksum      uint16
+	GlobalChecksumValid bool
+
+	LogoValid bool // Logo da Nintendo em 0x104-0x133
+}
+
+// ParseHeader decodifica o header da ROM e confere os checksums e o logo
+func ParseHeader(rom []uint8) (*ROMHeader, error) {
+	if len(rom) < HeaderSize {
+		return nil, fmt.Errorf("ROM muito pequena para conter o header: %d bytes", len(rom))
+	}
+
+	h := &ROMHeader{
+		CGBFlag:        rom[HeaderCGBFlag],
+		NewLicensee:    string(rom[HeaderNewLicensee : HeaderNewLicensee+2]),
+		OldLicensee:    rom[HeaderOldLicensee],
+		SGBFlag:        rom[HeaderSGBFlag],
+		CartridgeType:  rom[HeaderCartridgeType],
+		ROMSize:        ROMSizeFromHeader(rom[HeaderROMSize]),
+		RAMSize:        RAMSizeFromHeader(rom[HeaderRAMSize]),
+		Destination:    rom[HeaderDestination],
+		Version:        rom[HeaderVersion],
+		HeaderChecksum: rom[HeaderChecksum],
+		GlobalChecksum: uint16(rom[HeaderGlobalChecksum])<<8 | uint16(rom[HeaderGlobalChecksum+1]),
+	}
+
+	// Em cartuchos CGB o título perde o último byte (flag) e, quando há
+	// código de fabricante, mais quatro
+	titleEnd := HeaderCGBFlag + 1
+	if h.CGBFlag&0x80 != 0 {
+		titleEnd = HeaderCGBFlag
+		if isManufacturerCode(rom[HeaderManufacturer:HeaderCGBFlag]) {
+			h.Manufacturer = string(rom[HeaderManufacturer:HeaderCGBFlag])
+			titleEnd = HeaderManufacturer
+		}
+	}
+	h.Title = cleanTitle(rom[HeaderTitle:titleEnd])
+
+	h.HeaderChecksumValid = ComputeHeaderChecksum(rom) == h.HeaderChecksum
+	h.GlobalChecksumValid = ComputeGlobalChecksum(rom) == h.GlobalChecksum
+	h.LogoValid = string(rom[HeaderLogo:HeaderLogo+len(NintendoLogo)]) == string(NintendoLogo[:])
+
+	return h, nil
+}
+
+// ComputeHeaderChecksum calcula o checksum verificado pela boot ROM
+func ComputeHeaderChecksum(rom []uint8) uint8 {
+	var sum uint8
+	for _, b := range rom[HeaderTitle:HeaderChecksum] {
+		sum = sum - b - 1
+	}
+	return sum
+}
+
+// ComputeGlobalChecksum soma todos os bytes da ROM exceto o próprio checksum
+func ComputeGlobalChecksum(rom []uint8) uint16 {
+	var sum uint16
+	for i, b := range rom {
+		if i != HeaderGlobalChecksum && i != HeaderGlobalChecksum+1 {
+			sum += uint16(b)
+		}
+	}
+	return sum
+}
+
+// ROMSizeFromHeader decodifica o byte 0x148 em bytes
+func ROMSizeFromHeader(code uint8) int {
+	switch {
+	case code <= 0x08:
+		return 0x8000 << code // 32KB a 8MB
+	case code == 0x52:
+		return 72 * ROMBankSize // 1.1MB
+	case code == 0x53:
+		return 80 * ROMBankSize // 1.2MB
+	case code == 0x54:
+		return 96 * ROMBankSize // 1.5MB
+	default:
+		return 0
+	}
+}
+
+// isManufacturerCode verifica se os bytes formam um código de fabricante
+// (letras maiúsculas ou dígitos)
+func isManufacturerCode(code []uint8) bool {
+	for _, c := range code {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanTitle corta o título no primeiro byte nulo e troca bytes não
+// imprimíveis por '?'
+func cleanTitle(raw []uint8) string {
+	var b strings.Builder
+	for _, c := range raw {
+		if c == 0 {
+			break
+		}
+		if c < 0x20 || c > 0x7E {
+			c = '?'
+		}
+		b.WriteByte(c)
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// SupportsCGB retorna se o cartucho usa recursos do Game Boy Color
+func (h *ROMHeader) SupportsCGB() bool {
+	return h.CGBFlag&0x80 != 0
+}
+
+// CGBOnly retorna se o cartucho só funciona no Game Boy Color
+func (h *ROMHeader) CGBOnly() bool {
+	return h.CGBFlag == CGBFlagOnly
+}
+
+// SupportsSGB retorna se o cartucho usa recursos do Super Game Boy. A boot
+// ROM do SGB exige 0x03 em 0x146 e licenciado antigo 0x33.
+func (h *ROMHeader) SupportsSGB() bool {
+	return h.SGBFlag == 0x03 && h.OldLicensee == 0x33
+}
+
+// Licensee retorna o nome da publicadora
+func (h *ROMHeader) Licensee() string {
+	if h.OldLicensee == 0x33 {
+		if name, ok := newLicensees[h.NewLicensee]; ok {
+			return name
+		}
+		return fmt.Sprintf("Desconhecido (%q)", h.NewLicensee)
+	}
+	if name, ok := oldLicensees[h.OldLicensee]; ok {
+		return name
+	}
+	return fmt.Sprintf("Desconhecido (0x%02X)", h.OldLicensee)
+}
+
+// DestinationName retorna o mercado de destino (0x14A)
+func (h *ROMHeader) DestinationName() string {
+	if h.Destination == 0x00 {
+		return "Japão"
+	}
+	return "Internacional"
+}
+
+// MapperName retorna o nome do tipo de cartucho
+func (h *ROMHeader) MapperName() string {
+	if mapper, ok := Lookup(h.CartridgeType); ok {
+		return mapper.Name
+	}
+	if name, ok := unsupportedNames[h.CartridgeType]; ok {
+		return name
+	}
+	return fmt.Sprintf("Desconhecido (0x%02X)", h.CartridgeType)
+}
+
+// Warnings lista problemas do header em relação ao arquivo carregado
+func (h *ROMHeader) Warnings(fileSize int) []string {
+	var warnings []string
+	switch {
+	case h.ROMSize == 0:
+		warnings = append(warnings, "código de tamanho da ROM desconhecido no header")
+	case fileSize < h.ROMSize:
+		warnings = append(warnings, fmt.Sprintf("arquivo menor que o header indica (%d de %d bytes); a ROM será completada", fileSize, h.ROMSize))
+	case fileSize > h.ROMSize:
+		warnings = append(warnings, fmt.Sprintf("arquivo maior que o header indica (%d de %d bytes)", fileSize, h.ROMSize))
+	}
+	if !h.HeaderChecksumValid {
+		warnings = append(warnings, "checksum do header inválido; a boot ROM travaria")
+	}
+	if !h.LogoValid {
+		warnings = append(warnings, "logo da Nintendo inválido; a boot ROM travaria")
+	}
+	return warnings
+}
+
+// String retorna um resumo do header
+func (h *ROMHeader) String() string {
+	var features []string
+	if h.CGBOnly() {
+		features = append(features, "somente CGB")
+	} else if h.SupportsCGB() {
+		features = append(features, "CGB")
+	}
+	if h.SupportsSGB() {
+		features = append(features, "SGB")
+	}
+
+	summary := fmt.Sprintf("%s [%s] ROM=%dKB RAM=%dKB v%d %s, %s",
+		h.Title, h.MapperName(), h.ROMSize/1024, h.RAMSize/1024, h.Version, h.Licensee(), h.DestinationName())
+	if len(features) > 0 {
+		summary += " (" + strings.Join(features, ", ") + ")"
+	}
+	return summary
+}
+
+// Details retorna todos os campos do header, um por linha
+func (h *ROMHeader) Details() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Título: %s\n", h.Title)
+	if h.Manufacturer != "" {
+		fmt.Fprintf(&b, "Fabricante: %s\n", h.Manufacturer)
+	}
+	fmt.Fprintf(&b, "Tipo: 0x%02X (%s)\n", h.CartridgeType, h.MapperName())
+	fmt.Fprintf(&b, "ROM: %dKB, RAM: %dKB\n", h.ROMSize/1024, h.RAMSize/1024)
+	fmt.Fprintf(&b, "CGB: 0x%02X, SGB: %v\n", h.CGBFlag, h.SupportsSGB())
+	fmt.Fprintf(&b, "Licenciado: %s\n", h.Licensee())
+	fmt.Fprintf(&b, "Destino: %s, versão %d\n", h.DestinationName(), h.Version)
+	fmt.Fprintf(&b, "Checksum do header: 0x%02X (%s)\n", h.HeaderChecksum, validity(h.HeaderChecksumValid))
+	fmt.Fprintf(&b, "Checksum global: 0x%04X (%s)\n", h.GlobalChecksum, validity(h.GlobalChecksumValid))
+	fmt.Fprintf(&b, "Logo: %s\n", validity(h.LogoValid))
+	return b.String()
+}
+
+// validity descreve o resultado de uma verificação
+func validity(valid bool) string {
+	if valid {
+		return "ok"
+	}
+	return "inválido"
+}
+
+// FitROMSize ajusta uma ROM menor que o tamanho do header. Dumps com
+// tamanho potência de 2 são espelhados, como no cartucho com linhas de
+// endereço desconectadas; os demais são completados com 0xFF.
+func FitROMSize(rom []uint8, size int) []uint8 {
+	if size <= len(rom) {
+		return rom
+	}
+
+	fitted := make([]uint8, size)
+	n := copy(fitted, rom)
+	if n&(n-1) == 0 {
+		for offset := n; offset < size; offset += n {
+			copy(fitted[offset:], rom)
+		}
+		return fitted
+	}
+
+	for i := n; i < size; i++ {
+		fitted[i] = 0xFF
+	}
+	return fitted
+}
+
+// newLicensees são os códigos de publicadora em 0x144-0x145
+var newLicensees = map[string]string{
+	"00": "Nenhum", "01": "Nintendo R&D1", "08": "Capcom", "13": "Electronic Arts",
+	"18": "Hudson Soft", "19": "B-AI", "20": "KSS", "22": "Planning Office WADA",
+	"24": "PCM Complete", "25": "San-X", "28": "Kemco", "29": "SETA Corporation",
+	"30": "Viacom", "31": "Nintendo", "32": "Bandai", "33": "Ocean Software/Acclaim Entertainment",
+	"34": "Konami", "35": "HectorSoft", "37": "Taito", "38": "Hudson Soft",
+	"39": "Banpresto", "41": "Ubi Soft", "42": "Atlus", "44": "Malibu Interactive",
+	"46": "Angel", "47": "Bullet-Proof Software", "49": "Irem", "50": "Absolute",
+	"51": "Acclaim Entertainment", "52": "Activision", "53": "Sammy USA Corporation", "54": "Konami",
+	"55": "Hi Tech Expressions", "56": "LJN", "57": "Matchbox", "58": "Mattel",
+	"59": "Milton Bradley Company", "60": "Titus Interactive", "61": "Virgin Games Ltd.", "64": "Lucasfilm Games",
+	"67": "Ocean Software", "69": "Electronic Arts", "70": "Infogrames", "71": "Interplay Entertainment",
+	"72": "Broderbund", "73": "Sculptured Software", "75": "The Sales Curve Limited", "78": "THQ",
+	"79": "Accolade", "80": "Misawa Entertainment", "83": "lozc", "86": "Tokuma Shoten",
+	"87": "Tsukuda Original", "91": "Chunsoft Co.", "92": "Video System", "93": "Ocean Software/Acclaim Entertainment",
+	"95": "Varie", "96": "Yonezawa/S'Pal", "97": "Kaneko", "99": "Pack-In-Video",
+	"9H": "Bottom Up", "A4": "Konami (Yu-Gi-Oh!)", "BL": "MTO", "DK": "Kodansha",
+}
+
+// oldLicensees são os códigos de publicadora em 0x14B (0x33 = usar o novo)
+var oldLicensees = map[uint8]string{
+	0x00: "Nenhum", 0x01: "Nintendo", 0x08: "Capcom", 0x09: "HOT-B",
+	0x0A: "Jaleco", 0x0B: "Coconuts Japan", 0x0C: "Elite Systems", 0x13: "Electronic Arts",
+	0x18: "Hudson Soft", 0x19: "ITC Entertainment", 0x1A: "Yanoman", 0x1D: "Japan Clary",
+	0x1F: "Virgin Games Ltd.", 0x24: "PCM Complete", 0x25: "San-X", 0x28: "Kemco",
+	0x29: "SETA Corporation", 0x30: "Infogrames", 0x31: "Nintendo", 0x32: "Bandai",
+	0x34: "Konami", 0x35: "HectorSoft", 0x38: "Capcom", 0x39: "Banpresto",
+	0x3C: "Entertainment Interactive", 0x3E: "Gremlin", 0x41: "Ubi Soft", 0x42: "Atlus",
+	0x44: "Malibu Interactive", 0x46: "Angel", 0x47: "Spectrum HoloByte", 0x49: "Irem",
+	0x4A: "Virgin Games Ltd.", 0x4D: "Malibu Interactive", 0x4F: "U.S. Gold", 0x50: "Absolute",
+	0x51: "Acclaim Entertainment", 0x52: "Activision", 0x53: "Sammy USA Corporation", 0x54: "GameTek",
+	0x55: "Park Place", 0x56: "LJN", 0x57: "Matchbox", 0x59: "Milton Bradley Company",
+	0x5A: "Mindscape", 0x5B: "Romstar", 0x5C: "Naxat Soft", 0x5D: "Tradewest",
+	0x60: "Titus Interactive", 0x61: "Virgin Games Ltd.", 0x67: "Ocean Software", 0x69: "Electronic Arts",
+	0x6E: "Elite Systems", 0x6F: "Electro Brain", 0x70: "Infogrames", 0x71: "Interplay Entertainment",
+	0x72: "Broderbund", 0x73: "Sculptured Software", 0x75: "The Sales Curve Limited", 0x78: "THQ",
+	0x79: "Accolade", 0x7A: "Triffix Entertainment", 0x7C: "MicroProse", 0x7F: "Kemco",
+	0x80: "Misawa Entertainment", 0x83: "LOZC G.", 0x86: "Tokuma Shoten", 0x8B: "Bullet-Proof Software",
+	0x8C: "Vic Tokai Corp.", 0x8E: "Ape Inc.", 0x8F: "I'Max", 0x91: "Chunsoft Co.",
+	0x92: "Video System", 0x93: "Tsubaraya Productions", 0x95: "Varie", 0x96: "Yonezawa/S'Pal",
+	0x97: "Kemco", 0x99: "Arc", 0x9A: "Nihon Bussan", 0x9B: "Tecmo",
+	0x9C: "Imagineer", 0x9D: "Banpresto", 0x9F: "Nova", 0xA1: "Hori Electric",
+	0xA2: "Bandai", 0xA4: "Konami", 0xA6: "Kawada", 0xA7: "Takara",
+	0xA9: "Technos Japan", 0xAA: "Broderbund", 0xAC: "Toei Animation", 0xAD: "Toho",
+	0xAF: "Namco", 0xB0: "Acclaim Entertainment", 0xB1: "ASCII Corporation/Nexsoft", 0xB2: "Bandai",
+	0xB4: "Square Enix", 0xB6: "HAL Laboratory", 0xB7: "SNK", 0xB9: "Pony Canyon",
+	0xBA: "Culture Brain", 0xBB: "Sunsoft", 0xBD: "Sony Imagesoft", 0xBF: "Sammy Corporation",
+	0xC0: "Taito", 0xC2: "Kemco", 0xC3: "Square", 0xC4: "Tokuma Shoten",
+	0xC5: "Data East", 0xC6: "Tonkin House", 0xC8: "Koei", 0xC9: "UFL",
+	0xCA: "Ultra Games", 0xCB: "VAP, Inc.", 0xCC: "Use Corporation", 0xCD: "Meldac",
+	0xCE: "Pony Canyon", 0xCF: "Angel", 0xD0: "Taito", 0xD1: "SOFEL",
+	0xD2: "Quest", 0xD3: "Sigma Enterprises", 0xD4: "ASK Kodansha Co.", 0xD6: "Naxat Soft",
+	0xD7: "Copya System", 0xD9: "Banpresto", 0xDA: "Tomy", 0xDB: "LJN",
+	0xDD: "Nippon Computer Systems", 0xDE: "Human Ent.", 0xDF: "Altron", 0xE0: "Jaleco",
+	0xE1: "Towa Chiki", 0xE2: "Yutaka", 0xE3: "Varie", 0xE5: "Epoch",
+	0xE7: "Athena", 0xE8: "Asmik Ace Entertainment", 0xE9: "Natsume", 0xEA: "King Records",
+	0xEB: "Atlus", 0xEC: "Epic/Sony Records", 0xEE: "IGS", 0xF0: "A Wave",
+	0xF3: "Extreme Entertainment", 0xFF: "LJN",
+}
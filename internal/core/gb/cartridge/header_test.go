@@ -0,0 +1,150 @@
+package cartridge
+
+import (
+	"strings"
+	"testing"
+)
+
+// newHeaderROM cria uma ROM de 64KB com logo, título e checksums válidos
+func newHeaderROM() []uint8 {
+	rom := make([]uint8, 0x10000)
+	copy(rom[HeaderLogo:], NintendoLogo[:])
+	copy(rom[HeaderTitle:], "POKEMON YELLOW")
+	rom[HeaderCGBFlag] = CGBFlagCompatible
+	copy(rom[HeaderNewLicensee:], "01")
+	rom[HeaderSGBFlag] = 0x03
+	rom[HeaderCartridgeType] = 0x1B
+	rom[HeaderROMSize] = 0x01
+	rom[HeaderRAMSize] = 0x03
+	rom[HeaderDestination] = 0x01
+	rom[HeaderOldLicensee] = 0x33
+	rom[HeaderVersion] = 0x02
+	rom[0x4000] = 0x42
+	fixChecksums(rom)
+	return rom
+}
+
+// fixChecksums recalcula os dois checksums do header
+func fixChecksums(rom []uint8) {
+	rom[HeaderChecksum] = ComputeHeaderChecksum(rom)
+	global := ComputeGlobalChecksum(rom)
+	rom[HeaderGlobalChecksum] = uint8(global >> 8)
+	rom[HeaderGlobalChecksum+1] = uint8(global)
+}
+
+func TestParseHeader(t *testing.T) {
+	h, err := ParseHeader(newHeaderROM())
+	if err != nil {
+		t.Fatalf("erro ao decodificar header: %v", err)
+	}
+
+	if h.Title != "POKEMON YELLOW" || h.Manufacturer != "" {
+		t.Errorf("título/fabricante: obtido %q/%q", h.Title, h.Manufacturer)
+	}
+	if !h.SupportsCGB() || h.CGBOnly() || !h.SupportsSGB() {
+		t.Errorf("flags CGB/SGB incorretas: CGB=0x%02X SGB=0x%02X", h.CGBFlag, h.SGBFlag)
+	}
+	if h.ROMSize != 0x10000 || h.RAMSize != 0x8000 {
+		t.Errorf("tamanhos: esperado 64KB/32KB, obtido %d/%d", h.ROMSize, h.RAMSize)
+	}
+	if h.Licensee() != "Nintendo R&D1" {
+		t.Errorf("licenciado novo: obtido %q", h.Licensee())
+	}
+	if h.DestinationName() != "Internacional" || h.Version != 2 {
+		t.Errorf("destino/versão: obtido %s/%d", h.DestinationName(), h.Version)
+	}
+	if h.MapperName() != "MBC5+RAM+BATTERY" {
+		t.Errorf("mapeador: obtido %q", h.MapperName())
+	}
+	if !h.HeaderChecksumValid || !h.GlobalChecksumValid || !h.LogoValid {
+		t.Errorf("verificações deveriam passar: header=%v global=%v logo=%v",
+			h.HeaderChecksumValid, h.GlobalChecksumValid, h.LogoValid)
+	}
+	if warnings := h.Warnings(0x10000); len(warnings) != 0 {
+		t.Errorf("header válido não deveria gerar avisos: %v", warnings)
+	}
+	if !strings.Contains(h.Details(), "Checksum global: 0x") {
+		t.Errorf("detalhes incompletos:\n%s", h.Details())
+	}
+}
+
+func TestParseHeaderManufacturerAndOldLicensee(t *testing.T) {
+	rom := newHeaderROM()
+	copy(rom[HeaderTitle:], "ZELDA\x00\x00\x00\x00\x00\x00AZ7E")
+	rom[HeaderCGBFlag] = CGBFlagOnly
+	rom[HeaderOldLicensee] = 0x01
+	fixChecksums(rom)
+
+	h, err := ParseHeader(rom)
+	if err != nil {
+		t.Fatalf("erro ao decodificar header: %v", err)
+	}
+	if h.Title != "ZELDA" || h.Manufacturer != "AZ7E" {
+		t.Errorf("título/fabricante: obtido %q/%q", h.Title, h.Manufacturer)
+	}
+	if !h.CGBOnly() || h.SupportsSGB() {
+		t.Error("cartucho deveria ser exclusivo de CGB e sem SGB (licenciado antigo)")
+	}
+	if h.Licensee() != "Nintendo" {
+		t.Errorf("licenciado antigo: obtido %q", h.Licensee())
+	}
+}
+
+func TestHeaderValidation(t *testing.T) {
+	rom := newHeaderROM()
+	rom[HeaderLogo] ^= 0xFF
+	rom[HeaderVersion] = 0x03
+
+	h, err := ParseHeader(rom)
+	if err != nil {
+		t.Fatalf("erro ao decodificar header: %v", err)
+	}
+	if h.HeaderChecksumValid || h.GlobalChecksumValid || h.LogoValid {
+		t.Error("logo e checksums alterados deveriam ser inválidos")
+	}
+
+	warnings := h.Warnings(0x8000)
+	if len(warnings) != 3 || !strings.Contains(warnings[0], "menor") {
+		t.Errorf("esperado aviso de tamanho, checksum e logo, obtido %v", warnings)
+	}
+
+	if _, err := ParseHeader(make([]uint8, 0x100)); err == nil {
+		t.Error("ROM sem header deveria retornar erro")
+	}
+}
+
+func TestROMSizeFromHeader(t *testing.T) {
+	testCases := map[uint8]int{
+		0x00: 0x8000,
+		0x05: 0x100000,
+		0x08: 0x800000,
+		0x52: 72 * ROMBankSize,
+		0x09: 0,
+	}
+	for code, want := range testCases {
+		if got := ROMSizeFromHeader(code); got != want {
+			t.Errorf("código 0x%02X: esperado %d, obtido %d", code, want, got)
+		}
+	}
+}
+
+func TestFitROMSize(t *testing.T) {
+	rom := make([]uint8, 0x8000)
+	rom[0x4000] = 0x42
+
+	// Potência de 2: espelhada
+	mirrored := FitROMSize(rom, 0x20000)
+	if len(mirrored) != 0x20000 || mirrored[0x1C000] != 0x42 {
+		t.Errorf("ROM de 32KB deveria ser espelhada até 128KB")
+	}
+
+	// Dump truncado: completado com 0xFF
+	padded := FitROMSize(rom[:0x6000], 0x8000)
+	if padded[0x5FFF] != 0x00 || padded[0x6000] != 0xFF || padded[0x7FFF] != 0xFF {
+		t.Error("dump truncado deveria ser completado com 0xFF")
+	}
+
+	if got := FitROMSize(rom, 0x8000); len(got) != 0x8000 {
+		t.Error("ROM do tamanho certo não deveria mudar")
+	}
+}
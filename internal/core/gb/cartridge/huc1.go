@@ -0,0 +1,97 @@
+package cartridge
+
+import "fmt"
+
+// HuC1 é o mapeador da Hudson com RAM e porta infravermelha. A porta IR é
+// emulada apenas do lado do cartucho: o LED pode ser aceso, mas nenhuma luz
+// é recebida.
+type HuC1 struct {
+	base
+	regs huc1Registers
+}
+
+// huc1Registers guarda os registradores do HuC1
+type huc1Registers struct {
+	IRMode  bool  // 0x0000-0x1FFF: 0x0E mapeia a porta IR em 0xA000-0xBFFF
+	ROMBank uint8 // 6 bits (0 vira 1)
+	RAMBank uint8 // 2 bits
+	IRLED   bool  // Bit 0 da última escrita na porta IR
+}
+
+// HuC1IRNoLight é o valor lido da porta IR quando nenhuma luz é recebida
+const HuC1IRNoLight = 0xC0
+
+// newHuC1 cria um cartucho HuC1
+func newHuC1(rom []uint8, ramSize int, features Features) Cartridge {
+	c := &HuC1{base: newBase(rom, ramSize, features)}
+	c.Reset()
+	return c
+}
+
+// Reset reinicia os registradores do HuC1
+func (c *HuC1) Reset() {
+	c.regs = huc1Registers{ROMBank: 1}
+}
+
+// IRLED retorna se o LED infravermelho está aceso
+func (c *HuC1) IRLED() bool {
+	return c.regs.IRLED
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *HuC1) ReadROM(addr uint16) uint8 {
+	if addr < 0x4000 {
+		return c.readROMBank(0, addr)
+	}
+	return c.readROMBank(int(c.regs.ROMBank), addr)
+}
+
+// ReadRAM lê um byte da RAM externa ou da porta IR
+func (c *HuC1) ReadRAM(addr uint16) uint8 {
+	if c.regs.IRMode {
+		return HuC1IRNoLight
+	}
+	return c.readRAMBank(int(c.regs.RAMBank), addr)
+}
+
+// WriteControl escreve nos registradores do HuC1
+func (c *HuC1) WriteControl(addr uint16, value uint8) {
+	switch {
+	case addr <= 0x1FFF:
+		// O HuC1 não tem bit de habilitação: a RAM é sempre acessível
+		// fora do modo IR
+		c.regs.IRMode = value&0x0F == 0x0E
+	case addr <= 0x3FFF:
+		c.regs.ROMBank = value & 0x3F
+		if c.regs.ROMBank == 0 {
+			c.regs.ROMBank = 1
+		}
+	case addr <= 0x5FFF:
+		c.regs.RAMBank = value & 0x03
+	}
+}
+
+// WriteRAM escreve na RAM externa ou acende/apaga o LED IR
+func (c *HuC1) WriteRAM(addr uint16, value uint8) {
+	if c.regs.IRMode {
+		c.regs.IRLED = value&0x01 != 0
+		return
+	}
+	c.writeRAMBank(int(c.regs.RAMBank), addr, value)
+}
+
+// SaveState serializa os registradores e a RAM
+func (c *HuC1) SaveState() ([]byte, error) {
+	return c.marshalState(&c.regs)
+}
+
+// LoadState restaura os registradores e a RAM
+func (c *HuC1) LoadState(data []byte) error {
+	return c.unmarshalState(data, &c.regs)
+}
+
+// String retorna uma representação em string do cartucho
+func (c *HuC1) String() string {
+	return fmt.Sprintf("HuC1: ROM=%dKB RAM=%dKB ROMBank=%d RAMBank=%d IR=%v",
+		len(c.rom)/1024, len(c.ram)/1024, int(c.regs.ROMBank)%c.romBanks, c.regs.RAMBank, c.regs.IRMode)
+}
@@ -0,0 +1,269 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Modos do HuC3, selecionados pelos bits 0-3 de 0x0000-0x1FFF
+const (
+	HuC3ModeRAMRead  = 0x0 // RAM somente leitura
+	HuC3ModeRAM      = 0xA // RAM leitura/escrita
+	HuC3ModeCommand  = 0xB // Escrita de comandos do RTC
+	HuC3ModeResponse = 0xC // Leitura da resposta do RTC
+	HuC3ModeReady    = 0xD // Semáforo: bit 0 = 1 indica comando concluído
+	HuC3ModeIR       = 0xE // Porta infravermelha
+)
+
+// Comandos do RTC do HuC3 (bits 4-6 do valor escrito no modo 0xB)
+const (
+	huc3CmdRead       = 0x1 // Lê o nibble do endereço atual e avança
+	huc3CmdWrite      = 0x3 // Grava o nibble no endereço atual e avança
+	huc3CmdAddrLow    = 0x4 // Define os bits 0-3 do endereço
+	huc3CmdAddrHigh   = 0x5 // Define os bits 4-7 do endereço
+	huc3CmdExtended   = 0x6 // Comandos estendidos (argumento no nibble)
+	huc3ExtLatch      = 0x0 // Copia o relógio para a memória 0x00-0x05
+	huc3ExtCommit     = 0x1 // Copia a memória 0x00-0x05 para o relógio
+	huc3ExtStatus     = 0x2 // Consulta de estado (responde 1)
+	huc3MinutesPerDay = 24 * 60
+)
+
+// Tamanho do rodapé do HuC3 no arquivo de save: minutos e dias em uint32 e
+// timestamp Unix em uint64
+const HuC3FooterSize = 16
+
+// HuC3 é o mapeador da Hudson com relógio de tempo real e porta IR. O RTC é
+// acessado por comandos de 4 bits sobre uma memória interna de 256 nibbles;
+// o relógio conta minutos (12 bits) e dias (12 bits).
+type HuC3 struct {
+	base
+	regs huc3Registers
+
+	// Fonte de tempo do host (substituível em testes)
+	now func() time.Time
+}
+
+// huc3Registers guarda os registradores e o relógio do HuC3
+type huc3Registers struct {
+	Mode     uint8
+	ROMBank  uint8 // 7 bits (0 vira 1)
+	RAMBank  uint8 // 2 bits
+	Address  uint8 // Endereço na memória do RTC
+	Response uint8 // Último comando com o nibble de resposta
+	IRLED    bool
+
+	Minutes   uint16 // 0-1439
+	Days      uint16 // 12 bits
+	SubCycles int64  // Ciclos acumulados no minuto atual
+
+	Memory [256]uint8 // Nibbles
+}
+
+// newHuC3 cria um cartucho HuC3
+func newHuC3(rom []uint8, ramSize int, features Features) Cartridge {
+	c := &HuC3{base: newBase(rom, ramSize, features), now: time.Now}
+	c.Reset()
+	return c
+}
+
+// Reset reinicia os registradores do HuC3; o relógio continua contando
+func (c *HuC3) Reset() {
+	c.regs.Mode = HuC3ModeRAMRead
+	c.regs.ROMBank = 1
+	c.regs.RAMBank = 0
+	c.regs.Address = 0
+	c.regs.Response = 0
+	c.regs.IRLED = false
+}
+
+// Step avança o relógio
+func (c *HuC3) Step(cycles int) {
+	c.advanceCycles(int64(cycles))
+}
+
+// Advance avança o relógio pelo número de segundos informado
+func (c *HuC3) Advance(seconds int64) {
+	if seconds > 0 {
+		c.advanceCycles(seconds * RTCCyclesPerSecond)
+	}
+}
+
+// advanceCycles soma ciclos ao minuto atual e propaga para minutos e dias
+func (c *HuC3) advanceCycles(cycles int64) {
+	const cyclesPerMinute = 60 * RTCCyclesPerSecond
+
+	c.regs.SubCycles += cycles
+	if c.regs.SubCycles < cyclesPerMinute {
+		return
+	}
+
+	minutes := int64(c.regs.Minutes) + c.regs.SubCycles/cyclesPerMinute
+	c.regs.SubCycles %= cyclesPerMinute
+	days := int64(c.regs.Days) + minutes/huc3MinutesPerDay
+	c.regs.Minutes = uint16(minutes % huc3MinutesPerDay)
+	c.regs.Days = uint16(days & 0xFFF)
+}
+
+// Clock retorna os minutos do dia e o contador de dias
+func (c *HuC3) Clock() (minutes, days int) {
+	return int(c.regs.Minutes), int(c.regs.Days)
+}
+
+// IRLED retorna se o LED infravermelho está aceso
+func (c *HuC3) IRLED() bool {
+	return c.regs.IRLED
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *HuC3) ReadROM(addr uint16) uint8 {
+	if addr < 0x4000 {
+		return c.readROMBank(0, addr)
+	}
+	return c.readROMBank(int(c.regs.ROMBank), addr)
+}
+
+// ReadRAM lê a RAM externa ou o registrador do modo selecionado
+func (c *HuC3) ReadRAM(addr uint16) uint8 {
+	switch c.regs.Mode {
+	case HuC3ModeRAMRead, HuC3ModeRAM:
+		return c.readRAMBank(int(c.regs.RAMBank), addr)
+	case HuC3ModeResponse:
+		return 0x80 | c.regs.Response
+	case HuC3ModeReady:
+		// Os comandos são executados instantaneamente
+		return 0xFF
+	case HuC3ModeIR:
+		return HuC1IRNoLight
+	default:
+		return 0xFF
+	}
+}
+
+// WriteControl escreve nos registradores do HuC3
+func (c *HuC3) WriteControl(addr uint16, value uint8) {
+	switch {
+	case addr <= 0x1FFF:
+		c.regs.Mode = value & 0x0F
+	case addr <= 0x3FFF:
+		c.regs.ROMBank = value & 0x7F
+		if c.regs.ROMBank == 0 {
+			c.regs.ROMBank = 1
+		}
+	case addr <= 0x5FFF:
+		c.regs.RAMBank = value & 0x03
+	}
+}
+
+// WriteRAM escreve na RAM externa, executa um comando do RTC ou controla
+// o LED IR, conforme o modo
+func (c *HuC3) WriteRAM(addr uint16, value uint8) {
+	switch c.regs.Mode {
+	case HuC3ModeRAM:
+		c.writeRAMBank(int(c.regs.RAMBank), addr, value)
+	case HuC3ModeCommand:
+		c.command(value)
+	case HuC3ModeIR:
+		c.regs.IRLED = value&0x01 != 0
+	}
+}
+
+// command executa um comando do RTC
+func (c *HuC3) command(value uint8) {
+	cmd := (value >> 4) & 0x07
+	arg := value & 0x0F
+	c.regs.Response = value & 0x70
+
+	switch cmd {
+	case huc3CmdRead:
+		c.regs.Response |= c.regs.Memory[c.regs.Address] & 0x0F
+		c.regs.Address++
+	case huc3CmdWrite:
+		c.regs.Memory[c.regs.Address] = arg
+		c.regs.Address++
+		c.ramDirty = true
+	case huc3CmdAddrLow:
+		c.regs.Address = c.regs.Address&0xF0 | arg
+	case huc3CmdAddrHigh:
+		c.regs.Address = c.regs.Address&0x0F | arg<<4
+	case huc3CmdExtended:
+		switch arg {
+		case huc3ExtLatch:
+			c.latchClock()
+		case huc3ExtCommit:
+			c.commitClock()
+		case huc3ExtStatus:
+			c.regs.Response |= 0x01
+		}
+	}
+}
+
+// latchClock grava minutos e dias nos nibbles 0x00-0x05
+func (c *HuC3) latchClock() {
+	for i := 0; i < 3; i++ {
+		c.regs.Memory[i] = uint8(c.regs.Minutes>>(4*i)) & 0x0F
+		c.regs.Memory[3+i] = uint8(c.regs.Days>>(4*i)) & 0x0F
+	}
+}
+
+// commitClock carrega o relógio a partir dos nibbles 0x00-0x05
+func (c *HuC3) commitClock() {
+	var minutes, days uint16
+	for i := 0; i < 3; i++ {
+		minutes |= uint16(c.regs.Memory[i]&0x0F) << (4 * i)
+		days |= uint16(c.regs.Memory[3+i]&0x0F) << (4 * i)
+	}
+	c.regs.Minutes = minutes % huc3MinutesPerDay
+	c.regs.Days = days
+	c.regs.SubCycles = 0
+	c.ramDirty = true
+}
+
+// BatteryData retorna a RAM externa seguida do rodapé do relógio
+func (c *HuC3) BatteryData() []byte {
+	footer := make([]byte, HuC3FooterSize)
+	binary.LittleEndian.PutUint32(footer[0:], uint32(c.regs.Minutes))
+	binary.LittleEndian.PutUint32(footer[4:], uint32(c.regs.Days))
+	binary.LittleEndian.PutUint64(footer[8:], uint64(c.now().Unix()))
+	return append(c.base.BatteryData(), footer...)
+}
+
+// LoadBatteryData restaura a RAM e o relógio, avançando-o pelo tempo
+// decorrido desde que o save foi gravado. O rodapé é opcional.
+func (c *HuC3) LoadBatteryData(data []byte) error {
+	if err := c.base.LoadBatteryData(data); err != nil {
+		return err
+	}
+
+	footer := data[len(c.ram):]
+	if len(footer) < HuC3FooterSize {
+		return nil
+	}
+
+	c.regs.Minutes = uint16(binary.LittleEndian.Uint32(footer[0:]) % huc3MinutesPerDay)
+	c.regs.Days = uint16(binary.LittleEndian.Uint32(footer[4:]) & 0xFFF)
+	c.regs.SubCycles = 0
+
+	saved := int64(binary.LittleEndian.Uint64(footer[8:]))
+	if elapsed := c.now().Unix() - saved; saved > 0 && elapsed > 0 {
+		c.Advance(elapsed)
+	}
+	return nil
+}
+
+// SaveState serializa os registradores, o relógio e a RAM
+func (c *HuC3) SaveState() ([]byte, error) {
+	return c.marshalState(&c.regs)
+}
+
+// LoadState restaura os registradores, o relógio e a RAM
+func (c *HuC3) LoadState(data []byte) error {
+	return c.unmarshalState(data, &c.regs)
+}
+
+// String retorna uma representação em string do cartucho
+func (c *HuC3) String() string {
+	return fmt.Sprintf("HuC3: ROM=%dKB RAM=%dKB ROMBank=%d RAMBank=%d Mode=0x%X Clock=%dd%02d:%02d",
+		len(c.rom)/1024, len(c.ram)/1024, int(c.regs.ROMBank)%c.romBanks, c.regs.RAMBank, c.regs.Mode,
+		c.regs.Days, c.regs.Minutes/60, c.regs.Minutes%60)
+}
@@ -0,0 +1,160 @@
+package cartridge
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// MBC1 suporta até 2MB de ROM e 32KB de RAM. Os registradores BANK1 (5
+// bits) e BANK2 (2 bits) formam o número do banco; no modo 1, BANK2 também
+// seleciona o banco de 0x0000-0x3FFF e o banco de RAM.
+//
+// Nos multicarts (MBC1M) o bit 4 de BANK1 não é ligado ao chip de ROM e
+// BANK2 seleciona um dos quatro jogos de 256KB.
+type MBC1 struct {
+	base
+	regs      mbc1Registers
+	multicart bool
+}
+
+// mbc1Registers guarda os registradores do MBC1
+type mbc1Registers struct {
+	RAMEnabled bool
+	Bank1      uint8 // 0x2000-0x3FFF: bits 0-4 do banco (0 vira 1)
+	Bank2      uint8 // 0x4000-0x5FFF: bits 5-6 do banco ou banco de RAM
+	Mode       uint8 // 0x6000-0x7FFF: modo de banking
+}
+
+// newMBC1 cria um cartucho MBC1
+func newMBC1(rom []uint8, ramSize int, features Features) Cartridge {
+	c := &MBC1{base: newBase(rom, ramSize, features)}
+	c.multicart = isMBC1Multicart(rom)
+	c.Reset()
+	return c
+}
+
+// isMBC1Multicart detecta um MBC1M: ROM de 1MB com o logo da Nintendo no
+// header de pelo menos dois dos jogos seguintes ao menu (bancos 0x10,
+// 0x20 e 0x30)
+func isMBC1Multicart(rom []uint8) bool {
+	if len(rom) != 64*ROMBankSize {
+		return false
+	}
+
+	matches := 0
+	for game := 1; game < 4; game++ {
+		offset := game*0x10*ROMBankSize + HeaderLogo
+		if bytes.Equal(rom[offset:offset+len(NintendoLogo)], NintendoLogo[:]) {
+			matches++
+		}
+	}
+	return matches >= 2
+}
+
+// IsMulticart retorna se o cartucho foi detectado como MBC1M
+func (c *MBC1) IsMulticart() bool {
+	return c.multicart
+}
+
+// bank2Shift retorna a posição de BANK2 no número do banco
+func (c *MBC1) bank2Shift() uint {
+	if c.multicart {
+		return 4
+	}
+	return 5
+}
+
+// bank1Bits retorna os bits de BANK1 ligados ao chip de ROM
+func (c *MBC1) bank1Bits() int {
+	if c.multicart {
+		return int(c.regs.Bank1 & 0x0F)
+	}
+	return int(c.regs.Bank1)
+}
+
+// Reset reinicia os registradores do MBC1
+func (c *MBC1) Reset() {
+	c.regs = mbc1Registers{Bank1: 1}
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *MBC1) ReadROM(addr uint16) uint8 {
+	if addr < 0x4000 {
+		return c.readROMBank(c.zeroBank(), addr)
+	}
+	return c.readROMBank(c.highBank(), addr)
+}
+
+// zeroBank retorna o banco mapeado em 0x0000-0x3FFF
+func (c *MBC1) zeroBank() int {
+	if c.regs.Mode == 0 {
+		return 0
+	}
+	return int(c.regs.Bank2) << c.bank2Shift()
+}
+
+// highBank retorna o banco mapeado em 0x4000-0x7FFF
+func (c *MBC1) highBank() int {
+	return int(c.regs.Bank2)<<c.bank2Shift() | c.bank1Bits()
+}
+
+// ramBank retorna o banco de RAM selecionado
+func (c *MBC1) ramBank() int {
+	if c.regs.Mode == 0 {
+		return 0
+	}
+	return int(c.regs.Bank2)
+}
+
+// ReadRAM lê um byte da RAM externa
+func (c *MBC1) ReadRAM(addr uint16) uint8 {
+	if !c.regs.RAMEnabled {
+		return 0xFF
+	}
+	return c.readRAMBank(c.ramBank(), addr)
+}
+
+// WriteControl escreve nos registradores do MBC1
+func (c *MBC1) WriteControl(addr uint16, value uint8) {
+	switch {
+	case addr <= 0x1FFF:
+		c.regs.RAMEnabled = ramEnableValue(value)
+	case addr <= 0x3FFF:
+		// O teste de zero usa os 5 bits, mesmo em ROMs pequenas
+		c.regs.Bank1 = value & 0x1F
+		if c.regs.Bank1 == 0 {
+			c.regs.Bank1 = 1
+		}
+	case addr <= 0x5FFF:
+		c.regs.Bank2 = value & 0x03
+	default:
+		c.regs.Mode = value & 0x01
+	}
+}
+
+// WriteRAM escreve na RAM externa
+func (c *MBC1) WriteRAM(addr uint16, value uint8) {
+	if c.regs.RAMEnabled {
+		c.writeRAMBank(c.ramBank(), addr, value)
+	}
+}
+
+// SaveState serializa os registradores e a RAM
+func (c *MBC1) SaveState() ([]byte, error) {
+	return c.marshalState(&c.regs)
+}
+
+// LoadState restaura os registradores e a RAM
+func (c *MBC1) LoadState(data []byte) error {
+	return c.unmarshalState(data, &c.regs)
+}
+
+// String retorna uma representação em string do cartucho
+func (c *MBC1) String() string {
+	name := "MBC1"
+	if c.multicart {
+		name = "MBC1M"
+	}
+	return fmt.Sprintf("%s: ROM=%dKB RAM=%dKB ROMBank=%d RAMBank=%d Mode=%d",
+		name, len(c.rom)/1024, len(c.ram)/1024, c.highBank()%c.romBanks, c.ramBank(), c.regs.Mode)
+}
@@ -0,0 +1,96 @@
+package cartridge
+
+import "fmt"
+
+// MBC2 suporta até 256KB de ROM e possui 512 x 4 bits de RAM interna,
+// espelhada em toda a janela 0xA000-0xBFFF. O bit 8 do endereço escolhe
+// entre o enable da RAM e o banco de ROM.
+type MBC2 struct {
+	base
+	regs mbc2Registers
+}
+
+// mbc2Registers guarda os registradores do MBC2
+type mbc2Registers struct {
+	RAMEnabled bool
+	ROMBank    uint8
+}
+
+// newMBC2 cria um cartucho MBC2; o header sempre informa RAM 0x00
+func newMBC2(rom []uint8, ramSize int, features Features) Cartridge {
+	c := &MBC2{base: newBase(rom, MBC2RAMSize, features)}
+	c.Reset()
+	return c
+}
+
+// Reset reinicia os registradores do MBC2
+func (c *MBC2) Reset() {
+	c.regs = mbc2Registers{ROMBank: 1}
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *MBC2) ReadROM(addr uint16) uint8 {
+	if addr < 0x4000 {
+		return c.readROMBank(0, addr)
+	}
+	return c.readROMBank(int(c.regs.ROMBank), addr)
+}
+
+// ReadRAM lê um byte da RAM interna; apenas os 4 bits inferiores existem
+func (c *MBC2) ReadRAM(addr uint16) uint8 {
+	if !c.regs.RAMEnabled {
+		return 0xFF
+	}
+	return c.ram[int(addr)%MBC2RAMSize] | 0xF0
+}
+
+// WriteControl escreve nos registradores do MBC2
+func (c *MBC2) WriteControl(addr uint16, value uint8) {
+	if addr > 0x3FFF {
+		return
+	}
+
+	if addr&0x0100 == 0 {
+		c.regs.RAMEnabled = ramEnableValue(value)
+		return
+	}
+
+	c.regs.ROMBank = value & 0x0F
+	if c.regs.ROMBank == 0 {
+		c.regs.ROMBank = 1
+	}
+}
+
+// WriteRAM escreve na RAM interna
+func (c *MBC2) WriteRAM(addr uint16, value uint8) {
+	if c.regs.RAMEnabled {
+		c.ram[int(addr)%MBC2RAMSize] = value & 0x0F
+		c.ramDirty = true
+	}
+}
+
+// LoadBatteryData restaura a RAM interna, descartando os 4 bits superiores
+func (c *MBC2) LoadBatteryData(data []byte) error {
+	if err := c.base.LoadBatteryData(data); err != nil {
+		return err
+	}
+	for i := range c.ram {
+		c.ram[i] &= 0x0F
+	}
+	return nil
+}
+
+// SaveState serializa os registradores e a RAM
+func (c *MBC2) SaveState() ([]byte, error) {
+	return c.marshalState(&c.regs)
+}
+
+// LoadState restaura os registradores e a RAM
+func (c *MBC2) LoadState(data []byte) error {
+	return c.unmarshalState(data, &c.regs)
+}
+
+// String retorna uma representação em string do cartucho
+func (c *MBC2) String() string {
+	return fmt.Sprintf("MBC2: ROM=%dKB ROMBank=%d", len(c.rom)/1024, int(c.regs.ROMBank)%c.romBanks)
+}
@@ -0,0 +1,170 @@
+package cartridge
+
+import "fmt"
+
+// MBC3 suporta até 2MB de ROM, 32KB de RAM e, nas variantes com TIMER, o
+// relógio de tempo real mapeado na janela de RAM (registradores 0x08-0x0C)
+type MBC3 struct {
+	base
+	regs mbc3Registers
+	rtc  *RTC
+}
+
+// mbc3Registers guarda os registradores do MBC3
+type mbc3Registers struct {
+	RAMEnabled bool
+	ROMBank    uint8 // 7 bits (0 vira 1)
+	RAMBank    uint8 // Banco de RAM (0x00-0x03) ou registrador do RTC (0x08-0x0C)
+}
+
+// mbc3State é o estado serializado nos save states
+type mbc3State struct {
+	Regs mbc3Registers
+	RTC  rtcState
+}
+
+// newMBC3 cria um cartucho MBC3
+func newMBC3(rom []uint8, ramSize int, features Features) Cartridge {
+	c := &MBC3{base: newBase(rom, ramSize, features)}
+	if features.Timer {
+		c.rtc = NewRTC()
+	}
+	c.Reset()
+	return c
+}
+
+// RTC retorna o relógio de tempo real (nil se o cartucho não possuir)
+func (c *MBC3) RTC() *RTC {
+	return c.rtc
+}
+
+// Reset reinicia os registradores do MBC3; o relógio continua contando
+func (c *MBC3) Reset() {
+	c.regs = mbc3Registers{ROMBank: 1}
+	if c.rtc != nil {
+		c.rtc.Reset()
+	}
+}
+
+// Step avança o RTC
+func (c *MBC3) Step(cycles int) {
+	if c.rtc != nil {
+		c.rtc.Step(cycles)
+	}
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *MBC3) ReadROM(addr uint16) uint8 {
+	if addr < 0x4000 {
+		return c.readROMBank(0, addr)
+	}
+	return c.readROMBank(int(c.regs.ROMBank), addr)
+}
+
+// isRTCSelected retorna se a janela de RAM está mapeada no RTC
+func (c *MBC3) isRTCSelected() bool {
+	return c.rtc != nil && IsRTCRegister(c.regs.RAMBank)
+}
+
+// ReadRAM lê um byte da RAM externa ou um registrador do RTC
+func (c *MBC3) ReadRAM(addr uint16) uint8 {
+	switch {
+	case !c.regs.RAMEnabled:
+		return 0xFF
+	case c.isRTCSelected():
+		return c.rtc.ReadRegister(c.regs.RAMBank)
+	case c.regs.RAMBank > 0x03:
+		return 0xFF
+	default:
+		return c.readRAMBank(int(c.regs.RAMBank), addr)
+	}
+}
+
+// WriteControl escreve nos registradores do MBC3
+func (c *MBC3) WriteControl(addr uint16, value uint8) {
+	switch {
+	case addr <= 0x1FFF:
+		c.regs.RAMEnabled = ramEnableValue(value)
+	case addr <= 0x3FFF:
+		c.regs.ROMBank = value & 0x7F
+		if c.regs.ROMBank == 0 {
+			c.regs.ROMBank = 1
+		}
+	case addr <= 0x5FFF:
+		if IsRTCRegister(value) {
+			c.regs.RAMBank = value
+		} else {
+			c.regs.RAMBank = value & 0x03
+		}
+	default:
+		// Latch Clock Data
+		if c.rtc != nil {
+			c.rtc.WriteLatch(value)
+		}
+	}
+}
+
+// WriteRAM escreve na RAM externa ou em um registrador do RTC
+func (c *MBC3) WriteRAM(addr uint16, value uint8) {
+	switch {
+	case !c.regs.RAMEnabled:
+	case c.isRTCSelected():
+		c.rtc.WriteRegister(c.regs.RAMBank, value)
+		c.ramDirty = true
+	case c.regs.RAMBank <= 0x03:
+		c.writeRAMBank(int(c.regs.RAMBank), addr, value)
+	}
+}
+
+// BatteryData retorna a RAM externa seguida do rodapé de 48 bytes do RTC,
+// quando presente
+func (c *MBC3) BatteryData() []byte {
+	data := c.base.BatteryData()
+	if c.rtc != nil {
+		data = append(data, c.rtc.MarshalFooter()...)
+	}
+	return data
+}
+
+// LoadBatteryData restaura a RAM e, se presente, o rodapé do RTC. Saves
+// antigos sem rodapé continuam válidos.
+func (c *MBC3) LoadBatteryData(data []byte) error {
+	if err := c.base.LoadBatteryData(data); err != nil {
+		return err
+	}
+
+	footer := data[len(c.ram):]
+	if c.rtc != nil && len(footer) >= RTCFooterSize {
+		return c.rtc.UnmarshalFooter(footer[:RTCFooterSize])
+	}
+	return nil
+}
+
+// SaveState serializa os registradores, o RTC e a RAM
+func (c *MBC3) SaveState() ([]byte, error) {
+	state := mbc3State{Regs: c.regs}
+	if c.rtc != nil {
+		state.RTC = c.rtc.state()
+	}
+	return c.marshalState(&state)
+}
+
+// LoadState restaura os registradores, o RTC e a RAM
+func (c *MBC3) LoadState(data []byte) error {
+	var state mbc3State
+	if err := c.unmarshalState(data, &state); err != nil {
+		return err
+	}
+
+	c.regs = state.Regs
+	if c.rtc != nil {
+		c.rtc.setState(state.RTC)
+	}
+	return nil
+}
+
+// String retorna uma representação em string do cartucho
+func (c *MBC3) String() string {
+	return fmt.Sprintf("MBC3: ROM=%dKB RAM=%dKB ROMBank=%d RAMBank=0x%02X RTC=%v",
+		len(c.rom)/1024, len(c.ram)/1024, int(c.regs.ROMBank)%c.romBanks, c.regs.RAMBank, c.rtc != nil)
+}
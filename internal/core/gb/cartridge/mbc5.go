@@ -0,0 +1,133 @@
+package cartridge
+
+import "fmt"
+
+// MBC5 suporta até 8MB de ROM (banco de 9 bits, banco 0 permitido em
+// 0x4000-0x7FFF) e 128KB de RAM. Nas variantes com rumble (0x1C-0x1E) o
+// bit 3 do banco de RAM liga o motor e só os bits 0-2 selecionam o banco.
+type MBC5 struct {
+	base
+	regs   mbc5Registers
+	rumble bool
+
+	// Ciclos com o motor ligado e ciclos totais desde o último RumbleFrame
+	motorCycles int64
+	frameCycles int64
+}
+
+// mbc5Registers guarda os registradores do MBC5
+type mbc5Registers struct {
+	RAMEnabled bool
+	ROMBank    uint16 // 9 bits
+	RAMBank    uint8  // 4 bits (3 nas variantes com rumble)
+	Motor      bool   // Bit 3 de 0x4000-0x5FFF nas variantes com rumble
+}
+
+// newMBC5 cria um cartucho MBC5
+func newMBC5(rom []uint8, ramSize int, features Features) Cartridge {
+	c := &MBC5{base: newBase(rom, ramSize, features), rumble: features.Rumble}
+	c.Reset()
+	return c
+}
+
+// HasRumble retorna se o cartucho possui motor de vibração
+func (c *MBC5) HasRumble() bool {
+	return c.rumble
+}
+
+// MotorOn retorna se o motor está ligado
+func (c *MBC5) MotorOn() bool {
+	return c.regs.Motor
+}
+
+// RumbleFrame retorna o estado do motor e a fração dos ciclos em que ele
+// esteve ligado desde a chamada anterior
+func (c *MBC5) RumbleFrame() (bool, float64) {
+	var duty float64
+	if c.frameCycles > 0 {
+		duty = float64(c.motorCycles) / float64(c.frameCycles)
+	}
+	c.motorCycles = 0
+	c.frameCycles = 0
+	return c.regs.Motor, duty
+}
+
+// Step contabiliza o tempo com o motor ligado
+func (c *MBC5) Step(cycles int) {
+	if !c.rumble {
+		return
+	}
+	c.frameCycles += int64(cycles)
+	if c.regs.Motor {
+		c.motorCycles += int64(cycles)
+	}
+}
+
+// Reset reinicia os registradores do MBC5
+func (c *MBC5) Reset() {
+	c.regs = mbc5Registers{ROMBank: 1}
+	c.motorCycles = 0
+	c.frameCycles = 0
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *MBC5) ReadROM(addr uint16) uint8 {
+	if addr < 0x4000 {
+		return c.readROMBank(0, addr)
+	}
+	return c.readROMBank(int(c.regs.ROMBank), addr)
+}
+
+// ReadRAM lê um byte da RAM externa
+func (c *MBC5) ReadRAM(addr uint16) uint8 {
+	if !c.regs.RAMEnabled {
+		return 0xFF
+	}
+	return c.readRAMBank(int(c.regs.RAMBank), addr)
+}
+
+// WriteControl escreve nos registradores do MBC5
+func (c *MBC5) WriteControl(addr uint16, value uint8) {
+	switch {
+	case addr <= 0x1FFF:
+		c.regs.RAMEnabled = ramEnableValue(value)
+	case addr <= 0x2FFF:
+		c.regs.ROMBank = (c.regs.ROMBank & 0x100) | uint16(value)
+	case addr <= 0x3FFF:
+		c.regs.ROMBank = (c.regs.ROMBank & 0xFF) | uint16(value&0x01)<<8
+	case addr <= 0x5FFF:
+		if c.rumble {
+			c.regs.RAMBank = value & 0x07
+			c.regs.Motor = value&0x08 != 0
+		} else {
+			c.regs.RAMBank = value & 0x0F
+		}
+	}
+}
+
+// WriteRAM escreve na RAM externa
+func (c *MBC5) WriteRAM(addr uint16, value uint8) {
+	if c.regs.RAMEnabled {
+		c.writeRAMBank(int(c.regs.RAMBank), addr, value)
+	}
+}
+
+// SaveState serializa os registradores e a RAM
+func (c *MBC5) SaveState() ([]byte, error) {
+	return c.marshalState(&c.regs)
+}
+
+// LoadState restaura os registradores e a RAM
+func (c *MBC5) LoadState(data []byte) error {
+	return c.unmarshalState(data, &c.regs)
+}
+
+// String retorna uma representação em string do cartucho
+func (c *MBC5) String() string {
+	if c.rumble {
+		return fmt.Sprintf("MBC5+RUMBLE: ROM=%dKB RAM=%dKB ROMBank=%d RAMBank=%d Motor=%v",
+			len(c.rom)/1024, len(c.ram)/1024, int(c.regs.ROMBank)%c.romBanks, c.regs.RAMBank, c.regs.Motor)
+	}
+	return fmt.Sprintf("MBC5: ROM=%dKB RAM=%dKB ROMBank=%d RAMBank=%d",
+		len(c.rom)/1024, len(c.ram)/1024, int(c.regs.ROMBank)%c.romBanks, c.regs.RAMBank)
+}
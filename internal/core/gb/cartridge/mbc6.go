@@ -0,0 +1,284 @@
+package cartridge
+
+import "fmt"
+
+// Constantes do MBC6
+const (
+	MBC6ROMBankSize = 0x2000   // Janelas de ROM/flash de 8KB
+	MBC6RAMBankSize = 0x1000   // Janelas de RAM de 4KB
+	MBC6RAMSize     = 0x8000   // RAM padrão quando o header informa 0
+	MBC6FlashSize   = 0x100000 // Flash Macronix MX29F008 de 1MB
+
+	// Setor apagado pelo comando 0x30
+	MBC6FlashSectorSize = 0x20000
+
+	// Identificação retornada no modo ID (0x90)
+	MBC6FlashManufacturer = 0xC2
+	MBC6FlashDevice       = 0x81
+)
+
+// Estados da sequência de comandos JEDEC da flash
+const (
+	flashIdle = iota
+	flashUnlock1
+	flashUnlock2
+	flashProgram
+	flashErase
+	flashEraseUnlock1
+	flashEraseUnlock2
+)
+
+// MBC6 divide 0x4000-0x7FFF e 0xA000-0xBFFF em duas janelas independentes
+// (A e B). Cada janela de ROM pode mapear a ROM ou a flash de 1MB.
+type MBC6 struct {
+	base
+	regs  mbc6Registers
+	flash []uint8
+}
+
+// mbc6Registers guarda os registradores do MBC6
+type mbc6Registers struct {
+	RAMEnabled        bool
+	RAMBankA          uint8 // 0x0400-0x07FF
+	RAMBankB          uint8 // 0x0800-0x0BFF
+	FlashEnabled      bool  // 0x0C00-0x0FFF
+	FlashWriteEnabled bool  // 0x1000
+	ROMBankA          uint8 // 0x2000-0x27FF
+	FlashA            bool  // 0x2800-0x2FFF: 0x08 mapeia a flash
+	ROMBankB          uint8 // 0x3000-0x37FF
+	FlashB            bool  // 0x3800-0x3FFF: 0x08 mapeia a flash
+	FlashState        uint8 // Passo da sequência JEDEC
+	FlashID           bool  // Modo de identificação
+}
+
+// mbc6State é o estado serializado nos save states
+type mbc6State struct {
+	Regs  mbc6Registers
+	Flash [MBC6FlashSize]uint8
+}
+
+// newMBC6 cria um cartucho MBC6
+func newMBC6(rom []uint8, ramSize int, features Features) Cartridge {
+	if ramSize == 0 {
+		ramSize = MBC6RAMSize
+	}
+
+	c := &MBC6{base: newBase(rom, ramSize, features), flash: make([]uint8, MBC6FlashSize)}
+	for i := range c.flash {
+		c.flash[i] = 0xFF
+	}
+	c.Reset()
+	return c
+}
+
+// Reset reinicia os registradores do MBC6
+func (c *MBC6) Reset() {
+	c.regs = mbc6Registers{ROMBankA: 2, ROMBankB: 3}
+}
+
+// Flash retorna o conteúdo da flash
+func (c *MBC6) Flash() []uint8 {
+	return c.flash
+}
+
+// window retorna o banco e se a janela (0x4000 ou 0x6000) mapeia a flash
+func (c *MBC6) window(addr uint16) (int, bool) {
+	if addr < 0x6000 {
+		return int(c.regs.ROMBankA), c.regs.FlashA
+	}
+	return int(c.regs.ROMBankB), c.regs.FlashB
+}
+
+// flashAddress converte banco e endereço da janela em um offset da flash
+func flashAddress(bank int, addr uint16) int {
+	return (bank*MBC6ROMBankSize + int(addr&0x1FFF)) % MBC6FlashSize
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *MBC6) ReadROM(addr uint16) uint8 {
+	if addr < 0x4000 {
+		return c.readROMBank(0, addr)
+	}
+
+	bank, flash := c.window(addr)
+	if !flash {
+		index := (bank*MBC6ROMBankSize)%len(c.rom) + int(addr&0x1FFF)
+		if index < len(c.rom) {
+			return c.rom[index]
+		}
+		return 0xFF
+	}
+
+	if !c.regs.FlashEnabled {
+		return 0xFF
+	}
+	offset := flashAddress(bank, addr)
+	if c.regs.FlashID {
+		switch offset & 0xFF {
+		case 0x00:
+			return MBC6FlashManufacturer
+		case 0x01:
+			return MBC6FlashDevice
+		}
+	}
+	return c.flash[offset]
+}
+
+// ramOffset converte o endereço 0xA000-0xBFFF em um índice da RAM
+func (c *MBC6) ramOffset(addr uint16) int {
+	bank := c.regs.RAMBankA
+	if addr >= 0xB000 {
+		bank = c.regs.RAMBankB
+	}
+	return (int(bank)*MBC6RAMBankSize + int(addr&0x0FFF)) % len(c.ram)
+}
+
+// ReadRAM lê um byte da RAM externa
+func (c *MBC6) ReadRAM(addr uint16) uint8 {
+	if !c.regs.RAMEnabled {
+		return 0xFF
+	}
+	return c.ram[c.ramOffset(addr)]
+}
+
+// WriteControl escreve nos registradores do MBC6; escritas em 0x4000-0x7FFF
+// com a flash mapeada são comandos para a flash
+func (c *MBC6) WriteControl(addr uint16, value uint8) {
+	switch {
+	case addr <= 0x03FF:
+		c.regs.RAMEnabled = ramEnableValue(value)
+	case addr <= 0x07FF:
+		c.regs.RAMBankA = value & 0x07
+	case addr <= 0x0BFF:
+		c.regs.RAMBankB = value & 0x07
+	case addr <= 0x0FFF:
+		c.regs.FlashEnabled = value&0x01 != 0
+	case addr <= 0x1FFF:
+		if addr == 0x1000 {
+			c.regs.FlashWriteEnabled = value&0x01 != 0
+		}
+	case addr <= 0x27FF:
+		c.regs.ROMBankA = value & 0x7F
+	case addr <= 0x2FFF:
+		c.regs.FlashA = value&0x08 != 0
+	case addr <= 0x37FF:
+		c.regs.ROMBankB = value & 0x7F
+	case addr <= 0x3FFF:
+		c.regs.FlashB = value&0x08 != 0
+	default:
+		if bank, flash := c.window(addr); flash && c.regs.FlashEnabled {
+			c.writeFlash(flashAddress(bank, addr), value)
+		}
+	}
+}
+
+// writeFlash avança a sequência de comandos JEDEC da flash
+func (c *MBC6) writeFlash(offset int, value uint8) {
+	if value == 0xF0 {
+		c.regs.FlashState = flashIdle
+		c.regs.FlashID = false
+		return
+	}
+
+	command := offset & 0x7FFF
+	switch c.regs.FlashState {
+	case flashIdle, flashErase:
+		if command == 0x5555 && value == 0xAA {
+			c.regs.FlashState++
+			return
+		}
+	case flashUnlock1, flashEraseUnlock1:
+		if command == 0x2AAA && value == 0x55 {
+			c.regs.FlashState++
+			return
+		}
+	case flashUnlock2:
+		if command == 0x5555 {
+			switch value {
+			case 0xA0:
+				c.regs.FlashState = flashProgram
+				return
+			case 0x80:
+				c.regs.FlashState = flashErase
+				return
+			case 0x90:
+				c.regs.FlashID = true
+			}
+		}
+	case flashProgram:
+		// Programar só limpa bits; apenas um apagamento volta a 0xFF
+		if c.regs.FlashWriteEnabled {
+			c.flash[offset] &= value
+			c.ramDirty = true
+		}
+	case flashEraseUnlock2:
+		if c.regs.FlashWriteEnabled {
+			switch {
+			case value == 0x30:
+				c.eraseFlash(offset&^(MBC6FlashSectorSize-1), MBC6FlashSectorSize)
+			case value == 0x10 && command == 0x5555:
+				c.eraseFlash(0, MBC6FlashSize)
+			}
+		}
+	}
+	c.regs.FlashState = flashIdle
+}
+
+// eraseFlash apaga (0xFF) uma região da flash
+func (c *MBC6) eraseFlash(start, size int) {
+	for i := start; i < start+size; i++ {
+		c.flash[i] = 0xFF
+	}
+	c.ramDirty = true
+}
+
+// WriteRAM escreve na RAM externa
+func (c *MBC6) WriteRAM(addr uint16, value uint8) {
+	if c.regs.RAMEnabled {
+		c.ram[c.ramOffset(addr)] = value
+		c.ramDirty = true
+	}
+}
+
+// BatteryData retorna a RAM externa seguida da flash
+func (c *MBC6) BatteryData() []byte {
+	return append(c.base.BatteryData(), c.flash...)
+}
+
+// LoadBatteryData restaura a RAM e, se presente, a flash
+func (c *MBC6) LoadBatteryData(data []byte) error {
+	if err := c.base.LoadBatteryData(data); err != nil {
+		return err
+	}
+
+	if flash := data[len(c.ram):]; len(flash) >= MBC6FlashSize {
+		copy(c.flash, flash)
+	}
+	return nil
+}
+
+// SaveState serializa os registradores, a flash e a RAM
+func (c *MBC6) SaveState() ([]byte, error) {
+	state := mbc6State{Regs: c.regs}
+	copy(state.Flash[:], c.flash)
+	return c.marshalState(&state)
+}
+
+// LoadState restaura os registradores, a flash e a RAM
+func (c *MBC6) LoadState(data []byte) error {
+	var state mbc6State
+	if err := c.unmarshalState(data, &state); err != nil {
+		return err
+	}
+
+	c.regs = state.Regs
+	copy(c.flash, state.Flash[:])
+	return nil
+}
+
+// String retorna uma representação em string do cartucho
+func (c *MBC6) String() string {
+	return fmt.Sprintf("MBC6: ROM=%dKB RAM=%dKB A=%d(flash=%v) B=%d(flash=%v) RAMBanks=%d/%d",
+		len(c.rom)/1024, len(c.ram)/1024, c.regs.ROMBankA, c.regs.FlashA,
+		c.regs.ROMBankB, c.regs.FlashB, c.regs.RAMBankA, c.regs.RAMBankB)
+}
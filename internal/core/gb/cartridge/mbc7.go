@@ -0,0 +1,339 @@
+package cartridge
+
+import "fmt"
+
+// Constantes do MBC7
+const (
+	// EEPROM 93LC56 (128 palavras de 16 bits), gravada no arquivo .sav
+	MBC7EEPROMSize = 0x100
+
+	// Leitura do acelerômetro em repouso e variação por 1g
+	MBC7AccelCenter = 0x81D0
+	MBC7AccelGain   = 0x70
+
+	// Valor dos registradores após apagar o latch
+	MBC7AccelErased = 0x8000
+
+	// Bits do registrador da EEPROM (Ax8x)
+	MBC7EEPROMCS  = 1 << 7
+	MBC7EEPROMCLK = 1 << 6
+	MBC7EEPROMDI  = 1 << 1
+	MBC7EEPROMDO  = 1 << 0
+)
+
+// Estados do protocolo serial da EEPROM
+const (
+	eepromIdle    = iota // Aguardando o start bit
+	eepromCommand        // Recebendo opcode (2 bits) e endereço (8 bits)
+	eepromRead           // Enviando a palavra lida
+	eepromWrite          // Recebendo a palavra a gravar
+)
+
+// TiltSource fornece a inclinação do console (em g, de -1 a 1 por eixo)
+type TiltSource interface {
+	Tilt() (x, y float64)
+}
+
+// MBC7 é o mapeador com acelerômetro de 2 eixos e EEPROM serial 93LC56.
+// Os registradores aparecem em 0xA000-0xAFFF apenas com as duas
+// habilitações de RAM ativas.
+type MBC7 struct {
+	base
+	regs mbc7Registers
+	tilt TiltSource
+}
+
+// mbc7Registers guarda os registradores do MBC7 e o estado da EEPROM
+type mbc7Registers struct {
+	RAMEnabled1 bool   // 0x0000-0x1FFF: 0x0A
+	RAMEnabled2 bool   // 0x4000-0x5FFF: 0x40
+	ROMBank     uint8  // 7 bits
+	AccelX      uint16 // Leituras travadas do acelerômetro
+	AccelY      uint16
+	AccelErased bool // 0x55 em Ax0x libera um novo latch
+
+	CS, CLK, DI, DO bool
+	State           uint8
+	Shift           uint16 // Bits recebidos
+	Bits            uint8  // Quantidade de bits recebidos
+	Output          uint16 // Palavra sendo enviada
+	Address         uint8
+	WriteEnabled    bool
+	WriteAll        bool
+}
+
+// newMBC7 cria um cartucho MBC7; a RAM é a EEPROM, independente do header
+func newMBC7(rom []uint8, ramSize int, features Features) Cartridge {
+	c := &MBC7{base: newBase(rom, MBC7EEPROMSize, features)}
+	for i := range c.ram {
+		c.ram[i] = 0xFF
+	}
+	c.Reset()
+	return c
+}
+
+// SetTiltSource define de onde vem a inclinação lida pelo acelerômetro
+func (c *MBC7) SetTiltSource(source TiltSource) {
+	c.tilt = source
+}
+
+// Reset reinicia os registradores do MBC7
+func (c *MBC7) Reset() {
+	c.regs = mbc7Registers{
+		ROMBank: 1,
+		AccelX:  MBC7AccelErased,
+		AccelY:  MBC7AccelErased,
+		DO:      true,
+	}
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *MBC7) ReadROM(addr uint16) uint8 {
+	if addr < 0x4000 {
+		return c.readROMBank(0, addr)
+	}
+	return c.readROMBank(int(c.regs.ROMBank), addr)
+}
+
+// ReadRAM lê um registrador do acelerômetro ou da EEPROM
+func (c *MBC7) ReadRAM(addr uint16) uint8 {
+	if !c.regs.RAMEnabled1 || !c.regs.RAMEnabled2 || addr >= 0xB000 {
+		return 0xFF
+	}
+
+	switch (addr >> 4) & 0x0F {
+	case 0x2:
+		return uint8(c.regs.AccelX)
+	case 0x3:
+		return uint8(c.regs.AccelX >> 8)
+	case 0x4:
+		return uint8(c.regs.AccelY)
+	case 0x5:
+		return uint8(c.regs.AccelY >> 8)
+	case 0x6:
+		return 0x00
+	case 0x8:
+		return c.eepromRegister()
+	default:
+		return 0xFF
+	}
+}
+
+// WriteControl escreve nos registradores do MBC7
+func (c *MBC7) WriteControl(addr uint16, value uint8) {
+	switch {
+	case addr <= 0x1FFF:
+		c.regs.RAMEnabled1 = ramEnableValue(value)
+		if !c.regs.RAMEnabled1 {
+			c.regs.RAMEnabled2 = false
+		}
+	case addr <= 0x3FFF:
+		c.regs.ROMBank = value & 0x7F
+	case addr <= 0x5FFF:
+		c.regs.RAMEnabled2 = c.regs.RAMEnabled1 && value == 0x40
+	}
+}
+
+// WriteRAM escreve nos registradores do acelerômetro ou da EEPROM
+func (c *MBC7) WriteRAM(addr uint16, value uint8) {
+	if !c.regs.RAMEnabled1 || !c.regs.RAMEnabled2 || addr >= 0xB000 {
+		return
+	}
+
+	switch (addr >> 4) & 0x0F {
+	case 0x0:
+		if value == 0x55 {
+			c.regs.AccelX = MBC7AccelErased
+			c.regs.AccelY = MBC7AccelErased
+			c.regs.AccelErased = true
+		}
+	case 0x1:
+		if value == 0xAA && c.regs.AccelErased {
+			c.latchAccelerometer()
+			c.regs.AccelErased = false
+		}
+	case 0x8:
+		c.writeEEPROM(value)
+	}
+}
+
+// latchAccelerometer converte a inclinação atual nas leituras de 16 bits
+func (c *MBC7) latchAccelerometer() {
+	var x, y float64
+	if c.tilt != nil {
+		x, y = c.tilt.Tilt()
+	}
+	c.regs.AccelX = accelValue(x)
+	c.regs.AccelY = accelValue(y)
+}
+
+// accelValue converte uma aceleração em g na leitura do sensor
+func accelValue(g float64) uint16 {
+	value := MBC7AccelCenter + int(g*MBC7AccelGain)
+	if value < 0 {
+		value = 0
+	} else if value > 0xFFFF {
+		value = 0xFFFF
+	}
+	return uint16(value)
+}
+
+// eepromRegister monta o valor lido em Ax8x
+func (c *MBC7) eepromRegister() uint8 {
+	var value uint8
+	if c.regs.CS {
+		value |= MBC7EEPROMCS
+	}
+	if c.regs.CLK {
+		value |= MBC7EEPROMCLK
+	}
+	if c.regs.DI {
+		value |= MBC7EEPROMDI
+	}
+	if c.regs.DO {
+		value |= MBC7EEPROMDO
+	}
+	return value
+}
+
+// writeEEPROM atualiza as linhas CS/CLK/DI; a EEPROM amostra DI na borda
+// de subida de CLK enquanto CS está alto
+func (c *MBC7) writeEEPROM(value uint8) {
+	cs := value&MBC7EEPROMCS != 0
+	clk := value&MBC7EEPROMCLK != 0
+	c.regs.DI = value&MBC7EEPROMDI != 0
+
+	if !cs {
+		// Baixar CS encerra qualquer comando
+		c.regs.State = eepromIdle
+		c.regs.DO = true
+	} else if !c.regs.CLK && clk {
+		c.clockEEPROM(c.regs.DI)
+	}
+
+	c.regs.CS = cs
+	c.regs.CLK = clk
+}
+
+// clockEEPROM processa um bit recebido pela EEPROM
+func (c *MBC7) clockEEPROM(bit bool) {
+	switch c.regs.State {
+	case eepromIdle:
+		if bit {
+			c.regs.State = eepromCommand
+			c.regs.Shift = 0
+			c.regs.Bits = 0
+		}
+	case eepromCommand:
+		c.shiftIn(bit)
+		if c.regs.Bits == 10 {
+			c.executeEEPROM()
+		}
+	case eepromRead:
+		c.regs.DO = c.regs.Output&0x8000 != 0
+		c.regs.Output <<= 1
+		c.regs.Bits++
+		if c.regs.Bits == 16 {
+			// Leitura sequencial continua na próxima palavra
+			c.regs.Address = (c.regs.Address + 1) & 0x7F
+			c.regs.Output = c.eepromWord(c.regs.Address)
+			c.regs.Bits = 0
+		}
+	case eepromWrite:
+		c.shiftIn(bit)
+		if c.regs.Bits == 16 {
+			if c.regs.WriteEnabled {
+				if c.regs.WriteAll {
+					for addr := 0; addr < MBC7EEPROMSize/2; addr++ {
+						c.setEEPROMWord(uint8(addr), c.regs.Shift)
+					}
+				} else {
+					c.setEEPROMWord(c.regs.Address, c.regs.Shift)
+				}
+			}
+			c.regs.State = eepromIdle
+			c.regs.DO = true
+		}
+	}
+}
+
+// shiftIn adiciona um bit ao registrador de entrada
+func (c *MBC7) shiftIn(bit bool) {
+	c.regs.Shift <<= 1
+	if bit {
+		c.regs.Shift |= 1
+	}
+	c.regs.Bits++
+}
+
+// executeEEPROM executa o comando de 10 bits recebido
+func (c *MBC7) executeEEPROM() {
+	opcode := (c.regs.Shift >> 8) & 0x03
+	address := uint8(c.regs.Shift) & 0x7F
+	c.regs.State = eepromIdle
+	c.regs.Bits = 0
+
+	switch opcode {
+	case 0x2: // READ: um bit 0 de preâmbulo e 16 bits de dados
+		c.regs.State = eepromRead
+		c.regs.Address = address
+		c.regs.Output = c.eepromWord(address)
+		c.regs.DO = false
+	case 0x1: // WRITE
+		c.regs.State = eepromWrite
+		c.regs.Address = address
+		c.regs.WriteAll = false
+		c.regs.Shift = 0
+	case 0x3: // ERASE
+		if c.regs.WriteEnabled {
+			c.setEEPROMWord(address, 0xFFFF)
+		}
+	default:
+		switch (c.regs.Shift >> 6) & 0x03 {
+		case 0x0: // EWDS
+			c.regs.WriteEnabled = false
+		case 0x1: // WRAL
+			c.regs.State = eepromWrite
+			c.regs.WriteAll = true
+			c.regs.Shift = 0
+		case 0x2: // ERAL
+			if c.regs.WriteEnabled {
+				for addr := 0; addr < MBC7EEPROMSize/2; addr++ {
+					c.setEEPROMWord(uint8(addr), 0xFFFF)
+				}
+			}
+		case 0x3: // EWEN
+			c.regs.WriteEnabled = true
+		}
+	}
+}
+
+// eepromWord lê uma palavra da EEPROM
+func (c *MBC7) eepromWord(addr uint8) uint16 {
+	index := int(addr&0x7F) * 2
+	return uint16(c.ram[index]) | uint16(c.ram[index+1])<<8
+}
+
+// setEEPROMWord grava uma palavra na EEPROM
+func (c *MBC7) setEEPROMWord(addr uint8, value uint16) {
+	index := int(addr&0x7F) * 2
+	c.ram[index] = uint8(value)
+	c.ram[index+1] = uint8(value >> 8)
+	c.ramDirty = true
+}
+
+// SaveState serializa os registradores e a EEPROM
+func (c *MBC7) SaveState() ([]byte, error) {
+	return c.marshalState(&c.regs)
+}
+
+// LoadState restaura os registradores e a EEPROM
+func (c *MBC7) LoadState(data []byte) error {
+	return c.unmarshalState(data, &c.regs)
+}
+
+// String retorna uma representação em string do cartucho
+func (c *MBC7) String() string {
+	return fmt.Sprintf("MBC7: ROM=%dKB ROMBank=%d Accel=(0x%04X,0x%04X) EEPROM=%v",
+		len(c.rom)/1024, int(c.regs.ROMBank)%c.romBanks, c.regs.AccelX, c.regs.AccelY, c.regs.WriteEnabled)
+}
@@ -0,0 +1,166 @@
+package cartridge
+
+import "fmt"
+
+// MMM01 é o mapeador das coletâneas multi-jogo. Após o reset ele mapeia os
+// últimos 32KB da ROM (o menu); quando o menu grava o bit 6 de 0x0000-0x1FFF
+// a configuração fica travada e o jogo escolhido passa a funcionar como um
+// cartucho MBC1 dentro da sua fatia da ROM.
+type MMM01 struct {
+	base
+	regs mmm01Registers
+}
+
+// mmm01Registers guarda os registradores do MMM01
+type mmm01Registers struct {
+	Mapped      bool  // Configuração travada (bit 6 de 0x0000)
+	RAMEnabled  bool  // 0x0000-0x1FFF: bits 0-3
+	RAMMask     uint8 // 0x0000-0x1FFF: bits 4-5, bits de banco de RAM fixos
+	ROMLow      uint8 // 0x2000-0x3FFF: bits 0-4 do banco de ROM
+	ROMMid      uint8 // 0x2000-0x3FFF: bits 5-6, bits 5-6 do banco de ROM
+	RAMLow      uint8 // 0x4000-0x5FFF: bits 0-1 do banco de RAM
+	RAMHigh     uint8 // 0x4000-0x5FFF: bits 2-3 do banco de RAM
+	ROMHigh     uint8 // 0x4000-0x5FFF: bits 4-5, bits 7-8 do banco de ROM
+	ModeLocked  bool  // 0x4000-0x5FFF: bit 6 impede escritas no modo
+	Mode        uint8 // 0x6000-0x7FFF: bit 0
+	ROMMask     uint8 // 0x6000-0x7FFF: bits 2-5, bits 1-4 de ROMLow fixos
+	Multiplexed bool  // 0x6000-0x7FFF: bit 6 troca ROMMid e RAMLow
+}
+
+// newMMM01 cria um cartucho MMM01
+func newMMM01(rom []uint8, ramSize int, features Features) Cartridge {
+	c := &MMM01{base: newBase(rom, ramSize, features)}
+	c.Reset()
+	return c
+}
+
+// Reset volta ao modo não mapeado, que expõe o menu no fim da ROM
+func (c *MMM01) Reset() {
+	c.regs = mmm01Registers{}
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *MMM01) ReadROM(addr uint16) uint8 {
+	if !c.regs.Mapped {
+		if addr < 0x4000 {
+			return c.readROMBank(c.romBanks-2, addr)
+		}
+		return c.readROMBank(c.romBanks-1, addr)
+	}
+
+	if addr < 0x4000 {
+		return c.readROMBank(c.zeroBank(), addr)
+	}
+	return c.readROMBank(c.highBank(), addr)
+}
+
+// outerBank retorna os bits do banco fixados pelo menu (jogo selecionado)
+func (c *MMM01) outerBank() int {
+	mid := c.regs.ROMMid
+	if c.regs.Multiplexed {
+		mid = c.regs.RAMLow
+	}
+	return int(c.regs.ROMHigh)<<7 | int(mid)<<5
+}
+
+// zeroBank retorna o banco mapeado em 0x0000-0x3FFF
+func (c *MMM01) zeroBank() int {
+	// Apenas os bits fixados pela máscara aparecem no banco 0 do jogo
+	return c.outerBank() | int(c.regs.ROMLow&c.regs.ROMMask)
+}
+
+// highBank retorna o banco mapeado em 0x4000-0x7FFF
+func (c *MMM01) highBank() int {
+	low := c.regs.ROMLow
+	// O teste de zero considera apenas os bits não fixados pela máscara
+	if low&^c.regs.ROMMask == 0 {
+		low |= 1
+	}
+	return c.outerBank() | int(low)
+}
+
+// ramBank retorna o banco de RAM selecionado
+func (c *MMM01) ramBank() int {
+	low := c.regs.RAMLow
+	if c.regs.Multiplexed {
+		low = c.regs.ROMMid
+	}
+	if c.regs.Mode == 0 {
+		low &= c.regs.RAMMask
+	}
+	return int(c.regs.RAMHigh)<<2 | int(low)
+}
+
+// ReadRAM lê um byte da RAM externa
+func (c *MMM01) ReadRAM(addr uint16) uint8 {
+	if !c.regs.RAMEnabled {
+		return 0xFF
+	}
+	return c.readRAMBank(c.ramBank(), addr)
+}
+
+// WriteControl escreve nos registradores do MMM01. Enquanto não mapeado,
+// todos os bits são graváveis; depois só os bits do MBC1 interno mudam.
+func (c *MMM01) WriteControl(addr uint16, value uint8) {
+	mapped := c.regs.Mapped
+
+	switch {
+	case addr <= 0x1FFF:
+		c.regs.RAMEnabled = ramEnableValue(value)
+		if !mapped {
+			c.regs.RAMMask = (value >> 4) & 0x03
+			c.regs.Mapped = value&0x40 != 0
+		}
+	case addr <= 0x3FFF:
+		// Bits fixados pela máscara não podem mais ser alterados
+		writable := uint8(0x1F)
+		if mapped {
+			writable &^= c.regs.ROMMask
+		}
+		c.regs.ROMLow = c.regs.ROMLow&^writable | value&writable
+		if !mapped {
+			c.regs.ROMMid = (value >> 5) & 0x03
+		}
+	case addr <= 0x5FFF:
+		if !mapped {
+			c.regs.RAMLow = value & 0x03
+			c.regs.RAMHigh = (value >> 2) & 0x03
+			c.regs.ROMHigh = (value >> 4) & 0x03
+			c.regs.ModeLocked = value&0x40 != 0
+		} else {
+			writable := ^c.regs.RAMMask & 0x03
+			c.regs.RAMLow = c.regs.RAMLow&^writable | value&writable
+		}
+	default:
+		if !c.regs.ModeLocked || !mapped {
+			c.regs.Mode = value & 0x01
+		}
+		if !mapped {
+			c.regs.ROMMask = (value & 0x3C) >> 1
+			c.regs.Multiplexed = value&0x40 != 0
+		}
+	}
+}
+
+// WriteRAM escreve na RAM externa
+func (c *MMM01) WriteRAM(addr uint16, value uint8) {
+	if c.regs.RAMEnabled {
+		c.writeRAMBank(c.ramBank(), addr, value)
+	}
+}
+
+// SaveState serializa os registradores e a RAM
+func (c *MMM01) SaveState() ([]byte, error) {
+	return c.marshalState(&c.regs)
+}
+
+// LoadState restaura os registradores e a RAM
+func (c *MMM01) LoadState(data []byte) error {
+	return c.unmarshalState(data, &c.regs)
+}
+
+// String retorna uma representação em string do cartucho
+func (c *MMM01) String() string {
+	return fmt.Sprintf("MMM01: ROM=%dKB RAM=%dKB Mapped=%v ROMBank=%d RAMBank=%d",
+		len(c.rom)/1024, len(c.ram)/1024, c.regs.Mapped, c.highBank()%c.romBanks, c.ramBank())
+}
@@ -0,0 +1,56 @@
+package cartridge
+
+import "fmt"
+
+// ROMOnly é um cartucho sem MBC: 32KB de ROM e, opcionalmente, até 8KB de
+// RAM sempre habilitada (ROM+RAM)
+type ROMOnly struct {
+	base
+}
+
+// romOnlyRegisters é vazio: o cartucho não possui registradores
+type romOnlyRegisters struct{}
+
+// newROMOnly cria um cartucho sem MBC
+func newROMOnly(rom []uint8, ramSize int, features Features) Cartridge {
+	return &ROMOnly{base: newBase(rom, ramSize, features)}
+}
+
+// ReadROM lê um byte de 0x0000-0x7FFF
+func (c *ROMOnly) ReadROM(addr uint16) uint8 {
+	if int(addr) < len(c.rom) {
+		return c.rom[addr]
+	}
+	return 0xFF
+}
+
+// ReadRAM lê um byte da RAM externa
+func (c *ROMOnly) ReadRAM(addr uint16) uint8 {
+	return c.readRAMBank(0, addr)
+}
+
+// WriteControl ignora escritas: não há registradores
+func (c *ROMOnly) WriteControl(addr uint16, value uint8) {}
+
+// WriteRAM escreve na RAM externa
+func (c *ROMOnly) WriteRAM(addr uint16, value uint8) {
+	c.writeRAMBank(0, addr, value)
+}
+
+// Reset não faz nada: não há registradores
+func (c *ROMOnly) Reset() {}
+
+// SaveState serializa a RAM externa
+func (c *ROMOnly) SaveState() ([]byte, error) {
+	return c.marshalState(&romOnlyRegisters{})
+}
+
+// LoadState restaura a RAM externa
+func (c *ROMOnly) LoadState(data []byte) error {
+	return c.unmarshalState(data, &romOnlyRegisters{})
+}
+
+// String retorna uma representação em string do cartucho
+func (c *ROMOnly) String() string {
+	return fmt.Sprintf("ROM ONLY: ROM=%dKB RAM=%dKB", len(c.rom)/1024, len(c.ram)/1024)
+}
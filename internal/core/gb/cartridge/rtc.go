@@ -0,0 +1,309 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Constantes do RTC do MBC3
+const (
+	// Registradores do RTC (selecionados via 0x4000-0x5FFF)
+	RTCRegSeconds = 0x08 // Segundos (0-59)
+	RTCRegMinutes = 0x09 // Minutos (0-59)
+	RTCRegHours   = 0x0A // Horas (0-23)
+	RTCRegDayLow  = 0x0B // 8 bits inferiores do contador de dias
+	RTCRegDayHigh = 0x0C // Bit 0 = dia bit 8, bit 6 = halt, bit 7 = carry
+
+	// Bits do registrador DH
+	RTCDayHighBit8  = 1 << 0
+	RTCDayHighHalt  = 1 << 6
+	RTCDayHighCarry = 1 << 7
+
+	// Ciclos do clock de 4,19 MHz por segundo. O RTC tem oscilador próprio
+	// e não acompanha a velocidade dupla do CGB.
+	RTCCyclesPerSecond = 4194304
+
+	// Tamanho do rodapé de RTC no arquivo de save (formato VBA-M/BGB)
+	RTCFooterSize = 48
+)
+
+// RTC representa o relógio de tempo real do MBC3
+type RTC struct {
+	// Registradores em contagem
+	seconds  uint8
+	minutes  uint8
+	hours    uint8
+	days     uint16 // 9 bits
+	halt     bool
+	dayCarry bool
+
+	// Registradores travados (visíveis para o jogo)
+	latched [5]uint8
+
+	// Último valor escrito em 0x6000-0x7FFF (latch em 0x00 -> 0x01)
+	latchValue uint8
+
+	// Ciclos acumulados desde o último segundo
+	subCycles int
+
+	// Fonte de tempo do host (substituível em testes)
+	now func() time.Time
+}
+
+// NewRTC cria uma nova instância do RTC
+func NewRTC() *RTC {
+	return &RTC{
+		latchValue: 0xFF,
+		now:        time.Now,
+	}
+}
+
+// Step avança o RTC pelo número de ciclos do clock fixo informado. Em
+// velocidade dupla o MMU repassa metade dos ciclos de CPU, como ao LCD.
+func (r *RTC) Step(cycles int) {
+	if r.halt {
+		return
+	}
+
+	r.subCycles += cycles
+	for r.subCycles >= RTCCyclesPerSecond {
+		r.subCycles -= RTCCyclesPerSecond
+		r.tick()
+	}
+}
+
+// tick incrementa o relógio em um segundo
+func (r *RTC) tick() {
+	// Os contadores têm largura fixa em bits: valores inválidos
+	// (ex.: 62 segundos) continuam contando até o estouro do registrador
+	// sem gerar carry, como no hardware real
+	r.seconds = (r.seconds + 1) & 0x3F
+	if r.seconds != 60 {
+		return
+	}
+	r.seconds = 0
+
+	r.minutes = (r.minutes + 1) & 0x3F
+	if r.minutes != 60 {
+		return
+	}
+	r.minutes = 0
+
+	r.hours = (r.hours + 1) & 0x1F
+	if r.hours != 24 {
+		return
+	}
+	r.hours = 0
+
+	r.days++
+	if r.days > 0x1FF {
+		r.days = 0
+		r.dayCarry = true
+	}
+}
+
+// Advance avança o relógio por um número de segundos (ex.: tempo em que o
+// emulador ficou fechado)
+func (r *RTC) Advance(seconds int64) {
+	if r.halt || seconds <= 0 {
+		return
+	}
+
+	// Normaliza registradores fora da faixa segundo a segundo
+	for seconds > 0 && (r.seconds >= 60 || r.minutes >= 60 || r.hours >= 24) {
+		r.tick()
+		seconds--
+	}
+
+	total := int64(r.seconds) + int64(r.minutes)*60 + int64(r.hours)*3600 + int64(r.days)*86400 + seconds
+
+	days := total / 86400
+	if days > 0x1FF {
+		r.dayCarry = true
+		days %= 0x200
+	}
+
+	r.days = uint16(days)
+	r.hours = uint8((total % 86400) / 3600)
+	r.minutes = uint8((total % 3600) / 60)
+	r.seconds = uint8(total % 60)
+}
+
+// WriteLatch processa escritas em 0x6000-0x7FFF
+func (r *RTC) WriteLatch(value uint8) {
+	if r.latchValue == 0x00 && value == 0x01 {
+		r.latch()
+	}
+	r.latchValue = value
+}
+
+// latch copia os contadores para os registradores visíveis
+func (r *RTC) latch() {
+	r.latched[0] = r.seconds
+	r.latched[1] = r.minutes
+	r.latched[2] = r.hours
+	r.latched[3] = uint8(r.days)
+	r.latched[4] = r.dayHigh()
+}
+
+// dayHigh monta o valor do registrador DH
+func (r *RTC) dayHigh() uint8 {
+	value := uint8(r.days>>8) & RTCDayHighBit8
+	if r.halt {
+		value |= RTCDayHighHalt
+	}
+	if r.dayCarry {
+		value |= RTCDayHighCarry
+	}
+	return value
+}
+
+// ReadRegister lê um registrador travado do RTC
+func (r *RTC) ReadRegister(reg uint8) uint8 {
+	switch reg {
+	case RTCRegSeconds:
+		return r.latched[0] | 0xC0
+	case RTCRegMinutes:
+		return r.latched[1] | 0xC0
+	case RTCRegHours:
+		return r.latched[2] | 0xE0
+	case RTCRegDayLow:
+		return r.latched[3]
+	case RTCRegDayHigh:
+		return r.latched[4] | 0x3E
+	default:
+		return 0xFF
+	}
+}
+
+// WriteRegister escreve diretamente em um contador do RTC
+func (r *RTC) WriteRegister(reg uint8, value uint8) {
+	switch reg {
+	case RTCRegSeconds:
+		r.seconds = value & 0x3F
+		r.subCycles = 0 // Escrever os segundos reinicia o divisor
+		r.latched[0] = r.seconds
+	case RTCRegMinutes:
+		r.minutes = value & 0x3F
+		r.latched[1] = r.minutes
+	case RTCRegHours:
+		r.hours = value & 0x1F
+		r.latched[2] = r.hours
+	case RTCRegDayLow:
+		r.days = (r.days & 0x100) | uint16(value)
+		r.latched[3] = value
+	case RTCRegDayHigh:
+		r.days = (r.days & 0xFF) | uint16(value&RTCDayHighBit8)<<8
+		r.halt = value&RTCDayHighHalt != 0
+		r.dayCarry = value&RTCDayHighCarry != 0
+		r.latched[4] = r.dayHigh()
+	}
+}
+
+// IsRTCRegister retorna se o valor selecionado em 0x4000-0x5FFF mapeia o RTC
+func IsRTCRegister(reg uint8) bool {
+	return reg >= RTCRegSeconds && reg <= RTCRegDayHigh
+}
+
+// Reset reinicia apenas o estado do latch; o relógio continua contando
+// porque é alimentado pela bateria do cartucho
+func (r *RTC) Reset() {
+	r.latchValue = 0xFF
+}
+
+// MarshalFooter gera o rodapé de 48 bytes usado pelos arquivos .sav
+// (5 contadores + 5 registradores travados em uint32, e timestamp Unix em uint64)
+func (r *RTC) MarshalFooter() []byte {
+	footer := make([]byte, RTCFooterSize)
+
+	current := [5]uint8{r.seconds, r.minutes, r.hours, uint8(r.days), r.dayHigh()}
+	for i, value := range current {
+		binary.LittleEndian.PutUint32(footer[i*4:], uint32(value))
+	}
+	for i, value := range r.latched {
+		binary.LittleEndian.PutUint32(footer[20+i*4:], uint32(value))
+	}
+	binary.LittleEndian.PutUint64(footer[40:], uint64(r.now().Unix()))
+
+	return footer
+}
+
+// UnmarshalFooter restaura o RTC a partir do rodapé do arquivo .sav e
+// avança o relógio pelo tempo decorrido desde que o save foi gravado
+func (r *RTC) UnmarshalFooter(footer []byte) error {
+	if len(footer) < RTCFooterSize {
+		return fmt.Errorf("rodapé de RTC muito pequeno: %d bytes", len(footer))
+	}
+
+	r.seconds = uint8(binary.LittleEndian.Uint32(footer[0:])) & 0x3F
+	r.minutes = uint8(binary.LittleEndian.Uint32(footer[4:])) & 0x3F
+	r.hours = uint8(binary.LittleEndian.Uint32(footer[8:])) & 0x1F
+	dayLow := uint8(binary.LittleEndian.Uint32(footer[12:]))
+	dayHigh := uint8(binary.LittleEndian.Uint32(footer[16:]))
+	r.days = uint16(dayHigh&RTCDayHighBit8)<<8 | uint16(dayLow)
+	r.halt = dayHigh&RTCDayHighHalt != 0
+	r.dayCarry = dayHigh&RTCDayHighCarry != 0
+
+	for i := range r.latched {
+		r.latched[i] = uint8(binary.LittleEndian.Uint32(footer[20+i*4:]))
+	}
+
+	r.subCycles = 0
+
+	// Sincroniza com o relógio do host
+	saved := int64(binary.LittleEndian.Uint64(footer[40:]))
+	if elapsed := r.now().Unix() - saved; saved > 0 && elapsed > 0 {
+		r.Advance(elapsed)
+	}
+
+	return nil
+}
+
+// rtcState é o estado do RTC serializado nos save states
+type rtcState struct {
+	Seconds    uint8
+	Minutes    uint8
+	Hours      uint8
+	Days       uint16
+	Halt       bool
+	DayCarry   bool
+	Latched    [5]uint8
+	LatchValue uint8
+	SubCycles  int32
+}
+
+// state retorna o estado atual do RTC
+func (r *RTC) state() rtcState {
+	return rtcState{
+		Seconds:    r.seconds,
+		Minutes:    r.minutes,
+		Hours:      r.hours,
+		Days:       r.days,
+		Halt:       r.halt,
+		DayCarry:   r.dayCarry,
+		Latched:    r.latched,
+		LatchValue: r.latchValue,
+		SubCycles:  int32(r.subCycles),
+	}
+}
+
+// setState restaura o estado gerado por state, sem sincronizar com o
+// relógio do host
+func (r *RTC) setState(s rtcState) {
+	r.seconds = s.Seconds
+	r.minutes = s.Minutes
+	r.hours = s.Hours
+	r.days = s.Days
+	r.halt = s.Halt
+	r.dayCarry = s.DayCarry
+	r.latched = s.Latched
+	r.latchValue = s.LatchValue
+	r.subCycles = int(s.SubCycles)
+}
+
+// String retorna uma representação em string do estado do RTC
+func (r *RTC) String() string {
+	return fmt.Sprintf("RTC: Day=%d %02d:%02d:%02d Halt=%v Carry=%v",
+		r.days, r.hours, r.minutes, r.seconds, r.halt, r.dayCarry)
+}
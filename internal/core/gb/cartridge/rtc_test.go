@@ -0,0 +1,214 @@
+package cartridge
+
+import (
+	"testing"
+	"time"
+)
+
+// testBus encaminha os acessos do CPU ao cartucho, como o MMU
+type testBus struct {
+	Cartridge
+}
+
+func (b *testBus) Read(addr uint16) uint8 {
+	if addr < 0x8000 {
+		return b.ReadROM(addr)
+	}
+	return b.ReadRAM(addr)
+}
+
+func (b *testBus) Write(addr uint16, value uint8) {
+	if addr < 0x8000 {
+		b.WriteControl(addr, value)
+	} else {
+		b.WriteRAM(addr, value)
+	}
+}
+
+func (b *testBus) GetRTC() *RTC {
+	return b.Cartridge.(*MBC3).RTC()
+}
+
+// newMBC3Timer cria um cartucho MBC3+TIMER+RAM+BATTERY com RAM habilitada
+func newMBC3Timer(t *testing.T) *testBus {
+	rom := make([]uint8, 0x8000)
+	rom[0x147] = 0x10 // MBC3+TIMER+RAM+BATTERY
+	rom[0x149] = 0x03 // 32KB
+
+	cart, err := New(rom)
+	if err != nil {
+		t.Fatalf("erro ao criar cartucho: %v", err)
+	}
+	bus := &testBus{cart}
+	bus.Write(0x0000, 0x0A) // Habilita RAM/RTC
+	return bus
+}
+
+func latchRTC(cart *testBus) {
+	cart.Write(0x6000, 0x00)
+	cart.Write(0x6000, 0x01)
+}
+
+func readRTC(cart *testBus, reg uint8) uint8 {
+	cart.Write(0x4000, reg)
+	return cart.Read(0xA000)
+}
+
+func writeRTC(cart *testBus, reg uint8, value uint8) {
+	cart.Write(0x4000, reg)
+	cart.Write(0xA000, value)
+}
+
+func TestRTCLatch(t *testing.T) {
+	cart := newMBC3Timer(t)
+	if cart.GetRTC() == nil {
+		t.Fatal("RTC não foi criado para cartucho MBC3+TIMER")
+	}
+
+	cart.Step(RTCCyclesPerSecond * 3)
+
+	// Sem latch os registradores visíveis não mudam
+	if got := readRTC(cart, RTCRegSeconds) & 0x3F; got != 0 {
+		t.Errorf("segundos antes do latch: esperado 0, obtido %d", got)
+	}
+
+	latchRTC(cart)
+	if got := readRTC(cart, RTCRegSeconds) & 0x3F; got != 3 {
+		t.Errorf("segundos após latch: esperado 3, obtido %d", got)
+	}
+
+	// Valor travado permanece até o próximo latch
+	cart.Step(RTCCyclesPerSecond)
+	if got := readRTC(cart, RTCRegSeconds) & 0x3F; got != 3 {
+		t.Errorf("segundos travados: esperado 3, obtido %d", got)
+	}
+
+	// Escrever 0x01 sem 0x00 antes não trava
+	cart.Write(0x6000, 0x01)
+	if got := readRTC(cart, RTCRegSeconds) & 0x3F; got != 3 {
+		t.Errorf("latch sem transição 0->1: esperado 3, obtido %d", got)
+	}
+}
+
+func TestRTCRolloverAndCarry(t *testing.T) {
+	cart := newMBC3Timer(t)
+
+	writeRTC(cart, RTCRegSeconds, 59)
+	writeRTC(cart, RTCRegMinutes, 59)
+	writeRTC(cart, RTCRegHours, 23)
+	writeRTC(cart, RTCRegDayLow, 0xFF)
+	writeRTC(cart, RTCRegDayHigh, RTCDayHighBit8)
+
+	cart.Step(RTCCyclesPerSecond)
+	latchRTC(cart)
+
+	if got := readRTC(cart, RTCRegSeconds) & 0x3F; got != 0 {
+		t.Errorf("segundos: esperado 0, obtido %d", got)
+	}
+	if got := readRTC(cart, RTCRegHours) & 0x1F; got != 0 {
+		t.Errorf("horas: esperado 0, obtido %d", got)
+	}
+	if got := readRTC(cart, RTCRegDayLow); got != 0 {
+		t.Errorf("dia baixo: esperado 0, obtido %d", got)
+	}
+	dh := readRTC(cart, RTCRegDayHigh)
+	if dh&RTCDayHighBit8 != 0 {
+		t.Error("bit 8 do dia deveria ter zerado")
+	}
+	if dh&RTCDayHighCarry == 0 {
+		t.Error("carry do contador de dias deveria estar ativo")
+	}
+}
+
+func TestRTCHalt(t *testing.T) {
+	cart := newMBC3Timer(t)
+
+	writeRTC(cart, RTCRegDayHigh, RTCDayHighHalt)
+	cart.Step(RTCCyclesPerSecond * 5)
+	latchRTC(cart)
+
+	if got := readRTC(cart, RTCRegSeconds) & 0x3F; got != 0 {
+		t.Errorf("RTC parado não deveria contar: obtido %d segundos", got)
+	}
+
+	writeRTC(cart, RTCRegDayHigh, 0x00)
+	cart.Step(RTCCyclesPerSecond * 2)
+	latchRTC(cart)
+
+	if got := readRTC(cart, RTCRegSeconds) & 0x3F; got != 2 {
+		t.Errorf("segundos após retomar: esperado 2, obtido %d", got)
+	}
+}
+
+func TestRTCInvalidValuesWrap(t *testing.T) {
+	rtc := NewRTC()
+	rtc.WriteRegister(RTCRegSeconds, 62)
+
+	// 62 -> 63 -> 0 (estouro de 6 bits) sem incrementar os minutos
+	rtc.Step(RTCCyclesPerSecond * 2)
+	rtc.WriteLatch(0x00)
+	rtc.WriteLatch(0x01)
+
+	if got := rtc.ReadRegister(RTCRegSeconds) & 0x3F; got != 0 {
+		t.Errorf("segundos: esperado 0, obtido %d", got)
+	}
+	if got := rtc.ReadRegister(RTCRegMinutes) & 0x3F; got != 0 {
+		t.Errorf("minutos: esperado 0, obtido %d", got)
+	}
+}
+
+func TestRTCBankSelectKeepsRAM(t *testing.T) {
+	cart := newMBC3Timer(t)
+
+	cart.Write(0x4000, 0x01)
+	cart.Write(0xA000, 0x42)
+
+	writeRTC(cart, RTCRegMinutes, 10)
+
+	cart.Write(0x4000, 0x01)
+	if got := cart.Read(0xA000); got != 0x42 {
+		t.Errorf("RAM banco 1: esperado 0x42, obtido 0x%02X", got)
+	}
+}
+
+func TestRTCBatteryFooterRoundTrip(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+
+	cart := newMBC3Timer(t)
+	cart.GetRTC().now = func() time.Time { return base }
+
+	cart.Write(0x4000, 0x00)
+	cart.Write(0xA010, 0x99)
+	writeRTC(cart, RTCRegHours, 5)
+	writeRTC(cart, RTCRegMinutes, 30)
+
+	data := cart.BatteryData()
+	if len(data) != 4*RAMBankSize+RTCFooterSize {
+		t.Fatalf("tamanho do save: esperado %d, obtido %d", 4*RAMBankSize+RTCFooterSize, len(data))
+	}
+
+	// Restaura 90 minutos depois
+	restored := newMBC3Timer(t)
+	restored.GetRTC().now = func() time.Time { return base.Add(90 * time.Minute) }
+	if err := restored.LoadBatteryData(data); err != nil {
+		t.Fatalf("erro ao carregar save: %v", err)
+	}
+
+	restored.Write(0x4000, 0x00)
+	if got := restored.Read(0xA010); got != 0x99 {
+		t.Errorf("RAM restaurada: esperado 0x99, obtido 0x%02X", got)
+	}
+
+	latchRTC(restored)
+	if got := readRTC(restored, RTCRegHours) & 0x1F; got != 7 {
+		t.Errorf("horas: esperado 7, obtido %d", got)
+	}
+	if got := readRTC(restored, RTCRegMinutes) & 0x3F; got != 0 {
+		t.Errorf("minutos: esperado 0, obtido %d", got)
+	}
+
+	// Saves sem rodapé continuam válidos
+	if err := restored.LoadBatteryData(data[:4*RAMBankSize]); err != nil {
+		t.Errorf("save sem rodapé de RTC deveria ser aceito: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package gb
+
+import (
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
+)
+
+// newLoopROM cria uma ROM que fica em loop infinito em 0x0100
+func newLoopROM(cgbFlag uint8) []uint8 {
+	rom := make([]uint8, 0x8000)
+	copy(rom[0x134:0x143], []byte("CGB TEST"))
+	rom[0x143] = cgbFlag
+	rom[0x100] = 0x18 // JR
+	rom[0x101] = 0xFE // -2
+	return rom
+}
+
+// TestGameBoyModelSelection testa a seleção DMG/CGB pelo header e pela configuração
+func TestGameBoyModelSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   Model
+		cgbFlag uint8
+		wantCGB bool
+	}{
+		{"auto DMG ROM", ModelAuto, 0x00, false},
+		{"auto CGB-enhanced ROM", ModelAuto, 0x80, true},
+		{"auto CGB-only ROM", ModelAuto, 0xC0, true},
+		{"forced DMG", ModelDMG, 0x80, false},
+		{"forced CGB", ModelCGB, 0x00, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Model = tt.model
+			gameboy := NewGameBoy(config)
+
+			if err := gameboy.LoadROM(newLoopROM(tt.cgbFlag)); err != nil {
+				t.Fatalf("Failed to load ROM: %v", err)
+			}
+
+			if gameboy.IsCGB() != tt.wantCGB {
+				t.Errorf("Expected CGB=%v, got %v", tt.wantCGB, gameboy.IsCGB())
+			}
+
+			wantA := uint8(0x01)
+			if tt.wantCGB {
+				wantA = 0x11
+			}
+			if gameboy.cpu.GetA() != wantA {
+				t.Errorf("Expected A=0x%02X after boot, got 0x%02X", wantA, gameboy.cpu.GetA())
+			}
+		})
+	}
+}
+
+// TestGameBoyCGBColorOutput testa a saída RGB555 com paletas e atributos CGB
+func TestGameBoyCGBColorOutput(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableVSync = false
+	gameboy := NewGameBoy(config)
+
+	if err := gameboy.LoadROM(newLoopROM(0x80)); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+
+	mmu := gameboy.mmu
+	mmu.Write(video.RegLCDC, 0x00)
+
+	// Tile 0 (banco 1) com a primeira linha na cor 1
+	mmu.Write(video.RegVBK, 1)
+	mmu.Write(0x8000, 0xFF)
+	mmu.Write(0x8001, 0x00)
+
+	// Atributo do tile (0,0): banco 1, paleta 2
+	mmu.Write(0x9800, video.AttrBank|0x02)
+	mmu.Write(video.RegVBK, 0)
+
+	// Paleta BG 2, cor 1 = vermelho puro
+	mmu.Write(video.RegBCPS, 0x80|(2*8+2))
+	mmu.Write(video.RegBCPD, 0x1F)
+	mmu.Write(video.RegBCPD, 0x00)
+
+	mmu.Write(video.RegLCDC, 0x91)
+
+	var frame [144][160]uint16
+	gameboy.SetColorFrameCallback(func(f [144][160]uint16) {
+		frame = f
+	})
+
+	gameboy.Start()
+	for i := 0; i < 3 && gameboy.GetFrameCount() == 0; i++ {
+		gameboy.Step()
+	}
+
+	if gameboy.GetFrameCount() == 0 {
+		t.Fatal("No frame was produced")
+	}
+	if frame[0][0] != 0x001F {
+		t.Errorf("Expected pixel (0,0) = 0x001F, got 0x%04X", frame[0][0])
+	}
+
+	r, g, b, a := video.ColorToRGBA(frame[0][0])
+	if r != 0xFF || g != 0 || b != 0 || a != 0xFF {
+		t.Errorf("Expected RGBA (255,0,0,255), got (%d,%d,%d,%d)", r, g, b, a)
+	}
+
+	// Linha 1 do tile usa a cor 0 da paleta (branco após reset)
+	if frame[1][0] != 0x7FFF {
+		t.Errorf("Expected pixel (0,1) = 0x7FFF, got 0x%04X", frame[1][0])
+	}
+}
@@ -1,12 +1,25 @@
 package cpu
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
+)
 
 // Endereços especiais
 const (
 	hramBase uint16 = 0xFF00 // Endereço base da High RAM
 )
 
+// Interrupções
+const (
+	interruptJoypad  = 1 << 4 // Bit da interrupção do joypad em IF
+	interruptVectors = 0x40   // Vetor da interrupção de bit 0 (V-Blank)
+
+	// Ciclos de CPU do atendimento de uma interrupção (5 M-cycles)
+	InterruptDispatchCycles = 20
+)
+
 // Registradores do CPU Sharp LR35902
 const (
 	// Registradores de 8 bits
@@ -44,13 +57,31 @@ type CPU struct {
 	pc   uint16   // Program Counter
 
 	// Estado do processador
-	ime    bool   // Interrupt Master Enable
-	halt   bool   // Estado HALT
-	stop   bool   // Estado STOP
-	cycles uint64 // Ciclos executados
+	ime     bool   // Interrupt Master Enable
+	eiDelay int    // Instruções até o EI ativar o IME
+	halt    bool   // Estado HALT
+	haltBug bool   // Próximo opcode é lido sem incrementar o PC
+	stop    bool   // Estado STOP
+	cycles  uint64 // Ciclos executados
 
 	// Interface de memória
 	mem Memory
+
+	// Fonte das interrupções (IE/IF)
+	interrupts InterruptSource
+}
+
+// InterruptSource dá ao CPU acesso aos registradores IE e IF. O CPU decide
+// quando atender uma interrupção; o controlador apenas mantém os registradores.
+type InterruptSource interface {
+	// PendingInterrupts retorna IE & IF
+	PendingInterrupts() uint8
+
+	// RequestedInterrupts retorna IF, independente de IE
+	RequestedInterrupts() uint8
+
+	// AcknowledgeInterrupt limpa o bit da interrupção atendida em IF
+	AcknowledgeInterrupt(interrupt uint8)
 }
 
 // Memory define a interface para acessar a memória
@@ -61,6 +92,12 @@ type Memory interface {
 	WriteWord(addr uint16, value uint16)
 }
 
+// SpeedSwitcher é implementado por memórias com suporte à velocidade dupla
+// do Game Boy Color. SwitchSpeed retorna true se a troca ocorreu.
+type SpeedSwitcher interface {
+	SwitchSpeed() bool
+}
+
 // NewCPU cria uma nova instância do CPU
 func NewCPU(mem Memory) *CPU {
 	return &CPU{
@@ -79,11 +116,18 @@ func (c *CPU) Reset() {
 	c.pc = 0x0100
 
 	c.ime = false
+	c.eiDelay = 0
 	c.halt = false
+	c.haltBug = false
 	c.stop = false
 	c.cycles = 0
 }
 
+// SetInterruptSource conecta o CPU ao controlador de interrupções
+func (c *CPU) SetInterruptSource(source InterruptSource) {
+	c.interrupts = source
+}
+
 // Getters e setters para registradores de 8 bits
 func (c *CPU) GetA() uint8 { return c.regs[RegA] }
 func (c *CPU) GetF() uint8 { return c.regs[RegF] }
@@ -144,29 +188,104 @@ func (c *CPU) SetFlag(flag uint8, value bool) {
 	}
 }
 
-// Step executa uma instrução
+// Step executa uma instrução ou atende uma interrupção pendente
 func (c *CPU) Step() int {
-	if c.halt {
-		return 4 // HALT consome 4 ciclos
+	cycles := c.step()
+	c.cycles += uint64(cycles)
+	return cycles
+}
+
+// step executa uma instrução, respeitando HALT, STOP e interrupções
+func (c *CPU) step() int {
+	var pending uint8
+	if c.interrupts != nil {
+		pending = c.interrupts.PendingInterrupts()
+
+		// STOP só termina quando uma linha do joypad é pressionada
+		if c.stop && c.interrupts.RequestedInterrupts()&interruptJoypad != 0 {
+			c.stop = false
+		}
 	}
 
 	if c.stop {
 		return 4 // STOP consome 4 ciclos
 	}
 
-	// Lê a instrução
+	// Uma interrupção pendente acorda o HALT mesmo com IME desabilitado
+	wokeUp := false
+	if c.halt && pending != 0 {
+		c.halt = false
+		wokeUp = true
+	}
+
+	if c.halt {
+		return 4 // HALT consome 4 ciclos
+	}
+
+	if c.ime && pending != 0 {
+		cycles := c.dispatchInterrupt()
+		if wokeUp {
+			cycles += 4 // Ciclo extra para sair do HALT
+		}
+		return cycles
+	}
+
+	// Lê a instrução. Com o HALT bug o PC não é incrementado e o mesmo
+	// byte é lido novamente na próxima instrução.
 	opcode := c.mem.Read(c.pc)
-	c.pc++
+	if c.haltBug {
+		c.haltBug = false
+	} else {
+		c.pc++
+	}
 
 	// Executa a instrução
 	cycles := c.executeInstruction(opcode)
 
-	// Atualiza o contador de ciclos
-	c.cycles += uint64(cycles)
+	// O EI só ativa o IME após a instrução seguinte
+	if c.eiDelay > 0 {
+		c.eiDelay--
+		if c.eiDelay == 0 {
+			c.ime = true
+		}
+	}
 
 	return cycles
 }
 
+// dispatchInterrupt atende a interrupção pendente de maior prioridade em
+// 5 M-cycles: 2 de espera, 2 para empilhar o PC e 1 para saltar. O vetor
+// é escolhido depois de empilhar o byte alto; se essa escrita alterar IE
+// (SP = 0x0000) e nenhuma interrupção continuar pendente, o atendimento é
+// cancelado e o PC vai para 0x0000.
+func (c *CPU) dispatchInterrupt() int {
+	c.ime = false
+	c.eiDelay = 0
+
+	c.sp--
+	c.mem.Write(c.sp, uint8(c.pc>>8))
+
+	pending := c.interrupts.PendingInterrupts()
+
+	c.sp--
+	c.mem.Write(c.sp, uint8(c.pc))
+
+	if pending == 0 {
+		c.pc = 0x0000
+		return InterruptDispatchCycles
+	}
+
+	for bit := uint8(0); bit < 5; bit++ {
+		if pending&(1<<bit) != 0 {
+			c.interrupts.AcknowledgeInterrupt(1 << bit)
+			c.pc = interruptVectors + uint16(bit)*8
+			break
+		}
+	}
+
+	return InterruptDispatchCycles
+}
+
 // Push coloca um valor de 16 bits na pilha
 func (c *CPU) Push(value uint16) {
 	c.sp -= 2
@@ -197,11 +316,21 @@ func (c *CPU) Interrupt(vector uint16) {
 // EnableInterrupts habilita as interrupções
 func (c *CPU) EnableInterrupts() {
 	c.ime = true
+	c.eiDelay = 0
+}
+
+// EnableInterruptsDelayed habilita as interrupções após a próxima
+// instrução, como o EI
+func (c *CPU) EnableInterruptsDelayed() {
+	if !c.ime {
+		c.eiDelay = 2 // Conta a própria instrução EI
+	}
 }
 
 // DisableInterrupts desabilita as interrupções
 func (c *CPU) DisableInterrupts() {
 	c.ime = false
+	c.eiDelay = 0
 }
 
 // Halt coloca o CPU em estado HALT
@@ -209,6 +338,17 @@ func (c *CPU) Halt() {
 	c.halt = true
 }
 
+// executeHalt executa a instrução HALT. Com IME desabilitado e uma
+// interrupção já pendente, o CPU não para e o próximo opcode é lido duas
+// vezes (HALT bug).
+func (c *CPU) executeHalt() {
+	if c.interrupts != nil && !c.ime && c.interrupts.PendingInterrupts() != 0 {
+		c.haltBug = true
+		return
+	}
+	c.halt = true
+}
+
 // Stop coloca o CPU em estado STOP
 func (c *CPU) Stop() {
 	c.stop = true
@@ -242,6 +382,25 @@ func (c *CPU) SetHalted(halted bool) {
 // SetInterruptsEnabled define o estado das interrupções
 func (c *CPU) SetInterruptsEnabled(enabled bool) {
 	c.ime = enabled
+	c.eiDelay = 0
+}
+
+// stateFields lista os campos gravados no save state
+func (c *CPU) stateFields() []any {
+	return []any{&c.regs, &c.sp, &c.pc, &c.ime, &c.eiDelay, &c.halt, &c.haltBug, &c.stop, &c.cycles}
+}
+
+// SaveState serializa registradores, IME e os estados HALT/STOP
+func (c *CPU) SaveState() ([]byte, error) {
+	return savestate.Marshal(c.stateFields()...)
+}
+
+// LoadState restaura o estado gerado por SaveState
+func (c *CPU) LoadState(data []byte) error {
+	if err := savestate.Unmarshal(data, c.stateFields()...); err != nil {
+		return fmt.Errorf("estado do CPU inválido: %w", err)
+	}
+	return nil
 }
 
 // String retorna uma representação em string do estado do CPU
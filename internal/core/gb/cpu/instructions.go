@@ -112,12 +112,16 @@ func (c *CPU) executeInstruction(opcode uint8) int {
 
 	// STOP
 	case OpSTOP:
+		// No CGB, STOP com KEY1 preparado apenas troca a velocidade
+		if switcher, ok := c.mem.(SpeedSwitcher); ok && switcher.SwitchSpeed() {
+			return cycles[opcode]
+		}
 		c.Stop()
 		return cycles[opcode]
 
 	// HALT
 	case OpHALT:
-		c.Halt()
+		c.executeHalt()
 		return cycles[opcode]
 
 	// DI/EI
@@ -125,7 +129,7 @@ func (c *CPU) executeInstruction(opcode uint8) int {
 		c.DisableInterrupts()
 		return cycles[opcode]
 	case OpEI:
-		c.EnableInterrupts()
+		c.EnableInterruptsDelayed()
 		return cycles[opcode]
 
 	// Load/Store
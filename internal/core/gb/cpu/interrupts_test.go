@@ -0,0 +1,199 @@
+package cpu
+
+import "testing"
+
+// mockInterrupts simula IF, com IE lido da memória em 0xFFFF
+type mockInterrupts struct {
+	mem *mockMemory
+	IF  uint8
+}
+
+func (m *mockInterrupts) PendingInterrupts() uint8 {
+	return m.IF & m.mem.data[0xFFFF] & 0x1F
+}
+
+func (m *mockInterrupts) RequestedInterrupts() uint8 {
+	return m.IF
+}
+
+func (m *mockInterrupts) AcknowledgeInterrupt(interrupt uint8) {
+	m.IF &^= interrupt
+}
+
+// newInterruptCPU cria um CPU em 0xC000 com o programa informado
+func newInterruptCPU(program ...uint8) (*CPU, *mockMemory, *mockInterrupts) {
+	mem := &mockMemory{}
+	copy(mem.data[0xC000:], program)
+
+	ic := &mockInterrupts{mem: mem}
+	cpu := NewCPU(mem)
+	cpu.Reset()
+	cpu.SetInterruptSource(ic)
+	cpu.SetPC(0xC000)
+	cpu.SetSP(0xD000)
+	return cpu, mem, ic
+}
+
+func TestCPUEIDelay(t *testing.T) {
+	// EI; NOP; NOP
+	cpu, mem, ic := newInterruptCPU(OpEI, OpNOP, OpNOP)
+	mem.data[0xFFFF] = 0x01
+	ic.IF = 0x01
+
+	cpu.Step() // EI
+	if cpu.IsInterruptsEnabled() {
+		t.Fatal("IME não deveria ser ativado pelo próprio EI")
+	}
+
+	cpu.Step() // NOP executa antes da interrupção
+	if cpu.GetPC() != 0xC002 {
+		t.Fatalf("instrução após EI deveria executar, PC=0x%04X", cpu.GetPC())
+	}
+
+	if cycles := cpu.Step(); cycles != InterruptDispatchCycles {
+		t.Errorf("atendimento: esperado %d ciclos, obtido %d", InterruptDispatchCycles, cycles)
+	}
+	if cpu.GetPC() != 0x0040 || ic.IF != 0 {
+		t.Errorf("esperado salto para 0x0040 com IF limpo, PC=0x%04X IF=0x%02X", cpu.GetPC(), ic.IF)
+	}
+	if mem.ReadWord(cpu.GetSP()) != 0xC002 {
+		t.Errorf("endereço de retorno: esperado 0xC002, obtido 0x%04X", mem.ReadWord(cpu.GetSP()))
+	}
+}
+
+func TestCPUEIDICancels(t *testing.T) {
+	// EI; DI; NOP
+	cpu, _, _ := newInterruptCPU(OpEI, OpDI, OpNOP)
+
+	cpu.Step()
+	cpu.Step()
+	cpu.Step()
+	if cpu.IsInterruptsEnabled() {
+		t.Error("DI logo após EI deveria manter o IME desabilitado")
+	}
+}
+
+func TestCPUHaltWakesWithoutIME(t *testing.T) {
+	// HALT; INC A
+	cpu, mem, ic := newInterruptCPU(OpHALT, 0x3C)
+	mem.data[0xFFFF] = 0x04
+	cpu.SetA(0)
+
+	cpu.Step()
+	if !cpu.IsHalted() {
+		t.Fatal("CPU deveria estar em HALT")
+	}
+	if cycles := cpu.Step(); cycles != 4 {
+		t.Errorf("HALT deveria consumir 4 ciclos, consumiu %d", cycles)
+	}
+
+	// Interrupção solicitada mas não habilitada em IE não acorda
+	ic.IF = 0x01
+	cpu.Step()
+	if !cpu.IsHalted() {
+		t.Fatal("interrupção desabilitada em IE não deveria acordar o CPU")
+	}
+
+	// Com IE & IF, acorda e continua sem atender (IME = 0)
+	ic.IF |= 0x04
+	cpu.Step()
+	if cpu.IsHalted() || cpu.GetA() != 1 || cpu.GetPC() != 0xC002 {
+		t.Errorf("CPU deveria acordar e executar INC A, A=%d PC=0x%04X", cpu.GetA(), cpu.GetPC())
+	}
+	if ic.IF&0x04 == 0 {
+		t.Error("IF não deveria ser limpo sem atendimento")
+	}
+}
+
+func TestCPUHaltWakeDispatch(t *testing.T) {
+	cpu, mem, ic := newInterruptCPU(OpHALT, OpNOP)
+	mem.data[0xFFFF] = 0x02
+	cpu.SetInterruptsEnabled(true)
+
+	cpu.Step()
+	ic.IF = 0x02
+
+	if cycles := cpu.Step(); cycles != InterruptDispatchCycles+4 {
+		t.Errorf("acordar e atender: esperado %d ciclos, obtido %d", InterruptDispatchCycles+4, cycles)
+	}
+	if cpu.GetPC() != 0x0048 {
+		t.Errorf("esperado vetor 0x0048, obtido 0x%04X", cpu.GetPC())
+	}
+}
+
+func TestCPUHaltBug(t *testing.T) {
+	// HALT; INC A; NOP — com o bug, INC A executa duas vezes
+	cpu, mem, ic := newInterruptCPU(OpHALT, 0x3C, OpNOP)
+	mem.data[0xFFFF] = 0x01
+	ic.IF = 0x01
+	cpu.SetA(0)
+
+	cpu.Step()
+	if cpu.IsHalted() {
+		t.Fatal("HALT com IME=0 e interrupção pendente não deveria parar o CPU")
+	}
+
+	cpu.Step()
+	cpu.Step()
+	if cpu.GetA() != 2 || cpu.GetPC() != 0xC002 {
+		t.Errorf("HALT bug: esperado A=2 PC=0xC002, obtido A=%d PC=0x%04X", cpu.GetA(), cpu.GetPC())
+	}
+}
+
+func TestCPUDispatchCancelledByIEWrite(t *testing.T) {
+	cpu, mem, ic := newInterruptCPU(OpNOP)
+	cpu.SetPC(0x0234)
+	cpu.SetSP(0x0000)
+	cpu.SetInterruptsEnabled(true)
+
+	// O byte alto do PC (0x02) é empilhado em 0xFFFF (IE) e desabilita a
+	// interrupção do V-Blank antes da escolha do vetor
+	mem.data[0xFFFF] = 0x01
+	ic.IF = 0x01
+
+	cpu.Step()
+	if cpu.GetPC() != 0x0000 {
+		t.Errorf("atendimento cancelado deveria saltar para 0x0000, PC=0x%04X", cpu.GetPC())
+	}
+	if ic.IF != 0x01 {
+		t.Errorf("IF não deveria ser limpo no cancelamento, IF=0x%02X", ic.IF)
+	}
+
+	// Se o novo IE ainda habilita outra interrupção pendente, ela é atendida
+	cpu, mem, ic = newInterruptCPU(OpNOP)
+	cpu.SetPC(0x0234)
+	cpu.SetSP(0x0000)
+	cpu.SetInterruptsEnabled(true)
+	mem.data[0xFFFF] = 0x01
+	ic.IF = 0x03
+
+	cpu.Step()
+	if cpu.GetPC() != 0x0048 || ic.IF != 0x01 {
+		t.Errorf("esperado vetor 0x0048 com IF=0x01, obtido PC=0x%04X IF=0x%02X", cpu.GetPC(), ic.IF)
+	}
+}
+
+func TestCPUStopWakesOnJoypad(t *testing.T) {
+	// STOP 0x00; INC A
+	cpu, _, ic := newInterruptCPU(OpSTOP, 0x00, 0x3C)
+	cpu.SetA(0)
+
+	cpu.Step()
+	if !cpu.IsStopped() {
+		t.Fatal("CPU deveria estar em STOP")
+	}
+
+	// Outras interrupções não acordam o STOP
+	ic.IF = 0x01
+	cpu.Step()
+	if !cpu.IsStopped() {
+		t.Fatal("apenas o joypad deveria acordar o STOP")
+	}
+
+	// Joypad acorda mesmo desabilitado em IE
+	ic.IF |= 0x10
+	cpu.Step()
+	if cpu.IsStopped() {
+		t.Error("joypad deveria acordar o CPU do STOP")
+	}
+}
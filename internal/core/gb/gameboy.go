@@ -1,14 +1,21 @@
 package gb
 
 import (
+	"crypto/sha1"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/hobbiee/visualboy-go/internal/core/gb/cartridge"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/cpu"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/input"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/interrupts"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/memory"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/sgb"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
+	"github.com/hobbiee/visualboy-go/internal/core/movie"
+	"github.com/hobbiee/visualboy-go/internal/core/rewind"
 )
 
 // GameBoy representa o emulador completo do Game Boy
@@ -17,6 +24,7 @@ type GameBoy struct {
 	cpu        *cpu.CPU
 	mmu        *memory.MMU
 	interrupts *interrupts.InterruptController
+	sgb        *sgb.SGB // Super Game Boy (nil fora do modo SGB)
 
 	// Estado da emulação
 	running    bool
@@ -31,14 +39,71 @@ type GameBoy struct {
 	// Configurações
 	config Config
 
+	// Persistência da RAM do cartucho (.sav)
+	savePath         string
+	framesSinceFlush int
+
 	// Callbacks
-	frameCallback func([144][160]uint8)
-	audioCallback func([]int16)
+	frameCallback      func([144][160]uint8)
+	colorFrameCallback func([144][160]uint16)
+	sgbFrameCallback   func([sgb.Height][sgb.Width]uint16)
+	audioCallback      func([]int16)
+	rumbleListener     RumbleListener
+	rumbleActive       bool // Último estado informado ao rumbleListener
+
+	// Histórico de rewind (nil = desligado)
+	rewind *rewind.Buffer
+
+	// Movie sendo gravado ou reproduzido
+	movieRecorder *movie.Recorder
+	moviePlayer   *movie.Player
+	movieInput    uint16 // Botões gravados, amostrados no início do frame
+}
+
+// Model seleciona o hardware emulado
+type Model int
+
+const (
+	ModelAuto Model = iota // Detecta pelo header da ROM (0x143)
+	ModelDMG               // Game Boy original
+	ModelCGB               // Game Boy Color
+	ModelSGB               // Super Game Boy (para ROMs com a flag SGB)
+)
+
+// String retorna o nome do modelo
+func (m Model) String() string {
+	switch m {
+	case ModelDMG:
+		return "DMG"
+	case ModelCGB:
+		return "CGB"
+	case ModelSGB:
+		return "SGB"
+	default:
+		return "Auto"
+	}
+}
+
+// ParseModel converte o nome de um modelo ("auto", "dmg", "cgb", "sgb")
+func ParseModel(name string) (Model, error) {
+	switch strings.ToLower(name) {
+	case "", "auto":
+		return ModelAuto, nil
+	case "dmg":
+		return ModelDMG, nil
+	case "cgb":
+		return ModelCGB, nil
+	case "sgb":
+		return ModelSGB, nil
+	default:
+		return ModelAuto, fmt.Errorf("modelo desconhecido: %s", name)
+	}
 }
 
 // Config contém as configurações do Game Boy
 type Config struct {
 	// Emulação
+	Model         Model
 	EnableBootROM bool
 	EnableSound   bool
 	EnableDebug   bool
@@ -51,16 +116,28 @@ type Config struct {
 	// Vídeo
 	Scale         int
 	EnableFilters bool
+	Renderer      video.Renderer // Scanline (rápido) ou pixel FIFO (preciso)
 
 	// Áudio
 	SampleRate int
 	BufferSize int
 	Volume     float64
+
+	// Persistência
+	SavesDir           string // Diretório dos arquivos .sav (vazio = ao lado da ROM)
+	BatteryFlushFrames int    // Intervalo, em frames, da verificação de RAM alterada
+
+	// Rewind (desligado por padrão: os snapshots por frame só compensam
+	// na interface gráfica)
+	EnableRewind   bool
+	RewindInterval int // Frames entre snapshots
+	RewindBudget   int // Memória máxima do histórico, em bytes
 }
 
 // DefaultConfig retorna uma configuração padrão
 func DefaultConfig() Config {
 	return Config{
+		Model:         ModelAuto,
 		EnableBootROM: false,
 		EnableSound:   true,
 		EnableDebug:   false,
@@ -69,9 +146,17 @@ func DefaultConfig() Config {
 		FrameSkip:     0,
 		Scale:         2,
 		EnableFilters: false,
+		Renderer:      video.RendererScanline,
 		SampleRate:    44100,
 		BufferSize:    1024,
 		Volume:        1.0,
+
+		SavesDir:           "",
+		BatteryFlushFrames: 60,
+
+		EnableRewind:   false,
+		RewindInterval: rewind.DefaultInterval,
+		RewindBudget:   rewind.DefaultBudget,
 	}
 }
 
@@ -85,14 +170,21 @@ func NewGameBoy(config Config) *GameBoy {
 
 	// Cria MMU
 	gb.mmu = memory.NewMMU()
+	if config.SampleRate > 0 {
+		gb.mmu.GetSound().SetSampleRate(config.SampleRate)
+	}
+	gb.mmu.GetLCD().SetRenderer(config.Renderer)
 
 	// Cria CPU
 	gb.cpu = cpu.NewCPU(gb.mmu)
 
 	// Cria controlador de interrupções
 	gb.interrupts = interrupts.NewInterruptController(gb.cpu)
+	gb.cpu.SetInterruptSource(gb.interrupts)
 	gb.mmu.SetInterruptController(gb.interrupts)
 
+	gb.configureRewind()
+
 	return gb
 }
 
@@ -102,30 +194,65 @@ func (gb *GameBoy) LoadROM(data []uint8) error {
 		return fmt.Errorf("ROM data is empty")
 	}
 
+	// Grava a RAM do cartucho anterior antes de trocar de ROM
+	gb.FlushBattery()
+	gb.savePath = ""
+
 	// Carrega ROM no MMU
 	err := gb.mmu.LoadROM(data)
 	if err != nil {
 		return fmt.Errorf("failed to load ROM: %w", err)
 	}
 
-	// Reset do sistema
+	// Reset do sistema (seleciona também o hardware)
 	gb.Reset()
 
 	return nil
 }
 
+// selectModel escolhe entre DMG e CGB: CGB para ROMs compatíveis, a menos
+// que forçado. A boot ROM do CGB sempre roda em modo CGB e decide o modo de
+// compatibilidade por conta própria ao terminar.
+func (gb *GameBoy) selectModel() {
+	cgb := gb.config.Model == ModelCGB || (gb.config.Model == ModelAuto && gb.mmu.IsCGBROM())
+	if gb.usesBootROM() && gb.mmu.IsCGBBootROM() && gb.config.Model != ModelDMG && gb.config.Model != ModelSGB {
+		cgb = true
+	}
+	gb.mmu.SetCGBMode(cgb)
+}
+
+// selectSGB liga o Super Game Boy quando o modelo SGB foi escolhido e o
+// header da ROM indica suporte; os pacotes chegam pelo registrador JOYP
+func (gb *GameBoy) selectSGB() {
+	header := gb.mmu.GetHeader()
+	if gb.config.Model != ModelSGB || header == nil || !header.SupportsSGB() {
+		gb.sgb = nil
+		gb.mmu.GetInput().EnableSGB(nil)
+		return
+	}
+
+	if gb.sgb == nil {
+		gb.sgb = sgb.New(gb.mmu.GetInput())
+	}
+	gb.sgb.Reset()
+	gb.mmu.GetInput().EnableSGB(gb.sgb.ReceivePacket)
+}
+
 // Reset reinicia o Game Boy
 func (gb *GameBoy) Reset() {
+	gb.selectModel()
 	gb.cpu.Reset()
 	gb.mmu.Reset()
 	gb.interrupts.Reset()
+	gb.selectSGB()
 
 	gb.frameCount = 0
 	gb.cycleCount = 0
 	gb.lastFrameTime = time.Now()
+	gb.clearRewind()
 
 	// Se não há boot ROM, inicia direto no jogo
-	if !gb.config.EnableBootROM {
+	if !gb.usesBootROM() {
 		gb.cpu.SetPC(0x0100)
 		gb.cpu.SetSP(0xFFFE)
 		gb.cpu.SetA(0x01)
@@ -134,6 +261,24 @@ func (gb *GameBoy) Reset() {
 		gb.cpu.SetDE(0x00D8)
 		gb.cpu.SetHL(0x014D)
 
+		// Valores deixados pela boot ROM do CGB (A=0x11 identifica o hardware)
+		if gb.mmu.IsCGBMode() {
+			gb.cpu.SetA(0x11)
+			gb.cpu.SetF(0x80)
+			gb.cpu.SetBC(0x0000)
+			gb.cpu.SetDE(0xFF56)
+			gb.cpu.SetHL(0x000D)
+		}
+
+		// Valores deixados pela boot ROM do SGB
+		if gb.sgb != nil {
+			gb.cpu.SetA(0x01)
+			gb.cpu.SetF(0x00)
+			gb.cpu.SetBC(0x0014)
+			gb.cpu.SetDE(0x0000)
+			gb.cpu.SetHL(0xC060)
+		}
+
 		// Configura registradores iniciais
 		gb.mmu.Write(0xFF05, 0x00) // TIMA
 		gb.mmu.Write(0xFF06, 0x00) // TMA
@@ -165,8 +310,13 @@ func (gb *GameBoy) Reset() {
 		gb.mmu.Write(0xFF49, 0xFF) // OBP1
 		gb.mmu.Write(0xFF4A, 0x00) // WY
 		gb.mmu.Write(0xFF4B, 0x00) // WX
+		gb.mmu.Write(0xFF50, 0x01) // BOOT
 		gb.mmu.Write(0xFFFF, 0x00) // IE
+		return
 	}
+
+	// Com boot ROM, a execução começa em 0x0000 no estado de power-on
+	gb.powerOn()
 }
 
 // Start inicia a emulação
@@ -175,9 +325,10 @@ func (gb *GameBoy) Start() {
 	gb.paused = false
 }
 
-// Stop para a emulação
+// Stop para a emulação e grava a RAM do cartucho em disco
 func (gb *GameBoy) Stop() {
 	gb.running = false
+	gb.FlushBattery()
 }
 
 // Pause pausa/despausa a emulação
@@ -203,44 +354,87 @@ func (gb *GameBoy) Step() {
 
 	// Executa até completar um frame (aproximadamente 70224 ciclos)
 	targetCycles := 70224
+	if gb.mmu.IsDoubleSpeed() {
+		targetCycles *= 2
+	}
 	currentCycles := 0
 
+	gb.applyMovieInput()
+
 	for currentCycles < targetCycles {
-		// Executa uma instrução do CPU
-		cycles := gb.cpu.Step()
+		cycles, frameDone := gb.stepInstruction()
 		currentCycles += cycles
-		gb.cycleCount += uint64(cycles)
+		if frameDone {
+			break
+		}
+	}
 
-		// Atualiza outros componentes
-		gb.mmu.Step(cycles)
+	// Controle de timing
+	gb.handleTiming()
+}
 
-		// Verifica interrupções
-		gb.interrupts.CheckInterrupts()
+// stepInstruction executa uma instrução do CPU e avança os demais
+// componentes pelos mesmos ciclos. Retorna os ciclos gastos e se um frame
+// foi completado.
+func (gb *GameBoy) stepInstruction() (int, bool) {
+	cycles := gb.cpu.Step()
+	gb.cycleCount += uint64(cycles)
 
-		// Verifica se um frame foi completado
-		if gb.mmu.GetLCD().IsFrameReady() {
-			gb.frameCount++
+	// Atualiza outros componentes. As interrupções solicitadas aqui são
+	// atendidas pelo CPU antes da próxima instrução.
+	gb.mmu.Step(cycles)
 
-			// Chama callback de frame se definido
-			if gb.frameCallback != nil {
-				frameBuffer := gb.mmu.GetLCD().GetFrameBuffer()
-				gb.frameCallback(frameBuffer)
-			}
+	if !gb.mmu.GetLCD().IsFrameReady() {
+		return cycles, false
+	}
+	gb.endFrame()
+	return cycles, true
+}
 
-			// Chama callback de áudio se definido
-			if gb.audioCallback != nil && gb.config.EnableSound {
-				audioBuffer := gb.mmu.GetSound().GetAudioBuffer()
-				if len(audioBuffer) > 0 {
-					gb.audioCallback(audioBuffer)
-				}
-			}
+// endFrame conclui um frame: vídeo, áudio, movie, bateria, vibração e o
+// snapshot de rewind
+func (gb *GameBoy) endFrame() {
+	gb.frameCount++
 
-			break
+	// Monta a imagem do Super Game Boy com moldura
+	if gb.sgb != nil {
+		gb.sgb.UpdateFrame(gb.mmu.GetLCD().GetFrameBuffer())
+	}
+
+	gb.presentFrame()
+
+	// Chama callback de áudio se definido
+	if gb.audioCallback != nil && gb.config.EnableSound {
+		audioBuffer := gb.mmu.GetSound().GetAudioBuffer()
+		if len(audioBuffer) > 0 {
+			gb.audioCallback(audioBuffer)
 		}
 	}
 
-	// Controle de timing
-	gb.handleTiming()
+	gb.mmu.GetLCD().ClearFrameReady()
+	gb.updateMovie()
+	gb.mmu.GetInput().AdvanceFrame()
+	gb.checkBatteryFlush()
+	gb.reportRumble()
+	gb.captureRewind()
+}
+
+// presentFrame entrega o frame atual aos callbacks de vídeo
+func (gb *GameBoy) presentFrame() {
+	// Chama callback de frame colorido se definido
+	if gb.colorFrameCallback != nil {
+		gb.colorFrameCallback(gb.mmu.GetLCD().GetColorFrameBuffer())
+	}
+
+	if gb.sgb != nil && gb.sgbFrameCallback != nil {
+		gb.sgbFrameCallback(gb.sgb.GetFrameBuffer())
+	}
+
+	// Chama callback de frame se definido
+	if gb.frameCallback != nil {
+		frameBuffer := gb.mmu.GetLCD().GetFrameBuffer()
+		gb.frameCallback(frameBuffer)
+	}
 }
 
 // handleTiming controla o timing da emulação
@@ -265,6 +459,33 @@ func (gb *GameBoy) SetFrameCallback(callback func([144][160]uint8)) {
 	gb.frameCallback = callback
 }
 
+// SetColorFrameCallback define o callback para frames em RGB555. Use
+// video.ColorToRGBA/ColorFrameToRGBA para converter para RGBA.
+func (gb *GameBoy) SetColorFrameCallback(callback func([144][160]uint16)) {
+	gb.colorFrameCallback = callback
+}
+
+// SetSGBFrameCallback define o callback para a imagem de 256x224 do Super
+// Game Boy (RGB555, tela no centro da moldura). Só é chamado no modo SGB.
+func (gb *GameBoy) SetSGBFrameCallback(callback func([sgb.Height][sgb.Width]uint16)) {
+	gb.sgbFrameCallback = callback
+}
+
+// GetSGB retorna o Super Game Boy (nil fora do modo SGB)
+func (gb *GameBoy) GetSGB() *sgb.SGB {
+	return gb.sgb
+}
+
+// IsSGB retorna se a emulação está em modo Super Game Boy
+func (gb *GameBoy) IsSGB() bool {
+	return gb.sgb != nil
+}
+
+// IsCGB retorna se a emulação está em modo Game Boy Color
+func (gb *GameBoy) IsCGB() bool {
+	return gb.mmu.IsCGBMode()
+}
+
 // SetAudioCallback define o callback para áudio
 func (gb *GameBoy) SetAudioCallback(callback func([]int16)) {
 	gb.audioCallback = callback
@@ -275,6 +496,11 @@ func (gb *GameBoy) GetInput() *input.Input {
 	return gb.mmu.GetInput()
 }
 
+// GetColorFrameBuffer retorna o último frame em RGB555
+func (gb *GameBoy) GetColorFrameBuffer() [video.ScreenHeight][video.ScreenWidth]uint16 {
+	return gb.mmu.GetLCD().GetColorFrameBuffer()
+}
+
 // GetFrameCount retorna o número de frames processados
 func (gb *GameBoy) GetFrameCount() uint64 {
 	return gb.frameCount
@@ -300,157 +526,215 @@ func (gb *GameBoy) GetCartridgeType() uint8 {
 	return gb.mmu.GetCartridgeType()
 }
 
-// SaveState salva o estado atual da emulação
+// GetROMHeader retorna o header decodificado da ROM carregada (nil sem ROM)
+func (gb *GameBoy) GetROMHeader() *cartridge.ROMHeader {
+	return gb.mmu.GetHeader()
+}
+
+// GetROMHash retorna o SHA-1 do arquivo da ROM carregada, usado para
+// associar save states à ROM
+func (gb *GameBoy) GetROMHash() [sha1.Size]byte {
+	return gb.mmu.GetROMHash()
+}
+
+// GetROMWarnings retorna os problemas do header encontrados ao carregar a
+// ROM (tamanho divergente, checksum ou logo inválidos)
+func (gb *GameBoy) GetROMWarnings() []string {
+	return gb.mmu.GetROMWarnings()
+}
+
+// HasTiltSensor retorna se o cartucho possui acelerômetro (MBC7); nesse
+// caso o frontend deve alimentar Input.SetTilt
+func (gb *GameBoy) HasTiltSensor() bool {
+	_, ok := gb.mmu.GetCartridge().(*cartridge.MBC7)
+	return ok
+}
+
+// stateSection associa um componente à seção do save state onde ele é
+// gravado
+type stateSection struct {
+	name      string
+	component savestate.Component
+	data      *[]byte
+	optional  bool // Seção vazia mantém o componente como está
+}
+
+// stateSections lista os componentes da máquina e suas seções. O
+// cartucho e o SGB só entram quando presentes.
+func (gb *GameBoy) stateSections(ss *savestate.SaveState) []stateSection {
+	sections := []stateSection{
+		{"CPU", gb.cpu, &ss.CPU, false},
+		{"memória", gb.mmu, &ss.Memory, false},
+		{"LCD", gb.mmu.GetLCD(), &ss.LCD, false},
+		{"timer", gb.mmu.GetTimer(), &ss.Timer, false},
+		{"som", gb.mmu.GetSound(), &ss.Sound, false},
+		{"input", gb.mmu.GetInput(), &ss.Input, false},
+		{"interrupções", gb.interrupts, &ss.Interrupts, false},
+		{"serial", gb.mmu.GetSerial(), &ss.Serial, false},
+	}
+	if cart := gb.mmu.GetCartridge(); cart != nil {
+		// Cartuchos sem mapeador nem RAM gravam uma seção vazia, aceita
+		// pelo próprio LoadState
+		sections = append(sections, stateSection{"cartucho", cart, &ss.Cartridge, false})
+	}
+	if gb.sgb != nil {
+		sections = append(sections, stateSection{"SGB", gb.sgb, &ss.SGB, true})
+	}
+	return sections
+}
+
+// SaveState salva o estado completo da emulação: cada componente grava a
+// própria seção com SaveState
 func (gb *GameBoy) SaveState() ([]byte, error) {
 	saveState := savestate.NewSaveState()
 
-	// Define título da ROM
+	// Define título e hash da ROM
 	saveState.SetROMTitle(gb.GetROMTitle())
+	if gb.mmu.GetCartridge() != nil {
+		hash := gb.GetROMHash()
+		saveState.ROMHash = hash[:]
+	}
+
+	for _, section := range gb.stateSections(saveState) {
+		data, err := section.component.SaveState()
+		if err != nil {
+			return nil, fmt.Errorf("erro ao salvar save state (%s): %w", section.name, err)
+		}
+		*section.data = data
+	}
 
-	// Salva estado do CPU
-	saveState.CPU.A = gb.cpu.GetA()
-	saveState.CPU.B = gb.cpu.GetB()
-	saveState.CPU.C = gb.cpu.GetC()
-	saveState.CPU.D = gb.cpu.GetD()
-	saveState.CPU.E = gb.cpu.GetE()
-	saveState.CPU.H = gb.cpu.GetH()
-	saveState.CPU.L = gb.cpu.GetL()
-	saveState.CPU.SP = gb.cpu.GetSP()
-	saveState.CPU.PC = gb.cpu.GetPC()
-
-	flags := gb.cpu.GetF()
-	saveState.CPU.FlagZ = (flags & 0x80) != 0
-	saveState.CPU.FlagN = (flags & 0x40) != 0
-	saveState.CPU.FlagH = (flags & 0x20) != 0
-	saveState.CPU.FlagC = (flags & 0x10) != 0
-
-	saveState.CPU.Halted = gb.cpu.IsHalted()
-	saveState.CPU.InterruptsEnabled = gb.cpu.IsInterruptsEnabled()
-
-	// Salva estado da memória
-	// TODO: Implementar salvamento completo da memória
-
-	// Salva estado do LCD
-	saveState.LCD.LCDC = gb.mmu.GetLCD().ReadRegister(0xFF40)
-	saveState.LCD.STAT = gb.mmu.GetLCD().ReadRegister(0xFF41)
-	saveState.LCD.SCY = gb.mmu.GetLCD().ReadRegister(0xFF42)
-	saveState.LCD.SCX = gb.mmu.GetLCD().ReadRegister(0xFF43)
-	saveState.LCD.LY = gb.mmu.GetLCD().ReadRegister(0xFF44)
-	saveState.LCD.LYC = gb.mmu.GetLCD().ReadRegister(0xFF45)
-	saveState.LCD.BGP = gb.mmu.GetLCD().ReadRegister(0xFF47)
-	saveState.LCD.OBP0 = gb.mmu.GetLCD().ReadRegister(0xFF48)
-	saveState.LCD.OBP1 = gb.mmu.GetLCD().ReadRegister(0xFF49)
-	saveState.LCD.WY = gb.mmu.GetLCD().ReadRegister(0xFF4A)
-	saveState.LCD.WX = gb.mmu.GetLCD().ReadRegister(0xFF4B)
-
-	// Salva estado do Timer
-	saveState.Timer.DIV = gb.mmu.GetTimer().ReadRegister(0xFF04)
-	saveState.Timer.TIMA = gb.mmu.GetTimer().ReadRegister(0xFF05)
-	saveState.Timer.TMA = gb.mmu.GetTimer().ReadRegister(0xFF06)
-	saveState.Timer.TAC = gb.mmu.GetTimer().ReadRegister(0xFF07)
-
-	// Salva estado do Input
-	saveState.Input.JOYP = gb.mmu.GetInput().ReadRegister(0xFF00)
-	for i := 0; i < 8; i++ {
-		saveState.Input.Buttons[i] = gb.mmu.GetInput().IsButtonPressed(i)
-	}
-
-	// Salva estado do Sound
-	saveState.Sound.NR50 = gb.mmu.GetSound().ReadRegister(0xFF24)
-	saveState.Sound.NR51 = gb.mmu.GetSound().ReadRegister(0xFF25)
-	saveState.Sound.NR52 = gb.mmu.GetSound().ReadRegister(0xFF26)
-
-	// Salva estado das Interrupções
-	if gb.interrupts != nil {
-		saveState.Interrupts.InterruptFlag = gb.interrupts.ReadRegister(0xFF0F)
-		saveState.Interrupts.InterruptEnable = gb.interrupts.ReadRegister(0xFFFF)
-		saveState.Interrupts.MasterEnable = gb.interrupts.IsInterruptsEnabled()
+	emulator, err := savestate.Marshal(&gb.frameCount, &gb.cycleCount)
+	if err != nil {
+		return nil, err
 	}
+	saveState.Emulator = emulator
 
 	return saveState.Serialize()
 }
 
-// LoadState carrega um estado salvo
+// LoadState carrega um estado salvo. Estados de uma versão mais nova ou de
+// outra ROM retornam *statefile.VersionError e *statefile.ROMMismatchError.
+// Se algum componente rejeitar a sua seção, o estado anterior é restaurado.
 func (gb *GameBoy) LoadState(data []byte) error {
+	saveState, err := gb.parseState(data)
+	if err != nil {
+		return err
+	}
+
+	backup, err := gb.SaveState()
+	if err != nil {
+		return err
+	}
+	if err := gb.loadSections(saveState); err != nil {
+		previous, _ := savestate.Deserialize(backup)
+		gb.loadSections(previous)
+		return err
+	}
+
+	gb.clearRewind()
+	return nil
+}
+
+// parseState decodifica o save state e confere a versão e a ROM, sem
+// alterar a máquina
+func (gb *GameBoy) parseState(data []byte) (*savestate.SaveState, error) {
 	saveState, err := savestate.Deserialize(data)
 	if err != nil {
-		return fmt.Errorf("erro ao deserializar save state: %w", err)
+		return nil, fmt.Errorf("erro ao deserializar save state: %w", err)
 	}
 
 	if err := saveState.Validate(); err != nil {
-		return fmt.Errorf("save state inválido: %w", err)
-	}
-
-	// Carrega estado do CPU
-	gb.cpu.SetA(saveState.CPU.A)
-	gb.cpu.SetB(saveState.CPU.B)
-	gb.cpu.SetC(saveState.CPU.C)
-	gb.cpu.SetD(saveState.CPU.D)
-	gb.cpu.SetE(saveState.CPU.E)
-	gb.cpu.SetH(saveState.CPU.H)
-	gb.cpu.SetL(saveState.CPU.L)
-	gb.cpu.SetSP(saveState.CPU.SP)
-	gb.cpu.SetPC(saveState.CPU.PC)
-
-	var flags uint8
-	if saveState.CPU.FlagZ {
-		flags |= 0x80
-	}
-	if saveState.CPU.FlagN {
-		flags |= 0x40
-	}
-	if saveState.CPU.FlagH {
-		flags |= 0x20
-	}
-	if saveState.CPU.FlagC {
-		flags |= 0x10
-	}
-	gb.cpu.SetF(flags)
-
-	gb.cpu.SetHalted(saveState.CPU.Halted)
-	gb.cpu.SetInterruptsEnabled(saveState.CPU.InterruptsEnabled)
-
-	// Carrega estado da memória
-	// TODO: Implementar carregamento completo da memória
-
-	// Carrega estado do LCD
-	gb.mmu.GetLCD().WriteRegister(0xFF40, saveState.LCD.LCDC)
-	gb.mmu.GetLCD().WriteRegister(0xFF41, saveState.LCD.STAT)
-	gb.mmu.GetLCD().WriteRegister(0xFF42, saveState.LCD.SCY)
-	gb.mmu.GetLCD().WriteRegister(0xFF43, saveState.LCD.SCX)
-	gb.mmu.GetLCD().WriteRegister(0xFF45, saveState.LCD.LYC)
-	gb.mmu.GetLCD().WriteRegister(0xFF47, saveState.LCD.BGP)
-	gb.mmu.GetLCD().WriteRegister(0xFF48, saveState.LCD.OBP0)
-	gb.mmu.GetLCD().WriteRegister(0xFF49, saveState.LCD.OBP1)
-	gb.mmu.GetLCD().WriteRegister(0xFF4A, saveState.LCD.WY)
-	gb.mmu.GetLCD().WriteRegister(0xFF4B, saveState.LCD.WX)
-
-	// Carrega estado do Timer
-	gb.mmu.GetTimer().WriteRegister(0xFF05, saveState.Timer.TIMA)
-	gb.mmu.GetTimer().WriteRegister(0xFF06, saveState.Timer.TMA)
-	gb.mmu.GetTimer().WriteRegister(0xFF07, saveState.Timer.TAC)
-
-	// Carrega estado do Input
-	for i := 0; i < 8; i++ {
-		gb.mmu.GetInput().SetButtonState(i, saveState.Input.Buttons[i])
-	}
-
-	// Carrega estado do Sound
-	gb.mmu.GetSound().WriteRegister(0xFF24, saveState.Sound.NR50)
-	gb.mmu.GetSound().WriteRegister(0xFF25, saveState.Sound.NR51)
-	gb.mmu.GetSound().WriteRegister(0xFF26, saveState.Sound.NR52)
-
-	// Carrega estado das Interrupções
-	if gb.interrupts != nil {
-		gb.interrupts.WriteRegister(0xFF0F, saveState.Interrupts.InterruptFlag)
-		gb.interrupts.WriteRegister(0xFFFF, saveState.Interrupts.InterruptEnable)
-		if saveState.Interrupts.MasterEnable {
-			gb.interrupts.EnableInterrupts()
-		} else {
-			gb.interrupts.DisableInterrupts()
+		return nil, fmt.Errorf("save state inválido: %w", err)
+	}
+
+	hash := gb.GetROMHash()
+	if err := saveState.CheckROM(hash[:]); err != nil {
+		return nil, err
+	}
+
+	if len(saveState.Cartridge) > 0 && gb.mmu.GetCartridge() == nil {
+		return nil, fmt.Errorf("save state inválido: cartucho não corresponde à ROM carregada")
+	}
+	return saveState, nil
+}
+
+// loadSections restaura cada componente a partir da sua seção
+func (gb *GameBoy) loadSections(saveState *savestate.SaveState) error {
+	for _, section := range gb.stateSections(saveState) {
+		if section.optional && len(*section.data) == 0 {
+			continue
+		}
+		if err := section.component.LoadState(*section.data); err != nil {
+			return fmt.Errorf("erro ao carregar save state (%s): %w", section.name, err)
 		}
 	}
 
+	return savestate.Unmarshal(saveState.Emulator, &gb.frameCount, &gb.cycleCount)
+}
+
+// configureRewind cria o histórico de rewind conforme a configuração
+func (gb *GameBoy) configureRewind() {
+	if !gb.config.EnableRewind {
+		gb.rewind = nil
+		return
+	}
+	gb.rewind = rewind.NewBuffer(rewind.Config{
+		Interval: gb.config.RewindInterval,
+		Budget:   gb.config.RewindBudget,
+	})
+}
+
+// captureRewind grava o snapshot do frame no histórico de rewind. Uma
+// falha só deixa o frame sem snapshot.
+func (gb *GameBoy) captureRewind() {
+	if gb.rewind == nil || !gb.rewind.ShouldCapture(gb.frameCount) {
+		return
+	}
+
+	state, err := gb.SaveState()
+	if err != nil {
+		return
+	}
+	gb.rewind.Push(gb.frameCount, state)
+}
+
+// clearRewind descarta o histórico de rewind
+func (gb *GameBoy) clearRewind() {
+	if gb.rewind != nil {
+		gb.rewind.Clear()
+	}
+}
+
+// Rewind volta a emulação frames quadros, até o snapshot mais antigo
+// disponível, e entrega o frame restaurado aos callbacks de vídeo
+func (gb *GameBoy) Rewind(frames int) error {
+	if gb.rewind == nil {
+		return fmt.Errorf("rewind desativado")
+	}
+	if frames <= 0 {
+		return nil
+	}
+
+	target := uint64(0)
+	if uint64(frames) < gb.frameCount {
+		target = gb.frameCount - uint64(frames)
+	}
+	state, _, ok := gb.rewind.Rewind(target)
+	if !ok {
+		return fmt.Errorf("histórico de rewind vazio")
+	}
+
+	// Os snapshots foram gravados por esta mesma máquina: dispensam a cópia
+	// de segurança de LoadState, que custaria um SaveState por frame
+	saveState, err := gb.parseState(state)
+	if err != nil {
+		return err
+	}
+	if err := gb.loadSections(saveState); err != nil {
+		return fmt.Errorf("erro ao restaurar snapshot de rewind: %w", err)
+	}
+
+	gb.presentFrame()
 	return nil
 }
 
@@ -461,8 +745,14 @@ func (gb *GameBoy) GetConfig() Config {
 
 // SetConfig atualiza a configuração
 func (gb *GameBoy) SetConfig(config Config) {
+	previous := gb.config
 	gb.config = config
 	gb.targetFPS = config.TargetFPS
+
+	if config.EnableRewind != previous.EnableRewind || config.RewindInterval != previous.RewindInterval ||
+		config.RewindBudget != previous.RewindBudget {
+		gb.configureRewind()
+	}
 }
 
 // String retorna uma representação em string do estado do Game Boy
@@ -1,8 +1,11 @@
 package gb
 
 import (
+	"errors"
+	"image"
 	"testing"
 
+	"github.com/hobbiee/visualboy-go/internal/core/gb/cartridge"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/cpu"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/input"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/sound"
@@ -273,6 +276,144 @@ func TestGameBoyMemoryMapping(t *testing.T) {
 	t.Log("Memory mapping test completed successfully")
 }
 
+// TestLoadROMUnsupportedMapper verifica que tipos sem mapeador são recusados
+func TestLoadROMUnsupportedMapper(t *testing.T) {
+	gameboy := NewGameBoy(DefaultConfig())
+
+	rom := make([]uint8, 0x8000)
+	rom[0x147] = 0xFD // BANDAI TAMA5
+	err := gameboy.LoadROM(rom)
+	if !errors.Is(err, cartridge.ErrUnsupportedMapper) {
+		t.Fatalf("expected unsupported mapper error, got %v", err)
+	}
+
+	var unsupported *cartridge.UnsupportedMapperError
+	if !errors.As(err, &unsupported) || unsupported.Type != 0xFD {
+		t.Errorf("error should carry cartridge type 0xFD: %v", err)
+	}
+
+	rom[0x147] = 0xFF // HuC1
+	if err := gameboy.LoadROM(rom); err != nil {
+		t.Errorf("HuC1 should be supported: %v", err)
+	}
+}
+
+// TestTiltSensor verifica que o MBC7 lê a inclinação do input
+func TestTiltSensor(t *testing.T) {
+	gameboy := NewGameBoy(DefaultConfig())
+
+	rom := make([]uint8, 0x8000)
+	rom[0x147] = 0x22 // MBC7
+	if err := gameboy.LoadROM(rom); err != nil {
+		t.Fatalf("failed to load MBC7 ROM: %v", err)
+	}
+	if !gameboy.HasTiltSensor() {
+		t.Fatal("MBC7 cartridge should report a tilt sensor")
+	}
+
+	gameboy.GetInput().SetTilt(-2, 0.5)
+	if x, y := gameboy.GetInput().Tilt(); x != -1 || y != 0.5 {
+		t.Errorf("tilt should be clamped to -1..1, got (%v, %v)", x, y)
+	}
+
+	gameboy.mmu.Write(0x0000, 0x0A)
+	gameboy.mmu.Write(0x4000, 0x40)
+	gameboy.mmu.Write(0xA000, 0x55)
+	gameboy.mmu.Write(0xA010, 0xAA)
+
+	x := uint16(gameboy.mmu.Read(0xA030))<<8 | uint16(gameboy.mmu.Read(0xA020))
+	if x != cartridge.MBC7AccelCenter-cartridge.MBC7AccelGain {
+		t.Errorf("expected X reading 0x%04X, got 0x%04X",
+			cartridge.MBC7AccelCenter-cartridge.MBC7AccelGain, x)
+	}
+}
+
+// TestCameraSource verifica que a fonte de imagens vale para ROMs
+// carregadas depois e que a captura grava a imagem na SRAM
+func TestCameraSource(t *testing.T) {
+	gameboy := NewGameBoy(DefaultConfig())
+
+	black := image.NewGray(image.Rect(0, 0, 128, 112))
+	gameboy.SetCameraSource(cartridge.NewStaticImageSource(black))
+
+	rom := make([]uint8, 0x8000)
+	rom[0x147] = 0xFC // POCKET CAMERA
+	if err := gameboy.LoadROM(rom); err != nil {
+		t.Fatalf("failed to load camera ROM: %v", err)
+	}
+	if !gameboy.HasCamera() {
+		t.Fatal("cartridge 0xFC should be a camera")
+	}
+
+	// Limiares 0x40/0x80/0xC0 e captura
+	gameboy.mmu.Write(0x4000, 0x10)
+	for i := uint16(0); i < 48; i++ {
+		gameboy.mmu.Write(0xA006+i, uint8(0x40*(i%3+1)))
+	}
+	gameboy.mmu.Write(0xA000, 0x01)
+	for gameboy.mmu.Read(0xA000)&0x01 != 0 {
+		gameboy.mmu.Step(4)
+	}
+
+	gameboy.mmu.Write(0x4000, 0x00)
+	if got := gameboy.mmu.Read(0xA100); got != 0xFF {
+		t.Errorf("black image should produce color 3 pixels, got low plane 0x%02X", got)
+	}
+}
+
+// TestRumbleListener verifica que o motor do MBC5 é informado a cada frame
+// enquanto ligado e uma última vez ao desligar
+func TestRumbleListener(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableVSync = false
+	gameboy := NewGameBoy(config)
+
+	rom := make([]uint8, 0x8000)
+	rom[0x147] = 0x1C // MBC5+RUMBLE
+	copy(rom[0x100:], []uint8{
+		0x3E, 0x08, // LD A, 0x08
+		0xEA, 0x00, 0x40, // LD (0x4000), A
+		0x18, 0xFE, // JR -2
+	})
+	if err := gameboy.LoadROM(rom); err != nil {
+		t.Fatalf("failed to load rumble ROM: %v", err)
+	}
+	if !gameboy.HasRumble() {
+		t.Fatal("cartridge 0x1C should report rumble")
+	}
+
+	type report struct {
+		on   bool
+		duty float64
+	}
+	var reports []report
+	gameboy.SetRumbleListener(func(on bool, duty float64) {
+		reports = append(reports, report{on, duty})
+	})
+
+	gameboy.Start()
+	gameboy.Step()
+	gameboy.Step()
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports with the motor on, got %d", len(reports))
+	}
+	if last := reports[1]; !last.on || last.duty != 1 {
+		t.Errorf("motor on for the whole frame: expected (true, 1), got (%v, %v)", last.on, last.duty)
+	}
+
+	// Desliga o motor: um aviso final e depois silêncio
+	gameboy.mmu.Write(0x4000, 0x00)
+	gameboy.cpu.SetPC(0x0105)
+	gameboy.Step()
+	gameboy.Step()
+	if len(reports) != 3 {
+		t.Fatalf("expected a single report after the motor stops, got %d", len(reports)-2)
+	}
+	if last := reports[2]; last.on || last.duty != 0 {
+		t.Errorf("motor off: expected (false, 0), got (%v, %v)", last.on, last.duty)
+	}
+}
+
 // BenchmarkGameBoyStep benchmarks a execução de um step completo
 func BenchmarkGameBoyStep(b *testing.B) {
 	interruptHandler := &MockInterruptHandler{}
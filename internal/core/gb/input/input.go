@@ -1,6 +1,10 @@
 package input
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
+)
 
 // Constantes do Input
 const (
@@ -29,6 +33,21 @@ const (
 	ButtonCount
 )
 
+// Super Game Boy
+const (
+	// Tamanho de um pacote de comando SGB (128 bits)
+	SGBPacketSize = 16
+
+	// Número máximo de controles com MLT_REQ
+	MaxPlayers = 4
+)
+
+// Duração de cada passo de SimulateKeySequence, em frames
+const (
+	SequencePressFrames   = 2
+	SequenceReleaseFrames = 2
+)
+
 // Input representa o sistema de input do Game Boy
 type Input struct {
 	// Estado dos botões (true = pressionado)
@@ -37,6 +56,26 @@ type Input struct {
 	// Registrador JOYP
 	joyp uint8
 
+	// Inclinação do console (-1 a 1 por eixo) lida por cartuchos com
+	// acelerômetro
+	tiltX, tiltY float64
+
+	// Super Game Boy: pacotes recebidos pelos pulsos em P14/P15 e controles
+	// extras do modo multiplayer (o jogador 1 usa buttons)
+	packetHandler func(packet [SGBPacketSize]uint8)
+	packet        [SGBPacketSize]uint8
+	packetBit     int  // Próximo bit do pacote (128 = stop bit)
+	receiving     bool // Pulso de reset recebido
+	players       int  // 1, 2 ou 4
+	currentPlayer int
+	playerButtons [MaxPlayers][ButtonCount]bool
+
+	// Pressionamentos simulados: frames restantes de cada botão e a
+	// sequência ainda não iniciada
+	simulated    [ButtonCount]int
+	sequence     []int
+	sequenceWait int // Frames até o próximo botão da sequência
+
 	// Interface de interrupções
 	interruptHandler InterruptHandler
 }
@@ -51,6 +90,7 @@ func NewInput(interruptHandler InterruptHandler) *Input {
 	return &Input{
 		interruptHandler: interruptHandler,
 		joyp:             0xFF, // Todos os bits em 1 (nenhum botão pressionado)
+		players:          1,
 	}
 }
 
@@ -60,6 +100,37 @@ func (inp *Input) Reset() {
 		inp.buttons[i] = false
 	}
 	inp.joyp = 0xFF
+	inp.tiltX, inp.tiltY = 0, 0
+	inp.playerButtons = [MaxPlayers][ButtonCount]bool{}
+	inp.receiving = false
+	inp.players = 1
+	inp.currentPlayer = 0
+	inp.simulated = [ButtonCount]int{}
+	inp.sequence = nil
+	inp.sequenceWait = 0
+}
+
+// SetTilt define a inclinação do console em cada eixo, de -1 a 1 (1g).
+// X positivo inclina para a direita e Y positivo para baixo.
+func (inp *Input) SetTilt(x, y float64) {
+	inp.tiltX = clampTilt(x)
+	inp.tiltY = clampTilt(y)
+}
+
+// Tilt retorna a inclinação atual do console
+func (inp *Input) Tilt() (float64, float64) {
+	return inp.tiltX, inp.tiltY
+}
+
+// clampTilt limita a inclinação ao intervalo aceito
+func clampTilt(value float64) float64 {
+	if value < -1 {
+		return -1
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
 }
 
 // SetButtonState define o estado de um botão
@@ -101,6 +172,7 @@ func (inp *Input) ReleaseButton(button int) {
 func (inp *Input) updateJOYP() {
 	// Começa com todos os bits em 1 (nenhum botão pressionado)
 	result := uint8(0xFF)
+	buttons := inp.activeButtons()
 
 	// Verifica qual grupo de botões está selecionado
 	selectButtons := (inp.joyp & JOYPSelectButtons) == 0
@@ -108,36 +180,42 @@ func (inp *Input) updateJOYP() {
 
 	if selectButtons {
 		// Botões A, B, Select, Start
-		if inp.buttons[ButtonStart] {
+		if buttons[ButtonStart] {
 			result &= ^uint8(JOYPDown) // Bit 3 = 0 quando pressionado
 		}
-		if inp.buttons[ButtonSelect] {
+		if buttons[ButtonSelect] {
 			result &= ^uint8(JOYPUp) // Bit 2 = 0 quando pressionado
 		}
-		if inp.buttons[ButtonB] {
+		if buttons[ButtonB] {
 			result &= ^uint8(JOYPLeft) // Bit 1 = 0 quando pressionado
 		}
-		if inp.buttons[ButtonA] {
+		if buttons[ButtonA] {
 			result &= ^uint8(JOYPRight) // Bit 0 = 0 quando pressionado
 		}
 	}
 
 	if selectDPad {
 		// D-Pad: Down, Up, Left, Right
-		if inp.buttons[ButtonDown] {
+		if buttons[ButtonDown] {
 			result &= ^uint8(JOYPDown) // Bit 3 = 0 quando pressionado
 		}
-		if inp.buttons[ButtonUp] {
+		if buttons[ButtonUp] {
 			result &= ^uint8(JOYPUp) // Bit 2 = 0 quando pressionado
 		}
-		if inp.buttons[ButtonLeft] {
+		if buttons[ButtonLeft] {
 			result &= ^uint8(JOYPLeft) // Bit 1 = 0 quando pressionado
 		}
-		if inp.buttons[ButtonRight] {
+		if buttons[ButtonRight] {
 			result &= ^uint8(JOYPRight) // Bit 0 = 0 quando pressionado
 		}
 	}
 
+	// Com MLT_REQ ativo e nenhum grupo selecionado, os bits 0-3 informam o
+	// controle atual (0xF = jogador 1, 0xE = jogador 2...)
+	if !selectButtons && !selectDPad && inp.players > 1 {
+		result = (result & 0xF0) | (0x0F - uint8(inp.currentPlayer))
+	}
+
 	// Preserva os bits de seleção (bits 4-5)
 	result = (result & 0x0F) | (inp.joyp & 0x30)
 
@@ -156,11 +234,94 @@ func (inp *Input) ReadRegister(addr uint16) uint8 {
 func (inp *Input) WriteRegister(addr uint16, value uint8) {
 	if addr == RegJOYP {
 		// Apenas os bits 4-5 podem ser escritos (seleção de grupo)
+		previous := inp.joyp & 0x30
 		inp.joyp = (inp.joyp & 0x0F) | (value & 0x30)
+		inp.handleSGBPulse(previous, value&0x30)
 		inp.updateJOYP()
 	}
 }
 
+// activeButtons retorna os botões do controle selecionado por MLT_REQ
+func (inp *Input) activeButtons() *[ButtonCount]bool {
+	if inp.currentPlayer == 0 {
+		return &inp.buttons
+	}
+	return &inp.playerButtons[inp.currentPlayer]
+}
+
+// EnableSGB liga a recepção de pacotes do Super Game Boy; cada pacote de
+// 16 bytes recebido é entregue ao handler. nil desliga a recepção.
+func (inp *Input) EnableSGB(handler func(packet [SGBPacketSize]uint8)) {
+	inp.packetHandler = handler
+	inp.receiving = false
+	inp.SetPlayerCount(1)
+}
+
+// handleSGBPulse decodifica os pulsos em P14/P15: ambos em 0 iniciam um
+// pacote, P14=0 envia um bit 0 e P15=0 um bit 1. Cada pulso termina com
+// ambos em 1. Após 128 bits vem um stop bit 0.
+func (inp *Input) handleSGBPulse(previous, current uint8) {
+	if inp.packetHandler == nil {
+		return
+	}
+
+	// Com MLT_REQ ativo, a subida de P15 seleciona o próximo controle
+	if inp.players > 1 && previous&JOYPSelectButtons == 0 && current&JOYPSelectButtons != 0 {
+		inp.currentPlayer = (inp.currentPlayer + 1) % inp.players
+	}
+
+	if current == 0x00 {
+		inp.receiving = true
+		inp.packetBit = 0
+		inp.packet = [SGBPacketSize]uint8{}
+		return
+	}
+	if !inp.receiving || previous != 0x30 || current == 0x30 {
+		return
+	}
+
+	if inp.packetBit == SGBPacketSize*8 {
+		// Stop bit: o pacote está completo
+		inp.receiving = false
+		inp.packetHandler(inp.packet)
+		return
+	}
+	if current == JOYPSelectDPad { // P15 em 0: bit 1
+		inp.packet[inp.packetBit/8] |= 1 << uint(inp.packetBit%8)
+	}
+	inp.packetBit++
+}
+
+// SetPlayerCount define o número de controles lidos pelo jogo (MLT_REQ):
+// 1, 2 ou 4
+func (inp *Input) SetPlayerCount(players int) {
+	if players != 2 && players != 4 {
+		players = 1
+	}
+	inp.players = players
+	inp.currentPlayer = 0
+	inp.updateJOYP()
+}
+
+// GetPlayerCount retorna o número de controles ativos
+func (inp *Input) GetPlayerCount() int {
+	return inp.players
+}
+
+// SetPlayerButtonState define o estado de um botão de um controle (0 a 3);
+// o controle 0 é o mesmo de SetButtonState
+func (inp *Input) SetPlayerButtonState(player, button int, pressed bool) {
+	if player == 0 {
+		inp.SetButtonState(button, pressed)
+		return
+	}
+	if player < 0 || player >= MaxPlayers || button < 0 || button >= ButtonCount {
+		return
+	}
+	inp.playerButtons[player][button] = pressed
+	inp.updateJOYP()
+}
+
 // GetJOYP retorna o valor atual do registrador JOYP
 func (inp *Input) GetJOYP() uint8 {
 	return inp.joyp
@@ -259,6 +420,26 @@ func (inp *Input) GetDirectionVector() (int, int) {
 	return x, y
 }
 
+// stateFields lista os campos gravados no save state. Os botões vêm do
+// frontend e não são salvos.
+func (inp *Input) stateFields() []any {
+	return []any{&inp.joyp, &inp.packet, &inp.packetBit, &inp.receiving, &inp.players, &inp.currentPlayer}
+}
+
+// SaveState serializa o JOYP, o pacote SGB em recepção e o controle
+// selecionado no modo multiplayer
+func (inp *Input) SaveState() ([]byte, error) {
+	return savestate.Marshal(inp.stateFields()...)
+}
+
+// LoadState restaura o estado gerado por SaveState
+func (inp *Input) LoadState(data []byte) error {
+	if err := savestate.Unmarshal(data, inp.stateFields()...); err != nil {
+		return fmt.Errorf("estado do input inválido: %w", err)
+	}
+	return nil
+}
+
 // String retorna uma representação em string do estado do input
 func (inp *Input) String() string {
 	pressed := inp.GetPressedButtonNames()
@@ -268,18 +449,70 @@ func (inp *Input) String() string {
 	return "Input: Pressed=" + fmt.Sprintf("%v", pressed) + " (JOYP=0x" + fmt.Sprintf("%02X", inp.joyp) + ")"
 }
 
-// SimulateKeyPress simula o pressionamento de uma tecla por um período
+// SimulateKeyPress pressiona um botão por duration frames (no mínimo
+// um). O botão é solto por AdvanceFrame.
 func (inp *Input) SimulateKeyPress(button int, duration int) {
+	if button < 0 || button >= ButtonCount {
+		return
+	}
+	if duration < 1 {
+		duration = 1
+	}
 	inp.PressButton(button)
-	// Em uma implementação real, você usaria um timer para soltar o botão
-	// Por enquanto, apenas pressiona o botão
+	inp.simulated[button] = duration
 }
 
-// SimulateKeySequence simula uma sequência de pressionamentos de tecla
+// SimulateKeySequence enfileira uma sequência de botões. Cada botão fica
+// pressionado por SequencePressFrames frames e solto por
+// SequenceReleaseFrames antes do seguinte, para que o jogo leia cada
+// pressionamento separadamente.
 func (inp *Input) SimulateKeySequence(sequence []int) {
-	for _, button := range sequence {
-		inp.PressButton(button)
-		// Em uma implementação real, você adicionaria delays entre os pressionamentos
-		inp.ReleaseButton(button)
+	inp.sequence = append(inp.sequence, sequence...)
+	if inp.sequenceWait == 0 {
+		inp.nextInSequence()
+	}
+}
+
+// IsSimulating retorna se ainda há pressionamentos simulados pendentes
+func (inp *Input) IsSimulating() bool {
+	if len(inp.sequence) > 0 {
+		return true
+	}
+	for _, frames := range inp.simulated {
+		if frames > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// AdvanceFrame avança os pressionamentos simulados ao fim de um frame
+func (inp *Input) AdvanceFrame() {
+	for button, frames := range inp.simulated {
+		if frames == 0 {
+			continue
+		}
+		inp.simulated[button]--
+		if frames == 1 {
+			inp.ReleaseButton(button)
+		}
+	}
+
+	if inp.sequenceWait > 0 {
+		inp.sequenceWait--
+		if inp.sequenceWait == 0 {
+			inp.nextInSequence()
+		}
+	}
+}
+
+// nextInSequence pressiona o próximo botão da sequência
+func (inp *Input) nextInSequence() {
+	if len(inp.sequence) == 0 {
+		return
 	}
+	button := inp.sequence[0]
+	inp.sequence = inp.sequence[1:]
+	inp.SimulateKeyPress(button, SequencePressFrames)
+	inp.sequenceWait = SequencePressFrames + SequenceReleaseFrames
 }
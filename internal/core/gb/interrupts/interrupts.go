@@ -1,6 +1,10 @@
 package interrupts
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
+)
 
 // Constantes de Interrupções
 const (
@@ -23,26 +27,21 @@ const (
 	VectorJoypad  = 0x60
 )
 
-// InterruptController gerencia o sistema de interrupções do Game Boy
+// InterruptController gerencia o sistema de interrupções do Game Boy.
+// Mantém IE e IF; o CPU lê as interrupções pendentes a cada instrução e
+// decide quando acordar do HALT/STOP e quando atendê-las (ver
+// cpu.InterruptSource). O IME pertence ao CPU.
 type InterruptController struct {
 	// Registradores
 	interruptFlag   uint8 // IF - Interrupt Flag
 	interruptEnable uint8 // IE - Interrupt Enable
 	
-	// Estado
-	masterEnable bool // IME - Interrupt Master Enable
-	
 	// Interface para CPU
 	cpuInterface CPUInterface
 }
 
 // CPUInterface define a interface para comunicação com o CPU
 type CPUInterface interface {
-	GetPC() uint16
-	SetPC(pc uint16)
-	Push(value uint16)
-	IsHalted() bool
-	SetHalted(halted bool)
 	IsInterruptsEnabled() bool
 	SetInterruptsEnabled(enabled bool)
 }
@@ -51,7 +50,6 @@ type CPUInterface interface {
 func NewInterruptController(cpu CPUInterface) *InterruptController {
 	return &InterruptController{
 		cpuInterface: cpu,
-		masterEnable: true,
 	}
 }
 
@@ -59,89 +57,53 @@ func NewInterruptController(cpu CPUInterface) *InterruptController {
 func (ic *InterruptController) Reset() {
 	ic.interruptFlag = 0x00
 	ic.interruptEnable = 0x00
-	ic.masterEnable = true
 }
 
-// RequestInterrupt solicita uma interrupção
+// RequestInterrupt solicita uma interrupção. O CPU acorda do HALT na
+// próxima instrução se a interrupção também estiver habilitada em IE.
 func (ic *InterruptController) RequestInterrupt(interrupt uint8) {
-	ic.interruptFlag |= interrupt
-	
-	// Se o CPU está em HALT, acorda mesmo se IME estiver desabilitado
-	if ic.cpuInterface.IsHalted() {
-		ic.cpuInterface.SetHalted(false)
-	}
+	ic.interruptFlag |= interrupt & 0x1F
 }
 
-// CheckInterrupts verifica e processa interrupções pendentes
+// CheckInterrupts retorna se o CPU atenderá uma interrupção antes da
+// próxima instrução (IME ativo e IE & IF diferente de zero)
 func (ic *InterruptController) CheckInterrupts() bool {
-	// Verifica se há interrupções habilitadas e pendentes
-	pending := ic.interruptFlag & ic.interruptEnable
-	if pending == 0 {
-		return false
-	}
-	
-	// Se IME está desabilitado, não processa interrupções
-	if !ic.masterEnable || !ic.cpuInterface.IsInterruptsEnabled() {
-		return false
-	}
-	
-	// Processa a interrupção de maior prioridade
-	for i := uint8(0); i < 5; i++ {
-		bit := uint8(1 << i)
-		if pending&bit != 0 {
-			ic.handleInterrupt(bit)
-			return true
-		}
-	}
-	
-	return false
+	return ic.HasPendingInterrupts() && ic.IsInterruptsEnabled()
 }
 
-// handleInterrupt processa uma interrupção específica
-func (ic *InterruptController) handleInterrupt(interrupt uint8) {
-	// Limpa a flag da interrupção
+// PendingInterrupts implementa cpu.InterruptSource (IE & IF)
+func (ic *InterruptController) PendingInterrupts() uint8 {
+	return ic.interruptFlag & ic.interruptEnable
+}
+
+// RequestedInterrupts implementa cpu.InterruptSource (IF)
+func (ic *InterruptController) RequestedInterrupts() uint8 {
+	return ic.interruptFlag
+}
+
+// AcknowledgeInterrupt implementa cpu.InterruptSource: limpa o bit em IF
+// quando o CPU atende a interrupção
+func (ic *InterruptController) AcknowledgeInterrupt(interrupt uint8) {
 	ic.interruptFlag &= ^interrupt
-	
-	// Desabilita interrupções
-	ic.masterEnable = false
-	ic.cpuInterface.SetInterruptsEnabled(false)
-	
-	// Salva PC atual na pilha
-	ic.cpuInterface.Push(ic.cpuInterface.GetPC())
-	
-	// Salta para o vetor de interrupção apropriado
-	var vector uint16
-	switch interrupt {
-	case InterruptVBlank:
-		vector = VectorVBlank
-	case InterruptLCDSTAT:
-		vector = VectorLCDSTAT
-	case InterruptTimer:
-		vector = VectorTimer
-	case InterruptSerial:
-		vector = VectorSerial
-	case InterruptJoypad:
-		vector = VectorJoypad
-	default:
-		return // Interrupção inválida
-	}
-	
-	ic.cpuInterface.SetPC(vector)
 }
 
 // EnableInterrupts habilita o sistema de interrupções (IME = 1)
 func (ic *InterruptController) EnableInterrupts() {
-	ic.masterEnable = true
+	if ic.cpuInterface != nil {
+		ic.cpuInterface.SetInterruptsEnabled(true)
+	}
 }
 
 // DisableInterrupts desabilita o sistema de interrupções (IME = 0)
 func (ic *InterruptController) DisableInterrupts() {
-	ic.masterEnable = false
+	if ic.cpuInterface != nil {
+		ic.cpuInterface.SetInterruptsEnabled(false)
+	}
 }
 
 // IsInterruptsEnabled retorna se as interrupções estão habilitadas
 func (ic *InterruptController) IsInterruptsEnabled() bool {
-	return ic.masterEnable
+	return ic.cpuInterface != nil && ic.cpuInterface.IsInterruptsEnabled()
 }
 
 // ReadRegister lê um registrador de interrupção
@@ -262,10 +224,23 @@ func (ic *InterruptController) GetPendingInterruptNames() []string {
 	return names
 }
 
+// SaveState serializa IF e IE (o IME faz parte do estado do CPU)
+func (ic *InterruptController) SaveState() ([]byte, error) {
+	return savestate.Marshal(&ic.interruptFlag, &ic.interruptEnable)
+}
+
+// LoadState restaura o estado gerado por SaveState
+func (ic *InterruptController) LoadState(data []byte) error {
+	if err := savestate.Unmarshal(data, &ic.interruptFlag, &ic.interruptEnable); err != nil {
+		return fmt.Errorf("estado das interrupções inválido: %w", err)
+	}
+	return nil
+}
+
 // String retorna uma representação em string do estado das interrupções
 func (ic *InterruptController) String() string {
 	ime := "disabled"
-	if ic.masterEnable {
+	if ic.IsInterruptsEnabled() {
 		ime = "enabled"
 	}
 	
@@ -0,0 +1,38 @@
+package gb
+
+import "testing"
+
+func TestGameBoyHaltWithoutIMEWakesOnVBlank(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableVSync = false
+	gameboy := NewGameBoy(config)
+
+	rom := newLoopROM(0x00)
+	program := []uint8{
+		0xF3,       // DI
+		0x3E, 0x01, // LD A, 0x01
+		0xE0, 0xFF, // LDH (IE), A
+		0x76,       // HALT
+		0x3C,       // INC A
+		0x18, 0xFE, // JR -2
+	}
+	copy(rom[0x100:], program)
+
+	if err := gameboy.LoadROM(rom); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+
+	gameboy.Start()
+	gameboy.Step()
+	gameboy.Step()
+
+	if gameboy.cpu.IsHalted() {
+		t.Fatal("Expected V-Blank to wake the CPU with IME=0")
+	}
+	if gameboy.cpu.GetPC() != 0x0107 {
+		t.Errorf("Expected PC=0x0107 after HALT, got 0x%04X", gameboy.cpu.GetPC())
+	}
+	if gameboy.cpu.GetA() != 0x02 {
+		t.Errorf("Expected INC A to run once, A=0x%02X", gameboy.cpu.GetA())
+	}
+}
@@ -0,0 +1,37 @@
+package gb
+
+import (
+	"github.com/hobbiee/visualboy-go/internal/core/gb/printer"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/serial"
+)
+
+// SerialPeer representa o outro lado do cabo link (ver serial.Peer). As
+// implementações disponíveis são serial.NullPeer (sem cabo),
+// serial.LoopbackPeer, serial.PairPeer (via ConnectLink), serial.TCPPeer e
+// printer.Printer (via ConnectPrinter).
+type SerialPeer = serial.Peer
+
+// SetSerialPeer conecta a porta serial a um SerialPeer (nil desconecta)
+func (gb *GameBoy) SetSerialPeer(peer SerialPeer) {
+	gb.mmu.GetSerial().SetPeer(peer)
+}
+
+// GetSerialPeer retorna o SerialPeer conectado à porta serial
+func (gb *GameBoy) GetSerialPeer() SerialPeer {
+	return gb.mmu.GetSerial().GetPeer()
+}
+
+// ConnectLink conecta duas instâncias no mesmo processo por um cabo link
+func ConnectLink(a, b *GameBoy) {
+	peerA, peerB := serial.NewPairPeers()
+	a.SetSerialPeer(peerA)
+	b.SetSerialPeer(peerB)
+}
+
+// ConnectPrinter conecta um Game Boy Printer à porta serial. As impressões
+// concluídas são gravadas como PNG em outputDir.
+func (gb *GameBoy) ConnectPrinter(outputDir string) *printer.Printer {
+	p := printer.NewPrinter(outputDir)
+	gb.SetSerialPeer(p)
+	return p
+}
@@ -0,0 +1,74 @@
+package gb
+
+import "testing"
+
+// newSerialROM cria uma ROM que escreve value em SB, sc em SC e fica em loop
+func newSerialROM(value, sc uint8) []uint8 {
+	rom := newLoopROM(0x00)
+	program := []uint8{
+		0x3E, value, // LD A, value
+		0xE0, 0x01, // LDH (SB), A
+		0x3E, sc, // LD A, sc
+		0xE0, 0x02, // LDH (SC), A
+		0x18, 0xFE, // JR -2
+	}
+	copy(rom[0x100:], program)
+	return rom
+}
+
+func TestGameBoyLinkCable(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableVSync = false
+
+	master := NewGameBoy(config)
+	slave := NewGameBoy(config)
+
+	if err := master.LoadROM(newSerialROM(0x42, 0x81)); err != nil {
+		t.Fatalf("Failed to load master ROM: %v", err)
+	}
+	if err := slave.LoadROM(newSerialROM(0x99, 0x80)); err != nil {
+		t.Fatalf("Failed to load slave ROM: %v", err)
+	}
+
+	ConnectLink(master, slave)
+
+	master.Start()
+	slave.Start()
+
+	// O escravo prepara SB/SC antes do mestre iniciar o clock
+	slave.Step()
+	master.Step()
+
+	if got := master.mmu.Read(0xFF01); got != 0x99 {
+		t.Errorf("Expected master SB=0x99, got 0x%02X", got)
+	}
+	if got := slave.mmu.Read(0xFF01); got != 0x42 {
+		t.Errorf("Expected slave SB=0x42, got 0x%02X", got)
+	}
+	if master.mmu.Read(0xFF0F)&0x08 == 0 || slave.mmu.Read(0xFF0F)&0x08 == 0 {
+		t.Error("Expected serial interrupt on both sides")
+	}
+}
+
+func TestGameBoyPrinterReplies(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableVSync = false
+
+	gameboy := NewGameBoy(config)
+	if err := gameboy.LoadROM(newSerialROM(0x88, 0x81)); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+
+	p := gameboy.ConnectPrinter("")
+	if gameboy.GetSerialPeer() != p {
+		t.Fatal("Expected printer to be the serial peer")
+	}
+
+	gameboy.Start()
+	gameboy.Step()
+
+	// Com a impressora conectada, a linha não fica mais em 0xFF
+	if got := gameboy.mmu.Read(0xFF01); got != 0x00 {
+		t.Errorf("Expected printer reply 0x00, got 0x%02X", got)
+	}
+}
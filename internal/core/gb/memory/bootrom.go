@@ -0,0 +1,59 @@
+package memory
+
+import "fmt"
+
+// Tamanhos válidos de boot ROM
+const (
+	BootROMSizeDMG = 0x100 // 256 bytes (0x0000-0x00FF)
+	BootROMSizeCGB = 0x900 // 2304 bytes (0x0000-0x00FF e 0x0200-0x08FF)
+
+	// KEY0 bit 2: boot ROM do CGB selecionou o modo de compatibilidade DMG
+	Key0DMGCompat = 0x04
+)
+
+// LoadBootROM carrega uma imagem de boot ROM. A imagem passa a ser mapeada
+// sobre a ROM do cartucho no próximo Reset, até uma escrita em 0xFF50.
+func (mmu *MMU) LoadBootROM(data []uint8) error {
+	if len(data) != BootROMSizeDMG && len(data) != BootROMSizeCGB {
+		return fmt.Errorf("tamanho de boot ROM inválido: %d bytes (esperado %d ou %d)",
+			len(data), BootROMSizeDMG, BootROMSizeCGB)
+	}
+
+	mmu.bootROM = make([]uint8, len(data))
+	copy(mmu.bootROM, data)
+	return nil
+}
+
+// HasBootROM retorna se uma boot ROM foi carregada
+func (mmu *MMU) HasBootROM() bool {
+	return mmu.bootROM != nil
+}
+
+// IsCGBBootROM retorna se a boot ROM carregada é a do Game Boy Color
+func (mmu *MMU) IsCGBBootROM() bool {
+	return len(mmu.bootROM) == BootROMSizeCGB
+}
+
+// IsBootROMMapped retorna se a boot ROM ainda está sobreposta à ROM
+func (mmu *MMU) IsBootROMMapped() bool {
+	return mmu.bootROMMapped
+}
+
+// isBootROMAddress retorna se o endereço é coberto pela boot ROM. A boot ROM
+// do CGB deixa 0x0100-0x01FF livre para a leitura do header do cartucho.
+func (mmu *MMU) isBootROMAddress(addr uint16) bool {
+	if addr < BootROMSizeDMG {
+		return true
+	}
+	return mmu.IsCGBBootROM() && addr >= 0x0200 && int(addr) < BootROMSizeCGB
+}
+
+// unmapBootROM desmapeia a boot ROM (escrita em 0xFF50). Se a boot ROM do
+// CGB escolheu o modo de compatibilidade em KEY0, o MMU volta ao modo DMG.
+func (mmu *MMU) unmapBootROM() {
+	mmu.bootROMMapped = false
+
+	if mmu.cgbMode && mmu.key0&Key0DMGCompat != 0 {
+		mmu.SetCGBMode(false)
+	}
+}
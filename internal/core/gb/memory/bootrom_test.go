@@ -0,0 +1,98 @@
+package memory
+
+import "testing"
+
+func TestBootROMSizeValidation(t *testing.T) {
+	mmu := NewMMU()
+
+	for _, size := range []int{0, 255, 512, 2048} {
+		if err := mmu.LoadBootROM(make([]uint8, size)); err == nil {
+			t.Errorf("boot ROM de %d bytes deveria ser rejeitada", size)
+		}
+	}
+	if mmu.HasBootROM() {
+		t.Error("nenhuma boot ROM deveria ter sido carregada")
+	}
+
+	if err := mmu.LoadBootROM(make([]uint8, BootROMSizeDMG)); err != nil {
+		t.Errorf("boot ROM DMG rejeitada: %v", err)
+	}
+	if err := mmu.LoadBootROM(make([]uint8, BootROMSizeCGB)); err != nil {
+		t.Errorf("boot ROM CGB rejeitada: %v", err)
+	}
+}
+
+func TestBootROMOverlayAndUnmap(t *testing.T) {
+	rom := make([]uint8, 0x8000)
+	for i := range rom {
+		rom[i] = 0xCA
+	}
+	rom[0x147] = 0x00 // ROM only
+
+	boot := make([]uint8, BootROMSizeCGB)
+	for i := range boot {
+		boot[i] = 0xB0
+	}
+
+	mmu := NewMMU()
+	if err := mmu.LoadROM(rom); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+	if err := mmu.LoadBootROM(boot); err != nil {
+		t.Fatalf("erro ao carregar boot ROM: %v", err)
+	}
+	mmu.Reset()
+
+	tests := []struct {
+		addr uint16
+		want uint8
+	}{
+		{0x0000, 0xB0},
+		{0x00FF, 0xB0},
+		{0x0100, 0xCA}, // Header do cartucho continua visível
+		{0x01FF, 0xCA},
+		{0x0200, 0xB0},
+		{0x08FF, 0xB0},
+		{0x0900, 0xCA},
+	}
+	for _, tt := range tests {
+		if got := mmu.Read(tt.addr); got != tt.want {
+			t.Errorf("0x%04X com boot ROM: esperado 0x%02X, obtido 0x%02X", tt.addr, tt.want, got)
+		}
+	}
+
+	// Escrita zero não desmapeia
+	mmu.Write(RegBOOT, 0x00)
+	if !mmu.IsBootROMMapped() {
+		t.Fatal("escrita de 0 em 0xFF50 não deveria desmapear a boot ROM")
+	}
+
+	mmu.Write(RegBOOT, 0x01)
+	if mmu.IsBootROMMapped() {
+		t.Fatal("boot ROM deveria ser desmapeada")
+	}
+	if got := mmu.Read(0x0000); got != 0xCA {
+		t.Errorf("0x0000 após desmapear: esperado 0xCA, obtido 0x%02X", got)
+	}
+
+	// Reset volta a mapear a boot ROM
+	mmu.Reset()
+	if !mmu.IsBootROMMapped() {
+		t.Error("Reset deveria mapear a boot ROM novamente")
+	}
+}
+
+func TestBootROMDMGCompatibilityMode(t *testing.T) {
+	mmu := NewMMU()
+	mmu.LoadROM(make([]uint8, 0x8000))
+	mmu.LoadBootROM(make([]uint8, BootROMSizeCGB))
+	mmu.SetCGBMode(true)
+	mmu.Reset()
+
+	mmu.Write(RegKEY0, Key0DMGCompat)
+	mmu.Write(RegBOOT, 0x11)
+
+	if mmu.IsCGBMode() {
+		t.Error("KEY0 em modo de compatibilidade deveria voltar ao modo DMG")
+	}
+}
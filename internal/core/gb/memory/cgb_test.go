@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/cartridge"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
+)
+
+// newCGBMMU cria um MMU em modo Game Boy Color com uma ROM mínima
+func newCGBMMU(t *testing.T) *MMU {
+	rom := make([]uint8, 0x8000)
+	rom[0x143] = 0x80 // Compatível com CGB
+
+	mmu := NewMMU()
+	if err := mmu.LoadROM(rom); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+	if !mmu.IsCGBROM() {
+		t.Fatal("header 0x143=0x80 deveria indicar ROM CGB")
+	}
+	mmu.SetCGBMode(true)
+	mmu.Reset()
+	return mmu
+}
+
+// stepLine avança o MMU por uma linha do LCD em passos de 4 ciclos
+func stepLine(mmu *MMU) {
+	for i := 0; i < video.CyclesLine; i += 4 {
+		mmu.Step(4)
+	}
+}
+
+func TestCGBWRAMBanks(t *testing.T) {
+	mmu := newCGBMMU(t)
+
+	for bank := uint8(1); bank < 8; bank++ {
+		mmu.Write(RegSVBK, bank)
+		mmu.Write(0xD000, bank*0x11)
+	}
+
+	for bank := uint8(1); bank < 8; bank++ {
+		mmu.Write(RegSVBK, bank)
+		if got := mmu.Read(0xD000); got != bank*0x11 {
+			t.Errorf("WRAM banco %d: esperado 0x%02X, obtido 0x%02X", bank, bank*0x11, got)
+		}
+	}
+
+	// Banco 0 seleciona o banco 1
+	mmu.Write(RegSVBK, 0)
+	if got := mmu.Read(RegSVBK) & 0x07; got != 1 {
+		t.Errorf("SVBK=0 deveria selecionar banco 1, obtido %d", got)
+	}
+
+	// Echo RAM acompanha o banco selecionado
+	mmu.Write(RegSVBK, 3)
+	if got := mmu.Read(0xF000); got != 0x33 {
+		t.Errorf("Echo RAM: esperado 0x33, obtido 0x%02X", got)
+	}
+}
+
+func TestCGBVRAMBanks(t *testing.T) {
+	mmu := newCGBMMU(t)
+	mmu.Write(video.RegLCDC, 0x00) // Desliga o LCD para acesso livre à VRAM
+
+	mmu.Write(video.RegVBK, 0)
+	mmu.Write(0x8000, 0x12)
+	mmu.Write(video.RegVBK, 1)
+	mmu.Write(0x8000, 0x34)
+
+	if got := mmu.Read(0x8000); got != 0x34 {
+		t.Errorf("VRAM banco 1: esperado 0x34, obtido 0x%02X", got)
+	}
+	mmu.Write(video.RegVBK, 0)
+	if got := mmu.Read(0x8000); got != 0x12 {
+		t.Errorf("VRAM banco 0: esperado 0x12, obtido 0x%02X", got)
+	}
+}
+
+func TestCGBPaletteAutoIncrement(t *testing.T) {
+	mmu := newCGBMMU(t)
+
+	mmu.Write(video.RegBCPS, 0x80) // Índice 0 com auto-incremento
+	mmu.Write(video.RegBCPD, 0x1F)
+	mmu.Write(video.RegBCPD, 0x00)
+
+	if got := mmu.Read(video.RegBCPS) & 0x3F; got != 2 {
+		t.Errorf("BCPS deveria ter avançado para 2, obtido %d", got)
+	}
+
+	mmu.Write(video.RegBCPS, 0x00)
+	if got := mmu.Read(video.RegBCPD); got != 0x1F {
+		t.Errorf("BCPD[0]: esperado 0x1F, obtido 0x%02X", got)
+	}
+}
+
+func TestCGBSpeedSwitch(t *testing.T) {
+	mmu := newCGBMMU(t)
+
+	if mmu.SwitchSpeed() {
+		t.Error("troca de velocidade sem KEY1 preparado")
+	}
+
+	mmu.Write(RegKEY1, 0x01)
+	if !mmu.SwitchSpeed() {
+		t.Fatal("troca de velocidade deveria ocorrer com KEY1 preparado")
+	}
+	if !mmu.IsDoubleSpeed() {
+		t.Error("CPU deveria estar em velocidade dupla")
+	}
+	if got := mmu.Read(RegKEY1); got&0x81 != 0x80 {
+		t.Errorf("KEY1: esperado bit 7 ligado e bit 0 desligado, obtido 0x%02X", got)
+	}
+}
+
+func TestCGBDoubleSpeedRTC(t *testing.T) {
+	rom := make([]uint8, 0x8000)
+	rom[0x143] = 0x80 // Compatível com CGB
+	rom[0x147] = 0x10 // MBC3+TIMER+RAM+BATTERY
+	rom[0x149] = 0x03 // 32KB
+
+	mmu := NewMMU()
+	if err := mmu.LoadROM(rom); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+	mmu.SetCGBMode(true)
+	mmu.Reset()
+	mmu.Write(RegKEY1, 0x01)
+	if !mmu.SwitchSpeed() {
+		t.Fatal("troca de velocidade deveria ocorrer com KEY1 preparado")
+	}
+
+	// Dois segundos de ciclos de CPU em velocidade dupla são um segundo real
+	for i := 0; i < 2*cartridge.RTCCyclesPerSecond; i += 4 {
+		mmu.Step(4)
+	}
+
+	mmu.Write(0x0000, 0x0A) // Habilita RAM/RTC
+	mmu.Write(0x6000, 0x00)
+	mmu.Write(0x6000, 0x01) // Latch
+	mmu.Write(0x4000, cartridge.RTCRegSeconds)
+	if got := mmu.Read(0xA000) & 0x3F; got != 1 {
+		t.Errorf("RTC em velocidade dupla: esperado 1 segundo, obtido %d", got)
+	}
+}
+
+func TestCGBGeneralDMA(t *testing.T) {
+	mmu := newCGBMMU(t)
+	mmu.Write(video.RegLCDC, 0x00)
+
+	for i := uint16(0); i < 0x20; i++ {
+		mmu.Write(0xC100+i, uint8(i)+1)
+	}
+
+	mmu.Write(RegHDMA1, 0xC1)
+	mmu.Write(RegHDMA2, 0x00)
+	mmu.Write(RegHDMA3, 0x00)
+	mmu.Write(RegHDMA4, 0x40)
+	mmu.Write(RegHDMA5, 0x01) // GDMA de 2 blocos
+
+	for i := uint16(0); i < 0x20; i++ {
+		if got := mmu.Read(0x8040 + i); got != uint8(i)+1 {
+			t.Fatalf("VRAM[0x%04X]: esperado 0x%02X, obtido 0x%02X", 0x8040+i, uint8(i)+1, got)
+		}
+	}
+	if got := mmu.Read(RegHDMA5); got != 0xFF {
+		t.Errorf("HDMA5 após GDMA: esperado 0xFF, obtido 0x%02X", got)
+	}
+}
+
+func TestCGBHBlankDMA(t *testing.T) {
+	mmu := newCGBMMU(t)
+
+	for i := uint16(0); i < 0x20; i++ {
+		mmu.Write(0xC200+i, 0xA0+uint8(i))
+	}
+
+	mmu.Write(RegHDMA1, 0xC2)
+	mmu.Write(RegHDMA2, 0x00)
+	mmu.Write(RegHDMA3, 0x00)
+	mmu.Write(RegHDMA4, 0x00)
+	mmu.Write(RegHDMA5, 0x81) // HDMA de 2 blocos
+
+	if got := mmu.Read(RegHDMA5); got != 0x01 {
+		t.Errorf("HDMA5 ativo: esperado 0x01, obtido 0x%02X", got)
+	}
+
+	// Uma linha completa transfere um bloco
+	stepLine(mmu)
+	if got := mmu.Read(RegHDMA5); got != 0x00 {
+		t.Errorf("HDMA5 após 1 bloco: esperado 0x00, obtido 0x%02X", got)
+	}
+
+	stepLine(mmu)
+	if got := mmu.Read(RegHDMA5); got != 0xFF {
+		t.Errorf("HDMA5 após 2 blocos: esperado 0xFF, obtido 0x%02X", got)
+	}
+
+	mmu.Write(video.RegLCDC, 0x00)
+	for i := uint16(0); i < 0x20; i++ {
+		if got := mmu.Read(0x8000 + i); got != 0xA0+uint8(i) {
+			t.Fatalf("VRAM[0x%04X]: esperado 0x%02X, obtido 0x%02X", 0x8000+i, 0xA0+uint8(i), got)
+		}
+	}
+}
+
+func TestDMGModeIgnoresCGBRegisters(t *testing.T) {
+	mmu := NewMMU()
+	mmu.LoadROM(make([]uint8, 0x8000))
+	mmu.Reset()
+
+	mmu.Write(RegSVBK, 3)
+	if got := mmu.Read(RegSVBK); got != 0xFF {
+		t.Errorf("SVBK em modo DMG: esperado 0xFF, obtido 0x%02X", got)
+	}
+	if mmu.SwitchSpeed() {
+		t.Error("DMG não possui velocidade dupla")
+	}
+}
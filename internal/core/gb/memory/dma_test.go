@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
+)
+
+// newDMAMMU cria um MMU DMG com 160 bytes de dados em 0xC000
+func newDMAMMU(t *testing.T) *MMU {
+	mmu := NewMMU()
+	if err := mmu.LoadROM(make([]uint8, 0x8000)); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+	mmu.Reset()
+	for i := 0; i < OAMSize; i++ {
+		mmu.Write(0xC000+uint16(i), uint8(i+1))
+	}
+	return mmu
+}
+
+func TestOAMDMATiming(t *testing.T) {
+	mmu := newDMAMMU(t)
+	mmu.Write(video.RegDMA, 0xC0)
+
+	if got := mmu.Read(video.RegDMA); got != 0xC0 {
+		t.Errorf("0xFF46 deveria ler o último valor escrito, obtido 0x%02X", got)
+	}
+	if mmu.IsDMAActive() {
+		t.Fatal("DMA não deveria bloquear o barramento antes da preparação")
+	}
+
+	// Preparação
+	mmu.Step(dmaStartupMCycles * 4)
+	if !mmu.IsDMAActive() {
+		t.Fatal("DMA deveria estar ativa após a preparação")
+	}
+	if got := mmu.readBus(OAMStart); got != 0 {
+		t.Errorf("nenhum byte deveria ter sido copiado ainda, OAM[0]=0x%02X", got)
+	}
+
+	// 160 M-cycles de transferência
+	mmu.Step((OAMSize - 1) * 4)
+	if !mmu.IsDMAActive() {
+		t.Fatal("DMA deveria durar 160 M-cycles")
+	}
+	mmu.Step(4)
+	if mmu.IsDMAActive() {
+		t.Fatal("DMA deveria terminar após 160 M-cycles")
+	}
+
+	for i := 0; i < OAMSize; i++ {
+		if got := mmu.Read(OAMStart + uint16(i)); got != uint8(i+1) {
+			t.Fatalf("OAM[%d]: esperado 0x%02X, obtido 0x%02X", i, i+1, got)
+		}
+	}
+}
+
+func TestOAMDMABusConflicts(t *testing.T) {
+	mmu := newDMAMMU(t)
+	mmu.Write(HRAMStart, 0x42)
+	mmu.lcd.WriteVRAM(VRAMStart, 0x99)
+
+	mmu.Write(video.RegDMA, 0xC0)
+	mmu.Step(dmaStartupMCycles*4 + 4*4)
+
+	// OAM inacessível
+	if got := mmu.Read(OAMStart); got != 0xFF {
+		t.Errorf("OAM durante a DMA deveria ler 0xFF, obtido 0x%02X", got)
+	}
+	mmu.Write(OAMStart+0x50, 0x77)
+
+	// HRAM e I/O continuam acessíveis
+	if got := mmu.Read(HRAMStart); got != 0x42 {
+		t.Errorf("HRAM durante a DMA: esperado 0x42, obtido 0x%02X", got)
+	}
+	if got := mmu.Read(video.RegDMA); got != 0xC0 {
+		t.Errorf("I/O durante a DMA: esperado 0xC0, obtido 0x%02X", got)
+	}
+
+	// O barramento externo retorna o byte sendo copiado
+	if got := mmu.Read(0x0000); got != 4 {
+		t.Errorf("ROM durante a DMA da WRAM: esperado o byte da DMA (4), obtido 0x%02X", got)
+	}
+	mmu.Write(0xC000, 0xEE)
+	if got := mmu.readBus(0xC000); got != 1 {
+		t.Errorf("escrita na WRAM durante a DMA deveria ser ignorada, obtido 0x%02X", got)
+	}
+
+	// A VRAM está em outro barramento
+	if got := mmu.Read(VRAMStart); got != 0x99 {
+		t.Errorf("VRAM durante a DMA da WRAM: esperado 0x99, obtido 0x%02X", got)
+	}
+
+	mmu.Step(OAMSize * 4)
+	if got := mmu.Read(OAMStart + 0x50); got != 0x51 {
+		t.Errorf("escrita na OAM durante a DMA deveria ser ignorada, obtido 0x%02X", got)
+	}
+}
+
+func TestOAMDMARestart(t *testing.T) {
+	mmu := newDMAMMU(t)
+	for i := 0; i < OAMSize; i++ {
+		mmu.Write(0xD000+uint16(i), 0xA0)
+	}
+
+	mmu.Write(video.RegDMA, 0xC0)
+	mmu.Step(dmaStartupMCycles*4 + 10*4)
+
+	// A DMA anterior continua durante a preparação da nova
+	mmu.Write(video.RegDMA, 0xD0)
+	mmu.Step(4)
+	if !mmu.IsDMAActive() || mmu.readBus(OAMStart+10) != 11 {
+		t.Error("DMA em andamento deveria continuar durante a preparação da nova")
+	}
+
+	mmu.Step(4 + OAMSize*4)
+	if mmu.IsDMAActive() {
+		t.Fatal("DMA reiniciada deveria terminar após 160 M-cycles")
+	}
+	for i := 0; i < OAMSize; i++ {
+		if got := mmu.Read(OAMStart + uint16(i)); got != 0xA0 {
+			t.Fatalf("OAM[%d] após reinício: esperado 0xA0, obtido 0x%02X", i, got)
+		}
+	}
+}
@@ -1,11 +1,24 @@
 package memory
 
 import (
+	"strings"
 	"testing"
 )
 
+// newTestMMU cria um MMU com uma ROM sem MBC e o LCD desligado, para que
+// VRAM e OAM estejam sempre acessíveis
+func newTestMMU(t *testing.T) *MMU {
+	mmu := NewMMU()
+	if err := mmu.LoadROM(make([]uint8, 0x8000)); err != nil {
+		t.Fatalf("Erro ao carregar ROM: %v", err)
+	}
+	mmu.Reset()
+	mmu.Write(RegLCDC, 0x00)
+	return mmu
+}
+
 func TestMemoryBasicOperations(t *testing.T) {
-	mem := NewMemory()
+	mem := newTestMMU(t)
 
 	// Testa WRAM Bank 0
 	t.Run("WRAM Bank 0", func(t *testing.T) {
@@ -59,7 +72,7 @@ func TestMemoryBasicOperations(t *testing.T) {
 }
 
 func TestMemoryMirror(t *testing.T) {
-	mem := NewMemory()
+	mem := newTestMMU(t)
 
 	// Testa espelhamento do WRAM
 	t.Run("WRAM Mirror", func(t *testing.T) {
@@ -86,7 +99,7 @@ func TestMemoryMirror(t *testing.T) {
 }
 
 func TestMemoryWordOperations(t *testing.T) {
-	mem := NewMemory()
+	mem := newTestMMU(t)
 
 	// Testa leitura/escrita de 16 bits
 	t.Run("Word Operations", func(t *testing.T) {
@@ -109,33 +122,15 @@ func TestMemoryWordOperations(t *testing.T) {
 }
 
 func TestIORegisters(t *testing.T) {
-	mem := NewMemory()
-
-	// Testa registradores I/O normais
-	t.Run("Normal I/O Registers", func(t *testing.T) {
-		addr := uint16(RegNR10)
-		value := uint8(0x80)
-		mem.SetIORegister(addr, value)
-
-		if result := mem.GetIORegister(addr); result != value {
-			t.Errorf("I/O Register: esperado 0x%02X, obtido 0x%02X", value, result)
-		}
-	})
-
-	// Testa registrador IE
-	t.Run("Interrupt Enable Register", func(t *testing.T) {
-		value := uint8(0x1F)
-		mem.SetIORegister(InterruptEnableRegister, value)
-
-		if result := mem.GetIORegister(InterruptEnableRegister); result != value {
-			t.Errorf("IE Register: esperado 0x%02X, obtido 0x%02X", value, result)
-		}
-	})
+	mem := newTestMMU(t)
 
 	// Testa DIV reset
 	t.Run("DIV Reset", func(t *testing.T) {
-		// Define um valor em DIV
-		mem.SetIORegister(RegDIV, 0x80)
+		// Avança o divisor
+		mem.Step(256 * 0x80)
+		if result := mem.Read(RegDIV); result == 0 {
+			t.Fatal("DIV deveria ter incrementado")
+		}
 
 		// Escreve qualquer valor em DIV (deve resetar para 0)
 		mem.Write(RegDIV, 0xFF)
@@ -147,21 +142,20 @@ func TestIORegisters(t *testing.T) {
 
 	// Testa LY read-only
 	t.Run("LY Read-Only", func(t *testing.T) {
-		// Define um valor inicial em LY
-		mem.ioRegs[RegLY-IOStart] = 0x90
+		before := mem.Read(RegLY)
 
 		// Tenta escrever em LY
 		mem.Write(RegLY, 0x50)
 
 		// Verifica que não mudou
-		if result := mem.Read(RegLY); result != 0x90 {
-			t.Errorf("LY should be read-only: esperado 0x90, obtido 0x%02X", result)
+		if result := mem.Read(RegLY); result != before {
+			t.Errorf("LY should be read-only: esperado 0x%02X, obtido 0x%02X", before, result)
 		}
 	})
 }
 
 func TestDMATransfer(t *testing.T) {
-	mem := NewMemory()
+	mem := newTestMMU(t)
 
 	// Prepara dados na WRAM
 	for i := 0; i < 0xA0; i++ {
@@ -170,6 +164,7 @@ func TestDMATransfer(t *testing.T) {
 
 	// Executa DMA transfer
 	mem.Write(RegDMA, 0xC0)
+	mem.Step((dmaStartupMCycles + OAMSize) * 4)
 
 	// Verifica se os dados foram copiados para OAM
 	for i := 0; i < 0xA0; i++ {
@@ -180,61 +175,8 @@ func TestDMATransfer(t *testing.T) {
 	}
 }
 
-func TestCartridgeROMOnly(t *testing.T) {
-	mem := NewMemory()
-
-	// Cria uma ROM simples
-	romData := make([]uint8, 0x8000)
-	// Header: ROM only
-	romData[0x0147] = 0x00 // Cartridge type
-	romData[0x0148] = 0x00 // ROM size (32KB)
-	romData[0x0149] = 0x00 // RAM size (none)
-
-	// Dados de teste (preserva o header)
-	for i := 0x150; i < len(romData); i++ {
-		romData[i] = uint8(i & 0xFF)
-	}
-
-	err := mem.LoadCartridge(romData)
-	if err != nil {
-		t.Fatalf("Erro ao carregar cartucho: %v", err)
-	}
-
-	// Testa leitura da ROM Bank 0
-	t.Run("ROM Bank 0", func(t *testing.T) {
-		for i := 0x150; i < 0x4000; i++ {
-			addr := uint16(i)
-			expected := uint8(i & 0xFF)
-			if result := mem.Read(addr); result != expected {
-				t.Errorf("ROM Bank 0[0x%04X]: esperado 0x%02X, obtido 0x%02X", addr, expected, result)
-				break
-			}
-		}
-	})
-
-	// Testa leitura da ROM Bank 1
-	t.Run("ROM Bank 1", func(t *testing.T) {
-		for i := 0x150; i < 0x4000; i++ {
-			addr := uint16(0x4000 + i)
-			expected := uint8((0x4000 + i) & 0xFF)
-			if result := mem.Read(addr); result != expected {
-				t.Errorf("ROM Bank 1[0x%04X]: esperado 0x%02X, obtido 0x%02X", addr, expected, result)
-				break
-			}
-		}
-	})
-
-	// Testa que não há RAM externa
-	t.Run("No External RAM", func(t *testing.T) {
-		addr := uint16(0xA000)
-		if result := mem.Read(addr); result != 0xFF {
-			t.Errorf("External RAM should return 0xFF: obtido 0x%02X", result)
-		}
-	})
-}
-
 func TestCartridgeMBC1(t *testing.T) {
-	mem := NewMemory()
+	mem := NewMMU()
 
 	// Cria uma ROM MBC1 com RAM
 	romData := make([]uint8, 0x80000) // 512KB ROM
@@ -249,7 +191,7 @@ func TestCartridgeMBC1(t *testing.T) {
 		romData[i] = uint8((bank << 4) | (offset >> 8))
 	}
 
-	err := mem.LoadCartridge(romData)
+	err := mem.LoadROM(romData)
 	if err != nil {
 		t.Fatalf("Erro ao carregar cartucho MBC1: %v", err)
 	}
@@ -317,11 +259,14 @@ func TestCartridgeMBC1(t *testing.T) {
 		if result := mem.Read(addr); result != value {
 			t.Errorf("RAM enabled: esperado 0x%02X, obtido 0x%02X", value, result)
 		}
+		if !mem.IsRAMDirty() || !mem.HasBattery() {
+			t.Error("escrita na RAM com bateria deveria marcar o save como pendente")
+		}
 	})
 }
 
 func TestUnusedAreas(t *testing.T) {
-	mem := NewMemory()
+	mem := newTestMMU(t)
 
 	// Testa área não utilizada
 	t.Run("Unused Area", func(t *testing.T) {
@@ -339,6 +284,8 @@ func TestUnusedAreas(t *testing.T) {
 
 	// Testa leitura sem cartucho
 	t.Run("No Cartridge", func(t *testing.T) {
+		mem := NewMMU()
+
 		addr := uint16(0x0000)
 		if result := mem.Read(addr); result != 0xFF {
 			t.Errorf("No cartridge should return 0xFF: obtido 0x%02X", result)
@@ -348,82 +295,52 @@ func TestUnusedAreas(t *testing.T) {
 		if result := mem.Read(addr); result != 0xFF {
 			t.Errorf("No cartridge should return 0xFF: obtido 0x%02X", result)
 		}
-	})
-}
-
-func TestCartridgeTypes(t *testing.T) {
-	// Testa diferentes tipos de cartucho
-	testCases := []struct {
-		name          string
-		cartridgeType uint8
-		expectedType  CartridgeType
-		hasRAM        bool
-		hasBattery    bool
-	}{
-		{"ROM Only", 0x00, CartridgeROMOnly, false, false},
-		{"MBC1", 0x01, CartridgeMBC1, false, false},
-		{"MBC1+RAM", 0x02, CartridgeMBC1, true, false},
-		{"MBC1+RAM+Battery", 0x03, CartridgeMBC1, true, true},
-		{"MBC2", 0x05, CartridgeMBC2, true, false},
-		{"MBC2+Battery", 0x06, CartridgeMBC2, true, true},
-		{"MBC3+Battery", 0x0F, CartridgeMBC3, false, true},
-		{"MBC3+RAM+Battery", 0x10, CartridgeMBC3, true, true},
-		{"MBC5", 0x19, CartridgeMBC5, false, false},
-		{"MBC5+RAM", 0x1A, CartridgeMBC5, true, false},
-		{"MBC5+RAM+Battery", 0x1B, CartridgeMBC5, true, true},
-	}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			mem := NewMemory()
-			romData := make([]uint8, 0x8000)
-			romData[0x0147] = tc.cartridgeType
-			romData[0x0148] = 0x00 // 32KB ROM
-			if tc.hasRAM {
-				romData[0x0149] = 0x02 // 8KB RAM
-			} else {
-				romData[0x0149] = 0x00 // No RAM
-			}
-
-			err := mem.LoadCartridge(romData)
-			if err != nil {
-				t.Fatalf("Erro ao carregar cartucho %s: %v", tc.name, err)
-			}
-
-			if mem.cartridge.Type != tc.expectedType {
-				t.Errorf("Tipo incorreto: esperado %v, obtido %v", tc.expectedType, mem.cartridge.Type)
-			}
-
-			if mem.cartridge.HasRAM != tc.hasRAM {
-				t.Errorf("HasRAM incorreto: esperado %v, obtido %v", tc.hasRAM, mem.cartridge.HasRAM)
-			}
-
-			if mem.cartridge.HasBattery != tc.hasBattery {
-				t.Errorf("HasBattery incorreto: esperado %v, obtido %v", tc.hasBattery, mem.cartridge.HasBattery)
-			}
-		})
-	}
+		addr = uint16(0xA000)
+		if result := mem.Read(addr); result != 0xFF {
+			t.Errorf("No cartridge should return 0xFF: obtido 0x%02X", result)
+		}
+	})
 }
 
 func TestInvalidCartridge(t *testing.T) {
-	mem := NewMemory()
+	mem := NewMMU()
 
 	// Testa ROM muito pequena
 	t.Run("ROM Too Small", func(t *testing.T) {
 		romData := make([]uint8, 0x4000) // Muito pequena
-		err := mem.LoadCartridge(romData)
+		err := mem.LoadROM(romData)
 		if err == nil {
 			t.Error("Deveria retornar erro para ROM muito pequena")
 		}
 	})
+}
 
-	// Testa tipo de cartucho não suportado
-	t.Run("Unsupported Cartridge Type", func(t *testing.T) {
-		romData := make([]uint8, 0x8000)
-		romData[0x0147] = 0xFF // Tipo não suportado
-		err := mem.LoadCartridge(romData)
-		if err == nil {
-			t.Error("Deveria retornar erro para tipo não suportado")
-		}
-	})
+func TestLoadROMFitsHeaderSize(t *testing.T) {
+	mmu := NewMMU()
+
+	// Header indica 128KB (MBC5), arquivo tem 32KB
+	rom := make([]uint8, 0x8000)
+	copy(rom[0x134:], "SHORT DUMP")
+	rom[0x147] = 0x19
+	rom[0x148] = 0x02
+	rom[0x4000] = 0x42
+	if err := mmu.LoadROM(rom); err != nil {
+		t.Fatalf("erro ao carregar ROM: %v", err)
+	}
+
+	if mmu.GetROMSize() != 0x20000 {
+		t.Errorf("ROM deveria ser ajustada a 128KB, obtido %d bytes", mmu.GetROMSize())
+	}
+	mmu.Write(0x2000, 0x05)
+	if got := mmu.Read(0x4000); got != 0x42 {
+		t.Errorf("banco 5 deveria espelhar o banco 1: obtido 0x%02X", got)
+	}
+
+	if header := mmu.GetHeader(); header == nil || header.Title != "SHORT DUMP" {
+		t.Errorf("header não decodificado: %+v", header)
+	}
+	if warnings := mmu.GetROMWarnings(); len(warnings) == 0 || !strings.Contains(warnings[0], "menor") {
+		t.Errorf("esperado aviso de tamanho, obtido %v", warnings)
+	}
 }
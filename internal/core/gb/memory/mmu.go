@@ -1,10 +1,14 @@
 package memory
 
 import (
+	"crypto/sha1"
 	"fmt"
 
+	"github.com/hobbiee/visualboy-go/internal/core/gb/cartridge"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/input"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/interrupts"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/serial"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/sound"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/timer"
 	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
@@ -13,12 +17,18 @@ import (
 // Constantes de Memória específicas do MMU
 const (
 	// Tamanhos de memória
-	ROMBankSize = 0x4000 // 16KB
-	RAMBankSize = 0x2000 // 8KB
-	VRAMSize    = 0x2000 // 8KB
-	WRAMSize    = 0x2000 // 8KB
-	OAMSize     = 0xA0   // 160 bytes
-	HRAMSize    = 0x7F   // 127 bytes
+	ROMBankSize  = cartridge.ROMBankSize
+	RAMBankSize  = cartridge.RAMBankSize
+	VRAMSize     = 0x2000 // 8KB
+	WRAMSize     = 0x2000 // 8KB
+	CGBWRAMSize  = 0x8000 // 32KB (8 bancos de 4KB)
+	WRAMBankSize = 0x1000 // 4KB
+	OAMSize      = 0xA0   // 160 bytes
+	HRAMSize     = 0x7F   // 127 bytes
+
+	// M-cycles entre a escrita em 0xFF46 e o primeiro byte da OAM DMA. O
+	// Step da instrução que escreveu já inclui o M-cycle da escrita.
+	dmaStartupMCycles = 2
 )
 
 // MMU (Memory Management Unit) do Game Boy
@@ -28,36 +38,57 @@ type MMU struct {
 	timer      *timer.Timer
 	input      *input.Input
 	sound      *sound.Sound
+	serial     *serial.Serial
 	interrupts *interrupts.InterruptController
 
-	// Memória
-	rom  []uint8         // ROM (cartucho)
-	wram [WRAMSize]uint8 // Work RAM
-	hram [HRAMSize]uint8 // High RAM
-
-	// Estado do cartucho
-	romBanks       int
-	ramBanks       int
-	currentROMBank int
-	currentRAMBank int
-	ramEnabled     bool
-
-	// MBC (Memory Bank Controller)
-	mbcType int
-	mbcMode int
+	// Imagens vistas pela Pocket Camera (mantida entre trocas de ROM)
+	cameraSource cartridge.ImageSource
 
-	// RAM externa (cartucho)
-	externalRAM []uint8
+	// Memória
+	rom  []uint8            // ROM (cartucho)
+	wram [CGBWRAMSize]uint8 // Work RAM (bancos 2-7 apenas no CGB)
+	hram [HRAMSize]uint8    // High RAM
+
+	// Mapeador do cartucho (MBC), escolhido pelo byte 0x147 do header
+	cart cartridge.Cartridge
+
+	// Header decodificado e problemas encontrados ao carregar a ROM
+	header      *cartridge.ROMHeader
+	romWarnings []string
+	romHash     [sha1.Size]byte // SHA-1 do arquivo da ROM, antes do ajuste de tamanho
+
+	// Game Boy Color
+	cgbMode     bool
+	wramBank    int   // Banco de WRAM em 0xD000-0xDFFF (SVBK)
+	key1        uint8 // Bit 0 de KEY1: troca de velocidade preparada
+	doubleSpeed bool
+
+	// HDMA/GDMA (CGB)
+	hdmaSource uint16
+	hdmaDest   uint16
+	hdmaBlocks int // Blocos de 16 bytes restantes
+	hdmaActive bool
+
+	// OAM DMA
+	dmaRegister uint8  // Último valor escrito em 0xFF46
+	dmaActive   bool   // Transferência em andamento (barramento bloqueado)
+	dmaSource   uint16 // Endereço de origem da transferência atual
+	dmaIndex    int    // Próximo byte a copiar (0-159)
+	dmaValue    uint8  // Último byte lido pela transferência
+	dmaPending  uint16 // Origem da transferência aguardando o início
+	dmaDelay    int    // M-cycles até a transferência pendente começar
+	dmaCycles   int    // Ciclos acumulados para o próximo M-cycle
+
+	// Boot ROM
+	bootROM       []uint8 // Imagem DMG (256 bytes) ou CGB (2304 bytes)
+	bootROMMapped bool    // Boot ROM sobreposta à ROM até escrita em 0xFF50
+	key0          uint8   // KEY0 escrito pela boot ROM do CGB
 }
 
 // NewMMU cria uma nova instância do MMU
 func NewMMU() *MMU {
 	mmu := &MMU{
-		currentROMBank: 1,
-		currentRAMBank: 0,
-		ramEnabled:     false,
-		mbcType:        0,
-		mbcMode:        0,
+		wramBank: 1,
 	}
 
 	// Cria componentes
@@ -65,6 +96,11 @@ func NewMMU() *MMU {
 	mmu.timer = timer.NewTimer(mmu)
 	mmu.input = input.NewInput(mmu)
 	mmu.sound = sound.NewSound()
+	mmu.serial = serial.NewSerial(mmu)
+
+	// O frame sequencer do APU é clocado pelo bit 12 do DIV
+	mmu.sound.SetDIVClocked(true)
+	mmu.timer.SetDIVTap(mmu.sound.ClockFrameSequencer)
 
 	return mmu
 }
@@ -104,12 +140,35 @@ func (mmu *MMU) Reset() {
 	if mmu.sound != nil {
 		mmu.sound.Reset()
 	}
+	if mmu.serial != nil {
+		mmu.serial.Reset()
+	}
 
 	// Reset estado do cartucho
-	mmu.currentROMBank = 1
-	mmu.currentRAMBank = 0
-	mmu.ramEnabled = false
-	mmu.mbcMode = 0
+	if mmu.cart != nil {
+		mmu.cart.Reset()
+	}
+
+	// Boot ROM volta a ser mapeada se carregada
+	mmu.bootROMMapped = mmu.bootROM != nil
+	mmu.key0 = 0
+
+	// Reset estado CGB
+	mmu.wramBank = 1
+	mmu.key1 = 0
+	mmu.doubleSpeed = false
+	if mmu.timer != nil {
+		mmu.timer.SetDoubleSpeed(false)
+	}
+	mmu.hdmaSource = 0
+	mmu.hdmaDest = 0
+	mmu.hdmaBlocks = 0
+	mmu.hdmaActive = false
+	mmu.dmaRegister = 0xFF
+	mmu.dmaActive = false
+	mmu.dmaIndex = 0
+	mmu.dmaDelay = 0
+	mmu.dmaCycles = 0
 }
 
 // LoadROM carrega uma ROM no MMU
@@ -118,78 +177,69 @@ func (mmu *MMU) LoadROM(data []uint8) error {
 		return fmt.Errorf("ROM muito pequena: %d bytes", len(data))
 	}
 
-	mmu.rom = make([]uint8, len(data))
-	copy(mmu.rom, data)
-
-	// Determina o número de bancos ROM
-	mmu.romBanks = len(data) / ROMBankSize
-
-	// Lê informações do header da ROM
-	cartridgeType := data[0x147]
-	ramSize := data[0x149]
-
-	// Determina o tipo de MBC
-	switch cartridgeType {
-	case 0x00: // ROM ONLY
-		mmu.mbcType = 0
-	case 0x01, 0x02, 0x03: // MBC1
-		mmu.mbcType = 1
-	case 0x05, 0x06: // MBC2
-		mmu.mbcType = 2
-	case 0x0F, 0x10, 0x11, 0x12, 0x13: // MBC3
-		mmu.mbcType = 3
-	case 0x19, 0x1A, 0x1B, 0x1C, 0x1D, 0x1E: // MBC5
-		mmu.mbcType = 5
-	default:
-		mmu.mbcType = 0
-	}
-
-	// Determina o tamanho da RAM
-	switch ramSize {
-	case 0x00:
-		mmu.ramBanks = 0
-	case 0x01:
-		mmu.ramBanks = 1 // 2KB
-	case 0x02:
-		mmu.ramBanks = 1 // 8KB
-	case 0x03:
-		mmu.ramBanks = 4 // 32KB
-	case 0x04:
-		mmu.ramBanks = 16 // 128KB
-	case 0x05:
-		mmu.ramBanks = 8 // 64KB
-	default:
-		mmu.ramBanks = 0
+	header, err := cartridge.ParseHeader(data)
+	if err != nil {
+		return err
 	}
-
-	// Aloca RAM externa se necessário
-	if mmu.ramBanks > 0 {
-		mmu.externalRAM = make([]uint8, mmu.ramBanks*RAMBankSize)
+	warnings := header.Warnings(len(data))
+
+	// Dumps menores que o header indica são espelhados ou completados
+	rom := make([]uint8, len(data))
+	copy(rom, data)
+	rom = cartridge.FitROMSize(rom, header.ROMSize)
+
+	// Tipos sem mapeador retornam *cartridge.UnsupportedMapperError e o
+	// cartucho anterior continua carregado
+	cart, err := cartridge.New(rom)
+	if err != nil {
+		return err
+	}
+	mmu.rom = rom
+	mmu.cart = cart
+	mmu.header = header
+	mmu.romWarnings = warnings
+	mmu.romHash = sha1.Sum(data)
+
+	// Conecta os sensores do cartucho ao input e à fonte de imagens
+	switch c := cart.(type) {
+	case *cartridge.MBC7:
+		c.SetTiltSource(mmu.input)
+	case *cartridge.Camera:
+		c.SetImageSource(mmu.cameraSource)
 	}
 
 	return nil
 }
 
-// Read lê um byte da memória
+// Read lê um byte da memória pelo barramento do CPU. Durante uma OAM DMA
+// o CPU só acessa HRAM e I/O: a OAM lê 0xFF e o barramento usado pela
+// transferência retorna o byte que a DMA está copiando.
 func (mmu *MMU) Read(addr uint16) uint8 {
+	if mmu.dmaActive && addr < IOStart {
+		switch {
+		case addr >= OAMStart:
+			return 0xFF
+		case mmu.dmaConflicts(addr):
+			return mmu.dmaValue
+		}
+	}
+	return mmu.readBus(addr)
+}
+
+// readBus lê um byte da memória sem as restrições da OAM DMA
+func (mmu *MMU) readBus(addr uint16) uint8 {
 	switch {
 	case addr <= ROMBank0End:
-		// ROM Bank 0
-		if mmu.rom != nil && int(addr) < len(mmu.rom) {
-			return mmu.rom[addr]
+		// Boot ROM sobreposta
+		if mmu.bootROMMapped && mmu.isBootROMAddress(addr) {
+			return mmu.bootROM[addr]
 		}
-		return 0xFF
+
+		return mmu.readCartridgeROM(addr)
 
 	case addr >= ROMBankNStart && addr <= ROMBankNEnd:
 		// ROM Bank N
-		if mmu.rom != nil {
-			bankOffset := mmu.currentROMBank * ROMBankSize
-			realAddr := bankOffset + int(addr-ROMBankNStart)
-			if realAddr < len(mmu.rom) {
-				return mmu.rom[realAddr]
-			}
-		}
-		return 0xFF
+		return mmu.readCartridgeROM(addr)
 
 	case addr >= VRAMStart && addr <= VRAMEnd:
 		// Video RAM
@@ -197,22 +247,18 @@ func (mmu *MMU) Read(addr uint16) uint8 {
 
 	case addr >= ExternalRAMStart && addr <= ExternalRAMEnd:
 		// External RAM
-		if mmu.ramEnabled && mmu.externalRAM != nil {
-			bankOffset := mmu.currentRAMBank * RAMBankSize
-			realAddr := bankOffset + int(addr-ExternalRAMStart)
-			if realAddr < len(mmu.externalRAM) {
-				return mmu.externalRAM[realAddr]
-			}
+		if mmu.cart != nil {
+			return mmu.cart.ReadRAM(addr)
 		}
 		return 0xFF
 
 	case addr >= WRAMBank0Start && addr <= WRAMBank1End:
 		// Work RAM
-		return mmu.wram[addr-WRAMBank0Start]
+		return mmu.wram[mmu.wramOffset(addr)]
 
 	case addr >= WRAMMirrorStart && addr <= WRAMMirrorEnd:
 		// Echo RAM (mirror of WRAM)
-		return mmu.wram[(addr-WRAMMirrorStart)%WRAMSize]
+		return mmu.wram[mmu.wramOffset(addr-0x2000)]
 
 	case addr >= OAMStart && addr <= OAMEnd:
 		// Object Attribute Memory
@@ -242,8 +288,18 @@ func (mmu *MMU) Read(addr uint16) uint8 {
 	}
 }
 
-// Write escreve um byte na memória
+// Write escreve um byte na memória pelo barramento do CPU. Durante uma
+// OAM DMA escritas na OAM e no barramento usado pela transferência são
+// ignoradas.
 func (mmu *MMU) Write(addr uint16, value uint8) {
+	if mmu.dmaActive && addr < IOStart && (addr >= OAMStart || mmu.dmaConflicts(addr)) {
+		return
+	}
+	mmu.writeBus(addr, value)
+}
+
+// writeBus escreve um byte na memória sem as restrições da OAM DMA
+func (mmu *MMU) writeBus(addr uint16, value uint8) {
 	switch {
 	case addr <= ROMBank0End:
 		// ROM Bank 0 - MBC control
@@ -259,21 +315,17 @@ func (mmu *MMU) Write(addr uint16, value uint8) {
 
 	case addr >= ExternalRAMStart && addr <= ExternalRAMEnd:
 		// External RAM
-		if mmu.ramEnabled && mmu.externalRAM != nil {
-			bankOffset := mmu.currentRAMBank * RAMBankSize
-			realAddr := bankOffset + int(addr-ExternalRAMStart)
-			if realAddr < len(mmu.externalRAM) {
-				mmu.externalRAM[realAddr] = value
-			}
+		if mmu.cart != nil {
+			mmu.cart.WriteRAM(addr, value)
 		}
 
 	case addr >= WRAMBank0Start && addr <= WRAMBank1End:
 		// Work RAM
-		mmu.wram[addr-WRAMBank0Start] = value
+		mmu.wram[mmu.wramOffset(addr)] = value
 
 	case addr >= WRAMMirrorStart && addr <= WRAMMirrorEnd:
 		// Echo RAM (mirror of WRAM)
-		mmu.wram[(addr-WRAMMirrorStart)%WRAMSize] = value
+		mmu.wram[mmu.wramOffset(addr-0x2000)] = value
 
 	case addr >= OAMStart && addr <= OAMEnd:
 		// Object Attribute Memory
@@ -321,11 +373,25 @@ func (mmu *MMU) GetTimer() *timer.Timer {
 	return mmu.timer
 }
 
+// SetCameraSource define as imagens vistas pela Pocket Camera, inclusive
+// em cartuchos carregados depois
+func (mmu *MMU) SetCameraSource(source cartridge.ImageSource) {
+	mmu.cameraSource = source
+	if camera, ok := mmu.cart.(*cartridge.Camera); ok {
+		camera.SetImageSource(source)
+	}
+}
+
 // GetInput retorna o sistema de input
 func (mmu *MMU) GetInput() *input.Input {
 	return mmu.input
 }
 
+// GetSerial retorna a porta serial
+func (mmu *MMU) GetSerial() *serial.Serial {
+	return mmu.serial
+}
+
 // GetSound retorna o sistema de som
 func (mmu *MMU) GetSound() *sound.Sound {
 	return mmu.sound
@@ -336,10 +402,35 @@ func (mmu *MMU) readIO(addr uint16) uint8 {
 	switch {
 	case addr == input.RegJOYP:
 		return mmu.input.ReadRegister(addr)
+	case addr == video.RegDMA:
+		return mmu.dmaRegister
+	case addr == serial.RegSB || addr == serial.RegSC:
+		return mmu.serial.ReadRegister(addr)
 	case addr >= timer.RegDIV && addr <= timer.RegTAC:
 		return mmu.timer.ReadRegister(addr)
 	case addr >= video.RegLCDC && addr <= video.RegWX:
 		return mmu.lcd.ReadRegister(addr)
+	case addr == video.RegVBK || (addr >= video.RegBCPS && addr <= video.RegOCPD):
+		return mmu.lcd.ReadRegister(addr)
+	case addr == RegKEY1:
+		if mmu.cgbMode {
+			value := 0x7E | mmu.key1
+			if mmu.doubleSpeed {
+				value |= 0x80
+			}
+			return value
+		}
+		return 0xFF
+	case addr == RegSVBK:
+		if mmu.cgbMode {
+			return 0xF8 | uint8(mmu.wramBank)
+		}
+		return 0xFF
+	case addr == RegHDMA5:
+		if mmu.cgbMode && mmu.hdmaActive {
+			return uint8(mmu.hdmaBlocks-1) & 0x7F
+		}
+		return 0xFF
 	case addr >= sound.RegNR10 && addr <= sound.RegNR52:
 		return mmu.sound.ReadRegister(addr)
 	case addr >= sound.WaveRAMBase && addr < sound.WaveRAMBase+sound.WaveRAMSize:
@@ -359,10 +450,39 @@ func (mmu *MMU) writeIO(addr uint16, value uint8) {
 	switch {
 	case addr == input.RegJOYP:
 		mmu.input.WriteRegister(addr, value)
+	case addr == video.RegDMA:
+		mmu.startDMA(value)
+	case addr == serial.RegSB || addr == serial.RegSC:
+		mmu.serial.WriteRegister(addr, value)
 	case addr >= timer.RegDIV && addr <= timer.RegTAC:
 		mmu.timer.WriteRegister(addr, value)
 	case addr >= video.RegLCDC && addr <= video.RegWX:
 		mmu.lcd.WriteRegister(addr, value)
+	case addr == video.RegVBK || (addr >= video.RegBCPS && addr <= video.RegOCPD):
+		mmu.lcd.WriteRegister(addr, value)
+	case addr == RegBOOT:
+		if mmu.bootROMMapped && value != 0 {
+			mmu.unmapBootROM()
+		}
+	case addr == RegKEY0:
+		if mmu.cgbMode && mmu.bootROMMapped {
+			mmu.key0 = value
+		}
+	case addr == RegKEY1:
+		if mmu.cgbMode {
+			mmu.key1 = value & 0x01
+		}
+	case addr == RegSVBK:
+		if mmu.cgbMode {
+			mmu.wramBank = int(value & 0x07)
+			if mmu.wramBank == 0 {
+				mmu.wramBank = 1
+			}
+		}
+	case addr >= RegHDMA1 && addr <= RegHDMA5:
+		if mmu.cgbMode {
+			mmu.writeHDMA(addr, value)
+		}
 	case addr >= sound.RegNR10 && addr <= sound.RegNR52:
 		mmu.sound.WriteRegister(addr, value)
 	case addr >= sound.WaveRAMBase && addr < sound.WaveRAMBase+sound.WaveRAMSize:
@@ -371,188 +491,344 @@ func (mmu *MMU) writeIO(addr uint16, value uint8) {
 		if mmu.interrupts != nil {
 			mmu.interrupts.WriteRegister(addr, value)
 		}
-	case addr == 0xFF46: // DMA Transfer
-		mmu.performDMA(value)
 	}
 }
 
 // writeMBC escreve em registradores do Memory Bank Controller
 func (mmu *MMU) writeMBC(addr uint16, value uint8) {
-	switch mmu.mbcType {
-	case 0: // ROM ONLY
-		// Sem MBC, ignora escritas
-
-	case 1: // MBC1
-		mmu.writeMBC1(addr, value)
+	if mmu.cart != nil {
+		mmu.cart.WriteControl(addr, value)
+	}
+}
 
-	case 2: // MBC2
-		mmu.writeMBC2(addr, value)
+// readCartridgeROM lê 0x0000-0x7FFF do cartucho
+func (mmu *MMU) readCartridgeROM(addr uint16) uint8 {
+	if mmu.cart == nil {
+		return 0xFF
+	}
+	return mmu.cart.ReadROM(addr)
+}
 
-	case 3: // MBC3
-		mmu.writeMBC3(addr, value)
+// startDMA agenda uma OAM DMA a partir de value<<8. A transferência
+// começa após o M-cycle da escrita e mais um de preparação; uma DMA em
+// andamento continua (e mantém o barramento bloqueado) até a nova começar.
+func (mmu *MMU) startDMA(value uint8) {
+	mmu.dmaRegister = value
+	mmu.dmaPending = uint16(value) << 8
+	mmu.dmaDelay = dmaStartupMCycles
+}
 
-	case 5: // MBC5
-		mmu.writeMBC5(addr, value)
+// stepDMA avança a OAM DMA, copiando um byte por M-cycle
+func (mmu *MMU) stepDMA(cycles int) {
+	if !mmu.dmaActive && mmu.dmaDelay == 0 {
+		return
 	}
-}
 
-// writeMBC1 implementa controle do MBC1
-func (mmu *MMU) writeMBC1(addr uint16, value uint8) {
-	switch {
-	case addr <= 0x1FFF:
-		// RAM Enable
-		mmu.ramEnabled = (value & 0x0F) == 0x0A
-
-	case addr >= 0x2000 && addr <= 0x3FFF:
-		// ROM Bank Number
-		bank := int(value & 0x1F)
-		if bank == 0 {
-			bank = 1
+	mmu.dmaCycles += cycles
+	for mmu.dmaCycles >= 4 {
+		mmu.dmaCycles -= 4
+
+		if mmu.dmaActive {
+			source := mmu.dmaSource + uint16(mmu.dmaIndex)
+			if source >= WRAMMirrorStart {
+				source -= 0x2000 // 0xE000-0xFFFF lê o espelho da WRAM
+			}
+			mmu.dmaValue = mmu.readBus(source)
+			mmu.lcd.DMAWriteOAM(mmu.dmaIndex, mmu.dmaValue)
+
+			mmu.dmaIndex++
+			if mmu.dmaIndex >= OAMSize {
+				mmu.dmaActive = false
+			}
 		}
-		mmu.currentROMBank = bank
-
-	case addr >= 0x4000 && addr <= 0x5FFF:
-		// RAM Bank Number / Upper ROM Bank
-		if mmu.mbcMode == 0 {
-			// ROM Banking Mode
-			mmu.currentROMBank = (mmu.currentROMBank & 0x1F) | (int(value&0x03) << 5)
-		} else {
-			// RAM Banking Mode
-			mmu.currentRAMBank = int(value & 0x03)
+
+		if mmu.dmaDelay > 0 {
+			mmu.dmaDelay--
+			if mmu.dmaDelay == 0 {
+				mmu.dmaActive = true
+				mmu.dmaSource = mmu.dmaPending
+				mmu.dmaIndex = 0
+			}
 		}
+	}
 
-	case addr >= 0x6000 && addr <= 0x7FFF:
-		// Banking Mode Select
-		mmu.mbcMode = int(value & 0x01)
+	if !mmu.dmaActive && mmu.dmaDelay == 0 {
+		mmu.dmaCycles = 0
 	}
 }
 
-// writeMBC2 implementa controle do MBC2
-func (mmu *MMU) writeMBC2(addr uint16, value uint8) {
-	switch {
-	case addr <= 0x3FFF:
-		if addr&0x0100 == 0 {
-			// RAM Enable
-			mmu.ramEnabled = (value & 0x0F) == 0x0A
-		} else {
-			// ROM Bank Number
-			bank := int(value & 0x0F)
-			if bank == 0 {
-				bank = 1
-			}
-			mmu.currentROMBank = bank
-		}
+// dmaConflicts retorna se addr está no mesmo barramento que a origem da
+// OAM DMA em andamento (VRAM ou barramento externo: cartucho e WRAM)
+func (mmu *MMU) dmaConflicts(addr uint16) bool {
+	return isVRAMBus(addr) == isVRAMBus(mmu.dmaSource)
+}
+
+// isVRAMBus retorna se o endereço é acessado pelo barramento da VRAM
+func isVRAMBus(addr uint16) bool {
+	return addr >= VRAMStart && addr <= VRAMEnd
+}
+
+// IsDMAActive retorna se uma OAM DMA está bloqueando o barramento
+func (mmu *MMU) IsDMAActive() bool {
+	return mmu.dmaActive
+}
+
+// GetROMTitle retorna o título da ROM
+func (mmu *MMU) GetROMTitle() string {
+	if mmu.header == nil {
+		return "Unknown"
 	}
+	return mmu.header.Title
 }
 
-// writeMBC3 implementa controle do MBC3
-func (mmu *MMU) writeMBC3(addr uint16, value uint8) {
-	switch {
-	case addr <= 0x1FFF:
-		// RAM Enable
-		mmu.ramEnabled = (value & 0x0F) == 0x0A
-
-	case addr >= 0x2000 && addr <= 0x3FFF:
-		// ROM Bank Number
-		bank := int(value & 0x7F)
-		if bank == 0 {
-			bank = 1
-		}
-		mmu.currentROMBank = bank
+// GetCartridgeType retorna o tipo do cartucho
+func (mmu *MMU) GetCartridgeType() uint8 {
+	if mmu.header == nil {
+		return 0
+	}
+	return mmu.header.CartridgeType
+}
 
-	case addr >= 0x4000 && addr <= 0x5FFF:
-		// RAM Bank Number
-		mmu.currentRAMBank = int(value & 0x03)
+// GetHeader retorna o header da ROM carregada (nil sem ROM)
+func (mmu *MMU) GetHeader() *cartridge.ROMHeader {
+	return mmu.header
+}
 
-	case addr >= 0x6000 && addr <= 0x7FFF:
-		// Latch Clock Data (RTC)
-		// TODO: Implementar RTC
+// GetROMHash retorna o SHA-1 do arquivo da ROM carregada
+func (mmu *MMU) GetROMHash() [sha1.Size]byte {
+	return mmu.romHash
+}
+
+// GetROMWarnings retorna os problemas do header encontrados no LoadROM
+func (mmu *MMU) GetROMWarnings() []string {
+	return mmu.romWarnings
+}
+
+// GetROMSize retorna o tamanho da ROM
+func (mmu *MMU) GetROMSize() int {
+	return len(mmu.rom)
+}
+
+// GetRAMSize retorna o tamanho da RAM externa
+func (mmu *MMU) GetRAMSize() int {
+	if mmu.cart == nil {
+		return 0
 	}
+	return mmu.cart.RAMSize()
 }
 
-// writeMBC5 implementa controle do MBC5
-func (mmu *MMU) writeMBC5(addr uint16, value uint8) {
-	switch {
-	case addr <= 0x1FFF:
-		// RAM Enable
-		mmu.ramEnabled = (value & 0x0F) == 0x0A
+// GetCartridge retorna o mapeador do cartucho carregado (nil sem ROM)
+func (mmu *MMU) GetCartridge() cartridge.Cartridge {
+	return mmu.cart
+}
 
-	case addr >= 0x2000 && addr <= 0x2FFF:
-		// ROM Bank Number (lower 8 bits)
-		mmu.currentROMBank = (mmu.currentROMBank & 0x100) | int(value)
+// wramOffset converte um endereço 0xC000-0xDFFF no offset da WRAM,
+// considerando o banco selecionado em SVBK
+func (mmu *MMU) wramOffset(addr uint16) int {
+	if addr < WRAMBank1Start {
+		return int(addr - WRAMBank0Start)
+	}
+	return mmu.wramBank*WRAMBankSize + int(addr-WRAMBank1Start)
+}
 
-	case addr >= 0x3000 && addr <= 0x3FFF:
-		// ROM Bank Number (upper 1 bit)
-		mmu.currentROMBank = (mmu.currentROMBank & 0xFF) | (int(value&0x01) << 8)
+// writeHDMA escreve nos registradores de DMA de VRAM do CGB
+func (mmu *MMU) writeHDMA(addr uint16, value uint8) {
+	switch addr {
+	case RegHDMA1:
+		mmu.hdmaSource = (mmu.hdmaSource & 0x00F0) | uint16(value)<<8
+	case RegHDMA2:
+		mmu.hdmaSource = (mmu.hdmaSource & 0xFF00) | uint16(value&0xF0)
+	case RegHDMA3:
+		mmu.hdmaDest = (mmu.hdmaDest & 0x00F0) | uint16(value&0x1F)<<8
+	case RegHDMA4:
+		mmu.hdmaDest = (mmu.hdmaDest & 0x1F00) | uint16(value&0xF0)
+	case RegHDMA5:
+		// Escrever com bit 7 = 0 durante um HDMA o cancela
+		if mmu.hdmaActive && value&0x80 == 0 {
+			mmu.hdmaActive = false
+			return
+		}
 
-	case addr >= 0x4000 && addr <= 0x5FFF:
-		// RAM Bank Number
-		mmu.currentRAMBank = int(value & 0x0F)
+		mmu.hdmaBlocks = int(value&0x7F) + 1
+		if value&0x80 == 0 {
+			// GDMA: transfere tudo imediatamente
+			for mmu.hdmaBlocks > 0 {
+				mmu.hdmaTransferBlock()
+			}
+			return
+		}
+		mmu.hdmaActive = true
 	}
 }
 
-// performDMA executa uma transferência DMA
-func (mmu *MMU) performDMA(value uint8) {
-	sourceAddr := uint16(value) << 8
+// hdmaTransferBlock copia 16 bytes para a VRAM
+func (mmu *MMU) hdmaTransferBlock() {
+	for i := 0; i < 16; i++ {
+		mmu.lcd.WriteVRAM(VRAMStart+(mmu.hdmaDest&0x1FFF), mmu.readBus(mmu.hdmaSource))
+		mmu.hdmaSource++
+		mmu.hdmaDest++
+	}
 
-	// Copia 160 bytes para OAM
-	for i := uint16(0); i < 0xA0; i++ {
-		data := mmu.Read(sourceAddr + i)
-		mmu.lcd.WriteOAM(OAMStart+i, data)
+	mmu.hdmaBlocks--
+	if mmu.hdmaBlocks <= 0 {
+		mmu.hdmaBlocks = 0
+		mmu.hdmaActive = false
 	}
 }
 
-// GetROMTitle retorna o título da ROM
-func (mmu *MMU) GetROMTitle() string {
-	if mmu.rom == nil || len(mmu.rom) < 0x143 {
-		return "Unknown"
+// SetCGBMode habilita ou desabilita o modo Game Boy Color
+func (mmu *MMU) SetCGBMode(enabled bool) {
+	mmu.cgbMode = enabled
+	mmu.lcd.SetCGBMode(enabled)
+	mmu.serial.SetCGBMode(enabled)
+	if !enabled {
+		mmu.wramBank = 1
+		mmu.doubleSpeed = false
+		mmu.timer.SetDoubleSpeed(false)
 	}
+}
 
-	title := make([]byte, 0, 16)
-	for i := 0x134; i <= 0x143; i++ {
-		if mmu.rom[i] == 0 {
-			break
-		}
-		title = append(title, mmu.rom[i])
+// IsCGBMode retorna se o MMU está em modo Game Boy Color
+func (mmu *MMU) IsCGBMode() bool {
+	return mmu.cgbMode
+}
+
+// IsCGBROM retorna se o header (0x143) indica suporte a Game Boy Color
+func (mmu *MMU) IsCGBROM() bool {
+	return mmu.header != nil && mmu.header.SupportsCGB()
+}
+
+// IsDoubleSpeed retorna se o CGB está em velocidade dupla
+func (mmu *MMU) IsDoubleSpeed() bool {
+	return mmu.doubleSpeed
+}
+
+// SwitchSpeed troca a velocidade do CPU quando KEY1 foi preparado;
+// chamado pela instrução STOP
+func (mmu *MMU) SwitchSpeed() bool {
+	if !mmu.cgbMode || mmu.key1&0x01 == 0 {
+		return false
 	}
 
-	return string(title)
+	mmu.doubleSpeed = !mmu.doubleSpeed
+	mmu.timer.SetDoubleSpeed(mmu.doubleSpeed)
+	mmu.key1 = 0
+	return true
 }
 
-// GetCartridgeType retorna o tipo do cartucho
-func (mmu *MMU) GetCartridgeType() uint8 {
-	if mmu.rom == nil || len(mmu.rom) < 0x147 {
-		return 0
+// HasBattery retorna se o cartucho mantém RAM/RTC com bateria
+func (mmu *MMU) HasBattery() bool {
+	return mmu.cart != nil && mmu.cart.HasBattery()
+}
+
+// IsRAMDirty retorna se a RAM do cartucho foi alterada desde o último salvamento
+func (mmu *MMU) IsRAMDirty() bool {
+	return mmu.cart != nil && mmu.cart.IsRAMDirty()
+}
+
+// ClearRAMDirty marca a RAM do cartucho como salva
+func (mmu *MMU) ClearRAMDirty() {
+	if mmu.cart != nil {
+		mmu.cart.ClearRAMDirty()
 	}
-	return mmu.rom[0x147]
 }
 
-// GetROMSize retorna o tamanho da ROM
-func (mmu *MMU) GetROMSize() int {
-	return len(mmu.rom)
+// GetBatteryRAM retorna o conteúdo persistente do cartucho: a RAM externa
+// seguida do rodapé de 48 bytes do RTC, quando presente
+func (mmu *MMU) GetBatteryRAM() []byte {
+	if mmu.cart == nil {
+		return nil
+	}
+	return mmu.cart.BatteryData()
 }
 
-// GetRAMSize retorna o tamanho da RAM externa
-func (mmu *MMU) GetRAMSize() int {
-	return len(mmu.externalRAM)
+// LoadBatteryRAM restaura o conteúdo persistente do cartucho gerado por
+// GetBatteryRAM (ou por outro emulador que use o mesmo formato)
+func (mmu *MMU) LoadBatteryRAM(data []byte) error {
+	if mmu.cart == nil {
+		return fmt.Errorf("nenhum cartucho carregado")
+	}
+	return mmu.cart.LoadBatteryData(data)
+}
+
+// GetWorkRAM retorna a WRAM (todos os bancos)
+func (mmu *MMU) GetWorkRAM() []uint8 {
+	return mmu.wram[:]
+}
+
+// GetHighRAM retorna a HRAM
+func (mmu *MMU) GetHighRAM() []uint8 {
+	return mmu.hram[:]
 }
 
 // Step executa um ciclo do MMU
 func (mmu *MMU) Step(cycles int) {
+	// Em velocidade dupla apenas CPU e timer são acelerados
+	videoCycles := cycles
+	if mmu.doubleSpeed {
+		videoCycles = cycles / 2
+	}
+
 	if mmu.lcd != nil {
-		mmu.lcd.Step(cycles)
+		previousMode := mmu.lcd.GetMode()
+		mmu.lcd.Step(videoCycles)
+
+		// HDMA transfere um bloco no início de cada H-Blank
+		if mmu.hdmaActive && previousMode != video.ModeHBlank && mmu.lcd.GetMode() == video.ModeHBlank {
+			mmu.hdmaTransferBlock()
+		}
 	}
 	if mmu.timer != nil {
 		mmu.timer.Step(cycles)
 	}
+	mmu.stepDMA(cycles)
+	if mmu.serial != nil {
+		mmu.serial.Step(cycles)
+	}
 	if mmu.sound != nil {
-		mmu.sound.Step(cycles)
+		mmu.sound.Step(videoCycles)
+	}
+	if mmu.cart != nil {
+		mmu.cart.Step(videoCycles)
+	}
+}
+
+// stateFields lista os campos gravados no save state. Os componentes
+// (LCD, timer, som...) e o cartucho têm o próprio SaveState.
+func (mmu *MMU) stateFields() []any {
+	return []any{
+		&mmu.wram, &mmu.hram,
+		&mmu.cgbMode, &mmu.wramBank, &mmu.key1, &mmu.doubleSpeed,
+		&mmu.hdmaSource, &mmu.hdmaDest, &mmu.hdmaBlocks, &mmu.hdmaActive,
+		&mmu.dmaRegister, &mmu.dmaActive, &mmu.dmaSource, &mmu.dmaIndex,
+		&mmu.dmaValue, &mmu.dmaPending, &mmu.dmaDelay, &mmu.dmaCycles,
+		&mmu.bootROMMapped, &mmu.key0,
 	}
 }
 
+// SaveState serializa WRAM, HRAM, os registradores CGB e as transferências
+// de OAM DMA e HDMA em andamento
+func (mmu *MMU) SaveState() ([]byte, error) {
+	return savestate.Marshal(mmu.stateFields()...)
+}
+
+// LoadState restaura o estado gerado por SaveState
+func (mmu *MMU) LoadState(data []byte) error {
+	if err := savestate.Unmarshal(data, mmu.stateFields()...); err != nil {
+		return fmt.Errorf("estado da memória inválido: %w", err)
+	}
+
+	mmu.SetCGBMode(mmu.cgbMode)
+	mmu.timer.SetDoubleSpeed(mmu.doubleSpeed)
+
+	if mmu.bootROMMapped && mmu.bootROM == nil {
+		mmu.bootROMMapped = false
+		return fmt.Errorf("estado salvo com a boot ROM mapeada, mas nenhuma boot ROM foi carregada")
+	}
+	return nil
+}
+
 // String retorna uma representação em string do estado do MMU
 func (mmu *MMU) String() string {
-	return fmt.Sprintf("MMU: ROM=%dKB RAM=%dKB MBC=%d ROMBank=%d RAMBank=%d",
-		len(mmu.rom)/1024, len(mmu.externalRAM)/1024, mmu.mbcType,
-		mmu.currentROMBank, mmu.currentRAMBank)
+	return fmt.Sprintf("MMU: %v", mmu.cart)
 }
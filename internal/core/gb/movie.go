@@ -0,0 +1,199 @@
+package gb
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/input"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
+	"github.com/hobbiee/visualboy-go/internal/core/movie"
+)
+
+// CoreVersion identifica a revisão do núcleo gravada nos movies. Deve mudar
+// quando uma alteração da emulação torna gravações antigas incompatíveis.
+const CoreVersion = "gb-1"
+
+// Configurações gravadas no header dos movies
+const (
+	movieSettingModel    = "model"
+	movieSettingRenderer = "renderer"
+	movieSettingBootROM  = "bootrom"
+)
+
+// StartRecording inicia a gravação de um movie. Com fromState o estado
+// atual é embutido no movie; sem ele o Game Boy é reiniciado e a gravação
+// começa no power-on. A RAM do cartucho não faz parte do power-on: jogos
+// com save devem ser gravados a partir de um save state.
+func (gb *GameBoy) StartRecording(fromState bool) error {
+	if gb.mmu.GetCartridge() == nil {
+		return fmt.Errorf("nenhuma ROM carregada")
+	}
+
+	hash := gb.GetROMHash()
+	header := movie.Header{
+		System:      movie.SystemGB,
+		CoreVersion: CoreVersion,
+		ROMHash:     hex.EncodeToString(hash[:]),
+		Settings: map[string]string{
+			movieSettingModel:    gb.config.Model.String(),
+			movieSettingRenderer: gb.config.Renderer.String(),
+			movieSettingBootROM:  strconv.FormatBool(gb.usesBootROM()),
+		},
+	}
+	if fromState {
+		state, err := gb.SaveState()
+		if err != nil {
+			return fmt.Errorf("erro ao gravar estado inicial do movie: %w", err)
+		}
+		header.StartState = state
+	} else {
+		gb.Reset()
+	}
+
+	gb.moviePlayer = nil
+	gb.movieRecorder = movie.NewRecorder(header)
+	return nil
+}
+
+// StopRecording encerra a gravação e retorna o movie (nil se não havia
+// gravação)
+func (gb *GameBoy) StopRecording() *movie.Movie {
+	if gb.movieRecorder == nil {
+		return nil
+	}
+	m := gb.movieRecorder.Movie()
+	gb.movieRecorder = nil
+	return m
+}
+
+// IsRecording retorna se um movie está sendo gravado
+func (gb *GameBoy) IsRecording() bool {
+	return gb.movieRecorder != nil
+}
+
+// PlayMovie aplica as configurações do movie, volta ao estado inicial
+// gravado e passa a controlar os botões a cada frame. A reprodução termina
+// ao fim do movie ou no primeiro frame dessincronizado, informado por
+// MovieError. Movies de outra ROM retornam *statefile.ROMMismatchError.
+func (gb *GameBoy) PlayMovie(m *movie.Movie) error {
+	hash := gb.GetROMHash()
+	if err := m.CheckROM(movie.SystemGB, hex.EncodeToString(hash[:])); err != nil {
+		return err
+	}
+
+	config := gb.config
+	if name := m.Setting(movieSettingModel); name != "" {
+		model, err := ParseModel(name)
+		if err != nil {
+			return fmt.Errorf("movie inválido: %w", err)
+		}
+		config.Model = model
+	}
+	if m.Setting(movieSettingRenderer) == video.RendererFIFO.String() {
+		config.Renderer = video.RendererFIFO
+	} else {
+		config.Renderer = video.RendererScanline
+	}
+	if bootROM, _ := strconv.ParseBool(m.Setting(movieSettingBootROM)); bootROM {
+		if !gb.mmu.HasBootROM() {
+			return fmt.Errorf("movie gravado com boot ROM, mas nenhuma foi carregada")
+		}
+		config.EnableBootROM = true
+	} else {
+		config.EnableBootROM = false
+	}
+	gb.SetConfig(config)
+	gb.mmu.GetLCD().SetRenderer(config.Renderer)
+
+	if m.StartState != nil {
+		if err := gb.LoadState(m.StartState); err != nil {
+			return fmt.Errorf("erro ao carregar estado inicial do movie: %w", err)
+		}
+	} else {
+		gb.Reset()
+	}
+
+	gb.movieRecorder = nil
+	gb.moviePlayer = movie.NewPlayer(m)
+	return nil
+}
+
+// StopMovie encerra a reprodução
+func (gb *GameBoy) StopMovie() {
+	gb.moviePlayer = nil
+}
+
+// IsPlayingMovie retorna se a reprodução ainda controla os botões
+func (gb *GameBoy) IsPlayingMovie() bool {
+	return gb.moviePlayer != nil && !gb.moviePlayer.Done()
+}
+
+// GetMoviePlayer retorna a reprodução atual (nil se nenhuma)
+func (gb *GameBoy) GetMoviePlayer() *movie.Player {
+	return gb.moviePlayer
+}
+
+// MovieError retorna a dessincronização encontrada na reprodução, como
+// *movie.DesyncError (nil se nenhuma)
+func (gb *GameBoy) MovieError() error {
+	if gb.moviePlayer == nil {
+		return nil
+	}
+	return gb.moviePlayer.Err()
+}
+
+// applyMovieInput define os botões do frame que vai começar ou, na
+// gravação, amostra os botões no mesmo ponto em que a reprodução os aplica.
+// Mudanças feitas durante o frame (ex.: no callback de vídeo) entram no
+// frame seguinte, tanto na gravação quanto na reprodução.
+func (gb *GameBoy) applyMovieInput() {
+	inp := gb.mmu.GetInput()
+	if gb.movieRecorder != nil {
+		gb.movieInput = 0
+		for button := 0; button < input.ButtonCount; button++ {
+			if inp.IsButtonPressed(button) {
+				gb.movieInput |= 1 << button
+			}
+		}
+		return
+	}
+
+	if gb.moviePlayer == nil {
+		return
+	}
+	buttons, ok := gb.moviePlayer.Input()
+	if !ok {
+		return
+	}
+	for button := 0; button < input.ButtonCount; button++ {
+		inp.SetButtonState(button, buttons&(1<<button) != 0)
+	}
+}
+
+// updateMovie grava ou confere o frame que terminou
+func (gb *GameBoy) updateMovie() {
+	if gb.movieRecorder == nil && !gb.IsPlayingMovie() {
+		return
+	}
+
+	checksum := gb.frameChecksum()
+	if gb.movieRecorder != nil {
+		gb.movieRecorder.Record(gb.movieInput, checksum)
+		return
+	}
+	gb.moviePlayer.Verify(checksum)
+}
+
+// frameChecksum calcula a soma do framebuffer colorido, da WRAM e da HRAM
+func (gb *GameBoy) frameChecksum() uint32 {
+	frame := gb.mmu.GetLCD().GetColorFrameBuffer()
+	pixels := make([]byte, 0, 2*video.ScreenWidth*video.ScreenHeight)
+	for _, row := range frame {
+		for _, pixel := range row {
+			pixels = binary.LittleEndian.AppendUint16(pixels, pixel)
+		}
+	}
+	return movie.Checksum(pixels, gb.mmu.GetWorkRAM(), gb.mmu.GetHighRAM())
+}
@@ -0,0 +1,194 @@
+package gb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/input"
+	"github.com/hobbiee/visualboy-go/internal/core/movie"
+	"github.com/hobbiee/visualboy-go/internal/core/statefile"
+)
+
+// newJoypadROM cria uma ROM que, em loop, lê os botões de ação no JOYP e
+// grava o valor na WRAM
+func newJoypadROM() []uint8 {
+	rom := newLoopROM(0x00)
+	copy(rom[0x100:], []uint8{
+		0x21, 0x00, 0xC0, // LD HL, 0xC000
+		// loop (0x0103):
+		0x3E, 0x10, // LD A, 0x10 (seleciona os botões de ação)
+		0xE0, 0x00, // LDH (JOYP), A
+		0xF0, 0x00, // LDH A, (JOYP)
+		0x22,       // LD (HL+), A
+		0x7C,       // LD A, H
+		0xE6, 0x1F, // AND 0x1F
+		0xF6, 0xC0, // OR 0xC0
+		0x67,       // LD H, A
+		0x18, 0xF1, // JR loop
+	})
+	return rom
+}
+
+// newJoypadGameBoy carrega a ROM que lê o joypad
+func newJoypadGameBoy(t *testing.T) *GameBoy {
+	config := DefaultConfig()
+	config.EnableVSync = false
+	gameboy := NewGameBoy(config)
+	if err := gameboy.LoadROM(newJoypadROM()); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+	gameboy.Start()
+	return gameboy
+}
+
+// recordMovie grava frames quadros com uma sequência de botões
+func recordMovie(t *testing.T, gameboy *GameBoy, fromState bool, frames int) *movie.Movie {
+	if err := gameboy.StartRecording(fromState); err != nil {
+		t.Fatalf("Failed to start recording: %v", err)
+	}
+	gameboy.GetInput().SimulateKeySequence([]int{input.ButtonStart, input.ButtonA, input.ButtonB})
+	for i := 0; i < frames; i++ {
+		gameboy.Step()
+	}
+	return gameboy.StopRecording()
+}
+
+// playMovie reproduz o movie até o fim ou até dessincronizar
+func playMovie(t *testing.T, gameboy *GameBoy, m *movie.Movie) error {
+	if err := gameboy.PlayMovie(m); err != nil {
+		t.Fatalf("Failed to play movie: %v", err)
+	}
+	for gameboy.IsPlayingMovie() {
+		gameboy.Step()
+	}
+	return gameboy.MovieError()
+}
+
+func TestMovieRecordAndPlayback(t *testing.T) {
+	for _, fromState := range []bool{false, true} {
+		gameboy := newJoypadGameBoy(t)
+		for i := 0; i < 5; i++ {
+			gameboy.Step()
+		}
+
+		m := recordMovie(t, gameboy, fromState, 20)
+		if len(m.Frames) != 20 {
+			t.Fatalf("expected 20 recorded frames, got %d", len(m.Frames))
+		}
+		if (m.StartState != nil) != fromState {
+			t.Errorf("fromState=%v: unexpected start state", fromState)
+		}
+		if m.Frames[0].Input != 1<<input.ButtonStart || m.Frames[4].Input != 1<<input.ButtonA {
+			t.Errorf("unexpected recorded input: %v", m.Frames[:6])
+		}
+		want, _ := gameboy.SaveState()
+
+		// O movie sobrevive à codificação e reproduz o mesmo estado final
+		data, err := m.Encode()
+		if err != nil {
+			t.Fatalf("Failed to encode movie: %v", err)
+		}
+		decoded, err := movie.Decode(data)
+		if err != nil {
+			t.Fatalf("Failed to decode movie: %v", err)
+		}
+
+		player := newJoypadGameBoy(t)
+		if err := playMovie(t, player, decoded); err != nil {
+			t.Fatalf("fromState=%v: unexpected desync: %v", fromState, err)
+		}
+		got, _ := player.SaveState()
+		if player.GetMoviePlayer().Frame() != 20 || string(stateSectionsOf(t, got).Memory) != string(stateSectionsOf(t, want).Memory) {
+			t.Errorf("fromState=%v: playback did not reach the recorded state", fromState)
+		}
+	}
+}
+
+func TestMovieDesync(t *testing.T) {
+	m := recordMovie(t, newJoypadGameBoy(t), false, 20)
+	m.Frames[10].Input = 1 << input.ButtonSelect
+
+	var desync *movie.DesyncError
+	err := playMovie(t, newJoypadGameBoy(t), m)
+	if !errors.As(err, &desync) || !errors.Is(err, movie.ErrDesync) {
+		t.Fatalf("expected a desync error, got %v", err)
+	}
+	if desync.Frame != 11 {
+		t.Errorf("expected desync at frame 11, got %d", desync.Frame)
+	}
+
+	// Movie de outra ROM
+	other := newBusyGameBoy(t, 0)
+	if err := other.PlayMovie(m); !errors.Is(err, statefile.ErrROMMismatch) {
+		t.Errorf("expected ErrROMMismatch, got %v", err)
+	}
+}
+
+// TestMovieInputChangedDuringFrame verifica que botões alterados no
+// callback de vídeo, no fim do frame, são gravados no frame em que o jogo
+// passa a vê-los
+func TestMovieInputChangedDuringFrame(t *testing.T) {
+	gameboy := newJoypadGameBoy(t)
+	if err := gameboy.StartRecording(false); err != nil {
+		t.Fatalf("Failed to start recording: %v", err)
+	}
+	frames := 0
+	gameboy.SetFrameCallback(func([144][160]uint8) {
+		frames++
+		gameboy.GetInput().SetButtonState(input.ButtonA, frames >= 5 && frames < 9)
+	})
+	for i := 0; i < 12; i++ {
+		gameboy.Step()
+	}
+	m := gameboy.StopRecording()
+
+	a := uint16(1 << input.ButtonA)
+	if m.Frames[4].Input != 0 || m.Frames[5].Input != a || m.Frames[8].Input != a || m.Frames[9].Input != 0 {
+		t.Errorf("unexpected recorded input: %v", m.Frames)
+	}
+	if err := playMovie(t, newJoypadGameBoy(t), m); err != nil {
+		t.Errorf("unexpected desync: %v", err)
+	}
+}
+
+func TestSimulateKeySequence(t *testing.T) {
+	gameboy := newJoypadGameBoy(t)
+	inp := gameboy.GetInput()
+	inp.SimulateKeySequence([]int{input.ButtonA, input.ButtonB})
+
+	var pressed []int
+	for inp.IsSimulating() {
+		buttons := 0
+		for _, button := range inp.GetPressedButtons() {
+			buttons |= 1 << button
+		}
+		pressed = append(pressed, buttons)
+		gameboy.Step()
+	}
+
+	a, b := 1<<input.ButtonA, 1<<input.ButtonB
+	want := []int{a, a, 0, 0, b, b}
+	if len(pressed) != len(want) {
+		t.Fatalf("expected %v, got %v", want, pressed)
+	}
+	for i := range want {
+		if pressed[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, pressed)
+		}
+	}
+	if inp.IsAnyButtonPressed() {
+		t.Error("buttons should be released after the sequence")
+	}
+
+	// SimulateKeyPress segura o botão pelo número de frames indicado
+	inp.SimulateKeyPress(input.ButtonStart, 3)
+	for i := 0; i < 3; i++ {
+		if !inp.IsButtonPressed(input.ButtonStart) {
+			t.Fatalf("Start should be held on frame %d", i+1)
+		}
+		gameboy.Step()
+	}
+	if inp.IsButtonPressed(input.ButtonStart) {
+		t.Error("Start should be released after 3 frames")
+	}
+}
@@ -0,0 +1,443 @@
+package printer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/serial"
+)
+
+// Constantes do protocolo do Game Boy Printer
+const (
+	// Bytes mágicos que iniciam cada pacote
+	Magic1 = 0x88
+	Magic2 = 0x33
+
+	// Comandos
+	CmdInit   = 0x01
+	CmdPrint  = 0x02
+	CmdData   = 0x04
+	CmdStatus = 0x0F
+
+	// Identificação devolvida no byte após o checksum
+	DeviceID = 0x81
+
+	// Bits de status
+	StatusChecksumError = 1 << 0
+	StatusPrinting      = 1 << 1
+	StatusImageFull     = 1 << 2
+	StatusUnprocessed   = 1 << 3
+	StatusPacketError   = 1 << 4
+	StatusPaperJam      = 1 << 5
+	StatusOtherError    = 1 << 6
+	StatusLowBattery    = 1 << 7
+
+	// Dimensões da imagem
+	Width      = 160
+	BandHeight = 16                     // Cada pacote DATA tem 2 linhas de tiles
+	BandSize   = Width / 8 * 2 * 16     // 640 bytes por faixa
+	BufferSize = 0x2280                 // 8832 bytes (até 13 faixas e meia)
+	MaxBands   = BufferSize / BandSize  // Faixas completas que cabem no buffer
+	MarginUnit = 8                      // Pixels por unidade de margem
+	maxPacket  = 6 + BufferSize + 2 + 2 // Cabeçalho + dados + checksum + resposta
+
+	// Consultas de STATUS reportando impressão em andamento após PRINT
+	printingPolls = 4
+
+	// Exposição neutra (PRINT byte 3, bits 0-6)
+	defaultExposure = 0x40
+)
+
+// Estados do recebimento de pacotes
+const (
+	stateMagic1 = iota
+	stateMagic2
+	stateCommand
+	stateCompression
+	stateLengthLow
+	stateLengthHigh
+	stateData
+	stateChecksumLow
+	stateChecksumHigh
+	stateAlive
+	stateStatus
+)
+
+// Printer emula o Game Boy Printer conectado ao cabo link. Implementa
+// serial.Peer: o Game Boy é o mestre e cada byte enviado recebe a resposta
+// da impressora.
+type Printer struct {
+	mu sync.Mutex
+
+	// Pacote em recebimento
+	state       int
+	command     uint8
+	compressed  bool
+	length      int
+	data        []uint8
+	checksum    uint16
+	received    uint16
+	packetValid bool
+
+	// Estado da impressora
+	status     uint8
+	buffer     []uint8 // Dados de imagem (2bpp, tiles) aguardando PRINT
+	pollsLeft  int     // Consultas restantes até terminar a impressão
+	page       *image.Gray
+	outputDir  string
+	printCount int
+	lastImage  *image.Gray
+	lastFile   string
+	lastError  error
+	onPrint    func(img *image.Gray, path string)
+	now        func() time.Time
+}
+
+// NewPrinter cria uma nova instância do Printer. As impressões concluídas
+// são gravadas como PNG em outputDir (vazio = não grava arquivos).
+func NewPrinter(outputDir string) *Printer {
+	return &Printer{
+		outputDir: outputDir,
+		now:       time.Now,
+	}
+}
+
+// SetPrintCallback define uma função chamada a cada impressão concluída,
+// com a imagem e o arquivo gravado (vazio se não houver outputDir)
+func (p *Printer) SetPrintCallback(callback func(img *image.Gray, path string)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onPrint = callback
+}
+
+// LastImage retorna a última imagem impressa (nil se nenhuma)
+func (p *Printer) LastImage() *image.Gray {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastImage
+}
+
+// LastFile retorna o caminho do último PNG gravado
+func (p *Printer) LastFile() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastFile
+}
+
+// LastError retorna o último erro ao gravar um PNG
+func (p *Printer) LastError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastError
+}
+
+// Exchange implementa serial.Peer: recebe um byte do Game Boy e devolve a
+// resposta da impressora
+func (p *Printer) Exchange(out uint8) uint8 {
+	p.mu.Lock()
+
+	reply, printed, path := p.receive(out)
+	callback := p.onPrint
+	p.mu.Unlock()
+
+	if printed != nil && callback != nil {
+		callback(printed, path)
+	}
+	return reply
+}
+
+// Attach implementa serial.Peer (a impressora nunca inicia transferências)
+func (p *Printer) Attach(device serial.Device) {}
+
+// Close implementa serial.Peer
+func (p *Printer) Close() error {
+	return nil
+}
+
+// receive processa um byte do pacote. Retorna a resposta e, se uma página
+// foi concluída, a imagem e o arquivo gravado.
+func (p *Printer) receive(value uint8) (uint8, *image.Gray, string) {
+	switch p.state {
+	case stateMagic1:
+		if value == Magic1 {
+			p.state = stateMagic2
+		}
+	case stateMagic2:
+		if value == Magic2 {
+			p.state = stateCommand
+		} else if value != Magic1 {
+			p.state = stateMagic1
+		}
+	case stateCommand:
+		p.command = value
+		p.checksum = uint16(value)
+		p.state = stateCompression
+	case stateCompression:
+		p.compressed = value&0x01 != 0
+		p.checksum += uint16(value)
+		p.state = stateLengthLow
+	case stateLengthLow:
+		p.length = int(value)
+		p.checksum += uint16(value)
+		p.state = stateLengthHigh
+	case stateLengthHigh:
+		p.length |= int(value) << 8
+		p.checksum += uint16(value)
+		p.data = p.data[:0]
+		p.state = stateData
+		if p.length == 0 {
+			p.state = stateChecksumLow
+		}
+	case stateData:
+		if len(p.data) < maxPacket {
+			p.data = append(p.data, value)
+		}
+		p.checksum += uint16(value)
+		if len(p.data) >= p.length {
+			p.state = stateChecksumLow
+		}
+	case stateChecksumLow:
+		p.received = uint16(value)
+		p.state = stateChecksumHigh
+	case stateChecksumHigh:
+		p.received |= uint16(value) << 8
+		p.state = stateAlive
+	case stateAlive:
+		p.state = stateStatus
+		return DeviceID, nil, ""
+	case stateStatus:
+		p.state = stateMagic1
+		printed, path := p.execute()
+		return p.status, printed, path
+	}
+
+	return 0x00, nil, ""
+}
+
+// execute processa o pacote recebido e atualiza o status
+func (p *Printer) execute() (*image.Gray, string) {
+	if p.received != p.checksum {
+		p.status |= StatusChecksumError
+		return nil, ""
+	}
+	p.status &^= StatusChecksumError
+
+	switch p.command {
+	case CmdInit:
+		p.buffer = p.buffer[:0]
+		p.status = 0
+		p.pollsLeft = 0
+
+	case CmdData:
+		data := p.data
+		if p.compressed {
+			data = decompress(data)
+		}
+		if len(p.buffer)+len(data) > BufferSize {
+			data = data[:BufferSize-len(p.buffer)]
+		}
+		p.buffer = append(p.buffer, data...)
+
+		if len(p.buffer) > 0 {
+			p.status |= StatusUnprocessed
+		}
+		if len(p.buffer) >= BufferSize {
+			p.status |= StatusImageFull
+		}
+
+	case CmdPrint:
+		if len(p.data) < 4 {
+			p.status |= StatusPacketError
+			return nil, ""
+		}
+		p.status &^= StatusPacketError | StatusUnprocessed | StatusImageFull
+		p.status |= StatusPrinting
+		p.pollsLeft = printingPolls
+		return p.print(p.data[1], p.data[2], p.data[3])
+
+	case CmdStatus:
+		// A impressão termina após algumas consultas de status
+		if p.pollsLeft > 0 {
+			p.pollsLeft--
+			if p.pollsLeft == 0 {
+				p.status &^= StatusPrinting
+			}
+		}
+
+	default:
+		p.status |= StatusPacketError
+	}
+
+	return nil, ""
+}
+
+// print renderiza o buffer na página atual. A página é concluída quando a
+// margem inferior é diferente de zero; com margem zero, o próximo PRINT
+// continua a mesma folha (como na impressão em partes do Pokémon).
+func (p *Printer) print(margins, palette, exposure uint8) (*image.Gray, string) {
+	if palette == 0 {
+		palette = 0xE4 // Paleta padrão
+	}
+
+	bands := len(p.buffer) / BandSize
+	before := int(margins>>4) * MarginUnit
+	after := int(margins&0x0F) * MarginUnit
+
+	shades := exposureShades(palette, exposure&0x7F)
+
+	// Monta a imagem desta impressão com a margem superior
+	height := before + bands*BandHeight
+	img := image.NewGray(image.Rect(0, 0, Width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+
+	for band := 0; band < bands; band++ {
+		data := p.buffer[band*BandSize : (band+1)*BandSize]
+		for tile := 0; tile < Width/8*2; tile++ {
+			tileX := (tile % (Width / 8)) * 8
+			tileY := before + band*BandHeight + (tile/(Width/8))*8
+			drawTile(img, data[tile*16:(tile+1)*16], tileX, tileY, shades)
+		}
+	}
+	p.buffer = p.buffer[:0]
+
+	p.page = appendImage(p.page, img)
+	if after == 0 {
+		return nil, ""
+	}
+
+	// Margem inferior: a folha é cortada e gravada
+	page := appendImage(p.page, blankImage(after))
+	p.page = nil
+	p.lastImage = page
+	p.lastFile = ""
+	p.lastError = nil
+
+	if p.outputDir != "" {
+		path, err := p.save(page)
+		p.lastFile = path
+		p.lastError = err
+	}
+
+	return page, p.lastFile
+}
+
+// save grava a página como PNG no diretório de saída
+func (p *Printer) save(page *image.Gray) (string, error) {
+	if err := os.MkdirAll(p.outputDir, 0755); err != nil {
+		return "", fmt.Errorf("erro ao criar diretório de impressões: %w", err)
+	}
+
+	p.printCount++
+	name := fmt.Sprintf("printer_%s_%03d.png", p.now().Format("20060102_150405"), p.printCount)
+	path := filepath.Join(p.outputDir, name)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar arquivo de impressão: %w", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, page); err != nil {
+		return "", fmt.Errorf("erro ao gravar PNG: %w", err)
+	}
+	return path, nil
+}
+
+// exposureShades calcula o tom de cinza de cada índice de cor, aplicando a
+// paleta e a exposição (0x40 = normal; menor clareia, maior escurece)
+func exposureShades(palette, exposure uint8) [4]uint8 {
+	base := [4]float64{255, 170, 85, 0}
+	darken := (float64(exposure) - defaultExposure) / defaultExposure * 0.25
+
+	var shades [4]uint8
+	for i := 0; i < 4; i++ {
+		shade := base[(palette>>(i*2))&0x03]
+		shade -= shade * darken
+		if shade > 255 {
+			shade = 255
+		} else if shade < 0 {
+			shade = 0
+		}
+		shades[i] = uint8(shade)
+	}
+	return shades
+}
+
+// drawTile desenha um tile 8x8 (2bpp) na imagem
+func drawTile(img *image.Gray, tile []uint8, x, y int, shades [4]uint8) {
+	for row := 0; row < 8; row++ {
+		low := tile[row*2]
+		high := tile[row*2+1]
+		for col := 0; col < 8; col++ {
+			bit := uint(7 - col)
+			index := ((high>>bit)&1)<<1 | (low>>bit)&1
+			img.SetGray(x+col, y+row, color.Gray{Y: shades[index]})
+		}
+	}
+}
+
+// decompress expande dados com a compressão RLE da impressora: um byte de
+// controle com bit 7 = 0 copia os próximos n+1 bytes; com bit 7 = 1 repete
+// o próximo byte (n&0x7F)+2 vezes
+func decompress(data []uint8) []uint8 {
+	var out []uint8
+
+	for i := 0; i < len(data); {
+		control := data[i]
+		i++
+
+		if control&0x80 == 0 {
+			count := int(control) + 1
+			if i+count > len(data) {
+				count = len(data) - i
+			}
+			out = append(out, data[i:i+count]...)
+			i += count
+			continue
+		}
+
+		if i >= len(data) {
+			break
+		}
+		count := int(control&0x7F) + 2
+		for j := 0; j < count; j++ {
+			out = append(out, data[i])
+		}
+		i++
+	}
+
+	return out
+}
+
+// blankImage cria uma faixa branca de altura height
+func blankImage(height int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, Width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	return img
+}
+
+// appendImage concatena duas imagens verticalmente
+func appendImage(top, bottom *image.Gray) *image.Gray {
+	if top == nil {
+		return bottom
+	}
+
+	img := image.NewGray(image.Rect(0, 0, Width, top.Rect.Dy()+bottom.Rect.Dy()))
+	copy(img.Pix, top.Pix)
+	copy(img.Pix[len(top.Pix):], bottom.Pix)
+	return img
+}
@@ -0,0 +1,213 @@
+package printer
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"testing"
+	"time"
+)
+
+// packet monta um pacote da impressora com checksum válido
+func packet(command uint8, compressed bool, data []uint8) []uint8 {
+	compression := uint8(0)
+	if compressed {
+		compression = 1
+	}
+
+	pkt := []uint8{Magic1, Magic2, command, compression, uint8(len(data)), uint8(len(data) >> 8)}
+	pkt = append(pkt, data...)
+
+	checksum := uint16(command) + uint16(compression) + uint16(len(data)&0xFF) + uint16(len(data)>>8)
+	for _, b := range data {
+		checksum += uint16(b)
+	}
+	return append(pkt, uint8(checksum), uint8(checksum>>8), 0x00, 0x00)
+}
+
+// send transfere o pacote e retorna as duas últimas respostas (ID e status)
+func send(p *Printer, pkt []uint8) (uint8, uint8) {
+	var replies []uint8
+	for _, b := range pkt {
+		replies = append(replies, p.Exchange(b))
+	}
+	return replies[len(replies)-2], replies[len(replies)-1]
+}
+
+// solidBand retorna uma faixa em que todos os pixels têm o índice de cor index
+func solidBand(index uint8) []uint8 {
+	var low, high uint8
+	if index&1 != 0 {
+		low = 0xFF
+	}
+	if index&2 != 0 {
+		high = 0xFF
+	}
+
+	band := make([]uint8, BandSize)
+	for i := 0; i < BandSize; i += 2 {
+		band[i] = low
+		band[i+1] = high
+	}
+	return band
+}
+
+func TestPrinterStatusReply(t *testing.T) {
+	p := NewPrinter("")
+
+	id, status := send(p, packet(CmdInit, false, nil))
+	if id != DeviceID {
+		t.Errorf("ID: esperado 0x%02X, obtido 0x%02X", DeviceID, id)
+	}
+	if status != 0x00 {
+		t.Errorf("status após INIT: esperado 0x00, obtido 0x%02X", status)
+	}
+
+	_, status = send(p, packet(CmdData, false, solidBand(3)))
+	if status&StatusUnprocessed == 0 {
+		t.Errorf("status após DATA deveria indicar dados pendentes, obtido 0x%02X", status)
+	}
+}
+
+func TestPrinterChecksumError(t *testing.T) {
+	p := NewPrinter("")
+
+	pkt := packet(CmdData, false, solidBand(1))
+	pkt[len(pkt)-4] ^= 0xFF // Corrompe o checksum
+
+	_, status := send(p, pkt)
+	if status&StatusChecksumError == 0 {
+		t.Errorf("checksum inválido deveria ser reportado, obtido 0x%02X", status)
+	}
+
+	// O pacote corrompido é descartado e o próximo válido limpa o erro
+	_, status = send(p, packet(CmdStatus, false, nil))
+	if status != 0x00 {
+		t.Errorf("status após pacote válido: esperado 0x00, obtido 0x%02X", status)
+	}
+}
+
+func TestPrinterDecompress(t *testing.T) {
+	data := []uint8{
+		0x02, 0x11, 0x22, 0x33, // 3 bytes literais
+		0x83, 0xAA, // 0xAA repetido 5 vezes
+	}
+	expected := []uint8{0x11, 0x22, 0x33, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA}
+
+	got := decompress(data)
+	if len(got) != len(expected) {
+		t.Fatalf("tamanho: esperado %d, obtido %d", len(expected), len(got))
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("byte %d: esperado 0x%02X, obtido 0x%02X", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestPrinterPrintWritesPNG(t *testing.T) {
+	dir := t.TempDir()
+	p := NewPrinter(dir)
+	p.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	var printed *image.Gray
+	p.SetPrintCallback(func(img *image.Gray, path string) {
+		printed = img
+	})
+
+	// Faixa comprimida: 640 bytes de índice 3 (preto)
+	var compressed []uint8
+	for remaining := BandSize; remaining > 0; {
+		n := remaining
+		if n > 129 {
+			n = 129
+		}
+		compressed = append(compressed, 0x80|uint8(n-2), 0xFF)
+		remaining -= n
+	}
+
+	send(p, packet(CmdInit, false, nil))
+	send(p, packet(CmdData, true, compressed))
+	send(p, packet(CmdData, false, solidBand(1)))
+	send(p, packet(CmdData, false, nil))
+
+	// 1 folha, margens 0 antes e 1 depois, paleta padrão, exposição normal
+	_, status := send(p, packet(CmdPrint, false, []uint8{0x01, 0x01, 0xE4, 0x40}))
+	if status&StatusPrinting == 0 {
+		t.Errorf("status após PRINT deveria indicar impressão, obtido 0x%02X", status)
+	}
+
+	// A impressão termina após algumas consultas
+	for i := 0; i < printingPolls; i++ {
+		_, status = send(p, packet(CmdStatus, false, nil))
+	}
+	if status&StatusPrinting != 0 {
+		t.Errorf("impressão deveria terminar, status 0x%02X", status)
+	}
+
+	if printed == nil {
+		t.Fatal("callback de impressão não foi chamado")
+	}
+	if p.LastError() != nil {
+		t.Fatalf("erro ao gravar PNG: %v", p.LastError())
+	}
+
+	file, err := os.Open(p.LastFile())
+	if err != nil {
+		t.Fatalf("PNG não encontrado: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("PNG inválido: %v", err)
+	}
+
+	height := 2*BandHeight + MarginUnit
+	if img.Bounds().Dx() != Width || img.Bounds().Dy() != height {
+		t.Fatalf("dimensões: esperado %dx%d, obtido %dx%d", Width, height, img.Bounds().Dx(), img.Bounds().Dy())
+	}
+
+	checks := []struct {
+		y     int
+		shade uint8
+	}{
+		{0, 0x00},              // Faixa comprimida (preto)
+		{BandHeight, 170},      // Índice 1 (cinza claro)
+		{2 * BandHeight, 0xFF}, // Margem inferior
+	}
+	for _, c := range checks {
+		r, _, _, _ := img.At(80, c.y).RGBA()
+		if uint8(r>>8) != c.shade {
+			t.Errorf("linha %d: esperado tom %d, obtido %d", c.y, c.shade, r>>8)
+		}
+	}
+}
+
+func TestPrinterZeroMarginContinuesPage(t *testing.T) {
+	p := NewPrinter("")
+
+	for i := 0; i < 2; i++ {
+		send(p, packet(CmdInit, false, nil))
+		send(p, packet(CmdData, false, solidBand(2)))
+		send(p, packet(CmdData, false, nil))
+
+		margins := uint8(0x00)
+		if i == 1 {
+			margins = 0x03
+		}
+		send(p, packet(CmdPrint, false, []uint8{0x01, margins, 0xE4, 0x40}))
+
+		if i == 0 && p.LastImage() != nil {
+			t.Fatal("folha não deveria ser concluída com margem inferior zero")
+		}
+	}
+
+	img := p.LastImage()
+	if img == nil {
+		t.Fatal("folha deveria ser concluída")
+	}
+	if got, want := img.Rect.Dy(), 2*BandHeight+3*MarginUnit; got != want {
+		t.Errorf("altura: esperado %d, obtido %d", want, got)
+	}
+}
@@ -0,0 +1,77 @@
+package gb
+
+import (
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
+)
+
+// renderFrame executa a ROM de loop com o renderizador informado e retorna
+// o primeiro frame completo, com tiles, window e um sprite na tela
+func renderFrame(t *testing.T, renderer video.Renderer) [144][160]uint8 {
+	config := DefaultConfig()
+	config.EnableVSync = false
+	config.Renderer = renderer
+	gameboy := NewGameBoy(config)
+
+	if err := gameboy.LoadROM(newLoopROM(0x00)); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+
+	mmu := gameboy.mmu
+	mmu.Write(video.RegLCDC, 0x00)
+
+	// Tile 1 listrado, tile 2 sólido
+	for row := uint16(0); row < 8; row++ {
+		mmu.Write(0x8010+row*2, 0xAA)
+		mmu.Write(0x8011+row*2, 0x0F)
+		mmu.Write(0x8020+row*2, 0xFF)
+		mmu.Write(0x8021+row*2, 0xFF)
+	}
+	for i := uint16(0); i < 32*32; i += 3 {
+		mmu.Write(0x9800+i, 0x01)
+	}
+	for i := uint16(0); i < 32*32; i++ {
+		mmu.Write(0x9C00+i, 0x02)
+	}
+
+	// Sprite com o tile 1 em (20, 30)
+	mmu.Write(0xFE00, 30+16)
+	mmu.Write(0xFE01, 20+8)
+	mmu.Write(0xFE02, 0x01)
+
+	mmu.Write(video.RegSCX, 3)
+	mmu.Write(video.RegSCY, 5)
+	mmu.Write(video.RegWY, 100)
+	mmu.Write(video.RegWX, 87)
+	mmu.Write(video.RegBGP, 0xE4)
+	mmu.Write(video.RegOBP0, 0x1B)
+	mmu.Write(video.RegLCDC, 0xF3)
+
+	var frame [144][160]uint8
+	gameboy.SetFrameCallback(func(f [144][160]uint8) {
+		frame = f
+	})
+
+	gameboy.Start()
+	for i := 0; i < 3 && gameboy.GetFrameCount() == 0; i++ {
+		gameboy.Step()
+	}
+	if gameboy.GetFrameCount() == 0 {
+		t.Fatal("No frame was produced")
+	}
+	return frame
+}
+
+func TestRendererFIFOMatchesScanline(t *testing.T) {
+	scanline := renderFrame(t, video.RendererScanline)
+	fifo := renderFrame(t, video.RendererFIFO)
+
+	for y := 0; y < 144; y++ {
+		for x := 0; x < 160; x++ {
+			if scanline[y][x] != fifo[y][x] {
+				t.Fatalf("Pixel (%d,%d) differs: scanline=%d fifo=%d", x, y, scanline[y][x], fifo[y][x])
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package gb
+
+import (
+	"github.com/hobbiee/visualboy-go/internal/core/gb/cartridge"
+)
+
+// RumbleListener recebe, a cada frame, o estado do motor de vibração e a
+// fração do frame em que ele esteve ligado (0 a 1). Os jogos controlam a
+// intensidade ligando e desligando o motor dentro do frame.
+type RumbleListener func(on bool, duty float64)
+
+// SetRumbleListener define o callback do motor de vibração. Ele é chamado
+// enquanto o motor estiver ativo e uma última vez quando ele desliga.
+func (gb *GameBoy) SetRumbleListener(listener RumbleListener) {
+	gb.rumbleListener = listener
+	gb.rumbleActive = false
+}
+
+// HasRumble retorna se o cartucho carregado possui motor de vibração
+func (gb *GameBoy) HasRumble() bool {
+	rumbler, ok := gb.mmu.GetCartridge().(cartridge.Rumbler)
+	return ok && rumbler.HasRumble()
+}
+
+// reportRumble informa o estado do motor no fim de um frame
+func (gb *GameBoy) reportRumble() {
+	rumbler, ok := gb.mmu.GetCartridge().(cartridge.Rumbler)
+	if !ok || !rumbler.HasRumble() {
+		return
+	}
+
+	// RumbleFrame zera a contagem do frame mesmo sem listener
+	on, duty := rumbler.RumbleFrame()
+	if gb.rumbleListener == nil {
+		return
+	}
+
+	active := on || duty > 0
+	if active || gb.rumbleActive {
+		gb.rumbleListener(on, duty)
+	}
+	gb.rumbleActive = active
+}
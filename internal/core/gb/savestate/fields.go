@@ -0,0 +1,122 @@
+package savestate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// Marshal serializa, em ordem, os valores apontados por fields. Cada campo
+// é um ponteiro para um tipo de tamanho fixo aceito por encoding/binary
+// (ou um slice desses tipos); *int é gravado com 64 bits. Os componentes
+// usam a mesma lista de campos em Marshal e Unmarshal.
+func Marshal(fields ...any) ([]byte, error) {
+	size, err := Size(fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	for _, field := range fields {
+		if v, ok := field.(*int); ok {
+			binary.Write(buf, binary.LittleEndian, int64(*v))
+			continue
+		}
+		if flat, ok := flatten(field); ok {
+			field = flat
+		}
+		if err := binary.Write(buf, binary.LittleEndian, field); err != nil {
+			return nil, fmt.Errorf("erro ao serializar campo %T: %w", field, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal restaura os campos gravados por Marshal. O tamanho dos dados é
+// conferido antes de qualquer campo ser alterado.
+func Unmarshal(data []byte, fields ...any) error {
+	size, err := Size(fields...)
+	if err != nil {
+		return err
+	}
+	if len(data) != size {
+		return fmt.Errorf("estado com %d bytes (esperado %d)", len(data), size)
+	}
+
+	r := bytes.NewReader(data)
+	for _, field := range fields {
+		if v, ok := field.(*int); ok {
+			var value int64
+			binary.Read(r, binary.LittleEndian, &value)
+			*v = int(value)
+			continue
+		}
+		if flat, ok := flatten(field); ok {
+			field = flat
+		}
+		if err := binary.Read(r, binary.LittleEndian, field); err != nil {
+			return fmt.Errorf("erro ao restaurar campo %T: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// Size retorna o tamanho em bytes dos campos serializados por Marshal
+func Size(fields ...any) (int, error) {
+	total := 0
+	for _, field := range fields {
+		if _, ok := field.(*int); ok {
+			total += 8
+			continue
+		}
+		size := binary.Size(field)
+		if size < 0 {
+			return 0, fmt.Errorf("campo de save state sem tamanho fixo: %T", field)
+		}
+		total += size
+	}
+	return total, nil
+}
+
+// flatten retorna um slice sobre a memória de um array de inteiros (mesmo
+// multidimensional, como os buffers de tela). encoding/binary percorre
+// arrays elemento a elemento por reflexão, mas copia slices de inteiros
+// diretamente; os bytes gravados são os mesmos nos dois casos.
+func flatten(field any) (any, bool) {
+	v := reflect.ValueOf(field)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Array {
+		return nil, false
+	}
+
+	n := 1
+	t := v.Elem().Type()
+	for ; t.Kind() == reflect.Array; t = t.Elem() {
+		n *= t.Len()
+	}
+	if n == 0 {
+		return nil, false
+	}
+
+	p := v.UnsafePointer()
+	switch t.Kind() {
+	case reflect.Uint8:
+		return unsafe.Slice((*uint8)(p), n), true
+	case reflect.Int8:
+		return unsafe.Slice((*int8)(p), n), true
+	case reflect.Uint16:
+		return unsafe.Slice((*uint16)(p), n), true
+	case reflect.Int16:
+		return unsafe.Slice((*int16)(p), n), true
+	case reflect.Uint32:
+		return unsafe.Slice((*uint32)(p), n), true
+	case reflect.Int32:
+		return unsafe.Slice((*int32)(p), n), true
+	case reflect.Uint64:
+		return unsafe.Slice((*uint64)(p), n), true
+	case reflect.Int64:
+		return unsafe.Slice((*int64)(p), n), true
+	}
+	return nil, false
+}
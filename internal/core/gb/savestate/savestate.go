@@ -3,16 +3,37 @@ package savestate
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"time"
+
+	"github.com/hobbiee/visualboy-go/internal/core/statefile"
 )
 
 // Constantes do save state
 const (
-	SaveStateVersion = 1
+	SaveStateVersion = 3
 	SaveStateMagic   = "VBGO" // VisualBoy Go
 )
 
+// format é o contêiner dos save states do Game Boy. A versão 2 gravava as
+// seções em posições fixas; a versão 3 identifica cada seção por uma tag.
+var format = newFormat()
+
+func newFormat() *statefile.Format {
+	f := statefile.NewFormat(SaveStateMagic, SaveStateVersion)
+	f.Register(2, migrateV2)
+	return f
+}
+
+// Component é implementado por cada parte da máquina que guarda estado:
+// CPU, MMU, cartucho, LCD, timer, APU, input, interrupções, serial e SGB.
+// LoadState recebe exatamente os bytes gerados por SaveState.
+type Component interface {
+	SaveState() ([]byte, error)
+	LoadState(data []byte) error
+}
+
 // SaveState representa um estado salvo do emulador
 type SaveState struct {
 	// Header
@@ -20,120 +41,43 @@ type SaveState struct {
 	Version   uint32
 	Timestamp int64
 	ROMTitle  [16]byte
-
-	// Estado do CPU
-	CPU CPUState
-
-	// Estado da memória
-	Memory MemoryState
-
-	// Estado do LCD
-	LCD LCDState
-
-	// Estado do Timer
-	Timer TimerState
-
-	// Estado do Input
-	Input InputState
-
-	// Estado do Sound
-	Sound SoundState
-
-	// Estado das Interrupções
-	Interrupts InterruptState
-}
-
-// CPUState representa o estado do CPU
-type CPUState struct {
-	// Registradores
-	A, B, C, D, E, H, L uint8
-	SP, PC              uint16
-
-	// Flags
-	FlagZ, FlagN, FlagH, FlagC bool
-
-	// Estado
-	Halted            bool
-	InterruptsEnabled bool
-}
-
-// MemoryState representa o estado da memória
-type MemoryState struct {
-	// Work RAM
-	WRAM [0x2000]uint8
-
-	// High RAM
-	HRAM [0x7F]uint8
-
-	// Estado do cartucho
-	CurrentROMBank uint16
-	CurrentRAMBank uint16
-	RAMEnabled     uint8 // 0 ou 1
-	MBCMode        uint16
-
-	// RAM externa (tamanho fixo para serialização)
-	ExternalRAMSize uint16
-	ExternalRAM     [0x8000]uint8 // Máximo 32KB
-}
-
-// LCDState representa o estado do LCD
-type LCDState struct {
-	// Registradores
-	LCDC, STAT, SCY, SCX, LY, LYC uint8
-	BGP, OBP0, OBP1               uint8
-	WY, WX                        uint8
-
-	// Estado interno
-	Mode   uint8
-	Cycles uint32
-
-	// VRAM
-	VRAM [0x2000]uint8
-
-	// OAM
-	OAM [0xA0]uint8
-}
-
-// TimerState representa o estado do timer
-type TimerState struct {
-	// Registradores
-	DIV, TIMA, TMA, TAC uint8
-
-	// Contadores internos
-	DIVCounter  uint32
-	TIMACounter uint32
-}
-
-// InputState representa o estado do input
-type InputState struct {
-	// Registrador
-	JOYP uint8
-
-	// Estado dos botões
-	Buttons [8]bool
+	ROMHash   []byte // SHA-1 da ROM; vazio em estados da versão 2
+
+	// Estado de cada componente, gerado pelo seu SaveState
+	CPU        []byte
+	Memory     []byte
+	Cartridge  []byte
+	LCD        []byte
+	Timer      []byte
+	Sound      []byte
+	Input      []byte
+	Interrupts []byte
+	Serial     []byte
+	SGB        []byte // Vazio fora do modo Super Game Boy
+
+	// Contadores do GameBoy (frames e ciclos)
+	Emulator []byte
 }
 
-// SoundState representa o estado do som
-type SoundState struct {
-	// Registradores principais
-	NR50, NR51, NR52 uint8
-
-	// Wave RAM
-	WaveRAM [16]uint8
+// Tags das seções do arquivo
+const (
+	tagInfo = "INFO" // Timestamp e título da ROM
+)
 
-	// Estado interno
-	FrameSequencer uint32
-	Cycles         uint32
+// section associa a tag de uma seção ao campo do save state
+type section struct {
+	tag  string
+	data *[]byte
 }
 
-// InterruptState representa o estado das interrupções
-type InterruptState struct {
-	// Registradores
-	InterruptFlag   uint8
-	InterruptEnable uint8
-
-	// Estado
-	MasterEnable bool
+// sections retorna as seções de componentes na ordem do arquivo
+func (ss *SaveState) sections() []section {
+	return []section{
+		{"CPU ", &ss.CPU}, {"MEM ", &ss.Memory}, {"CART", &ss.Cartridge},
+		{"LCD ", &ss.LCD}, {"TIMR", &ss.Timer}, {"SND ", &ss.Sound},
+		{"JOYP", &ss.Input}, {"INT ", &ss.Interrupts}, {"SER ", &ss.Serial},
+		{"SGB ", &ss.SGB}, {"EMU ", &ss.Emulator},
+	}
 }
 
 // NewSaveState cria um novo save state vazio
@@ -145,46 +89,89 @@ func NewSaveState() *SaveState {
 	return ss
 }
 
-// Serialize serializa o save state para bytes
+// Serialize serializa o save state para bytes: o header seguido das
+// seções não vazias, cada uma com a sua tag e o seu tamanho
 func (ss *SaveState) Serialize() ([]byte, error) {
-	var buf bytes.Buffer
-
-	// Escreve o save state usando binary encoding
-	err := binary.Write(&buf, binary.LittleEndian, ss)
+	info, err := Marshal(&ss.Timestamp, &ss.ROMTitle)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao serializar save state: %w", err)
 	}
 
-	return buf.Bytes(), nil
-}
-
-// Deserialize deserializa bytes para um save state
-func Deserialize(data []byte) (*SaveState, error) {
-	if len(data) < 16 {
-		return nil, fmt.Errorf("dados de save state muito pequenos")
+	file := &statefile.File{}
+	file.Add(tagInfo, info)
+	if len(ss.ROMHash) > 0 {
+		file.Add(statefile.TagROMHash, ss.ROMHash)
+	}
+	for _, section := range ss.sections() {
+		if len(*section.data) > 0 {
+			file.Add(section.tag, *section.data)
+		}
 	}
 
-	ss := &SaveState{}
-	buf := bytes.NewReader(data)
+	return format.Encode(file), nil
+}
 
-	// Lê o save state usando binary encoding
-	err := binary.Read(buf, binary.LittleEndian, ss)
+// Deserialize deserializa bytes para um save state. Estados de versões
+// anteriores são migrados; seções desconhecidas são ignoradas.
+func Deserialize(data []byte) (*SaveState, error) {
+	file, err := format.Decode(data)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao deserializar save state: %w", err)
 	}
 
-	// Verifica magic e versão
-	if string(ss.Magic[:]) != SaveStateMagic {
-		return nil, fmt.Errorf("magic inválido no save state")
+	ss := NewSaveState()
+	if err := Unmarshal(file.Get(tagInfo), &ss.Timestamp, &ss.ROMTitle); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar save state (%s): %w", tagInfo, err)
 	}
-
-	if ss.Version != SaveStateVersion {
-		return nil, fmt.Errorf("versão de save state não suportada: %d", ss.Version)
+	ss.ROMHash = file.Get(statefile.TagROMHash)
+	for _, section := range ss.sections() {
+		*section.data = file.Get(section.tag)
 	}
 
 	return ss, nil
 }
 
+// migrateV2 converte as seções em posições fixas da versão 2 em seções
+// com tag. Estados da versão 2 não guardam o hash da ROM.
+func migrateV2(payload []byte) ([]byte, error) {
+	if len(payload) < 24 {
+		return nil, fmt.Errorf("header truncado")
+	}
+
+	file := &statefile.File{}
+	file.Add(tagInfo, payload[:24]) // Timestamp (int64) e título
+	payload = payload[24:]
+
+	for i, section := range (&SaveState{}).sections() {
+		if len(payload) < 4 {
+			return nil, fmt.Errorf("seção %d ausente", i)
+		}
+		size := binary.LittleEndian.Uint32(payload)
+		payload = payload[4:]
+		if int64(size) > int64(len(payload)) {
+			return nil, fmt.Errorf("seção %d truncada", i)
+		}
+		if size > 0 {
+			file.Add(section.tag, payload[:size])
+		}
+		payload = payload[size:]
+	}
+
+	return file.Payload(), nil
+}
+
+// CheckROM verifica se o estado foi salvo com a ROM de hash informado.
+// Estados sem hash (versão 2) são aceitos.
+func (ss *SaveState) CheckROM(hash []byte) error {
+	if len(ss.ROMHash) == 0 || bytes.Equal(ss.ROMHash, hash) {
+		return nil
+	}
+	return &statefile.ROMMismatchError{
+		Saved:  hex.EncodeToString(ss.ROMHash),
+		Loaded: hex.EncodeToString(hash),
+	}
+}
+
 // GetTimestamp retorna o timestamp do save state
 func (ss *SaveState) GetTimestamp() time.Time {
 	return time.Unix(ss.Timestamp, 0)
@@ -222,7 +209,7 @@ func (ss *SaveState) Validate() error {
 
 	// Verifica versão
 	if ss.Version != SaveStateVersion {
-		return fmt.Errorf("versão não suportada: %d", ss.Version)
+		return &statefile.VersionError{Version: ss.Version, Current: SaveStateVersion}
 	}
 
 	// Verifica timestamp
@@ -230,14 +217,9 @@ func (ss *SaveState) Validate() error {
 		return fmt.Errorf("timestamp inválido: %d", ss.Timestamp)
 	}
 
-	// Verifica registradores do CPU
-	if ss.CPU.SP > 0xFFFF || ss.CPU.PC > 0xFFFF {
-		return fmt.Errorf("registradores de CPU inválidos")
-	}
-
-	// Verifica estado do LCD
-	if ss.LCD.Mode > 3 {
-		return fmt.Errorf("modo LCD inválido: %d", ss.LCD.Mode)
+	// Verifica componentes obrigatórios
+	if len(ss.CPU) == 0 || len(ss.Memory) == 0 || len(ss.LCD) == 0 {
+		return fmt.Errorf("save state sem o estado do CPU, da memória ou do LCD")
 	}
 
 	return nil
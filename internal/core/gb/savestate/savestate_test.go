@@ -0,0 +1,149 @@
+package savestate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/statefile"
+)
+
+func TestMarshalFields(t *testing.T) {
+	var (
+		a     uint8     = 0x12
+		b     int       = -5
+		c     bool      = true
+		table [3]uint16 = [3]uint16{1, 2, 3}
+	)
+
+	data, err := Marshal(&a, &b, &c, &table)
+	if err != nil {
+		t.Fatalf("erro ao serializar: %v", err)
+	}
+	if len(data) != 1+8+1+6 {
+		t.Errorf("esperado %d bytes, obtido %d", 16, len(data))
+	}
+
+	var a2 uint8
+	var b2 int
+	var c2 bool
+	var table2 [3]uint16
+	if err := Unmarshal(data, &a2, &b2, &c2, &table2); err != nil {
+		t.Fatalf("erro ao restaurar: %v", err)
+	}
+	if a2 != a || b2 != b || c2 != c || table2 != table {
+		t.Errorf("campos restaurados diferentes: %v %v %v %v", a2, b2, c2, table2)
+	}
+
+	// Tamanho errado não altera nenhum campo
+	a2 = 0
+	if err := Unmarshal(data[:len(data)-1], &a2, &b2, &c2, &table2); err == nil {
+		t.Error("dados truncados deveriam retornar erro")
+	}
+	if a2 != 0 {
+		t.Error("nenhum campo deveria ser alterado com dados truncados")
+	}
+
+	var unsized []any
+	if _, err := Marshal(&unsized); err == nil {
+		t.Error("campo sem tamanho fixo deveria retornar erro")
+	}
+}
+
+func TestSerializeSections(t *testing.T) {
+	ss := NewSaveState()
+	ss.SetROMTitle("TESTE")
+	ss.CPU = []byte{1, 2, 3}
+	ss.Memory = []byte{4}
+	ss.LCD = []byte{5, 6}
+
+	data, err := ss.Serialize()
+	if err != nil {
+		t.Fatalf("erro ao serializar: %v", err)
+	}
+
+	loaded, err := Deserialize(data)
+	if err != nil {
+		t.Fatalf("erro ao deserializar: %v", err)
+	}
+	if err := loaded.Validate(); err != nil {
+		t.Errorf("save state deveria ser válido: %v", err)
+	}
+	if loaded.GetROMTitle() != "TESTE" || !bytes.Equal(loaded.CPU, ss.CPU) || !bytes.Equal(loaded.LCD, ss.LCD) {
+		t.Errorf("seções diferentes após deserializar: %v", loaded)
+	}
+	if len(loaded.SGB) != 0 {
+		t.Error("seção vazia deveria continuar vazia")
+	}
+
+	if _, err := Deserialize(data[:len(data)-1]); err == nil {
+		t.Error("save state truncado deveria retornar erro")
+	}
+
+	ss.CPU = nil
+	if err := ss.Validate(); err == nil {
+		t.Error("save state sem CPU deveria ser inválido")
+	}
+}
+
+func TestMigrateVersion2(t *testing.T) {
+	// Arquivo da versão 2: header fixo e 11 seções em posições fixas
+	var buf bytes.Buffer
+	buf.WriteString(SaveStateMagic)
+	binary.Write(&buf, binary.LittleEndian, uint32(2))
+	binary.Write(&buf, binary.LittleEndian, int64(1234))
+	buf.Write(append([]byte("TESTE"), make([]byte, 11)...))
+	sections := [][]byte{{1, 2}, {3}, nil, {4}, nil, nil, nil, nil, nil, nil, {5, 6}}
+	for _, section := range sections {
+		binary.Write(&buf, binary.LittleEndian, uint32(len(section)))
+		buf.Write(section)
+	}
+
+	ss, err := Deserialize(buf.Bytes())
+	if err != nil {
+		t.Fatalf("erro ao migrar da versão 2: %v", err)
+	}
+	if ss.Version != SaveStateVersion || ss.Timestamp != 1234 || ss.GetROMTitle() != "TESTE" {
+		t.Errorf("header migrado incorreto: %v", ss)
+	}
+	if !bytes.Equal(ss.CPU, []byte{1, 2}) || !bytes.Equal(ss.LCD, []byte{4}) || !bytes.Equal(ss.Emulator, []byte{5, 6}) {
+		t.Errorf("seções migradas incorretas: %v %v %v", ss.CPU, ss.LCD, ss.Emulator)
+	}
+	if len(ss.ROMHash) != 0 || ss.CheckROM([]byte{1}) != nil {
+		t.Error("estado da versão 2 não tem hash e deveria aceitar qualquer ROM")
+	}
+
+	if _, err := Deserialize(buf.Bytes()[:buf.Len()-1]); err == nil {
+		t.Error("versão 2 truncada deveria retornar erro")
+	}
+}
+
+func TestVersionAndROMErrors(t *testing.T) {
+	ss := NewSaveState()
+	ss.ROMHash = []byte{0xAA}
+	ss.CPU = []byte{1}
+	data, _ := ss.Serialize()
+
+	// Versão mais nova que a do emulador
+	newer := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(newer[4:], SaveStateVersion+1)
+	if _, err := Deserialize(newer); !errors.Is(err, statefile.ErrNewerVersion) {
+		t.Errorf("esperado ErrNewerVersion, obtido %v", err)
+	}
+
+	// Seção desconhecida é ignorada
+	extra := append(append([]byte(nil), data...), 'N', 'O', 'V', 'A', 1, 0, 0, 0, 0xFF)
+	loaded, err := Deserialize(extra)
+	if err != nil || !bytes.Equal(loaded.CPU, ss.CPU) {
+		t.Errorf("seção desconhecida deveria ser ignorada: %v", err)
+	}
+
+	var mismatch *statefile.ROMMismatchError
+	if err := loaded.CheckROM([]byte{0xBB}); !errors.As(err, &mismatch) || mismatch.Saved != "aa" {
+		t.Errorf("esperado ROMMismatchError, obtido %v", err)
+	}
+	if err := loaded.CheckROM([]byte{0xAA}); err != nil {
+		t.Errorf("mesma ROM deveria ser aceita: %v", err)
+	}
+}
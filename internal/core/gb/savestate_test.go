@@ -0,0 +1,340 @@
+package gb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
+	"github.com/hobbiee/visualboy-go/internal/core/statefile"
+)
+
+// newBusyROM cria uma ROM MBC1+RAM que liga o som e, em loop, copia o DIV
+// para o scroll, a frequência do canal 1, a WRAM e a RAM do cartucho
+func newBusyROM() []uint8 {
+	rom := make([]uint8, 0x8000)
+	rom[0x147] = 0x03 // MBC1+RAM+BATTERY
+	rom[0x149] = 0x02 // 8KB
+	copy(rom[0x100:], []uint8{
+		0x3E, 0x80, 0xE0, 0x26, // NR52 = 0x80
+		0x3E, 0x77, 0xE0, 0x24, // NR50 = 0x77
+		0x3E, 0xFF, 0xE0, 0x25, // NR51 = 0xFF
+		0x3E, 0x80, 0xE0, 0x11, // NR11: duty 50%
+		0x3E, 0xF3, 0xE0, 0x12, // NR12: volume 15, decrescente
+		0x3E, 0x87, 0xE0, 0x14, // NR14: trigger
+		0x3E, 0xF1, 0xE0, 0x21, // NR42
+		0x3E, 0x55, 0xE0, 0x22, // NR43
+		0x3E, 0x80, 0xE0, 0x23, // NR44: trigger
+		0x3E, 0x05, 0xE0, 0x07, // TAC: timer ligado
+		0x3E, 0x0A, 0xEA, 0x00, 0x00, // Habilita a RAM do cartucho
+		0x21, 0x00, 0xC0, // LD HL, 0xC000
+		// loop (0x0130):
+		0xF0, 0x04, // LDH A, (DIV)
+		0xE0, 0x43, // LDH (SCX), A
+		0xE0, 0x13, // LDH (NR13), A
+		0x22,             // LD (HL+), A
+		0xEA, 0x00, 0xA0, // LD (0xA000), A
+		0x7C,       // LD A, H
+		0xE6, 0x1F, // AND 0x1F
+		0xF6, 0xC0, // OR 0xC0
+		0x67,       // LD H, A
+		0x18, 0xEE, // JR loop
+	})
+	return rom
+}
+
+// newBusyGameBoy carrega a ROM de teste com tiles e um sprite na tela
+func newBusyGameBoy(t testing.TB, renderer video.Renderer) *GameBoy {
+	config := DefaultConfig()
+	config.EnableVSync = false
+	config.Renderer = renderer
+	gameboy := NewGameBoy(config)
+	if err := gameboy.LoadROM(newBusyROM()); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+
+	mmu := gameboy.mmu
+	mmu.Write(video.RegLCDC, 0x00)
+	for row := uint16(0); row < 8; row++ {
+		mmu.Write(0x8010+row*2, 0xAA)
+		mmu.Write(0x8011+row*2, 0x3C)
+	}
+	for i := uint16(0); i < 32*32; i += 3 {
+		mmu.Write(0x9800+i, 0x01)
+	}
+	mmu.Write(0xFE00, 50+16)
+	mmu.Write(0xFE01, 40+8)
+	mmu.Write(0xFE02, 0x01)
+	mmu.Write(video.RegBGP, 0xE4)
+	mmu.Write(video.RegOBP0, 0x1B)
+	mmu.Write(video.RegLCDC, 0x93)
+
+	gameboy.Start()
+	return gameboy
+}
+
+// recording guarda os frames e o áudio produzidos
+type recording struct {
+	frames [][144][160]uint8
+	audio  []int16
+}
+
+// record executa frames quadros e retorna o vídeo e o áudio gerados
+func record(gameboy *GameBoy, frames int) recording {
+	var rec recording
+	gameboy.SetFrameCallback(func(frame [144][160]uint8) {
+		rec.frames = append(rec.frames, frame)
+	})
+	gameboy.SetAudioCallback(func(samples []int16) {
+		rec.audio = append(rec.audio, samples...)
+	})
+	for i := 0; i < frames; i++ {
+		gameboy.Step()
+	}
+	return rec
+}
+
+// stateSectionsOf retorna as seções de um save state, sem o header
+func stateSectionsOf(t *testing.T, data []byte) *savestate.SaveState {
+	ss, err := savestate.Deserialize(data)
+	if err != nil {
+		t.Fatalf("Failed to deserialize state: %v", err)
+	}
+	ss.Timestamp = 0
+	return ss
+}
+
+// corruptState altera as seções de um save state mantendo o header válido
+func corruptState(t *testing.T, data []byte, change func(ss *savestate.SaveState)) []byte {
+	ss, err := savestate.Deserialize(data)
+	if err != nil {
+		t.Fatalf("Failed to deserialize state: %v", err)
+	}
+	change(ss)
+	corrupt, err := ss.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize state: %v", err)
+	}
+	return corrupt
+}
+
+// TestSaveStateRoundTrip verifica que save -> N frames e load -> N frames
+// produzem exatamente o mesmo vídeo, áudio e estado final
+func TestSaveStateRoundTrip(t *testing.T) {
+	for _, renderer := range []video.Renderer{video.RendererScanline, video.RendererFIFO} {
+		t.Run(renderer.String(), func(t *testing.T) {
+			gameboy := newBusyGameBoy(t, renderer)
+			record(gameboy, 5)
+
+			// Salva no meio de um frame, durante o modo 3
+			for i := 0; i < 1234 || gameboy.mmu.GetLCD().GetMode() != video.ModeVRAM; i++ {
+				gameboy.mmu.Step(gameboy.cpu.Step())
+			}
+			state, err := gameboy.SaveState()
+			if err != nil {
+				t.Fatalf("Failed to save state: %v", err)
+			}
+
+			want := record(gameboy, 20)
+			wantState, _ := gameboy.SaveState()
+
+			// Mesma instância e uma instância nova com a mesma ROM
+			restored := newBusyGameBoy(t, renderer)
+			for name, target := range map[string]*GameBoy{"same": gameboy, "new": restored} {
+				if err := target.LoadState(state); err != nil {
+					t.Fatalf("%s: failed to load state: %v", name, err)
+				}
+				got := record(target, 20)
+
+				if len(got.frames) != len(want.frames) {
+					t.Fatalf("%s: expected %d frames, got %d", name, len(want.frames), len(got.frames))
+				}
+				for i := range want.frames {
+					if got.frames[i] != want.frames[i] {
+						t.Fatalf("%s: frame %d differs after load", name, i)
+					}
+				}
+				if len(want.audio) == 0 {
+					t.Fatal("test ROM should produce audio")
+				}
+				if !reflect.DeepEqual(got.audio, want.audio) {
+					t.Errorf("%s: audio differs after load (%d vs %d samples)", name, len(got.audio), len(want.audio))
+				}
+
+				gotState, _ := target.SaveState()
+				if !reflect.DeepEqual(stateSectionsOf(t, gotState), stateSectionsOf(t, wantState)) {
+					t.Errorf("%s: machine state differs after running from the loaded state", name)
+				}
+			}
+		})
+	}
+}
+
+// TestSaveStateROMOnly verifica o save state de uma ROM sem mapeador nem
+// RAM, cuja seção do cartucho é vazia
+func TestSaveStateROMOnly(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableVSync = false
+	gameboy := NewGameBoy(config)
+	if err := gameboy.LoadROM(newLoopROM(0x00)); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+	gameboy.Start()
+	record(gameboy, 3)
+
+	state, err := gameboy.SaveState()
+	if err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if len(stateSectionsOf(t, state).Cartridge) != 0 {
+		t.Fatal("ROM-only cartridge should save an empty section")
+	}
+	want := record(gameboy, 5)
+	wantState, _ := gameboy.SaveState()
+
+	if err := gameboy.LoadState(state); err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	got := record(gameboy, 5)
+	if !reflect.DeepEqual(got.frames, want.frames) {
+		t.Error("frames differ after load")
+	}
+	gotState, _ := gameboy.SaveState()
+	if !reflect.DeepEqual(stateSectionsOf(t, gotState), stateSectionsOf(t, wantState)) {
+		t.Error("machine state differs after running from the loaded state")
+	}
+}
+
+// TestLoadStateRejectsCorruptSection verifica que uma seção inválida não
+// deixa a máquina em um estado misturado
+func TestLoadStateRejectsCorruptSection(t *testing.T) {
+	gameboy := newBusyGameBoy(t, video.RendererScanline)
+	record(gameboy, 2)
+	before, _ := gameboy.SaveState()
+
+	corrupt := corruptState(t, before, func(ss *savestate.SaveState) {
+		ss.CPU[2] ^= 0xFF // Altera o registrador B salvo
+		ss.LCD = ss.LCD[:len(ss.LCD)-1]
+	})
+	if err := gameboy.LoadState(corrupt); err == nil {
+		t.Fatal("expected an error for a truncated LCD section")
+	}
+	after, _ := gameboy.SaveState()
+	if !bytes.Equal(stateSectionsOf(t, after).CPU, stateSectionsOf(t, before).CPU) {
+		t.Error("CPU state should be restored after a failed load")
+	}
+
+	// Estado salvo sem cartucho não combina com a ROM carregada
+	noCart := corruptState(t, before, func(ss *savestate.SaveState) {
+		ss.Cartridge = nil
+	})
+	if err := gameboy.LoadState(noCart); err == nil {
+		t.Error("expected an error for a state without cartridge section")
+	}
+}
+
+// TestLoadStateRejectsOtherROM verifica que o estado de outra ROM é
+// recusado com um erro tipado
+func TestLoadStateRejectsOtherROM(t *testing.T) {
+	gameboy := newBusyGameBoy(t, video.RendererScanline)
+	state, err := gameboy.SaveState()
+	if err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	other := NewGameBoy(DefaultConfig())
+	rom := newBusyROM()
+	rom[0x150] = 0x01
+	if err := other.LoadROM(rom); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+
+	var mismatch *statefile.ROMMismatchError
+	if err := other.LoadState(state); !errors.As(err, &mismatch) {
+		t.Fatalf("expected ROMMismatchError, got %v", err)
+	}
+	hash := gameboy.GetROMHash()
+	if mismatch.Saved != hex.EncodeToString(hash[:]) {
+		t.Errorf("expected saved hash %x, got %s", hash, mismatch.Saved)
+	}
+}
+
+// TestRewind verifica que o rewind restaura exatamente o estado gravado
+// naquele frame e que a emulação segue igual a partir dele
+func TestRewind(t *testing.T) {
+	gameboy := newBusyGameBoy(t, video.RendererScanline)
+	if gameboy.rewind != nil {
+		t.Fatal("rewind should be disabled by default")
+	}
+	config := gameboy.GetConfig()
+	config.EnableRewind = true
+	gameboy.SetConfig(config)
+
+	states := make(map[uint64][]byte)
+	for i := 0; i < 30; i++ {
+		gameboy.Step()
+		if gameboy.frameCount%2 == 0 {
+			states[gameboy.frameCount], _ = gameboy.SaveState()
+		}
+	}
+	current := gameboy.frameCount
+	want := record(gameboy, 10)
+
+	var presented int
+	gameboy.SetFrameCallback(func([144][160]uint8) { presented++ })
+	if err := gameboy.Rewind(int(gameboy.frameCount - current + 6)); err != nil {
+		t.Fatalf("Failed to rewind: %v", err)
+	}
+	if gameboy.frameCount != current-6 {
+		t.Fatalf("expected frame %d after rewind, got %d", current-6, gameboy.frameCount)
+	}
+	if presented != 1 {
+		t.Errorf("expected the restored frame to be presented once, got %d", presented)
+	}
+	got, _ := gameboy.SaveState()
+	if !reflect.DeepEqual(stateSectionsOf(t, got), stateSectionsOf(t, states[current-6])) {
+		t.Error("machine state differs from the one saved at the rewound frame")
+	}
+
+	// Volta ao frame atual e segue igual
+	record(gameboy, 6)
+	if again := record(gameboy, 10); !reflect.DeepEqual(again.frames, want.frames) {
+		t.Error("frames differ when running again after rewind")
+	}
+
+	// Histórico limitado ao snapshot mais antigo; LoadState o descarta
+	if err := gameboy.Rewind(1000); err != nil || gameboy.frameCount != 2 {
+		t.Errorf("expected the oldest snapshot (frame 2), got frame %d (%v)", gameboy.frameCount, err)
+	}
+	gameboy.LoadState(states[20])
+	gameboy.Rewind(4)
+	if gameboy.frameCount != 20 {
+		t.Errorf("history should be cleared by LoadState, rewound to frame %d", gameboy.frameCount)
+	}
+}
+
+// BenchmarkRewindOverhead compara o custo de um frame com e sem os
+// snapshots de rewind
+func BenchmarkRewindOverhead(b *testing.B) {
+	for _, enabled := range []bool{false, true} {
+		name := "off"
+		if enabled {
+			name = "on"
+		}
+		b.Run(name, func(b *testing.B) {
+			gameboy := newBusyGameBoy(b, video.RendererScanline)
+			config := gameboy.GetConfig()
+			config.EnableRewind = enabled
+			config.RewindInterval = 1
+			gameboy.SetConfig(config)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				gameboy.Step()
+			}
+		})
+	}
+}
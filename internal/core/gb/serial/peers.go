@@ -0,0 +1,131 @@
+package serial
+
+import "sync"
+
+// NullPeer representa a porta sem cabo: toda transferência recebe 0xFF
+type NullPeer struct{}
+
+// NewNullPeer cria uma nova instância do NullPeer
+func NewNullPeer() *NullPeer {
+	return &NullPeer{}
+}
+
+// Exchange implementa Peer
+func (p *NullPeer) Exchange(out uint8) uint8 {
+	return 0xFF
+}
+
+// Attach implementa Peer
+func (p *NullPeer) Attach(device Device) {}
+
+// Close implementa Peer
+func (p *NullPeer) Close() error {
+	return nil
+}
+
+// LoopbackPeer liga a saída à entrada do próprio cabo: cada byte enviado é
+// recebido de volta. Opcionalmente registra os bytes enviados.
+type LoopbackPeer struct {
+	mu      sync.Mutex
+	sent    []uint8
+	onWrite func(uint8)
+}
+
+// NewLoopbackPeer cria uma nova instância do LoopbackPeer
+func NewLoopbackPeer() *LoopbackPeer {
+	return &LoopbackPeer{}
+}
+
+// SetWriteCallback define uma função chamada para cada byte enviado
+func (p *LoopbackPeer) SetWriteCallback(callback func(uint8)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onWrite = callback
+}
+
+// Exchange implementa Peer
+func (p *LoopbackPeer) Exchange(out uint8) uint8 {
+	p.mu.Lock()
+	p.sent = append(p.sent, out)
+	callback := p.onWrite
+	p.mu.Unlock()
+
+	if callback != nil {
+		callback(out)
+	}
+	return out
+}
+
+// Sent retorna os bytes enviados desde a criação
+func (p *LoopbackPeer) Sent() []uint8 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sent := make([]uint8, len(p.sent))
+	copy(sent, p.sent)
+	return sent
+}
+
+// Attach implementa Peer
+func (p *LoopbackPeer) Attach(device Device) {}
+
+// Close implementa Peer
+func (p *LoopbackPeer) Close() error {
+	return nil
+}
+
+// PairPeer é uma ponta de um cabo link entre duas instâncias no mesmo
+// processo. O mestre transfere diretamente com o Device da outra ponta.
+type PairPeer struct {
+	mu     sync.Mutex
+	device Device
+	other  *PairPeer
+	closed bool
+}
+
+// NewPairPeers cria as duas pontas de um cabo link em processo
+func NewPairPeers() (*PairPeer, *PairPeer) {
+	a := &PairPeer{}
+	b := &PairPeer{}
+	a.other = b
+	b.other = a
+	return a, b
+}
+
+// Exchange implementa Peer
+func (p *PairPeer) Exchange(out uint8) uint8 {
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return 0xFF
+	}
+
+	p.other.mu.Lock()
+	device := p.other.device
+	closed = p.other.closed
+	p.other.mu.Unlock()
+
+	if device == nil || closed {
+		return 0xFF
+	}
+	return device.ReceiveExternal(out)
+}
+
+// Attach implementa Peer
+func (p *PairPeer) Attach(device Device) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.device = device
+}
+
+// Close implementa Peer
+func (p *PairPeer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	return nil
+}
@@ -0,0 +1,241 @@
+package serial
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
+)
+
+// Constantes do Serial
+const (
+	// Registradores
+	RegSB = 0xFF01 // Serial Transfer Data
+	RegSC = 0xFF02 // Serial Transfer Control
+
+	// Flags do SC
+	SCTransferStart = 1 << 7 // Transferência em andamento/solicitada
+	SCFastClock     = 1 << 1 // Clock rápido (apenas CGB)
+	SCInternalClock = 1 << 0 // Clock interno (mestre)
+
+	// Ciclos de CPU por bit transferido
+	CyclesPerBit     = 512 // 8192 Hz
+	CyclesPerBitFast = 16  // 262144 Hz (CGB)
+
+	// Interrupção serial (bit 3 de IF)
+	InterruptSerial = 0x08
+)
+
+// Peer representa o outro lado do cabo link
+type Peer interface {
+	// Exchange transfere um byte com o clock interno (mestre) e retorna o
+	// byte recebido do outro lado. Sem ninguém conectado, retorna 0xFF.
+	Exchange(out uint8) uint8
+
+	// Attach conecta o controlador local para responder às transferências
+	// iniciadas pelo outro lado (clock externo)
+	Attach(device Device)
+
+	// Close desconecta o cabo
+	Close() error
+}
+
+// Device representa o controlador que recebe transferências com clock externo
+type Device interface {
+	// ReceiveExternal recebe o byte do mestre e retorna o byte de SB. Se não
+	// houver transferência pendente com clock externo, retorna 0xFF.
+	ReceiveExternal(in uint8) uint8
+}
+
+// InterruptHandler define a interface para lidar com interrupções
+type InterruptHandler interface {
+	RequestInterrupt(interrupt uint8)
+}
+
+// Serial representa a porta serial (link cable) do Game Boy
+type Serial struct {
+	mu sync.Mutex
+
+	// Registradores
+	sb uint8 // Serial Transfer Data
+	sc uint8 // Serial Transfer Control
+
+	// Estado da transferência
+	cycles  int // Ciclos restantes para concluir o byte (clock interno)
+	cgbMode bool
+
+	peer             Peer
+	interruptHandler InterruptHandler
+}
+
+// NewSerial cria uma nova instância do Serial, inicialmente sem cabo
+func NewSerial(interruptHandler InterruptHandler) *Serial {
+	return &Serial{
+		peer:             NewNullPeer(),
+		interruptHandler: interruptHandler,
+	}
+}
+
+// Reset reinicia a porta serial para seu estado inicial
+func (s *Serial) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sb = 0x00
+	s.sc = 0x00
+	s.cycles = 0
+}
+
+// SetCGBMode habilita o clock rápido do Game Boy Color
+func (s *Serial) SetCGBMode(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cgbMode = enabled
+}
+
+// SetPeer conecta a porta serial a um Peer (nil desconecta o cabo)
+func (s *Serial) SetPeer(peer Peer) {
+	if peer == nil {
+		peer = NewNullPeer()
+	}
+	peer.Attach(s)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.peer = peer
+}
+
+// GetPeer retorna o Peer conectado
+func (s *Serial) GetPeer() Peer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.peer
+}
+
+// Step executa um ciclo da porta serial
+func (s *Serial) Step(cycles int) {
+	s.mu.Lock()
+	if !s.isMasterTransfer() {
+		s.mu.Unlock()
+		return
+	}
+
+	s.cycles -= cycles
+	if s.cycles > 0 {
+		s.mu.Unlock()
+		return
+	}
+
+	out := s.sb
+	peer := s.peer
+	s.mu.Unlock()
+
+	// O Peer pode chamar o outro lado, portanto a troca ocorre sem o lock
+	in := peer.Exchange(out)
+
+	s.mu.Lock()
+	s.complete(in)
+	s.mu.Unlock()
+}
+
+// ReceiveExternal implementa Device: recebe um byte do mestre conectado
+func (s *Serial) ReceiveExternal(in uint8) uint8 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sc&SCTransferStart == 0 || s.sc&SCInternalClock != 0 {
+		return 0xFF
+	}
+
+	out := s.sb
+	s.complete(in)
+	return out
+}
+
+// isMasterTransfer retorna se há uma transferência com clock interno
+func (s *Serial) isMasterTransfer() bool {
+	return s.sc&SCTransferStart != 0 && s.sc&SCInternalClock != 0
+}
+
+// complete conclui a transferência do byte atual e gera a interrupção
+func (s *Serial) complete(in uint8) {
+	s.sb = in
+	s.sc &^= SCTransferStart
+	s.cycles = 0
+
+	if s.interruptHandler != nil {
+		s.interruptHandler.RequestInterrupt(InterruptSerial)
+	}
+}
+
+// transferCycles retorna a duração de um byte com o clock interno
+func (s *Serial) transferCycles() int {
+	if s.cgbMode && s.sc&SCFastClock != 0 {
+		return CyclesPerBitFast * 8
+	}
+	return CyclesPerBit * 8
+}
+
+// ReadRegister lê um registrador serial
+func (s *Serial) ReadRegister(addr uint16) uint8 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch addr {
+	case RegSB:
+		return s.sb
+	case RegSC:
+		if s.cgbMode {
+			return s.sc | 0x7C
+		}
+		return s.sc | 0x7E
+	default:
+		return 0xFF
+	}
+}
+
+// WriteRegister escreve em um registrador serial
+func (s *Serial) WriteRegister(addr uint16, value uint8) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch addr {
+	case RegSB:
+		s.sb = value
+	case RegSC:
+		s.sc = value & (SCTransferStart | SCFastClock | SCInternalClock)
+		if s.isMasterTransfer() {
+			s.cycles = s.transferCycles()
+		}
+	}
+}
+
+// SaveState serializa os registradores e o byte em transferência. O
+// estado do periférico conectado não faz parte do save state.
+func (s *Serial) SaveState() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return savestate.Marshal(&s.sb, &s.sc, &s.cycles)
+}
+
+// LoadState restaura o estado gerado por SaveState
+func (s *Serial) LoadState(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := savestate.Unmarshal(data, &s.sb, &s.sc, &s.cycles); err != nil {
+		return fmt.Errorf("estado da porta serial inválido: %w", err)
+	}
+	return nil
+}
+
+// String retorna uma representação em string do estado da porta serial
+func (s *Serial) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fmt.Sprintf("Serial: SB=0x%02X SC=0x%02X Cycles=%d", s.sb, s.sc, s.cycles)
+}
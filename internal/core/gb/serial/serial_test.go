@@ -0,0 +1,175 @@
+package serial
+
+import (
+	"testing"
+	"time"
+)
+
+// mockInterrupts registra as interrupções solicitadas
+type mockInterrupts struct {
+	requested uint8
+}
+
+func (m *mockInterrupts) RequestInterrupt(interrupt uint8) {
+	m.requested |= interrupt
+}
+
+// startMaster inicia uma transferência com clock interno
+func startMaster(s *Serial, value uint8) {
+	s.WriteRegister(RegSB, value)
+	s.WriteRegister(RegSC, SCTransferStart|SCInternalClock)
+}
+
+func TestSerialNullPeerTiming(t *testing.T) {
+	ic := &mockInterrupts{}
+	s := NewSerial(ic)
+	startMaster(s, 0x42)
+
+	s.Step(CyclesPerBit*8 - 4)
+	if ic.requested != 0 || s.ReadRegister(RegSC)&SCTransferStart == 0 {
+		t.Fatal("transferência não deveria terminar antes de 8 bits")
+	}
+
+	s.Step(4)
+	if ic.requested&InterruptSerial == 0 {
+		t.Error("interrupção serial deveria ser solicitada")
+	}
+	if got := s.ReadRegister(RegSB); got != 0xFF {
+		t.Errorf("SB sem cabo: esperado 0xFF, obtido 0x%02X", got)
+	}
+	if got := s.ReadRegister(RegSC); got != 0x7F {
+		t.Errorf("SC após transferência: esperado 0x7F, obtido 0x%02X", got)
+	}
+}
+
+func TestSerialCGBFastClock(t *testing.T) {
+	ic := &mockInterrupts{}
+	s := NewSerial(ic)
+	s.SetCGBMode(true)
+
+	s.WriteRegister(RegSC, SCTransferStart|SCFastClock|SCInternalClock)
+	s.Step(CyclesPerBitFast * 8)
+	if ic.requested&InterruptSerial == 0 {
+		t.Error("clock rápido deveria concluir em 128 ciclos")
+	}
+}
+
+func TestSerialExternalClockWaits(t *testing.T) {
+	ic := &mockInterrupts{}
+	s := NewSerial(ic)
+
+	s.WriteRegister(RegSB, 0x11)
+	s.WriteRegister(RegSC, SCTransferStart)
+	s.Step(CyclesPerBit * 64)
+
+	if ic.requested != 0 {
+		t.Fatal("clock externo não deveria transferir sem mestre")
+	}
+
+	if out := s.ReceiveExternal(0x22); out != 0x11 {
+		t.Errorf("byte devolvido ao mestre: esperado 0x11, obtido 0x%02X", out)
+	}
+	if got := s.ReadRegister(RegSB); got != 0x22 {
+		t.Errorf("SB após transferência: esperado 0x22, obtido 0x%02X", got)
+	}
+	if ic.requested&InterruptSerial == 0 {
+		t.Error("escravo deveria receber a interrupção serial")
+	}
+
+	// Sem transferência pendente, o mestre recebe 0xFF
+	if out := s.ReceiveExternal(0x33); out != 0xFF {
+		t.Errorf("escravo inativo: esperado 0xFF, obtido 0x%02X", out)
+	}
+}
+
+func TestSerialLoopbackPeer(t *testing.T) {
+	s := NewSerial(&mockInterrupts{})
+	loopback := NewLoopbackPeer()
+	s.SetPeer(loopback)
+
+	for _, value := range []uint8{'O', 'K'} {
+		startMaster(s, value)
+		s.Step(CyclesPerBit * 8)
+		if got := s.ReadRegister(RegSB); got != value {
+			t.Errorf("loopback: esperado 0x%02X, obtido 0x%02X", value, got)
+		}
+	}
+
+	if got := string(loopback.Sent()); got != "OK" {
+		t.Errorf("bytes enviados: esperado %q, obtido %q", "OK", got)
+	}
+}
+
+func TestSerialPairPeers(t *testing.T) {
+	icA, icB := &mockInterrupts{}, &mockInterrupts{}
+	a, b := NewSerial(icA), NewSerial(icB)
+
+	peerA, peerB := NewPairPeers()
+	a.SetPeer(peerA)
+	b.SetPeer(peerB)
+
+	b.WriteRegister(RegSB, 0xB0)
+	b.WriteRegister(RegSC, SCTransferStart)
+	startMaster(a, 0xA0)
+	a.Step(CyclesPerBit * 8)
+
+	if got := a.ReadRegister(RegSB); got != 0xB0 {
+		t.Errorf("mestre: esperado 0xB0, obtido 0x%02X", got)
+	}
+	if got := b.ReadRegister(RegSB); got != 0xA0 {
+		t.Errorf("escravo: esperado 0xA0, obtido 0x%02X", got)
+	}
+	if icA.requested&InterruptSerial == 0 || icB.requested&InterruptSerial == 0 {
+		t.Error("as duas pontas deveriam receber a interrupção serial")
+	}
+
+	// Cabo desconectado
+	peerB.Close()
+	startMaster(a, 0xA1)
+	a.Step(CyclesPerBit * 8)
+	if got := a.ReadRegister(RegSB); got != 0xFF {
+		t.Errorf("cabo desconectado: esperado 0xFF, obtido 0x%02X", got)
+	}
+}
+
+func TestSerialTCPPeer(t *testing.T) {
+	server, err := ListenTCP("127.0.0.1:0")
+	if err != nil {
+		t.Skipf("TCP indisponível: %v", err)
+	}
+	defer server.Close()
+
+	client, err := DialTCP(server.Addr())
+	if err != nil {
+		t.Fatalf("erro ao conectar: %v", err)
+	}
+	defer client.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !server.IsConnected() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !server.IsConnected() {
+		t.Fatal("servidor deveria aceitar a conexão")
+	}
+
+	icA, icB := &mockInterrupts{}, &mockInterrupts{}
+	a, b := NewSerial(icA), NewSerial(icB)
+	a.SetPeer(client)
+	b.SetPeer(server)
+
+	b.WriteRegister(RegSB, 0x5A)
+	b.WriteRegister(RegSC, SCTransferStart)
+	startMaster(a, 0xA5)
+	a.Step(CyclesPerBit * 8)
+
+	if got := a.ReadRegister(RegSB); got != 0x5A {
+		t.Errorf("mestre via TCP: esperado 0x5A, obtido 0x%02X", got)
+	}
+	if got := b.ReadRegister(RegSB); got != 0xA5 {
+		t.Errorf("escravo via TCP: esperado 0xA5, obtido 0x%02X", got)
+	}
+	if icB.requested&InterruptSerial == 0 {
+		t.Error("escravo via TCP deveria receber a interrupção serial")
+	}
+}
@@ -0,0 +1,232 @@
+package serial
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Constantes do transporte TCP
+const (
+	// DefaultTCPAddress é o endereço padrão do cabo link via TCP
+	DefaultTCPAddress = "127.0.0.1:5739"
+
+	// DefaultTCPTimeout é o tempo máximo de espera pela resposta do outro lado
+	DefaultTCPTimeout = time.Second
+
+	// Tipos de mensagem (cada mensagem tem 2 bytes: tipo e dado)
+	tcpMsgTransfer = 0x01 // Byte enviado pelo mestre
+	tcpMsgReply    = 0x02 // Byte de SB devolvido pelo escravo
+)
+
+// TCPPeer conecta duas instâncias (possivelmente em processos diferentes)
+// por TCP. Cada transferência do mestre envia um byte e aguarda o byte do
+// outro lado; transferências recebidas são respondidas pelo Device local.
+type TCPPeer struct {
+	mu       sync.Mutex
+	writeMu  sync.Mutex
+	conn     net.Conn
+	listener net.Listener
+	device   Device
+	replies  chan uint8
+	timeout  time.Duration
+	closed   bool
+}
+
+// newTCPPeer cria um TCPPeer sem conexão
+func newTCPPeer() *TCPPeer {
+	return &TCPPeer{
+		replies: make(chan uint8, 1),
+		timeout: DefaultTCPTimeout,
+	}
+}
+
+// ListenTCP aguarda a conexão de outra instância no endereço informado
+// (vazio = DefaultTCPAddress). A conexão é aceita em segundo plano; até lá
+// as transferências recebem 0xFF, como com o cabo desconectado.
+func ListenTCP(addr string) (*TCPPeer, error) {
+	if addr == "" {
+		addr = DefaultTCPAddress
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao escutar em %s: %w", addr, err)
+	}
+
+	p := newTCPPeer()
+	p.listener = listener
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		p.setConn(conn)
+	}()
+
+	return p, nil
+}
+
+// DialTCP conecta a uma instância aguardando em ListenTCP
+func DialTCP(addr string) (*TCPPeer, error) {
+	if addr == "" {
+		addr = DefaultTCPAddress
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, DefaultTCPTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar em %s: %w", addr, err)
+	}
+
+	p := newTCPPeer()
+	p.setConn(conn)
+	return p, nil
+}
+
+// setConn associa a conexão e inicia a leitura das mensagens
+func (p *TCPPeer) setConn(conn net.Conn) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		conn.Close()
+		return
+	}
+	p.conn = conn
+	p.mu.Unlock()
+
+	go p.readLoop(conn)
+}
+
+// Addr retorna o endereço local (útil com a porta 0 em ListenTCP)
+func (p *TCPPeer) Addr() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listener != nil {
+		return p.listener.Addr().String()
+	}
+	if p.conn != nil {
+		return p.conn.LocalAddr().String()
+	}
+	return ""
+}
+
+// IsConnected retorna se há uma instância conectada
+func (p *TCPPeer) IsConnected() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.conn != nil && !p.closed
+}
+
+// SetTimeout define o tempo máximo de espera por uma resposta
+func (p *TCPPeer) SetTimeout(timeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.timeout = timeout
+}
+
+// Exchange implementa Peer
+func (p *TCPPeer) Exchange(out uint8) uint8 {
+	p.mu.Lock()
+	conn := p.conn
+	timeout := p.timeout
+	p.mu.Unlock()
+
+	if conn == nil {
+		return 0xFF
+	}
+
+	// Descarta respostas atrasadas de transferências anteriores
+	select {
+	case <-p.replies:
+	default:
+	}
+
+	if err := p.send(conn, tcpMsgTransfer, out); err != nil {
+		return 0xFF
+	}
+
+	select {
+	case in := <-p.replies:
+		return in
+	case <-time.After(timeout):
+		return 0xFF
+	}
+}
+
+// Attach implementa Peer
+func (p *TCPPeer) Attach(device Device) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.device = device
+}
+
+// Close implementa Peer
+func (p *TCPPeer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+
+	var err error
+	if p.listener != nil {
+		err = p.listener.Close()
+	}
+	if p.conn != nil {
+		if cerr := p.conn.Close(); err == nil {
+			err = cerr
+		}
+		p.conn = nil
+	}
+	return err
+}
+
+// send escreve uma mensagem na conexão
+func (p *TCPPeer) send(conn net.Conn, kind, value uint8) error {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	_, err := conn.Write([]byte{kind, value})
+	return err
+}
+
+// readLoop processa as mensagens recebidas até a conexão fechar
+func (p *TCPPeer) readLoop(conn net.Conn) {
+	msg := make([]byte, 2)
+
+	for {
+		if _, err := io.ReadFull(conn, msg); err != nil {
+			p.mu.Lock()
+			if p.conn == conn {
+				p.conn = nil
+			}
+			p.mu.Unlock()
+			return
+		}
+
+		switch msg[0] {
+		case tcpMsgTransfer:
+			p.mu.Lock()
+			device := p.device
+			p.mu.Unlock()
+
+			reply := uint8(0xFF)
+			if device != nil {
+				reply = device.ReceiveExternal(msg[1])
+			}
+			p.send(conn, tcpMsgReply, reply)
+
+		case tcpMsgReply:
+			select {
+			case p.replies <- msg[1]:
+			default:
+			}
+		}
+	}
+}
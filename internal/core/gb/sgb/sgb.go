@@ -0,0 +1,536 @@
+package sgb
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/input"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
+)
+
+// Constantes do Super Game Boy
+const (
+	// Imagem enviada à TV: tela do Game Boy centralizada na moldura
+	Width   = 256
+	Height  = 224
+	ScreenX = (Width - video.ScreenWidth) / 2   // 48
+	ScreenY = (Height - video.ScreenHeight) / 2 // 40
+
+	// Grade de atributos: uma paleta por tile da tela (20x18)
+	AttrWidth  = video.ScreenWidth / 8
+	AttrHeight = video.ScreenHeight / 8
+
+	// Dados copiados da tela nas transferências *_TRN (256 tiles 2bpp)
+	TransferSize = 0x1000
+
+	// Paletas de sistema (PAL_TRN) e arquivos de atributos (ATTR_TRN)
+	SystemPalettes = 512
+	AttrFiles      = 45
+	AttrFileSize   = AttrWidth * AttrHeight / 4 // 90 bytes, 2 bits por tile
+
+	// Moldura: 256 tiles SNES 4bpp, mapa 32x28 e 4 paletas de 16 cores
+	borderTileSize = 32
+	borderMapSize  = 32 * 32 * 2
+
+	// Maior comando: 7 pacotes
+	maxCommandSize = 7 * input.SGBPacketSize
+)
+
+// Comandos (bits 3-7 do primeiro byte do pacote)
+const (
+	CmdPAL01   = 0x00
+	CmdPAL23   = 0x01
+	CmdPAL03   = 0x02
+	CmdPAL12   = 0x03
+	CmdATTRBlk = 0x04
+	CmdATTRLin = 0x05
+	CmdATTRDiv = 0x06
+	CmdATTRChr = 0x07
+	CmdPALSet  = 0x0A
+	CmdPALTrn  = 0x0B
+	CmdMLTReq  = 0x11
+	CmdCHRTrn  = 0x13
+	CmdPCTTrn  = 0x14
+	CmdATTRTrn = 0x15
+	CmdATTRSet = 0x16
+	CmdMaskEn  = 0x17
+)
+
+// Modos de MASK_EN
+const (
+	MaskCancel = iota // Tela normal
+	MaskFreeze        // Mantém a última imagem
+	MaskBlack         // Tela preta
+	MaskColor0        // Tela na cor 0
+)
+
+// defaultPalette é usada em todas as paletas até o jogo enviar as suas
+var defaultPalette = [4]uint16{0x7FFF, 0x56B5, 0x294A, 0x0000}
+
+// Joypad recebe o número de controles pedido por MLT_REQ
+type Joypad interface {
+	SetPlayerCount(players int)
+}
+
+// SGB interpreta os comandos do Super Game Boy e monta a imagem de 256x224
+// com as paletas por região e a moldura. Os pacotes chegam por
+// input.Input.EnableSGB.
+type SGB struct {
+	joypad Joypad
+
+	// Comando em montagem (até 7 pacotes)
+	command     []uint8
+	packetsLeft int
+
+	// Paletas e atributos da tela (cores RGB555)
+	palettes       [4][4]uint16
+	systemPalettes [SystemPalettes][4]uint16
+	attributes     [AttrHeight][AttrWidth]uint8
+	attrFiles      [AttrFiles][AttrFileSize]uint8
+	mask           uint8
+
+	// Moldura
+	borderTiles    [256 * borderTileSize]uint8
+	borderMap      [borderMapSize]uint8
+	borderPalettes [4][16]uint16
+
+	// Transferência *_TRN aguardando o próximo frame
+	pendingTransfer int
+	transferArg     uint8
+
+	// Imagens
+	frozen [video.ScreenHeight][video.ScreenWidth]uint16
+	output [Height][Width]uint16
+}
+
+// New cria o Super Game Boy; joypad pode ser nil
+func New(joypad Joypad) *SGB {
+	s := &SGB{joypad: joypad}
+	s.Reset()
+	return s
+}
+
+// Reset restaura o estado de power-on
+func (s *SGB) Reset() {
+	joypad := s.joypad
+	*s = SGB{joypad: joypad, pendingTransfer: -1}
+	for i := range s.palettes {
+		s.palettes[i] = defaultPalette
+	}
+	if s.joypad != nil {
+		s.joypad.SetPlayerCount(1)
+	}
+}
+
+// ReceivePacket recebe um pacote de 16 bytes. O primeiro pacote de um
+// comando traz o código (bits 3-7) e a quantidade de pacotes (bits 0-2).
+func (s *SGB) ReceivePacket(packet [input.SGBPacketSize]uint8) {
+	if s.packetsLeft == 0 {
+		length := int(packet[0] & 0x07)
+		if length == 0 {
+			return
+		}
+		s.command = append(s.command[:0], packet[:]...)
+		s.packetsLeft = length - 1
+	} else {
+		s.command = append(s.command, packet[:]...)
+		s.packetsLeft--
+	}
+
+	if s.packetsLeft == 0 {
+		s.execute(s.command)
+	}
+}
+
+// execute executa um comando completo
+func (s *SGB) execute(cmd []uint8) {
+	switch code := int(cmd[0] >> 3); code {
+	case CmdPAL01:
+		s.setPalettePair(0, 1, cmd[1:])
+	case CmdPAL23:
+		s.setPalettePair(2, 3, cmd[1:])
+	case CmdPAL03:
+		s.setPalettePair(0, 3, cmd[1:])
+	case CmdPAL12:
+		s.setPalettePair(1, 2, cmd[1:])
+	case CmdATTRBlk:
+		s.attrBlock(cmd)
+	case CmdATTRLin:
+		s.attrLine(cmd)
+	case CmdATTRDiv:
+		s.attrDivide(cmd)
+	case CmdATTRChr:
+		s.attrChar(cmd)
+	case CmdPALSet:
+		s.paletteSet(cmd)
+	case CmdMLTReq:
+		s.multiplayer(cmd[1] & 0x03)
+	case CmdATTRSet:
+		s.applyAttrFile(int(cmd[1] & 0x3F))
+		if cmd[1]&0x40 != 0 {
+			s.mask = MaskCancel
+		}
+	case CmdMaskEn:
+		s.mask = cmd[1] & 0x03
+	case CmdPALTrn, CmdCHRTrn, CmdPCTTrn, CmdATTRTrn:
+		// Os dados são lidos da tela exibida no próximo frame
+		s.pendingTransfer = code
+		s.transferArg = cmd[1]
+	}
+}
+
+// multiplayer aplica MLT_REQ: 0 = 1 controle, 1 = 2 controles, 3 = 4
+func (s *SGB) multiplayer(mode uint8) {
+	if s.joypad == nil {
+		return
+	}
+	switch mode {
+	case 0x01:
+		s.joypad.SetPlayerCount(2)
+	case 0x03:
+		s.joypad.SetPlayerCount(4)
+	default:
+		s.joypad.SetPlayerCount(1)
+	}
+}
+
+// color lê uma cor RGB555 little-endian
+func color(data []uint8) uint16 {
+	return binary.LittleEndian.Uint16(data) & 0x7FFF
+}
+
+// setPalettePair aplica PAL01/PAL23/PAL03/PAL12: a cor 0 é comum a todas
+// as paletas, seguida das cores 1-3 de cada uma das duas
+func (s *SGB) setPalettePair(a, b int, data []uint8) {
+	color0 := color(data[0:])
+	for i := range s.palettes {
+		s.palettes[i][0] = color0
+	}
+	for c := 1; c < 4; c++ {
+		s.palettes[a][c] = color(data[c*2:])
+		s.palettes[b][c] = color(data[6+c*2:])
+	}
+}
+
+// paletteSet aplica PAL_SET: quatro paletas de sistema e, opcionalmente,
+// um arquivo de atributos
+func (s *SGB) paletteSet(cmd []uint8) {
+	for i := range s.palettes {
+		index := binary.LittleEndian.Uint16(cmd[1+i*2:]) % SystemPalettes
+		s.palettes[i] = s.systemPalettes[index]
+	}
+	for i := 1; i < len(s.palettes); i++ {
+		s.palettes[i][0] = s.palettes[0][0]
+	}
+
+	if cmd[9]&0x80 != 0 {
+		s.applyAttrFile(int(cmd[9] & 0x3F))
+	}
+	if cmd[9]&0x40 != 0 {
+		s.mask = MaskCancel
+	}
+}
+
+// attrBlock aplica ATTR_BLK: retângulos com paletas para dentro, borda e
+// fora. Com apenas "dentro" ou apenas "fora" ligado, a borda acompanha.
+func (s *SGB) attrBlock(cmd []uint8) {
+	count := int(cmd[1] & 0x1F)
+	for i := 0; i < count && 2+i*6+6 <= len(cmd); i++ {
+		set := cmd[2+i*6:]
+		control := set[0] & 0x07
+		inside := set[1] & 0x03
+		border := (set[1] >> 2) & 0x03
+		outside := (set[1] >> 4) & 0x03
+		x1, y1 := int(set[2]&0x1F), int(set[3]&0x1F)
+		x2, y2 := int(set[4]&0x1F), int(set[5]&0x1F)
+
+		switch control {
+		case 0x01:
+			border = inside
+			control |= 0x02
+		case 0x04:
+			border = outside
+			control |= 0x02
+		}
+
+		for y := 0; y < AttrHeight; y++ {
+			for x := 0; x < AttrWidth; x++ {
+				switch {
+				case x > x1 && x < x2 && y > y1 && y < y2:
+					if control&0x01 != 0 {
+						s.attributes[y][x] = inside
+					}
+				case x >= x1 && x <= x2 && y >= y1 && y <= y2:
+					if control&0x02 != 0 {
+						s.attributes[y][x] = border
+					}
+				default:
+					if control&0x04 != 0 {
+						s.attributes[y][x] = outside
+					}
+				}
+			}
+		}
+	}
+}
+
+// attrLine aplica ATTR_LIN: linhas ou colunas inteiras de uma paleta
+func (s *SGB) attrLine(cmd []uint8) {
+	count := int(cmd[1])
+	for i := 0; i < count && 2+i < len(cmd); i++ {
+		line := int(cmd[2+i] & 0x1F)
+		palette := (cmd[2+i] >> 5) & 0x03
+
+		if cmd[2+i]&0x80 != 0 {
+			if line < AttrHeight {
+				for x := 0; x < AttrWidth; x++ {
+					s.attributes[line][x] = palette
+				}
+			}
+		} else if line < AttrWidth {
+			for y := 0; y < AttrHeight; y++ {
+				s.attributes[y][line] = palette
+			}
+		}
+	}
+}
+
+// attrDivide aplica ATTR_DIV: divide a tela em duas regiões e a linha
+// divisória
+func (s *SGB) attrDivide(cmd []uint8) {
+	after := cmd[1] & 0x03
+	before := (cmd[1] >> 2) & 0x03
+	onLine := (cmd[1] >> 4) & 0x03
+	horizontal := cmd[1]&0x40 != 0
+	coord := int(cmd[2] & 0x1F)
+
+	for y := 0; y < AttrHeight; y++ {
+		for x := 0; x < AttrWidth; x++ {
+			position := x
+			if horizontal {
+				position = y
+			}
+			switch {
+			case position < coord:
+				s.attributes[y][x] = before
+			case position == coord:
+				s.attributes[y][x] = onLine
+			default:
+				s.attributes[y][x] = after
+			}
+		}
+	}
+}
+
+// attrChar aplica ATTR_CHR: paletas tile a tile (2 bits cada), em linhas
+// ou em colunas
+func (s *SGB) attrChar(cmd []uint8) {
+	x, y := int(cmd[1]&0x1F), int(cmd[2]&0x1F)
+	count := int(binary.LittleEndian.Uint16(cmd[3:]))
+	vertical := cmd[5]&0x01 != 0
+
+	for i := 0; i < count && 6+i/4 < len(cmd); i++ {
+		if x >= AttrWidth || y >= AttrHeight {
+			return
+		}
+		s.attributes[y][x] = (cmd[6+i/4] >> (6 - 2*uint(i%4))) & 0x03
+
+		if vertical {
+			if y++; y == AttrHeight {
+				y = 0
+				x++
+			}
+		} else {
+			if x++; x == AttrWidth {
+				x = 0
+				y++
+			}
+		}
+	}
+}
+
+// applyAttrFile copia um arquivo de atributos recebido por ATTR_TRN
+func (s *SGB) applyAttrFile(file int) {
+	if file >= AttrFiles {
+		return
+	}
+	for i := 0; i < AttrWidth*AttrHeight; i++ {
+		s.attributes[i/AttrWidth][i%AttrWidth] = (s.attrFiles[file][i/4] >> (6 - 2*uint(i%4))) & 0x03
+	}
+}
+
+// transfer aplica uma transferência *_TRN com os 4KB lidos da tela
+func (s *SGB) transfer(data []uint8) {
+	switch s.pendingTransfer {
+	case CmdPALTrn:
+		for i := range s.systemPalettes {
+			for c := 0; c < 4; c++ {
+				s.systemPalettes[i][c] = color(data[i*8+c*2:])
+			}
+		}
+	case CmdCHRTrn:
+		copy(s.borderTiles[int(s.transferArg&0x01)*TransferSize:], data)
+	case CmdPCTTrn:
+		copy(s.borderMap[:], data)
+		for p := range s.borderPalettes {
+			for c := 0; c < 16; c++ {
+				s.borderPalettes[p][c] = color(data[borderMapSize+p*32+c*2:])
+			}
+		}
+	case CmdATTRTrn:
+		for i := range s.attrFiles {
+			copy(s.attrFiles[i][:], data[i*AttrFileSize:])
+		}
+	}
+	s.pendingTransfer = -1
+}
+
+// transferData reconstrói os 4KB de dados exibidos na tela: os tiles
+// 0-255 aparecem em ordem, 20 por linha, e cada pixel volta a ser 2bpp
+func transferData(frame *[video.ScreenHeight][video.ScreenWidth]uint8) []uint8 {
+	data := make([]uint8, TransferSize)
+	for tile := 0; tile < TransferSize/16; tile++ {
+		tx, ty := (tile%AttrWidth)*8, (tile/AttrWidth)*8
+		for row := 0; row < 8; row++ {
+			var low, high uint8
+			for col := 0; col < 8; col++ {
+				shade := frame[ty+row][tx+col]
+				low = low<<1 | shade&0x01
+				high = high<<1 | (shade>>1)&0x01
+			}
+			data[tile*16+row*2] = low
+			data[tile*16+row*2+1] = high
+		}
+	}
+	return data
+}
+
+// UpdateFrame recebe o frame em tons DMG (0-3), conclui transferências
+// pendentes e monta a imagem de 256x224
+func (s *SGB) UpdateFrame(frame [video.ScreenHeight][video.ScreenWidth]uint8) {
+	if s.pendingTransfer >= 0 {
+		s.transfer(transferData(&frame))
+	}
+
+	s.renderBorder()
+
+	backdrop := s.palettes[0][0]
+	for y := 0; y < video.ScreenHeight; y++ {
+		for x := 0; x < video.ScreenWidth; x++ {
+			var pixel uint16
+			switch s.mask {
+			case MaskCancel:
+				pixel = s.palettes[s.attributes[y/8][x/8]][frame[y][x]&0x03]
+				s.frozen[y][x] = pixel
+			case MaskFreeze:
+				pixel = s.frozen[y][x]
+			case MaskBlack:
+				pixel = 0x0000
+			case MaskColor0:
+				pixel = backdrop
+			}
+			s.output[ScreenY+y][ScreenX+x] = pixel
+		}
+	}
+}
+
+// renderBorder desenha a moldura; a cor 0 dos tiles é transparente e
+// mostra a cor 0 da paleta 0
+func (s *SGB) renderBorder() {
+	backdrop := s.palettes[0][0]
+	for ty := 0; ty < Height/8; ty++ {
+		for tx := 0; tx < Width/8; tx++ {
+			entry := binary.LittleEndian.Uint16(s.borderMap[(ty*32+tx)*2:])
+			tile := s.borderTiles[int(entry&0xFF)*borderTileSize:]
+			palette := (entry >> 10) & 0x03 // Paletas 4-7 do SNES
+			flipX := entry&0x4000 != 0
+			flipY := entry&0x8000 != 0
+
+			for py := 0; py < 8; py++ {
+				row := py
+				if flipY {
+					row = 7 - py
+				}
+				planes := [4]uint8{tile[row*2], tile[row*2+1], tile[16+row*2], tile[16+row*2+1]}
+
+				for px := 0; px < 8; px++ {
+					bit := uint(7 - px)
+					if flipX {
+						bit = uint(px)
+					}
+					var index uint8
+					for plane, value := range planes {
+						index |= ((value >> bit) & 0x01) << uint(plane)
+					}
+
+					pixel := backdrop
+					if index != 0 {
+						pixel = s.borderPalettes[palette][index]
+					}
+					s.output[ty*8+py][tx*8+px] = pixel
+				}
+			}
+		}
+	}
+}
+
+// GetFrameBuffer retorna a última imagem de 256x224 em RGB555
+func (s *SGB) GetFrameBuffer() [Height][Width]uint16 {
+	return s.output
+}
+
+// GetPalette retorna uma das 4 paletas da tela
+func (s *SGB) GetPalette(index int) [4]uint16 {
+	return s.palettes[index&0x03]
+}
+
+// GetAttribute retorna a paleta usada pelo tile (x, y) da tela
+func (s *SGB) GetAttribute(x, y int) uint8 {
+	return s.attributes[y][x]
+}
+
+// GetMask retorna o modo de MASK_EN
+func (s *SGB) GetMask() uint8 {
+	return s.mask
+}
+
+// stateFields lista os campos gravados no save state; o comando em
+// montagem é copiado para command
+func (s *SGB) stateFields(command *[maxCommandSize]uint8, length *int) []any {
+	return []any{
+		command, length, &s.packetsLeft,
+		&s.palettes, &s.systemPalettes, &s.attributes, &s.attrFiles, &s.mask,
+		&s.borderTiles, &s.borderMap, &s.borderPalettes,
+		&s.pendingTransfer, &s.transferArg, &s.frozen, &s.output,
+	}
+}
+
+// SaveState serializa paletas, atributos, moldura, transferências
+// pendentes e o comando em recepção
+func (s *SGB) SaveState() ([]byte, error) {
+	var command [maxCommandSize]uint8
+	length := copy(command[:], s.command)
+	return savestate.Marshal(s.stateFields(&command, &length)...)
+}
+
+// LoadState restaura o estado gerado por SaveState. O número de controles
+// é restaurado pelo estado do input.
+func (s *SGB) LoadState(data []byte) error {
+	var command [maxCommandSize]uint8
+	var length int
+	if err := savestate.Unmarshal(data, s.stateFields(&command, &length)...); err != nil {
+		return fmt.Errorf("estado do SGB inválido: %w", err)
+	}
+	if length < 0 || length > maxCommandSize {
+		length = 0
+		s.packetsLeft = 0
+	}
+	s.command = append(s.command[:0], command[:length]...)
+	return nil
+}
+
+// String retorna uma representação em string do estado do SGB
+func (s *SGB) String() string {
+	return fmt.Sprintf("SGB: Mask=%d PendingTransfer=%d", s.mask, s.pendingTransfer)
+}
@@ -0,0 +1,255 @@
+package sgb
+
+import (
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/input"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
+)
+
+// fakeJoypad registra o número de controles pedido por MLT_REQ
+type fakeJoypad struct {
+	players int
+}
+
+func (j *fakeJoypad) SetPlayerCount(players int) {
+	j.players = players
+}
+
+// packet monta um pacote de um único bloco com o comando e os dados
+func packet(command uint8, data ...uint8) [input.SGBPacketSize]uint8 {
+	var p [input.SGBPacketSize]uint8
+	p[0] = command<<3 | 1
+	copy(p[1:], data)
+	return p
+}
+
+// transferFrame monta a tela que transfere os dados: os 256 tiles em
+// ordem, 20 por linha
+func transferFrame(data []uint8) [video.ScreenHeight][video.ScreenWidth]uint8 {
+	var frame [video.ScreenHeight][video.ScreenWidth]uint8
+	for tile := 0; tile < TransferSize/16; tile++ {
+		tx, ty := (tile%AttrWidth)*8, (tile/AttrWidth)*8
+		for row := 0; row < 8; row++ {
+			low, high := data[tile*16+row*2], data[tile*16+row*2+1]
+			for col := 0; col < 8; col++ {
+				bit := uint(7 - col)
+				frame[ty+row][tx+col] = (low>>bit)&1 | ((high>>bit)&1)<<1
+			}
+		}
+	}
+	return frame
+}
+
+// putColor grava uma cor RGB555 little-endian
+func putColor(data []uint8, color uint16) {
+	data[0] = uint8(color)
+	data[1] = uint8(color >> 8)
+}
+
+func TestPaletteCommands(t *testing.T) {
+	s := New(nil)
+
+	// PAL01: cor 0 comum, cores 1-3 das paletas 0 e 1
+	s.ReceivePacket(packet(CmdPAL01,
+		0x1F, 0x00, // cor 0
+		0x01, 0x00, 0x02, 0x00, 0x03, 0x00, // paleta 0
+		0x04, 0x00, 0x05, 0x00, 0x06, 0x80, // paleta 1 (bit 15 ignorado)
+	))
+	if got := s.GetPalette(0); got != [4]uint16{0x001F, 1, 2, 3} {
+		t.Errorf("paleta 0: obtido %v", got)
+	}
+	if got := s.GetPalette(1); got != [4]uint16{0x001F, 4, 5, 6} {
+		t.Errorf("paleta 1: obtido %v", got)
+	}
+	if got := s.GetPalette(3); got[0] != 0x001F || got[1] != defaultPalette[1] {
+		t.Errorf("paleta 3 deveria mudar só a cor 0: obtido %v", got)
+	}
+
+	// Comando de dois pacotes só é executado no segundo
+	first := packet(CmdPAL23, 0x00, 0x00, 0x07, 0x00)
+	first[0] = CmdPAL23<<3 | 2
+	s.ReceivePacket(first)
+	if s.GetPalette(2)[1] == 7 {
+		t.Error("comando executado antes do último pacote")
+	}
+	s.ReceivePacket([input.SGBPacketSize]uint8{})
+	if got := s.GetPalette(2)[1]; got != 7 {
+		t.Errorf("PAL23 de dois pacotes: esperado cor 7, obtido %d", got)
+	}
+}
+
+func TestAttributeCommands(t *testing.T) {
+	s := New(nil)
+
+	// ATTR_BLK: só "dentro" (a borda acompanha)
+	s.ReceivePacket(packet(CmdATTRBlk, 1, 0x01, 0x02, 1, 1, 3, 3))
+	if s.GetAttribute(1, 1) != 2 || s.GetAttribute(3, 3) != 2 || s.GetAttribute(4, 4) != 0 {
+		t.Errorf("ATTR_BLK só dentro: obtido %d, %d e %d",
+			s.GetAttribute(1, 1), s.GetAttribute(3, 3), s.GetAttribute(4, 4))
+	}
+
+	// Dentro, borda e fora
+	s.ReceivePacket(packet(CmdATTRBlk, 1, 0x07, 0x01|2<<2|3<<4, 10, 10, 14, 14))
+	tests := []struct {
+		x, y int
+		want uint8
+	}{
+		{12, 12, 1}, {10, 12, 2}, {14, 10, 2}, {1, 1, 3}, {0, 0, 3},
+	}
+	for _, tc := range tests {
+		if got := s.GetAttribute(tc.x, tc.y); got != tc.want {
+			t.Errorf("ATTR_BLK (%d,%d): esperado paleta %d, obtido %d", tc.x, tc.y, tc.want, got)
+		}
+	}
+
+	// ATTR_LIN: linha 5 com a paleta 1 e coluna 7 com a paleta 2
+	s.ReceivePacket(packet(CmdATTRLin, 2, 0x80|1<<5|5, 2<<5|7))
+	if s.GetAttribute(0, 5) != 1 || s.GetAttribute(7, 0) != 2 || s.GetAttribute(7, 5) != 2 {
+		t.Errorf("ATTR_LIN: obtido %d, %d e %d",
+			s.GetAttribute(0, 5), s.GetAttribute(7, 0), s.GetAttribute(7, 5))
+	}
+
+	// ATTR_DIV horizontal na linha 9
+	s.ReceivePacket(packet(CmdATTRDiv, 0x40|3<<4|2<<2|1, 9))
+	for y, want := range map[int]uint8{8: 2, 9: 3, 10: 1} {
+		if got := s.GetAttribute(0, y); got != want {
+			t.Errorf("ATTR_DIV linha %d: esperado paleta %d, obtido %d", y, want, got)
+		}
+	}
+
+	// ATTR_CHR em linhas, passando para a linha seguinte
+	s.ReceivePacket(packet(CmdATTRChr, 18, 0, 3, 0, 0, 0x1B))
+	for _, tc := range []struct {
+		x, y int
+		want uint8
+	}{{18, 0, 0}, {19, 0, 1}, {0, 1, 2}} {
+		if got := s.GetAttribute(tc.x, tc.y); got != tc.want {
+			t.Errorf("ATTR_CHR (%d,%d): esperado paleta %d, obtido %d", tc.x, tc.y, tc.want, got)
+		}
+	}
+}
+
+func TestPaletteTransfer(t *testing.T) {
+	s := New(nil)
+
+	data := make([]uint8, TransferSize)
+	putColor(data[5*8:], 0x0011)
+	putColor(data[5*8+2:], 0x0022)
+	putColor(data[300*8+6:], 0x0033)
+
+	s.ReceivePacket(packet(CmdPALTrn))
+	s.UpdateFrame(transferFrame(data))
+
+	// PAL_SET: paleta 0 = sistema 5, paleta 1 = sistema 300
+	s.ReceivePacket(packet(CmdPALSet, 5, 0, 0x2C, 0x01, 0, 0, 0, 0, 0x00))
+	if got := s.GetPalette(0); got != [4]uint16{0x0011, 0x0022, 0, 0} {
+		t.Errorf("paleta 0: obtido %v", got)
+	}
+	if got := s.GetPalette(1); got != [4]uint16{0x0011, 0, 0, 0x0033} {
+		t.Errorf("paleta 1 deveria usar a cor 0 da paleta 0: obtido %v", got)
+	}
+
+	// Tom 1 da tela na paleta 0
+	var frame [video.ScreenHeight][video.ScreenWidth]uint8
+	frame[0][0] = 1
+	s.UpdateFrame(frame)
+	if got := s.GetFrameBuffer()[ScreenY][ScreenX]; got != 0x0022 {
+		t.Errorf("pixel da tela: esperado 0x0022, obtido 0x%04X", got)
+	}
+}
+
+func TestAttributeTransfer(t *testing.T) {
+	s := New(nil)
+
+	// Arquivo 2: primeiro tile com a paleta 3
+	data := make([]uint8, TransferSize)
+	data[2*AttrFileSize] = 0xC0
+
+	s.ReceivePacket(packet(CmdATTRTrn))
+	s.UpdateFrame(transferFrame(data))
+	s.ReceivePacket(packet(CmdATTRSet, 2))
+	if s.GetAttribute(0, 0) != 3 || s.GetAttribute(1, 0) != 0 {
+		t.Errorf("ATTR_SET: obtido %d e %d", s.GetAttribute(0, 0), s.GetAttribute(1, 0))
+	}
+}
+
+func TestBorder(t *testing.T) {
+	s := New(nil)
+
+	// Tile 1 com a cor 1 em todos os pixels
+	tiles := make([]uint8, TransferSize)
+	for row := 0; row < 8; row++ {
+		tiles[borderTileSize+row*2] = 0xFF
+	}
+	s.ReceivePacket(packet(CmdCHRTrn, 0))
+	s.UpdateFrame(transferFrame(tiles))
+
+	// Canto superior esquerdo usa o tile 1 com a paleta 1
+	picture := make([]uint8, TransferSize)
+	putColor(picture[0:], 0x0001|1<<10)
+	putColor(picture[borderMapSize+32+2:], 0x1234)
+	s.ReceivePacket(packet(CmdPCTTrn))
+	s.UpdateFrame(transferFrame(picture))
+
+	var frame [video.ScreenHeight][video.ScreenWidth]uint8
+	s.UpdateFrame(frame)
+	output := s.GetFrameBuffer()
+	if output[0][0] != 0x1234 || output[7][7] != 0x1234 {
+		t.Errorf("tile da moldura: esperado 0x1234, obtido 0x%04X", output[0][0])
+	}
+	if got := output[0][8]; got != s.GetPalette(0)[0] {
+		t.Errorf("cor 0 da moldura deveria ser transparente: obtido 0x%04X", got)
+	}
+	if got := output[ScreenY][ScreenX]; got != defaultPalette[0] {
+		t.Errorf("tela dentro da moldura: esperado 0x%04X, obtido 0x%04X", defaultPalette[0], got)
+	}
+}
+
+func TestMaskEnable(t *testing.T) {
+	s := New(nil)
+
+	var white, black [video.ScreenHeight][video.ScreenWidth]uint8
+	for y := range black {
+		for x := range black[y] {
+			black[y][x] = 3
+		}
+	}
+
+	s.UpdateFrame(white)
+	s.ReceivePacket(packet(CmdMaskEn, MaskFreeze))
+	s.UpdateFrame(black)
+	if got := s.GetFrameBuffer()[ScreenY][ScreenX]; got != defaultPalette[0] {
+		t.Errorf("tela congelada: esperado 0x%04X, obtido 0x%04X", defaultPalette[0], got)
+	}
+
+	s.ReceivePacket(packet(CmdMaskEn, MaskColor0))
+	s.UpdateFrame(black)
+	if got := s.GetFrameBuffer()[ScreenY][ScreenX]; got != defaultPalette[0] {
+		t.Errorf("tela na cor 0: obtido 0x%04X", got)
+	}
+
+	s.ReceivePacket(packet(CmdMaskEn, MaskBlack))
+	s.UpdateFrame(white)
+	if got := s.GetFrameBuffer()[ScreenY][ScreenX]; got != 0 {
+		t.Errorf("tela preta: obtido 0x%04X", got)
+	}
+
+	s.ReceivePacket(packet(CmdMaskEn, MaskCancel))
+	s.UpdateFrame(black)
+	if got := s.GetFrameBuffer()[ScreenY][ScreenX]; got != defaultPalette[3] {
+		t.Errorf("tela normal: esperado 0x%04X, obtido 0x%04X", defaultPalette[3], got)
+	}
+}
+
+func TestMultiplayerRequest(t *testing.T) {
+	joypad := &fakeJoypad{}
+	s := New(joypad)
+
+	for mode, want := range map[uint8]int{0x01: 2, 0x03: 4, 0x00: 1} {
+		s.ReceivePacket(packet(CmdMLTReq, mode))
+		if joypad.players != want {
+			t.Errorf("MLT_REQ %d: esperado %d controles, obtido %d", mode, want, joypad.players)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package gb
+
+import (
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/input"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/sgb"
+)
+
+// newSGBROM cria uma ROM em loop com a flag SGB no header
+func newSGBROM() []uint8 {
+	rom := newLoopROM(0x00)
+	rom[0x146] = 0x03 // Funções SGB
+	rom[0x14B] = 0x33 // Licenciado novo (exigido pelo SGB)
+	return rom
+}
+
+// sendSGBPacket envia um pacote pelos pulsos em P14/P15, como o jogo faz
+func sendSGBPacket(gameboy *GameBoy, packet [input.SGBPacketSize]uint8) {
+	gameboy.mmu.Write(0xFF00, 0x00)
+	gameboy.mmu.Write(0xFF00, 0x30)
+	for bit := 0; bit <= input.SGBPacketSize*8; bit++ {
+		value := uint8(0x20) // bit 0 (e stop bit)
+		if bit < input.SGBPacketSize*8 && packet[bit/8]&(1<<uint(bit%8)) != 0 {
+			value = 0x10
+		}
+		gameboy.mmu.Write(0xFF00, value)
+		gameboy.mmu.Write(0xFF00, 0x30)
+	}
+}
+
+// TestSGBModelSelection verifica que o SGB exige o modelo e a flag do header
+func TestSGBModelSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   Model
+		rom     []uint8
+		wantSGB bool
+	}{
+		{"SGB model with SGB ROM", ModelSGB, newSGBROM(), true},
+		{"SGB model with DMG ROM", ModelSGB, newLoopROM(0x00), false},
+		{"auto model with SGB ROM", ModelAuto, newSGBROM(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Model = tt.model
+			gameboy := NewGameBoy(config)
+			if err := gameboy.LoadROM(tt.rom); err != nil {
+				t.Fatalf("Failed to load ROM: %v", err)
+			}
+			if gameboy.IsSGB() != tt.wantSGB {
+				t.Errorf("Expected SGB=%v, got %v", tt.wantSGB, gameboy.IsSGB())
+			}
+			if tt.wantSGB && gameboy.cpu.GetC() != 0x14 {
+				t.Errorf("Expected C=0x14 after SGB boot, got 0x%02X", gameboy.cpu.GetC())
+			}
+		})
+	}
+}
+
+// TestSGBPackets verifica comandos enviados pelo JOYP e a saída com moldura
+func TestSGBPackets(t *testing.T) {
+	config := DefaultConfig()
+	config.Model = ModelSGB
+	config.EnableVSync = false
+	gameboy := NewGameBoy(config)
+	if err := gameboy.LoadROM(newSGBROM()); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+
+	// PAL01 com a cor 0 vermelha
+	var pal01 [input.SGBPacketSize]uint8
+	pal01[0] = sgb.CmdPAL01<<3 | 1
+	pal01[1] = 0x1F
+	sendSGBPacket(gameboy, pal01)
+	if got := gameboy.GetSGB().GetPalette(0)[0]; got != 0x001F {
+		t.Fatalf("Expected color 0 = 0x001F after PAL01, got 0x%04X", got)
+	}
+
+	var frames int
+	var last [sgb.Height][sgb.Width]uint16
+	gameboy.SetSGBFrameCallback(func(frame [sgb.Height][sgb.Width]uint16) {
+		frames++
+		last = frame
+	})
+	gameboy.Start()
+	gameboy.Step()
+	if frames != 1 {
+		t.Fatalf("Expected one SGB frame, got %d", frames)
+	}
+	if last[0][0] != 0x001F {
+		t.Errorf("Empty border should show color 0, got 0x%04X", last[0][0])
+	}
+
+	// MLT_REQ com 2 controles: P14/P15 em 1 leem o número do controle
+	var mlt [input.SGBPacketSize]uint8
+	mlt[0] = sgb.CmdMLTReq<<3 | 1
+	mlt[1] = 0x01
+	sendSGBPacket(gameboy, mlt)
+	if players := gameboy.GetInput().GetPlayerCount(); players != 2 {
+		t.Fatalf("Expected 2 players after MLT_REQ, got %d", players)
+	}
+
+	gameboy.GetInput().SetPlayerButtonState(1, input.ButtonA, true)
+	gameboy.mmu.Write(0xFF00, 0x30)
+	if got := gameboy.mmu.Read(0xFF00) & 0x0F; got != 0x0F {
+		t.Errorf("Expected player 1 ID 0xF, got 0x%X", got)
+	}
+	gameboy.mmu.Write(0xFF00, 0x10) // P15 em 0: botões do jogador 1
+	gameboy.mmu.Write(0xFF00, 0x30) // Subida de P15: próximo controle
+	if got := gameboy.mmu.Read(0xFF00) & 0x0F; got != 0x0E {
+		t.Errorf("Expected player 2 ID 0xE, got 0x%X", got)
+	}
+	gameboy.mmu.Write(0xFF00, 0x10)
+	if got := gameboy.mmu.Read(0xFF00) & 0x01; got != 0 {
+		t.Error("Expected A pressed on player 2")
+	}
+}
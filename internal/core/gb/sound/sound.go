@@ -1,6 +1,12 @@
 package sound
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+
+	"github.com/hobbiee/visualboy-go/internal/core/blip"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
+)
 
 // Constantes do Sound
 const (
@@ -10,36 +16,48 @@ const (
 	RegNR12 = 0xFF12 // Channel 1 Volume Envelope
 	RegNR13 = 0xFF13 // Channel 1 Frequency Lo
 	RegNR14 = 0xFF14 // Channel 1 Frequency Hi
-	
+
 	RegNR21 = 0xFF16 // Channel 2 Sound Length/Wave Pattern Duty
 	RegNR22 = 0xFF17 // Channel 2 Volume Envelope
 	RegNR23 = 0xFF18 // Channel 2 Frequency Lo
 	RegNR24 = 0xFF19 // Channel 2 Frequency Hi
-	
+
 	RegNR30 = 0xFF1A // Channel 3 Sound On/Off
 	RegNR31 = 0xFF1B // Channel 3 Sound Length
 	RegNR32 = 0xFF1C // Channel 3 Select Output Level
 	RegNR33 = 0xFF1D // Channel 3 Frequency Lo
 	RegNR34 = 0xFF1E // Channel 3 Frequency Hi
-	
+
 	RegNR41 = 0xFF20 // Channel 4 Sound Length
 	RegNR42 = 0xFF21 // Channel 4 Volume Envelope
 	RegNR43 = 0xFF22 // Channel 4 Polynomial Counter
 	RegNR44 = 0xFF23 // Channel 4 Counter/Consecutive; Initial
-	
+
 	RegNR50 = 0xFF24 // Channel Control / ON-OFF / Volume
 	RegNR51 = 0xFF25 // Selection of Sound Output Terminal
 	RegNR52 = 0xFF26 // Sound On/Off
-	
+
 	// Wave Pattern RAM
 	WaveRAMBase = 0xFF30
 	WaveRAMSize = 16
-	
-	// Frequência de amostragem
+
+	// Frequência de amostragem padrão
 	SampleRate = 44100
-	
+
 	// Número de canais
 	NumChannels = 4
+
+	// Clock do CPU (ciclos por segundo)
+	ClockSpeed = 4194304
+
+	// Ciclos entre passos do frame sequencer (512 Hz)
+	FrameSequencerPeriod = ClockSpeed / 512
+
+	// Amplitude máxima de um canal na saída int16
+	channelAmplitude = 4096
+
+	// Ciclos acumulados antes de fechar um frame do resampler
+	blipFrameCycles = ClockSpeed / 64
 )
 
 // Padrões de onda para canais 1 e 2
@@ -50,13 +68,25 @@ var WavePatterns = [4][8]uint8{
 	{0, 1, 1, 1, 1, 1, 1, 0}, // 75%
 }
 
+// Divisores do canal de ruído (NR43 bits 0-2)
+var noiseDivisors = [8]int{8, 16, 32, 48, 64, 80, 96, 112}
+
+// Máscaras OR aplicadas na leitura dos registradores (bits não legíveis)
+var readMasks = [0x17]uint8{
+	0x80, 0x3F, 0x00, 0xFF, 0xBF, // NR10-NR14
+	0xFF, 0x3F, 0x00, 0xFF, 0xBF, // (FF15), NR21-NR24
+	0x7F, 0xFF, 0x9F, 0xFF, 0xBF, // NR30-NR34
+	0xFF, 0xFF, 0x00, 0x00, 0xBF, // (FF1F), NR41-NR44
+	0x00, 0x00, 0x70, // NR50-NR52
+}
+
 // Channel representa um canal de som genérico
 type Channel struct {
 	enabled    bool
-	length     uint8
+	dacEnabled bool
 	volume     uint8
 	frequency  uint16
-	position   int
+	timer      int // Ciclos até o próximo passo da forma de onda
 	envelope   EnvelopeData
 	lengthData LengthData
 }
@@ -78,9 +108,9 @@ type LengthData struct {
 // SquareChannel representa um canal de onda quadrada (canais 1 e 2)
 type SquareChannel struct {
 	Channel
-	duty        uint8
-	sweepData   SweepData
-	patternPos  int
+	duty       uint8
+	sweepData  SweepData
+	patternPos int
 }
 
 // SweepData representa dados do sweep (apenas canal 1)
@@ -116,68 +146,140 @@ type Sound struct {
 	channel2 SquareChannel
 	channel3 WaveChannel
 	channel4 NoiseChannel
-	
+
 	// Registradores globais
 	nr50 uint8 // Master volume
 	nr51 uint8 // Sound panning
 	nr52 uint8 // Sound enable
-	
+
+	// Valores escritos em NR10-NR44 (para leitura)
+	regs [0x17]uint8
+
 	// Wave RAM
 	waveRAM [WaveRAMSize]uint8
-	
+
 	// Estado interno
 	frameSequencer int
 	cycles         int
-	
-	// Buffer de áudio
-	audioBuffer []int16
-	bufferPos   int
+	divClocked     bool // Frame sequencer clocado pelo tap do DIV do timer
+
+	// Resampler band-limited (clock do APU -> taxa de saída)
+	output     *blip.StereoBuffer
+	frameClock int                  // Ciclos desde o último EndFrame
+	lastLeft   [NumChannels]float64 // Última amplitude enviada por canal
+	lastRight  [NumChannels]float64
+
+	// Filtro passa-alta (capacitor da saída analógica)
+	capacitorLeft  float64
+	capacitorRight float64
+	charge         float64 // Fator do capacitor por amostra de saída
+
+	// Buffers temporários de leitura
+	leftSamples  []float64
+	rightSamples []float64
 }
 
 // NewSound cria uma nova instância do Sound
 func NewSound() *Sound {
-	return &Sound{
-		audioBuffer: make([]int16, SampleRate/60), // Buffer para 1/60 segundo
+	s := &Sound{
+		output: blip.NewStereoBuffer(ClockSpeed, SampleRate),
 	}
+	s.SetSampleRate(SampleRate)
+	return s
+}
+
+// SetSampleRate define a taxa de saída das amostras (ex.: 32000, 44100,
+// 48000 ou 96000 Hz). Amostras pendentes são descartadas.
+func (s *Sound) SetSampleRate(rate int) {
+	s.output.SetRates(ClockSpeed, rate)
+	s.charge = math.Pow(0.999958, float64(ClockSpeed)/float64(rate))
+	s.frameClock = 0
+	s.lastLeft = [NumChannels]float64{}
+	s.lastRight = [NumChannels]float64{}
+}
+
+// GetSampleRate retorna a taxa de saída das amostras
+func (s *Sound) GetSampleRate() int {
+	return s.output.SampleRate()
 }
 
 // Reset reinicia o som para seu estado inicial
 func (s *Sound) Reset() {
-	s.channel1 = SquareChannel{}
-	s.channel2 = SquareChannel{}
-	s.channel3 = WaveChannel{}
-	s.channel4 = NoiseChannel{}
-	
+	s.powerOff()
+
 	s.nr50 = 0x77
 	s.nr51 = 0xF3
-	s.nr52 = 0xF1
-	
+	s.nr52 = 0x80
+
 	// Limpa Wave RAM
 	for i := range s.waveRAM {
 		s.waveRAM[i] = 0
 	}
-	
-	s.frameSequencer = 0
+
 	s.cycles = 0
-	s.bufferPos = 0
+	s.capacitorLeft = 0
+	s.capacitorRight = 0
+	s.SetSampleRate(s.GetSampleRate())
+}
+
+// powerOff zera os registradores NR10-NR51; a Wave RAM é preservada
+func (s *Sound) powerOff() {
+	s.channel1 = SquareChannel{}
+	s.channel2 = SquareChannel{}
+	s.channel3 = WaveChannel{}
+	s.channel4 = NoiseChannel{}
+
+	for i := range s.regs {
+		s.regs[i] = 0
+	}
+	s.nr50 = 0
+	s.nr51 = 0
+	s.frameSequencer = 0
 }
 
 // Step executa um ciclo do sistema de som
 func (s *Sound) Step(cycles int) {
+	start := s.frameClock
+
+	if s.IsSoundEnabled() {
+		// Frame sequencer roda a 512 Hz; sem o timer conectado usa um
+		// contador próprio
+		if !s.divClocked {
+			s.cycles += cycles
+			for s.cycles >= FrameSequencerPeriod {
+				s.cycles -= FrameSequencerPeriod
+				s.stepFrameSequencer()
+				s.updateOutputs(start + cycles - s.cycles)
+			}
+		}
+
+		s.stepSquareTimer(0, &s.channel1, start, cycles)
+		s.stepSquareTimer(1, &s.channel2, start, cycles)
+		s.stepWaveTimer(start, cycles)
+		s.stepNoiseTimer(start, cycles)
+	}
+
+	s.frameClock += cycles
+	if s.frameClock >= blipFrameCycles {
+		s.endFrame()
+	}
+}
+
+// SetDIVClocked faz o frame sequencer ser clocado apenas por
+// ClockFrameSequencer (tap do DIV do timer) em vez do contador próprio
+func (s *Sound) SetDIVClocked(enabled bool) {
+	s.divClocked = enabled
+	s.cycles = 0
+}
+
+// ClockFrameSequencer avança o frame sequencer em um passo; chamado na
+// borda de descida do bit 12 do contador do DIV (bit 13 em velocidade dupla)
+func (s *Sound) ClockFrameSequencer() {
 	if !s.IsSoundEnabled() {
 		return
 	}
-	
-	s.cycles += cycles
-	
-	// Frame sequencer roda a 512 Hz
-	if s.cycles >= 8192 { // 4194304 / 512 = 8192
-		s.cycles -= 8192
-		s.stepFrameSequencer()
-	}
-	
-	// Gera amostras de áudio
-	s.generateSamples(cycles)
+	s.stepFrameSequencer()
+	s.updateOutputs(s.frameClock)
 }
 
 // stepFrameSequencer executa um passo do frame sequencer
@@ -186,81 +288,275 @@ func (s *Sound) stepFrameSequencer() {
 	if s.frameSequencer%2 == 0 {
 		s.stepLengthCounters()
 	}
-	
+
 	// Volume envelopes (step 7)
 	if s.frameSequencer == 7 {
 		s.stepVolumeEnvelopes()
 	}
-	
+
 	// Sweep (steps 2, 6)
 	if s.frameSequencer == 2 || s.frameSequencer == 6 {
 		s.stepSweep()
 	}
-	
+
 	s.frameSequencer = (s.frameSequencer + 1) % 8
 }
 
 // stepLengthCounters atualiza os contadores de duração
 func (s *Sound) stepLengthCounters() {
-	// Implementação básica - pode ser expandida
+	stepLength(&s.channel1.Channel)
+	stepLength(&s.channel2.Channel)
+	stepLength(&s.channel3.Channel)
+	stepLength(&s.channel4.Channel)
+}
+
+// stepLength decrementa o contador de duração e desliga o canal ao zerar
+func stepLength(ch *Channel) {
+	if !ch.lengthData.enabled || ch.lengthData.counter <= 0 {
+		return
+	}
+
+	ch.lengthData.counter--
+	if ch.lengthData.counter == 0 {
+		ch.enabled = false
+	}
 }
 
 // stepVolumeEnvelopes atualiza os envelopes de volume
 func (s *Sound) stepVolumeEnvelopes() {
-	// Implementação básica - pode ser expandida
+	stepEnvelope(&s.channel1.Channel)
+	stepEnvelope(&s.channel2.Channel)
+	stepEnvelope(&s.channel4.Channel)
+}
+
+// stepEnvelope avança o envelope de volume de um canal
+func stepEnvelope(ch *Channel) {
+	if ch.envelope.period == 0 {
+		return
+	}
+
+	ch.envelope.counter--
+	if ch.envelope.counter > 0 {
+		return
+	}
+	ch.envelope.counter = int(ch.envelope.period)
+
+	if ch.envelope.direction && ch.volume < 15 {
+		ch.volume++
+	} else if !ch.envelope.direction && ch.volume > 0 {
+		ch.volume--
+	}
 }
 
 // stepSweep atualiza o sweep do canal 1
 func (s *Sound) stepSweep() {
-	// Implementação básica - pode ser expandida
-}
-
-// generateSamples gera amostras de áudio
-func (s *Sound) generateSamples(cycles int) {
-	// Implementação básica - gera silêncio por enquanto
-	samplesNeeded := cycles * SampleRate / 4194304 // CPU frequency
-	
-	for i := 0; i < samplesNeeded && s.bufferPos < len(s.audioBuffer); i++ {
-		sample := int16(0)
-		
-		// Mix all channels
-		if s.channel1.enabled {
-			sample += s.getSquareChannelSample(&s.channel1)
-		}
-		if s.channel2.enabled {
-			sample += s.getSquareChannelSample(&s.channel2)
+	ch := &s.channel1
+	sweep := &ch.sweepData
+
+	sweep.counter--
+	if sweep.counter > 0 {
+		return
+	}
+	sweep.counter = sweepPeriod(sweep.period)
+
+	if !sweep.enabled || sweep.period == 0 {
+		return
+	}
+
+	newFrequency := s.calculateSweep()
+	if newFrequency <= 2047 && sweep.shift != 0 {
+		sweep.shadow = newFrequency
+		ch.frequency = newFrequency
+		s.regs[RegNR13-RegNR10] = uint8(newFrequency)
+		s.regs[RegNR14-RegNR10] = (s.regs[RegNR14-RegNR10] & 0xF8) | uint8(newFrequency>>8)
+
+		// Segunda verificação de overflow, sem aplicar o resultado
+		s.calculateSweep()
+	}
+}
+
+// calculateSweep calcula a próxima frequência do sweep e desliga o canal 1
+// em caso de overflow
+func (s *Sound) calculateSweep() uint16 {
+	sweep := &s.channel1.sweepData
+
+	delta := sweep.shadow >> sweep.shift
+	newFrequency := sweep.shadow + delta
+	if !sweep.direction {
+		newFrequency = sweep.shadow - delta
+	}
+
+	if newFrequency > 2047 {
+		s.channel1.enabled = false
+	}
+	return newFrequency
+}
+
+// sweepPeriod retorna o período efetivo do sweep (0 é tratado como 8)
+func sweepPeriod(period uint8) int {
+	if period == 0 {
+		return 8
+	}
+	return int(period)
+}
+
+// stepSquareTimer avança a forma de onda de um canal quadrado. Cada passo
+// é enviado ao resampler no ciclo exato em que ocorre.
+func (s *Sound) stepSquareTimer(index int, ch *SquareChannel, start, cycles int) {
+	ch.timer -= cycles
+	for ch.timer <= 0 {
+		clock := start + cycles + ch.timer
+		ch.timer += (2048 - int(ch.frequency)) * 4
+		ch.patternPos = (ch.patternPos + 1) % 8
+		s.updateChannel(index, clock)
+	}
+}
+
+// stepWaveTimer avança a leitura da Wave RAM
+func (s *Sound) stepWaveTimer(start, cycles int) {
+	ch := &s.channel3
+	ch.timer -= cycles
+	for ch.timer <= 0 {
+		clock := start + cycles + ch.timer
+		ch.timer += (2048 - int(ch.frequency)) * 2
+		ch.samplePos = (ch.samplePos + 1) % 32
+		s.updateChannel(2, clock)
+	}
+}
+
+// stepNoiseTimer avança o LFSR do canal de ruído
+func (s *Sound) stepNoiseTimer(start, cycles int) {
+	ch := &s.channel4
+
+	// Shifts 14 e 15 não recebem clock
+	if ch.clockShift >= 14 {
+		return
+	}
+
+	ch.timer -= cycles
+	for ch.timer <= 0 {
+		clock := start + cycles + ch.timer
+		ch.timer += noiseDivisors[ch.divisorCode] << ch.clockShift
+
+		xor := (ch.shiftRegister & 0x01) ^ ((ch.shiftRegister >> 1) & 0x01)
+		ch.shiftRegister = (ch.shiftRegister >> 1) | (xor << 14)
+		if ch.widthMode {
+			// Modo de 7 bits: o resultado também vai para o bit 6
+			ch.shiftRegister = (ch.shiftRegister &^ 0x40) | (xor << 6)
 		}
-		if s.channel3.enabled {
-			sample += s.getWaveChannelSample()
+		s.updateChannel(3, clock)
+	}
+}
+
+// channelOutput retorna a saída analógica (-1.0 a 1.0) de um canal
+func (s *Sound) channelOutput(index int) float64 {
+	switch index {
+	case 0:
+		return dacOutput(&s.channel1.Channel, s.getSquareChannelSample(&s.channel1))
+	case 1:
+		return dacOutput(&s.channel2.Channel, s.getSquareChannelSample(&s.channel2))
+	case 2:
+		return dacOutput(&s.channel3.Channel, s.getWaveChannelSample())
+	default:
+		return dacOutput(&s.channel4.Channel, s.getNoiseChannelSample())
+	}
+}
+
+// updateChannel envia ao resampler a mudança de amplitude de um canal,
+// aplicando NR51 (panning) e NR50 (volume mestre)
+func (s *Sound) updateChannel(index int, clock int) {
+	var left, right float64
+
+	if s.IsSoundEnabled() {
+		output := s.channelOutput(index)
+		if s.nr51&(0x10<<index) != 0 {
+			left = output * float64((s.nr50>>4)&0x07+1) / 8
 		}
-		if s.channel4.enabled {
-			sample += s.getNoiseChannelSample()
+		if s.nr51&(0x01<<index) != 0 {
+			right = output * float64(s.nr50&0x07+1) / 8
 		}
-		
-		s.audioBuffer[s.bufferPos] = sample
-		s.bufferPos++
+	}
+
+	s.output.AddDelta(clock, left-s.lastLeft[index], right-s.lastRight[index])
+	s.lastLeft[index] = left
+	s.lastRight[index] = right
+}
+
+// updateOutputs atualiza a amplitude de todos os canais no ciclo clock
+func (s *Sound) updateOutputs(clock int) {
+	for i := 0; i < NumChannels; i++ {
+		s.updateChannel(i, clock)
+	}
+}
+
+// endFrame fecha o frame atual do resampler. Se ninguém consumir o áudio,
+// mantém no máximo meio segundo de amostras.
+func (s *Sound) endFrame() {
+	s.updateOutputs(s.frameClock)
+	s.output.EndFrame(s.frameClock)
+	s.frameClock = 0
+
+	if excess := s.output.SamplesAvailable() - s.GetSampleRate()/2; excess > 0 {
+		s.output.RemoveSamples(excess)
 	}
 }
 
+// dacOutput converte a saída digital (0-15) de um canal em analógica
+func dacOutput(ch *Channel, digital uint8) float64 {
+	if !ch.dacEnabled {
+		return 0
+	}
+	if !ch.enabled {
+		digital = 0
+	}
+	return float64(digital)/7.5 - 1.0
+}
+
+// highPass remove o nível DC da saída, como o capacitor do hardware
+func (s *Sound) highPass(capacitor *float64, in float64) float64 {
+	out := in - *capacitor
+	*capacitor = in - out*s.charge
+	return out
+}
+
 // getSquareChannelSample obtém uma amostra de um canal de onda quadrada
-func (s *Sound) getSquareChannelSample(ch *SquareChannel) int16 {
-	// Implementação básica
+func (s *Sound) getSquareChannelSample(ch *SquareChannel) uint8 {
 	pattern := WavePatterns[ch.duty]
 	if pattern[ch.patternPos] == 1 {
-		return int16(ch.volume * 100) // Volume básico
+		return ch.volume
 	}
 	return 0
 }
 
 // getWaveChannelSample obtém uma amostra do canal de onda
-func (s *Sound) getWaveChannelSample() int16 {
-	// Implementação básica
-	return 0
+func (s *Sound) getWaveChannelSample() uint8 {
+	ch := &s.channel3
+
+	sample := s.waveRAM[ch.samplePos/2]
+	if ch.samplePos%2 == 0 {
+		sample >>= 4
+	}
+	sample &= 0x0F
+
+	// Nível de saída: 0 = mudo, 1 = 100%, 2 = 50%, 3 = 25%
+	switch ch.outputLevel {
+	case 0:
+		return 0
+	case 1:
+		return sample
+	case 2:
+		return sample >> 1
+	default:
+		return sample >> 2
+	}
 }
 
 // getNoiseChannelSample obtém uma amostra do canal de ruído
-func (s *Sound) getNoiseChannelSample() int16 {
-	// Implementação básica
+func (s *Sound) getNoiseChannelSample() uint8 {
+	ch := &s.channel4
+	if ch.shiftRegister&0x01 == 0 {
+		return ch.volume
+	}
 	return 0
 }
 
@@ -271,49 +567,207 @@ func (s *Sound) IsSoundEnabled() bool {
 
 // ReadRegister lê um registrador de som
 func (s *Sound) ReadRegister(addr uint16) uint8 {
-	switch addr {
-	case RegNR52:
-		return s.nr52 | 0x70 // Bits 4-6 sempre 1
-	case RegNR50:
+	switch {
+	case addr == RegNR52:
+		value := s.nr52&0x80 | 0x70 // Bits 4-6 sempre 1
+		for i := 1; i <= NumChannels; i++ {
+			if s.IsChannelEnabled(i) {
+				value |= 1 << (i - 1)
+			}
+		}
+		return value
+	case addr == RegNR50:
 		return s.nr50
-	case RegNR51:
+	case addr == RegNR51:
 		return s.nr51
+	case addr >= RegNR10 && addr <= RegNR44:
+		offset := addr - RegNR10
+		return s.regs[offset] | readMasks[offset]
+	case addr >= WaveRAMBase && addr < WaveRAMBase+WaveRAMSize:
+		return s.waveRAM[addr-WaveRAMBase]
 	default:
-		if addr >= WaveRAMBase && addr < WaveRAMBase+WaveRAMSize {
-			return s.waveRAM[addr-WaveRAMBase]
-		}
 		return 0xFF
 	}
 }
 
 // WriteRegister escreve em um registrador de som
 func (s *Sound) WriteRegister(addr uint16, value uint8) {
+	// Wave RAM continua acessível com o som desligado
+	if addr >= WaveRAMBase && addr < WaveRAMBase+WaveRAMSize {
+		s.waveRAM[addr-WaveRAMBase] = value
+		s.updateChannel(2, s.frameClock)
+		return
+	}
+
 	if !s.IsSoundEnabled() && addr != RegNR52 {
 		return // Não pode escrever quando som está desabilitado
 	}
-	
-	switch addr {
-	case RegNR52:
+
+	switch {
+	case addr == RegNR52:
+		wasEnabled := s.IsSoundEnabled()
 		s.nr52 = value & 0x80 // Apenas bit 7 pode ser escrito
-		if !s.IsSoundEnabled() {
-			s.Reset() // Desabilitar som reseta tudo
+		if wasEnabled && !s.IsSoundEnabled() {
+			s.powerOff() // Desabilitar som zera os registradores
+		} else if !wasEnabled && s.IsSoundEnabled() {
+			s.frameSequencer = 0
 		}
-	case RegNR50:
+	case addr == RegNR50:
 		s.nr50 = value
-	case RegNR51:
+	case addr == RegNR51:
 		s.nr51 = value
-	default:
-		if addr >= WaveRAMBase && addr < WaveRAMBase+WaveRAMSize {
-			s.waveRAM[addr-WaveRAMBase] = value
+	case addr >= RegNR10 && addr <= RegNR44:
+		s.regs[addr-RegNR10] = value
+		s.writeChannelRegister(addr, value)
+	}
+
+	s.updateOutputs(s.frameClock)
+}
+
+// writeChannelRegister aplica uma escrita em NR10-NR44 ao canal correspondente
+func (s *Sound) writeChannelRegister(addr uint16, value uint8) {
+	switch addr {
+	// Canal 1
+	case RegNR10:
+		s.channel1.sweepData.period = (value >> 4) & 0x07
+		s.channel1.sweepData.direction = value&0x08 == 0
+		s.channel1.sweepData.shift = value & 0x07
+	case RegNR11:
+		s.channel1.duty = value >> 6
+		s.channel1.lengthData.counter = 64 - int(value&0x3F)
+	case RegNR12:
+		writeEnvelope(&s.channel1.Channel, value)
+	case RegNR13:
+		s.channel1.frequency = (s.channel1.frequency & 0x700) | uint16(value)
+	case RegNR14:
+		s.channel1.frequency = (s.channel1.frequency & 0xFF) | uint16(value&0x07)<<8
+		s.channel1.lengthData.enabled = value&0x40 != 0
+		if value&0x80 != 0 {
+			s.triggerSquare(&s.channel1, 64)
+			s.triggerSweep()
+		}
+
+	// Canal 2
+	case RegNR21:
+		s.channel2.duty = value >> 6
+		s.channel2.lengthData.counter = 64 - int(value&0x3F)
+	case RegNR22:
+		writeEnvelope(&s.channel2.Channel, value)
+	case RegNR23:
+		s.channel2.frequency = (s.channel2.frequency & 0x700) | uint16(value)
+	case RegNR24:
+		s.channel2.frequency = (s.channel2.frequency & 0xFF) | uint16(value&0x07)<<8
+		s.channel2.lengthData.enabled = value&0x40 != 0
+		if value&0x80 != 0 {
+			s.triggerSquare(&s.channel2, 64)
+		}
+
+	// Canal 3
+	case RegNR30:
+		s.channel3.dacEnabled = value&0x80 != 0
+		if !s.channel3.dacEnabled {
+			s.channel3.enabled = false
+		}
+	case RegNR31:
+		s.channel3.lengthData.counter = 256 - int(value)
+	case RegNR32:
+		s.channel3.outputLevel = (value >> 5) & 0x03
+	case RegNR33:
+		s.channel3.frequency = (s.channel3.frequency & 0x700) | uint16(value)
+	case RegNR34:
+		s.channel3.frequency = (s.channel3.frequency & 0xFF) | uint16(value&0x07)<<8
+		s.channel3.lengthData.enabled = value&0x40 != 0
+		if value&0x80 != 0 {
+			ch := &s.channel3
+			ch.enabled = ch.dacEnabled
+			if ch.lengthData.counter == 0 {
+				ch.lengthData.counter = 256
+			}
+			ch.timer = (2048 - int(ch.frequency)) * 2
+			ch.samplePos = 0
+		}
+
+	// Canal 4
+	case RegNR41:
+		s.channel4.lengthData.counter = 64 - int(value&0x3F)
+	case RegNR42:
+		writeEnvelope(&s.channel4.Channel, value)
+	case RegNR43:
+		s.channel4.clockShift = value >> 4
+		s.channel4.widthMode = value&0x08 != 0
+		s.channel4.divisorCode = value & 0x07
+	case RegNR44:
+		s.channel4.lengthData.enabled = value&0x40 != 0
+		if value&0x80 != 0 {
+			ch := &s.channel4
+			triggerChannel(&ch.Channel, 64)
+			ch.timer = noiseDivisors[ch.divisorCode] << ch.clockShift
+			ch.shiftRegister = 0x7FFF
 		}
 	}
 }
 
-// GetAudioBuffer retorna o buffer de áudio atual
+// writeEnvelope processa escritas em NRx2 (envelope e DAC)
+func writeEnvelope(ch *Channel, value uint8) {
+	ch.envelope.initialVolume = value >> 4
+	ch.envelope.direction = value&0x08 != 0
+	ch.envelope.period = value & 0x07
+
+	// DAC ligado se os 5 bits superiores não forem zero
+	ch.dacEnabled = value&0xF8 != 0
+	if !ch.dacEnabled {
+		ch.enabled = false
+	}
+}
+
+// triggerChannel reinicia um canal com envelope (1, 2 e 4)
+func triggerChannel(ch *Channel, maxLength int) {
+	ch.enabled = ch.dacEnabled
+	if ch.lengthData.counter == 0 {
+		ch.lengthData.counter = maxLength
+	}
+	ch.volume = ch.envelope.initialVolume
+	ch.envelope.counter = int(ch.envelope.period)
+}
+
+// triggerSquare reinicia um canal de onda quadrada
+func (s *Sound) triggerSquare(ch *SquareChannel, maxLength int) {
+	triggerChannel(&ch.Channel, maxLength)
+	ch.timer = (2048 - int(ch.frequency)) * 4
+}
+
+// triggerSweep reinicia o sweep do canal 1
+func (s *Sound) triggerSweep() {
+	sweep := &s.channel1.sweepData
+	sweep.shadow = s.channel1.frequency
+	sweep.counter = sweepPeriod(sweep.period)
+	sweep.enabled = sweep.period != 0 || sweep.shift != 0
+
+	if sweep.shift != 0 {
+		s.calculateSweep()
+	}
+}
+
+// GetAudioBuffer retorna as amostras geradas até o momento em estéreo
+// intercalado (esquerdo, direito, esquerdo, direito...)
 func (s *Sound) GetAudioBuffer() []int16 {
-	buffer := make([]int16, s.bufferPos)
-	copy(buffer, s.audioBuffer[:s.bufferPos])
-	s.bufferPos = 0 // Reset buffer position
+	s.endFrame()
+
+	count := s.output.SamplesAvailable()
+	if len(s.leftSamples) < count {
+		s.leftSamples = make([]float64, count)
+		s.rightSamples = make([]float64, count)
+	}
+
+	left := s.leftSamples[:count]
+	right := s.rightSamples[:count]
+	s.output.ReadStereo(left, right)
+
+	buffer := make([]int16, count*2)
+	for i := 0; i < count; i++ {
+		buffer[i*2] = blip.Clamp(s.highPass(&s.capacitorLeft, left[i]) * channelAmplitude)
+		buffer[i*2+1] = blip.Clamp(s.highPass(&s.capacitorRight, right[i]) * channelAmplitude)
+	}
 	return buffer
 }
 
@@ -333,13 +787,78 @@ func (s *Sound) IsChannelEnabled(channel int) bool {
 	}
 }
 
+// channelFields lista os campos comuns de um canal para o save state
+func channelFields(ch *Channel) []any {
+	return []any{
+		&ch.enabled, &ch.dacEnabled, &ch.volume, &ch.frequency, &ch.timer,
+		&ch.envelope.initialVolume, &ch.envelope.direction, &ch.envelope.period, &ch.envelope.counter,
+		&ch.lengthData.enabled, &ch.lengthData.counter,
+	}
+}
+
+// squareFields lista os campos de um canal de onda quadrada
+func squareFields(ch *SquareChannel) []any {
+	sweep := &ch.sweepData
+	return append(channelFields(&ch.Channel), &ch.duty, &ch.patternPos,
+		&sweep.enabled, &sweep.period, &sweep.direction, &sweep.shift, &sweep.counter, &sweep.shadow)
+}
+
+// stateFields lista os campos gravados no save state
+func (s *Sound) stateFields() []any {
+	fields := squareFields(&s.channel1)
+	fields = append(fields, squareFields(&s.channel2)...)
+	fields = append(fields, channelFields(&s.channel3.Channel)...)
+	fields = append(fields, &s.channel3.outputLevel, &s.channel3.samplePos)
+	fields = append(fields, channelFields(&s.channel4.Channel)...)
+	fields = append(fields, &s.channel4.shiftRegister, &s.channel4.clockShift,
+		&s.channel4.widthMode, &s.channel4.divisorCode)
+	return append(fields,
+		&s.nr50, &s.nr51, &s.nr52, &s.regs, &s.waveRAM,
+		&s.frameSequencer, &s.cycles, &s.frameClock,
+		&s.lastLeft, &s.lastRight, &s.capacitorLeft, &s.capacitorRight,
+	)
+}
+
+// SaveState serializa os canais, o frame sequencer e as amostras ainda
+// não lidas do resampler
+func (s *Sound) SaveState() ([]byte, error) {
+	data, err := savestate.Marshal(s.stateFields()...)
+	if err != nil {
+		return nil, err
+	}
+	output, err := s.output.SaveState()
+	if err != nil {
+		return nil, err
+	}
+	return append(data, output...), nil
+}
+
+// LoadState restaura o estado gerado por SaveState
+func (s *Sound) LoadState(data []byte) error {
+	fields := s.stateFields()
+	size, err := savestate.Size(fields...)
+	if err != nil {
+		return err
+	}
+	if len(data) < size {
+		return fmt.Errorf("estado do som truncado: %d bytes", len(data))
+	}
+	if err := s.output.LoadState(data[size:]); err != nil {
+		return fmt.Errorf("estado do som inválido: %w", err)
+	}
+	if err := savestate.Unmarshal(data[:size], fields...); err != nil {
+		return fmt.Errorf("estado do som inválido: %w", err)
+	}
+	return nil
+}
+
 // String retorna uma representação em string do estado do som
 func (s *Sound) String() string {
 	enabled := "disabled"
 	if s.IsSoundEnabled() {
 		enabled = "enabled"
 	}
-	
+
 	return fmt.Sprintf("Sound: %s NR50=0x%02X NR51=0x%02X NR52=0x%02X",
-		enabled, s.nr50, s.nr51, s.nr52)
+		enabled, s.nr50, s.nr51, s.ReadRegister(RegNR52))
 }
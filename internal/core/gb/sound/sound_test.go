@@ -0,0 +1,280 @@
+package sound
+
+import "testing"
+
+// newPoweredSound cria um Sound ligado, com todos os canais nas duas saídas
+func newPoweredSound() *Sound {
+	s := NewSound()
+	s.Reset()
+	s.WriteRegister(RegNR50, 0x77)
+	s.WriteRegister(RegNR51, 0xFF)
+	return s
+}
+
+func TestSoundTriggerSetsNR52Status(t *testing.T) {
+	s := newPoweredSound()
+
+	// DAC desligado: trigger não habilita o canal
+	s.WriteRegister(RegNR22, 0x00)
+	s.WriteRegister(RegNR24, 0x80)
+	if s.IsChannelEnabled(2) {
+		t.Error("canal 2 não deveria ligar com o DAC desligado")
+	}
+
+	s.WriteRegister(RegNR22, 0xF0)
+	s.WriteRegister(RegNR24, 0x80)
+	if !s.IsChannelEnabled(2) {
+		t.Fatal("canal 2 deveria ligar após o trigger")
+	}
+	if got := s.ReadRegister(RegNR52); got != 0xF2 {
+		t.Errorf("NR52: esperado 0xF2, obtido 0x%02X", got)
+	}
+
+	// Desligar o DAC desliga o canal
+	s.WriteRegister(RegNR22, 0x00)
+	if s.IsChannelEnabled(2) {
+		t.Error("canal 2 deveria desligar junto com o DAC")
+	}
+}
+
+func TestSoundRegisterReadMasks(t *testing.T) {
+	s := newPoweredSound()
+
+	tests := []struct {
+		addr  uint16
+		value uint8
+		want  uint8
+	}{
+		{RegNR10, 0x00, 0x80},
+		{RegNR11, 0x80, 0xBF},
+		{RegNR12, 0xA5, 0xA5},
+		{RegNR13, 0x12, 0xFF},
+		{RegNR14, 0x40, 0xFF},
+		{RegNR30, 0x00, 0x7F},
+		{RegNR32, 0x20, 0xBF},
+		{RegNR43, 0x5A, 0x5A},
+		{0xFF15, 0x00, 0xFF},
+	}
+
+	for _, tt := range tests {
+		s.WriteRegister(tt.addr, tt.value)
+		if got := s.ReadRegister(tt.addr); got != tt.want {
+			t.Errorf("0x%04X: esperado 0x%02X, obtido 0x%02X", tt.addr, tt.want, got)
+		}
+	}
+}
+
+func TestSoundPowerOffClearsRegisters(t *testing.T) {
+	s := newPoweredSound()
+
+	s.WriteRegister(WaveRAMBase, 0xAB)
+	s.WriteRegister(RegNR12, 0xF0)
+	s.WriteRegister(RegNR14, 0x80)
+
+	s.WriteRegister(RegNR52, 0x00)
+	if s.IsChannelEnabled(1) {
+		t.Error("canal 1 deveria desligar com o som")
+	}
+	if got := s.ReadRegister(RegNR12); got != 0x00 {
+		t.Errorf("NR12 após desligar: esperado 0x00, obtido 0x%02X", got)
+	}
+
+	// Escritas são ignoradas com o som desligado, exceto na Wave RAM
+	s.WriteRegister(RegNR50, 0x77)
+	if got := s.ReadRegister(RegNR50); got != 0x00 {
+		t.Errorf("NR50 não deveria aceitar escrita desligado, obtido 0x%02X", got)
+	}
+	if got := s.ReadRegister(WaveRAMBase); got != 0xAB {
+		t.Errorf("Wave RAM deveria ser preservada, obtido 0x%02X", got)
+	}
+}
+
+func TestSoundLengthCounterDisablesChannel(t *testing.T) {
+	s := newPoweredSound()
+
+	s.WriteRegister(RegNR42, 0xF0)
+	s.WriteRegister(RegNR41, 0x3E) // Duração 2
+	s.WriteRegister(RegNR44, 0xC0) // Trigger com length habilitado
+
+	// Length counter recebe clock a cada 2 passos do frame sequencer
+	s.Step(FrameSequencerPeriod)
+	if !s.IsChannelEnabled(4) {
+		t.Fatal("canal 4 não deveria desligar após 1 clock de length")
+	}
+	s.Step(FrameSequencerPeriod * 2)
+	if s.IsChannelEnabled(4) {
+		t.Error("canal 4 deveria desligar ao zerar o length counter")
+	}
+}
+
+func TestSoundVolumeEnvelope(t *testing.T) {
+	s := newPoweredSound()
+
+	s.WriteRegister(RegNR12, 0x29) // Volume 2, aumenta, período 1
+	s.WriteRegister(RegNR14, 0x80)
+
+	// Envelope recebe clock no passo 7 (a cada 8 passos)
+	s.Step(FrameSequencerPeriod * 8)
+	if s.channel1.volume != 3 {
+		t.Errorf("volume: esperado 3, obtido %d", s.channel1.volume)
+	}
+
+	s.Step(FrameSequencerPeriod * 8 * 20)
+	if s.channel1.volume != 15 {
+		t.Errorf("volume deveria saturar em 15, obtido %d", s.channel1.volume)
+	}
+}
+
+func TestSoundSweepOverflowDisablesChannel(t *testing.T) {
+	s := newPoweredSound()
+
+	s.WriteRegister(RegNR12, 0xF0)
+	s.WriteRegister(RegNR13, 0xFF)
+	s.WriteRegister(RegNR10, 0x11) // Período 1, aumenta, shift 1
+
+	// 0x7FF + (0x7FF >> 1) passa de 2047 já no trigger
+	s.WriteRegister(RegNR14, 0x87)
+	if s.IsChannelEnabled(1) {
+		t.Error("canal 1 deveria desligar por overflow do sweep no trigger")
+	}
+
+	// 0x500 + 0x280 = 0x780, e o próximo cálculo (0x780 + 0x3C0) estoura
+	s.WriteRegister(RegNR13, 0x00)
+	s.WriteRegister(RegNR14, 0x85)
+	if !s.IsChannelEnabled(1) {
+		t.Fatal("canal 1 deveria ligar com frequência 0x500")
+	}
+
+	s.Step(FrameSequencerPeriod * 3) // Sweep no passo 2
+	if s.channel1.frequency != 0x780 {
+		t.Errorf("frequência após sweep: esperado 0x780, obtido 0x%03X", s.channel1.frequency)
+	}
+	if s.IsChannelEnabled(1) {
+		t.Error("canal 1 deveria desligar pela segunda verificação de overflow")
+	}
+}
+
+func TestSoundNoiseLFSR(t *testing.T) {
+	s := newPoweredSound()
+
+	s.WriteRegister(RegNR42, 0xF0)
+	s.WriteRegister(RegNR43, 0x00) // Divisor 8, shift 0, 15 bits
+	s.WriteRegister(RegNR44, 0x80)
+
+	if s.channel4.shiftRegister != 0x7FFF {
+		t.Fatalf("LFSR após trigger: esperado 0x7FFF, obtido 0x%04X", s.channel4.shiftRegister)
+	}
+
+	// Um clock: bits 0 e 1 iguais, XOR = 0 entra no bit 14
+	s.Step(8)
+	if s.channel4.shiftRegister != 0x3FFF {
+		t.Errorf("LFSR 15 bits: esperado 0x3FFF, obtido 0x%04X", s.channel4.shiftRegister)
+	}
+
+	// Modo de 7 bits também grava o resultado no bit 6
+	s.WriteRegister(RegNR43, 0x08)
+	s.WriteRegister(RegNR44, 0x80)
+	s.Step(8)
+	if s.channel4.shiftRegister != 0x3FBF {
+		t.Errorf("LFSR 7 bits: esperado 0x3FBF, obtido 0x%04X", s.channel4.shiftRegister)
+	}
+}
+
+func TestSoundWaveOutputLevel(t *testing.T) {
+	s := newPoweredSound()
+
+	s.WriteRegister(WaveRAMBase, 0xC0)
+	s.WriteRegister(RegNR30, 0x80)
+	s.WriteRegister(RegNR34, 0x80)
+
+	levels := []struct {
+		nr32 uint8
+		want uint8
+	}{
+		{0x00, 0},
+		{0x20, 12},
+		{0x40, 6},
+		{0x60, 3},
+	}
+
+	for _, level := range levels {
+		s.WriteRegister(RegNR32, level.nr32)
+		if got := s.getWaveChannelSample(); got != level.want {
+			t.Errorf("NR32=0x%02X: esperado %d, obtido %d", level.nr32, level.want, got)
+		}
+	}
+}
+
+func TestSoundStereoPanning(t *testing.T) {
+	s := newPoweredSound()
+
+	// Canal 2 com duty 75% e volume máximo apenas na saída esquerda
+	s.WriteRegister(RegNR51, 0x20)
+	s.WriteRegister(RegNR21, 0xC0)
+	s.WriteRegister(RegNR22, 0xF0)
+	s.WriteRegister(RegNR23, 0x00)
+	s.WriteRegister(RegNR24, 0x87)
+
+	s.GetAudioBuffer()
+	s.Step(ClockSpeed / 60)
+	buffer := s.GetAudioBuffer()
+
+	if len(buffer) == 0 || len(buffer)%2 != 0 {
+		t.Fatalf("buffer deveria ter pares L/R, obtido %d amostras", len(buffer))
+	}
+
+	var left, right int
+	for i := 0; i < len(buffer); i += 2 {
+		if buffer[i] != 0 {
+			left++
+		}
+		if buffer[i+1] != 0 {
+			right++
+		}
+	}
+
+	if left == 0 {
+		t.Error("saída esquerda deveria conter o canal 2")
+	}
+	if right != 0 {
+		t.Errorf("saída direita deveria estar em silêncio, %d amostras não nulas", right)
+	}
+}
+
+func TestSoundShortStepsProduceExactSampleCount(t *testing.T) {
+	rates := []int{32000, 44100, 48000, 96000}
+
+	for _, rate := range rates {
+		s := newPoweredSound()
+		s.SetSampleRate(rate)
+
+		// 1/4 de segundo em passos de 4 ciclos (instruções curtas)
+		for i := 0; i < ClockSpeed/16; i++ {
+			s.Step(4)
+		}
+
+		if got := len(s.GetAudioBuffer()); got != rate/2 {
+			t.Errorf("%d Hz: esperado %d amostras intercaladas, obtido %d", rate, rate/2, got)
+		}
+	}
+}
+
+func TestSoundDIVClockedFrameSequencer(t *testing.T) {
+	s := newPoweredSound()
+	s.SetDIVClocked(true)
+
+	s.WriteRegister(RegNR42, 0xF0)
+	s.WriteRegister(RegNR41, 0x3F) // Duração 1
+	s.WriteRegister(RegNR44, 0xC0)
+
+	// Sem clock do DIV o contador próprio não avança
+	s.Step(FrameSequencerPeriod * 4)
+	if !s.IsChannelEnabled(4) {
+		t.Fatal("frame sequencer não deveria avançar sem o tap do DIV")
+	}
+
+	s.ClockFrameSequencer()
+	if s.IsChannelEnabled(4) {
+		t.Error("clock do DIV deveria zerar o length counter")
+	}
+}
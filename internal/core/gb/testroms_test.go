@@ -0,0 +1,413 @@
+package gb
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/serial"
+)
+
+// Diretório das ROMs de teste da comunidade (Blargg e Mooneye). A variável
+// de ambiente tem precedência sobre testdata/test-roms.
+const (
+	testROMsEnv = "VISUALBOY_TEST_ROMS"
+	testROMsDir = "testdata/test-roms"
+)
+
+// Limite de ciclos de cada suíte (4194304 ciclos = 1 segundo emulado)
+const (
+	blarggMaxCycles  = 120 * 4194304 // cpu_instrs completo leva ~55 s
+	mooneyeMaxCycles = 20 * 4194304
+
+	// Ciclos executados após o resultado na serial, para capturar o resto
+	// da mensagem (ex.: "Failed #3")
+	blarggTrailCycles = 4194304 / 4
+)
+
+// testROMKind é o protocolo usado pela ROM para informar o resultado
+type testROMKind int
+
+const (
+	blarggROM  testROMKind = iota // Texto na serial ou em 0xA000
+	mooneyeROM                    // Registradores após LD B,B
+)
+
+// blarggSuites são os diretórios das ROMs do Blargg executadas
+var blarggSuites = map[string]bool{
+	"cpu_instrs":   true,
+	"instr_timing": true,
+	"mem_timing":   true,
+	"mem_timing-2": true,
+	"dmg_sound":    true,
+}
+
+// testROM é uma ROM encontrada no diretório
+type testROM struct {
+	path  string
+	suite string // Diretório relativo
+	name  string
+	kind  testROMKind
+}
+
+// testROMResult é o resultado de uma ROM
+type testROMResult struct {
+	rom     testROM
+	status  string // "pass", "fail", "timeout" ou "skip"
+	detail  string
+	cycles  uint64
+	elapsed time.Duration
+}
+
+// findTestROMs lista as ROMs reconhecidas em root: as suítes do Blargg pelo
+// nome do diretório e as do Mooneye em acceptance/ e emulator-only/.
+// ROMs de outras suítes e as de verificação manual são ignoradas.
+func findTestROMs(root string) ([]testROM, error) {
+	var roms []testROM
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".gb" && ext != ".gbc" {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		rom := testROM{path: path, suite: filepath.Dir(rel), name: strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))}
+		dirs := strings.Split(filepath.ToSlash(rom.suite), "/")
+		for _, dir := range dirs {
+			switch {
+			case dir == "manual-only" || dir == "utils":
+				return nil
+			case blarggSuites[dir]:
+				rom.kind = blarggROM
+				roms = append(roms, rom)
+				return nil
+			case dir == "acceptance" || dir == "emulator-only":
+				rom.kind = mooneyeROM
+				roms = append(roms, rom)
+				return nil
+			}
+		}
+		return nil
+	})
+	return roms, err
+}
+
+// mooneyeModel escolhe o hardware pelo sufixo do nome da ROM (boot_regs-dmgABC,
+// di_timing-GS, boot_hwio-C...). ok é false para hardware não emulado.
+func mooneyeModel(name string) (model Model, ok bool) {
+	i := strings.LastIndex(name, "-")
+	if i < 0 {
+		return ModelDMG, true
+	}
+	switch name[i+1:] {
+	case "dmgABC", "dmgABCmgb", "G", "GS":
+		return ModelDMG, true
+	case "C", "cgb", "cgbABCDE":
+		return ModelCGB, true
+	case "dmg0", "mgb", "S", "sgb", "sgb2", "A", "agb", "agb0", "agbB", "ags", "cgb0":
+		return ModelAuto, false
+	}
+	// Hífen que faz parte do nome, sem sufixo de modelo
+	return ModelDMG, true
+}
+
+// blarggResult procura o resultado na saída serial e, para ROMs que usam
+// o protocolo de memória, em 0xA000 (status) e 0xA004 (texto), validado
+// pela assinatura DE B0 61 em 0xA001
+func blarggResult(gameboy *GameBoy, output string) (status, detail string, done bool) {
+	switch {
+	case strings.Contains(output, "Passed"):
+		return "pass", "", true
+	case strings.Contains(output, "Failed"):
+		return "fail", "", true
+	}
+
+	mmu := gameboy.mmu
+	if mmu.Read(0xA001) != 0xDE || mmu.Read(0xA002) != 0xB0 || mmu.Read(0xA003) != 0x61 {
+		return "", "", false
+	}
+	code := mmu.Read(0xA000)
+	if code == 0x80 {
+		return "", "", false // Em execução
+	}
+
+	var text strings.Builder
+	for addr := uint16(0xA004); addr < 0xC000; addr++ {
+		c := mmu.Read(addr)
+		if c == 0 {
+			break
+		}
+		text.WriteByte(c)
+	}
+	if code == 0 {
+		return "pass", "", true
+	}
+	return "fail", fmt.Sprintf("code %d: %s", code, strings.TrimSpace(text.String())), true
+}
+
+// mooneyeResult confere os registradores após LD B,B: a sequência de
+// Fibonacci indica sucesso
+func mooneyeResult(gameboy *GameBoy) (status, detail string) {
+	c := gameboy.cpu
+	if c.GetB() == 3 && c.GetC() == 5 && c.GetD() == 8 && c.GetE() == 13 && c.GetH() == 21 && c.GetL() == 34 {
+		return "pass", ""
+	}
+	return "fail", fmt.Sprintf("B=%02X C=%02X D=%02X E=%02X H=%02X L=%02X",
+		c.GetB(), c.GetC(), c.GetD(), c.GetE(), c.GetH(), c.GetL())
+}
+
+// runTestROM executa a ROM até ela informar o resultado ou atingir
+// maxCycles ciclos
+func runTestROM(rom testROM, maxCycles uint64) (result testROMResult) {
+	result.rom = rom
+	start := time.Now()
+	defer func() { result.elapsed = time.Since(start) }()
+
+	config := DefaultConfig()
+	config.EnableVSync = false
+	config.EnableSound = false
+	if rom.kind == mooneyeROM {
+		model, ok := mooneyeModel(rom.name)
+		if !ok {
+			result.status, result.detail = "skip", "hardware not emulated"
+			return result
+		}
+		config.Model = model
+	}
+
+	data, err := os.ReadFile(rom.path)
+	if err != nil {
+		result.status, result.detail = "fail", err.Error()
+		return result
+	}
+	gameboy := NewGameBoy(config)
+	if err := gameboy.LoadROM(data); err != nil {
+		result.status, result.detail = "fail", err.Error()
+		return result
+	}
+
+	var output strings.Builder
+	outputChanged := false
+	peer := serial.NewLoopbackPeer()
+	peer.SetWriteCallback(func(b uint8) {
+		output.WriteByte(b)
+		outputChanged = true
+	})
+	gameboy.SetSerialPeer(peer)
+
+	deadline := maxCycles
+	for gameboy.cycleCount < deadline {
+		pc := gameboy.cpu.GetPC()
+		ldBB := rom.kind == mooneyeROM && !gameboy.cpu.IsHalted() && gameboy.mmu.Read(pc) == 0x40
+
+		_, frameDone := gameboy.stepInstruction()
+
+		// LD B,B executado (e não uma interrupção atendida no lugar dele)
+		if ldBB && gameboy.cpu.GetPC() == pc+1 {
+			result.status, result.detail = mooneyeResult(gameboy)
+			break
+		}
+		if rom.kind == blarggROM && result.status == "" && (outputChanged || frameDone) {
+			outputChanged = false
+			if status, detail, done := blarggResult(gameboy, output.String()); done {
+				result.status, result.detail = status, detail
+				deadline = gameboy.cycleCount + blarggTrailCycles
+			}
+		}
+	}
+
+	result.cycles = gameboy.cycleCount
+	if result.status == "" {
+		result.status = "timeout"
+	}
+	if result.status != "pass" && result.detail == "" {
+		result.detail = strings.TrimSpace(output.String())
+	}
+	return result
+}
+
+// writeTestROMSummary monta a tabela com o resultado de cada ROM
+func writeTestROMSummary(results []testROMResult) string {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].rom.suite != results[j].rom.suite {
+			return results[i].rom.suite < results[j].rom.suite
+		}
+		return results[i].rom.name < results[j].rom.name
+	})
+
+	var b strings.Builder
+	counts := make(map[string]int)
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SUITE\tROM\tRESULT\tEMULATED (s)\tTIME\tDETAIL")
+	for _, r := range results {
+		counts[r.status]++
+		detail := strings.ReplaceAll(r.detail, "\n", " ")
+		if len(detail) > 60 {
+			detail = detail[:57] + "..."
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.1f\t%v\t%s\n", r.rom.suite, r.rom.name, r.status,
+			float64(r.cycles)/4194304, r.elapsed.Round(time.Millisecond), detail)
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "%d ROMs: %d pass, %d fail, %d timeout, %d skip\n", len(results),
+		counts["pass"], counts["fail"], counts["timeout"], counts["skip"])
+	return b.String()
+}
+
+// TestCommunityROMs executa as ROMs de teste do Blargg (cpu_instrs,
+// instr_timing, mem_timing, dmg_sound) e do Mooneye (acceptance) de
+// $VISUALBOY_TEST_ROMS ou testdata/test-roms, e mostra uma tabela com o
+// resultado. Sem as ROMs o teste é ignorado.
+func TestCommunityROMs(t *testing.T) {
+	root := os.Getenv(testROMsEnv)
+	if root == "" {
+		root = testROMsDir
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Skipf("test ROMs not found (set %s or add them to %s)", testROMsEnv, testROMsDir)
+	}
+	if testing.Short() {
+		t.Skip("skipping test ROMs in short mode")
+	}
+
+	roms, err := findTestROMs(root)
+	if err != nil {
+		t.Fatalf("Failed to list test ROMs: %v", err)
+	}
+	if len(roms) == 0 {
+		t.Skipf("no Blargg or Mooneye ROMs found in %s", root)
+	}
+
+	var mu sync.Mutex
+	var results []testROMResult
+	t.Run("roms", func(t *testing.T) {
+		for _, rom := range roms {
+			rom := rom
+			t.Run(filepath.ToSlash(filepath.Join(rom.suite, rom.name)), func(t *testing.T) {
+				t.Parallel()
+				maxCycles := uint64(blarggMaxCycles)
+				if rom.kind == mooneyeROM {
+					maxCycles = mooneyeMaxCycles
+				}
+				result := runTestROM(rom, maxCycles)
+
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+
+				switch result.status {
+				case "skip":
+					t.Skip(result.detail)
+				case "fail", "timeout":
+					t.Errorf("%s after %.1f emulated seconds: %s", result.status, float64(result.cycles)/4194304, result.detail)
+				}
+			})
+		}
+	})
+
+	t.Log("\n" + writeTestROMSummary(results))
+}
+
+// newSerialTextROM cria uma ROM que envia text pela serial, esperando o fim
+// de cada transferência, e fica em loop
+func newSerialTextROM(text string) []uint8 {
+	rom := newLoopROM(0x00)
+	copy(rom[0x100:], []uint8{0xC3, 0x50, 0x01}) // JP 0x0150
+	copy(rom[0x150:], []uint8{
+		0x21, 0x70, 0x01, // LD HL, 0x0170
+		// loop (0x0153):
+		0x2A,       // LD A, (HL+)
+		0xB7,       // OR A
+		0x28, 0x0E, // JR Z, done
+		0xE0, 0x01, // LDH (SB), A
+		0x3E, 0x81, // LD A, 0x81
+		0xE0, 0x02, // LDH (SC), A
+		// wait (0x015D):
+		0xF0, 0x02, // LDH A, (SC)
+		0xCB, 0x7F, // BIT 7, A
+		0x20, 0xFA, // JR NZ, wait
+		0x18, 0xEE, // JR loop
+		// done (0x0165):
+		0x18, 0xFE, // JR done
+	})
+	copy(rom[0x170:], text)
+	return rom
+}
+
+// newRegistersROM cria uma ROM que carrega B, C, D, E, H e L e executa
+// LD B,B, como as ROMs do Mooneye ao terminar
+func newRegistersROM(b, c, d, e, h, l uint8) []uint8 {
+	rom := newLoopROM(0x00)
+	copy(rom[0x100:], []uint8{
+		0x06, b, 0x0E, c, 0x16, d, 0x1E, e, 0x26, h, 0x2E, l,
+		0x40,       // LD B,B
+		0x18, 0xFE, // JR -2
+	})
+	return rom
+}
+
+// TestCommunityROMHarness verifica a detecção de resultado do harness com
+// ROMs sintéticas que seguem os protocolos do Blargg e do Mooneye
+func TestCommunityROMHarness(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]uint8{
+		"cpu_instrs/individual/01-pass.gb": newSerialTextROM("01-special\n\n\nPassed\n"),
+		"cpu_instrs/individual/02-fail.gb": newSerialTextROM("02-interrupts\n\nFailed #3\n"),
+		"mem_timing/hang.gb":               newLoopROM(0x00),
+		"acceptance/pass-dmgABC.gb":        newRegistersROM(3, 5, 8, 13, 21, 34),
+		"acceptance/timer/fail.gb":         newRegistersROM(0x42, 0x42, 0x42, 0x42, 0x42, 0x42),
+		"acceptance/boot_hwio-S.gb":        newRegistersROM(3, 5, 8, 13, 21, 34),
+		"manual-only/sprite_priority.gb":   newLoopROM(0x00),
+		"other/readme.gb":                  newLoopROM(0x00),
+	}
+	for name, rom := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, rom, 0644); err != nil {
+			t.Fatalf("Failed to write ROM: %v", err)
+		}
+	}
+
+	roms, err := findTestROMs(dir)
+	if err != nil || len(roms) != 6 {
+		t.Fatalf("expected 6 test ROMs, got %d (%v)", len(roms), err)
+	}
+
+	want := map[string]string{
+		"01-pass":     "pass",
+		"02-fail":     "fail",
+		"hang":        "timeout",
+		"pass-dmgABC": "pass",
+		"fail":        "fail",
+		"boot_hwio-S": "skip",
+	}
+	var results []testROMResult
+	for _, rom := range roms {
+		result := runTestROM(rom, 4194304/4)
+		results = append(results, result)
+		if result.status != want[rom.name] {
+			t.Errorf("%s: expected %s, got %s (%s)", rom.name, want[rom.name], result.status, result.detail)
+		}
+		if rom.name == "02-fail" && !strings.Contains(result.detail, "Failed #3") {
+			t.Errorf("expected the serial output in the failure detail, got %q", result.detail)
+		}
+		if rom.name == "fail" && !strings.Contains(result.detail, "B=42") {
+			t.Errorf("expected the registers in the failure detail, got %q", result.detail)
+		}
+	}
+
+	summary := writeTestROMSummary(results)
+	if !strings.Contains(summary, "6 ROMs: 2 pass, 2 fail, 1 timeout, 1 skip") {
+		t.Errorf("unexpected summary:\n%s", summary)
+	}
+}
@@ -1,6 +1,10 @@
 package timer
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
+)
 
 // Constantes do Timer
 const (
@@ -21,17 +25,39 @@ const (
 	TACClock  = 0x03   // Clock Select
 )
 
-// Timer representa o sistema de timer do Game Boy
+// Tap do DIV para o frame sequencer do APU: o bit 12 do contador interno
+// (bit 4 do DIV) cai a 512 Hz; em velocidade dupla o contador corre duas
+// vezes mais rápido e o APU usa o bit 13.
+const (
+	DIVTapBit       = 12
+	DIVTapBitDouble = 13
+)
+
+// Ciclos entre o overflow do TIMA e a recarga com TMA (1 M-cycle). Durante
+// esse intervalo TIMA lê 0x00; no ciclo seguinte, escritas em TIMA são
+// ignoradas e escritas em TMA também vão para TIMA.
+const reloadDelay = 4
+
+// Timer representa o sistema de timer do Game Boy. DIV e TIMA derivam de
+// um contador interno de 16 bits incrementado a cada ciclo de CPU: DIV é o
+// byte alto e TIMA incrementa na borda de descida do bit selecionado por
+// TAC (combinado com o bit de enable).
 type Timer struct {
 	// Registradores
-	div  uint8 // Divider Register (incrementa a 16384 Hz)
 	tima uint8 // Timer Counter
 	tma  uint8 // Timer Modulo
 	tac  uint8 // Timer Control
 
-	// Contadores internos
-	divCounter  int // Contador para DIV
-	timaCounter int // Contador para TIMA
+	// Contador interno (DIV = counter >> 8)
+	counter uint16
+
+	// Estado da recarga após overflow
+	overflowCycles int // Ciclos até recarregar TIMA com TMA
+	reloadCycles   int // Ciclos restantes do ciclo de recarga
+
+	// Tap do DIV para o frame sequencer do APU
+	divTap      func()
+	doubleSpeed bool
 
 	// Interface de interrupções
 	interruptHandler InterruptHandler
@@ -51,41 +77,88 @@ func NewTimer(interruptHandler InterruptHandler) *Timer {
 
 // Reset reinicia o timer para seu estado inicial
 func (t *Timer) Reset() {
-	t.div = 0x00
 	t.tima = 0x00
 	t.tma = 0x00
 	t.tac = 0x00
-	t.divCounter = 0
-	t.timaCounter = 0
+	t.counter = 0
+	t.overflowCycles = 0
+	t.reloadCycles = 0
+}
+
+// SetDIVTap define a função chamada na borda de descida do bit do DIV que
+// alimenta o frame sequencer do APU
+func (t *Timer) SetDIVTap(tap func()) {
+	t.divTap = tap
+}
+
+// SetDoubleSpeed informa ao timer a velocidade do CPU (CGB), que define o
+// bit usado pelo tap do DIV
+func (t *Timer) SetDoubleSpeed(enabled bool) {
+	t.doubleSpeed = enabled
 }
 
 // Step executa um ciclo do timer
 func (t *Timer) Step(cycles int) {
-	// Atualiza o contador DIV (sempre ativo)
-	t.divCounter += cycles
-	if t.divCounter >= 256 { // DIV incrementa a cada 256 ciclos de CPU (16384 Hz)
-		t.divCounter -= 256
-		t.div++
+	for i := 0; i < cycles; i++ {
+		t.tick()
 	}
+}
 
-	// Atualiza o contador TIMA (se habilitado)
-	if t.IsTimerEnabled() {
-		t.timaCounter += cycles
-
-		frequency := t.getTimerFrequency()
-		if t.timaCounter >= frequency {
-			t.timaCounter -= frequency
-
-			// Incrementa TIMA
-			if t.tima == 0xFF {
-				// Overflow - recarrega com TMA e gera interrupção
-				t.tima = t.tma
-				t.interruptHandler.RequestInterrupt(0x04) // Timer interrupt
-			} else {
-				t.tima++
-			}
+// tick avança o contador interno em um ciclo de CPU
+func (t *Timer) tick() {
+	if t.reloadCycles > 0 {
+		t.reloadCycles--
+	}
+	if t.overflowCycles > 0 {
+		t.overflowCycles--
+		if t.overflowCycles == 0 {
+			t.tima = t.tma
+			t.reloadCycles = reloadDelay
+			t.interruptHandler.RequestInterrupt(0x04) // Timer interrupt
 		}
 	}
+
+	t.setCounter(t.counter + 1)
+}
+
+// setCounter altera o contador interno, incrementando TIMA e clocando o
+// frame sequencer nas bordas de descida dos bits monitorados
+func (t *Timer) setCounter(value uint16) {
+	oldCounter := t.counter
+	oldSignal := t.timerSignal()
+	t.counter = value
+
+	if oldSignal && !t.timerSignal() {
+		t.incrementTIMA()
+	}
+
+	tapMask := uint16(1) << DIVTapBit
+	if t.doubleSpeed {
+		tapMask = 1 << DIVTapBitDouble
+	}
+	if oldCounter&tapMask != 0 && value&tapMask == 0 && t.divTap != nil {
+		t.divTap()
+	}
+}
+
+// timerSignal retorna o bit do contador selecionado por TAC combinado com o
+// bit de enable; TIMA incrementa quando esse sinal cai de 1 para 0
+func (t *Timer) timerSignal() bool {
+	return t.IsTimerEnabled() && t.counter&t.selectedBitMask() != 0
+}
+
+// selectedBitMask retorna a máscara do bit do contador selecionado por TAC
+func (t *Timer) selectedBitMask() uint16 {
+	return uint16(t.getTimerFrequency() >> 1)
+}
+
+// incrementTIMA incrementa TIMA; no overflow TIMA fica em 0x00 por um
+// M-cycle antes da recarga com TMA e da interrupção
+func (t *Timer) incrementTIMA() {
+	t.tima++
+	if t.tima == 0x00 {
+		t.overflowCycles = reloadDelay
+	}
 }
 
 // IsTimerEnabled retorna se o timer está habilitado
@@ -113,7 +186,7 @@ func (t *Timer) getTimerFrequency() int {
 func (t *Timer) ReadRegister(addr uint16) uint8 {
 	switch addr {
 	case RegDIV:
-		return t.div
+		return t.GetDIV()
 	case RegTIMA:
 		return t.tima
 	case RegTMA:
@@ -129,27 +202,26 @@ func (t *Timer) ReadRegister(addr uint16) uint8 {
 func (t *Timer) WriteRegister(addr uint16, value uint8) {
 	switch addr {
 	case RegDIV:
-		// Escrever em DIV sempre o reseta para 0
-		t.div = 0
-		t.divCounter = 0
+		// Escrever em DIV zera o contador interno inteiro, o que pode gerar
+		// uma borda de descida no TIMA e no frame sequencer
+		t.setCounter(0)
 	case RegTIMA:
-		t.tima = value
+		t.SetTIMA(value)
 	case RegTMA:
-		t.tma = value
+		t.SetTMA(value)
 	case RegTAC:
-		oldEnabled := t.IsTimerEnabled()
-		t.tac = value & 0x07 // Apenas bits 0-2 são usados
-
-		// Se o timer foi desabilitado, reseta o contador
-		if oldEnabled && !t.IsTimerEnabled() {
-			t.timaCounter = 0
-		}
+		t.SetTAC(value)
 	}
 }
 
 // GetDIV retorna o valor atual do registrador DIV
 func (t *Timer) GetDIV() uint8 {
-	return t.div
+	return uint8(t.counter >> 8)
+}
+
+// GetCounter retorna o contador interno de 16 bits
+func (t *Timer) GetCounter() uint16 {
+	return t.counter
 }
 
 // GetTIMA retorna o valor atual do registrador TIMA
@@ -167,24 +239,34 @@ func (t *Timer) GetTAC() uint8 {
 	return t.tac
 }
 
-// SetTIMA define o valor do registrador TIMA
+// SetTIMA define o valor do registrador TIMA. Escrever entre o overflow e
+// a recarga cancela a recarga e a interrupção; no ciclo da recarga a escrita
+// é ignorada.
 func (t *Timer) SetTIMA(value uint8) {
+	if t.reloadCycles > 0 {
+		return
+	}
+	t.overflowCycles = 0
 	t.tima = value
 }
 
-// SetTMA define o valor do registrador TMA
+// SetTMA define o valor do registrador TMA. No ciclo da recarga o novo
+// valor também é copiado para TIMA.
 func (t *Timer) SetTMA(value uint8) {
 	t.tma = value
+	if t.reloadCycles > 0 {
+		t.tima = value
+	}
 }
 
-// SetTAC define o valor do registrador TAC
+// SetTAC define o valor do registrador TAC. Se a troca de frequência ou o
+// desligamento derrubar o sinal do bit selecionado, TIMA incrementa.
 func (t *Timer) SetTAC(value uint8) {
-	oldEnabled := t.IsTimerEnabled()
+	oldSignal := t.timerSignal()
 	t.tac = value & 0x07
 
-	// Se o timer foi desabilitado, reseta o contador
-	if oldEnabled && !t.IsTimerEnabled() {
-		t.timaCounter = 0
+	if oldSignal && !t.timerSignal() {
+		t.incrementTIMA()
 	}
 }
 
@@ -209,14 +291,24 @@ func (t *Timer) GetDividerFrequencyHz() int {
 	return 16384 // DIV sempre incrementa a 16384 Hz
 }
 
-// IsOverflowing retorna se o timer está prestes a fazer overflow
+// IsOverflowing retorna se o timer está prestes a fazer overflow ou
+// aguardando a recarga com TMA
 func (t *Timer) IsOverflowing() bool {
+	if t.overflowCycles > 0 {
+		return true
+	}
 	if !t.IsTimerEnabled() {
 		return false
 	}
 
-	frequency := t.getTimerFrequency()
-	return t.tima == 0xFF && t.timaCounter >= frequency-1
+	return t.tima == 0xFF && t.cyclesUntilIncrement() == 1
+}
+
+// cyclesUntilIncrement retorna quantos ciclos faltam para a próxima borda
+// de descida do bit selecionado
+func (t *Timer) cyclesUntilIncrement() int {
+	period := t.getTimerFrequency()
+	return period - int(t.counter)&(period-1)
 }
 
 // GetCyclesUntilOverflow retorna quantos ciclos faltam para o próximo overflow
@@ -225,16 +317,28 @@ func (t *Timer) GetCyclesUntilOverflow() int {
 		return -1 // Timer desabilitado
 	}
 
-	frequency := t.getTimerFrequency()
-	cyclesUntilIncrement := frequency - t.timaCounter
-
-	if t.tima == 0xFF {
-		return cyclesUntilIncrement
-	}
-
 	// Calcula quantos incrementos faltam até 0xFF
 	incrementsUntilOverflow := int(0xFF - t.tima)
-	return cyclesUntilIncrement + (incrementsUntilOverflow * frequency)
+	return t.cyclesUntilIncrement() + (incrementsUntilOverflow * t.getTimerFrequency())
+}
+
+// stateFields lista os campos gravados no save state
+func (t *Timer) stateFields() []any {
+	return []any{&t.tima, &t.tma, &t.tac, &t.counter, &t.overflowCycles, &t.reloadCycles, &t.doubleSpeed}
+}
+
+// SaveState serializa os registradores, o contador interno e a recarga
+// em andamento
+func (t *Timer) SaveState() ([]byte, error) {
+	return savestate.Marshal(t.stateFields()...)
+}
+
+// LoadState restaura o estado gerado por SaveState
+func (t *Timer) LoadState(data []byte) error {
+	if err := savestate.Unmarshal(data, t.stateFields()...); err != nil {
+		return fmt.Errorf("estado do timer inválido: %w", err)
+	}
+	return nil
 }
 
 // String retorna uma representação em string do estado do timer
@@ -245,5 +349,5 @@ func (t *Timer) String() string {
 	}
 
 	return fmt.Sprintf("Timer: DIV=0x%02X TIMA=0x%02X TMA=0x%02X TAC=0x%02X (%s, %d Hz)",
-		t.div, t.tima, t.tma, t.tac, enabled, t.GetTimerFrequencyHz())
+		t.GetDIV(), t.tima, t.tma, t.tac, enabled, t.GetTimerFrequencyHz())
 }
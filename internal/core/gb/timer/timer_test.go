@@ -0,0 +1,156 @@
+package timer
+
+import "testing"
+
+// mockInterrupts conta as interrupções de timer solicitadas
+type mockInterrupts struct {
+	timer int
+}
+
+func (m *mockInterrupts) RequestInterrupt(interrupt uint8) {
+	if interrupt == 0x04 {
+		m.timer++
+	}
+}
+
+// newTimer cria um timer zerado com TAC informado
+func newTimer(tac uint8) (*Timer, *mockInterrupts) {
+	ic := &mockInterrupts{}
+	t := NewTimer(ic)
+	t.Reset()
+	t.WriteRegister(RegTAC, tac)
+	return t, ic
+}
+
+func TestTimerDIVAndTIMARates(t *testing.T) {
+	tmr, _ := newTimer(TACEnable | 0x01) // 262144 Hz
+
+	tmr.Step(255)
+	if tmr.ReadRegister(RegDIV) != 0 {
+		t.Fatalf("DIV não deveria incrementar antes de 256 ciclos")
+	}
+	tmr.Step(1)
+	if tmr.ReadRegister(RegDIV) != 1 {
+		t.Errorf("DIV: esperado 1, obtido %d", tmr.ReadRegister(RegDIV))
+	}
+	if tmr.ReadRegister(RegTIMA) != 256/FreqCPU262144 {
+		t.Errorf("TIMA: esperado %d, obtido %d", 256/FreqCPU262144, tmr.ReadRegister(RegTIMA))
+	}
+}
+
+func TestTimerDIVWriteFallingEdge(t *testing.T) {
+	tmr, _ := newTimer(TACEnable | 0x01) // Bit 3
+
+	// Bit 3 ativo: zerar o DIV gera uma borda de descida
+	tmr.Step(8)
+	before := tmr.ReadRegister(RegTIMA)
+	tmr.WriteRegister(RegDIV, 0x12)
+	if got := tmr.ReadRegister(RegTIMA); got != before+1 {
+		t.Errorf("escrita em DIV com bit 3 ativo: esperado TIMA=%d, obtido %d", before+1, got)
+	}
+	if tmr.GetCounter() != 0 {
+		t.Errorf("escrita em DIV deveria zerar o contador interno, obtido 0x%04X", tmr.GetCounter())
+	}
+
+	// Bit 3 inativo: sem incremento
+	tmr.Step(4)
+	tmr.WriteRegister(RegDIV, 0)
+	if got := tmr.ReadRegister(RegTIMA); got != before+1 {
+		t.Errorf("escrita em DIV com bit 3 inativo não deveria incrementar, TIMA=%d", got)
+	}
+}
+
+func TestTimerTACWriteGlitch(t *testing.T) {
+	tmr, _ := newTimer(TACEnable | 0x01)
+	tmr.Step(8) // Bit 3 ativo
+
+	// Desligar o timer com o bit selecionado ativo incrementa TIMA
+	tmr.WriteRegister(RegTAC, 0x01)
+	if got := tmr.ReadRegister(RegTIMA); got != 1 {
+		t.Errorf("desligar o timer: esperado TIMA=1, obtido %d", got)
+	}
+
+	// Trocar para um bit inativo também gera a borda
+	tmr, _ = newTimer(TACEnable | 0x01)
+	tmr.Step(8)
+	tmr.WriteRegister(RegTAC, TACEnable|0x02) // Bit 5 ainda em 0
+	if got := tmr.ReadRegister(RegTIMA); got != 1 {
+		t.Errorf("trocar a frequência: esperado TIMA=1, obtido %d", got)
+	}
+}
+
+func TestTimerReloadDelay(t *testing.T) {
+	tmr, ic := newTimer(TACEnable | 0x01)
+	tmr.WriteRegister(RegTMA, 0x80)
+	tmr.WriteRegister(RegTIMA, 0xFF)
+
+	tmr.Step(FreqCPU262144)
+	if tmr.ReadRegister(RegTIMA) != 0x00 || ic.timer != 0 {
+		t.Fatalf("após overflow TIMA deveria ler 0x00 sem interrupção, TIMA=0x%02X int=%d",
+			tmr.ReadRegister(RegTIMA), ic.timer)
+	}
+	if !tmr.IsOverflowing() {
+		t.Error("timer deveria estar aguardando a recarga")
+	}
+
+	tmr.Step(reloadDelay)
+	if tmr.ReadRegister(RegTIMA) != 0x80 || ic.timer != 1 {
+		t.Errorf("após 4 ciclos: esperado TIMA=0x80 com interrupção, TIMA=0x%02X int=%d",
+			tmr.ReadRegister(RegTIMA), ic.timer)
+	}
+}
+
+func TestTimerWritesDuringReload(t *testing.T) {
+	// Escrever TIMA antes da recarga cancela recarga e interrupção
+	tmr, ic := newTimer(TACEnable | 0x01)
+	tmr.WriteRegister(RegTMA, 0x80)
+	tmr.WriteRegister(RegTIMA, 0xFF)
+	tmr.Step(FreqCPU262144)
+	tmr.WriteRegister(RegTIMA, 0x42)
+	tmr.Step(reloadDelay)
+	if tmr.ReadRegister(RegTIMA) != 0x42 || ic.timer != 0 {
+		t.Errorf("recarga cancelada: esperado TIMA=0x42 sem interrupção, TIMA=0x%02X int=%d",
+			tmr.ReadRegister(RegTIMA), ic.timer)
+	}
+
+	// No ciclo da recarga, TIMA ignora escritas e TMA é copiado para TIMA
+	tmr, ic = newTimer(TACEnable | 0x01)
+	tmr.WriteRegister(RegTMA, 0x80)
+	tmr.WriteRegister(RegTIMA, 0xFF)
+	tmr.Step(FreqCPU262144 + reloadDelay)
+	tmr.WriteRegister(RegTIMA, 0x42)
+	if tmr.ReadRegister(RegTIMA) != 0x80 {
+		t.Errorf("escrita em TIMA no ciclo da recarga deveria ser ignorada, TIMA=0x%02X", tmr.ReadRegister(RegTIMA))
+	}
+	tmr.WriteRegister(RegTMA, 0x90)
+	if tmr.ReadRegister(RegTIMA) != 0x90 || ic.timer != 1 {
+		t.Errorf("escrita em TMA no ciclo da recarga: esperado TIMA=0x90, TIMA=0x%02X int=%d",
+			tmr.ReadRegister(RegTIMA), ic.timer)
+	}
+}
+
+func TestTimerDIVTap(t *testing.T) {
+	tmr, _ := newTimer(0)
+	clocks := 0
+	tmr.SetDIVTap(func() { clocks++ })
+
+	// Bit 12 cai a cada 8192 ciclos (512 Hz)
+	tmr.Step(8192 * 3)
+	if clocks != 3 {
+		t.Errorf("tap do DIV: esperados 3 clocks, obtidos %d", clocks)
+	}
+
+	// Zerar o DIV com o bit 12 ativo também gera um clock
+	tmr.Step(4096)
+	tmr.WriteRegister(RegDIV, 0)
+	if clocks != 4 {
+		t.Errorf("escrita em DIV com bit 12 ativo: esperados 4 clocks, obtidos %d", clocks)
+	}
+
+	// Em velocidade dupla o bit 13 mantém os 512 Hz
+	tmr.SetDoubleSpeed(true)
+	tmr.Step(8192 * 2)
+	if clocks != 5 {
+		t.Errorf("velocidade dupla: esperados 5 clocks, obtidos %d", clocks)
+	}
+}
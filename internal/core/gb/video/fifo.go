@@ -0,0 +1,598 @@
+package video
+
+import "sort"
+
+// Renderer seleciona o renderizador do LCD
+type Renderer int
+
+const (
+	// RendererScanline desenha cada linha de uma vez, com duração fixa dos
+	// modos. É o mais rápido, mas ignora escritas no meio da linha.
+	RendererScanline Renderer = iota
+
+	// RendererFIFO emula o pixel FIFO ciclo a ciclo: escritas em SCX, BGP,
+	// LCDC etc. durante o modo 3 afetam os pixels seguintes e a duração do
+	// modo 3 varia com o scroll, a window e os sprites.
+	RendererFIFO
+)
+
+// String retorna o nome do renderizador
+func (r Renderer) String() string {
+	switch r {
+	case RendererFIFO:
+		return "fifo"
+	default:
+		return "scanline"
+	}
+}
+
+// Constantes do pixel FIFO
+const (
+	fetchTile = iota // Lê o índice do tile no mapa
+	fetchLow         // Lê o byte baixo dos dados do tile
+	fetchHigh        // Lê o byte alto dos dados do tile
+	fetchPush        // Aguarda o FIFO de BG esvaziar para empilhar 8 pixels
+
+	fifoStartupDots = 6  // Busca inicial descartada no começo do modo 3
+	spriteFetchDots = 6  // Duração da busca dos dados de um sprite
+	maxLineSprites  = 10 // Sprites por linha
+	line153ZeroDot  = 4  // Ciclo da linha 153 em que LY passa a ler 0
+)
+
+// fifoPixel representa um pixel no FIFO de BG ou de objetos
+type fifoPixel struct {
+	color    uint8 // Índice de cor (0-3)
+	palette  uint8 // Paleta CGB (0-7) ou, para sprites no DMG, OBP0/OBP1
+	priority bool  // Atributo de prioridade do BG/sprite
+	oam      uint8 // Índice do sprite na OAM (desempate no CGB)
+}
+
+// pixelFIFO é uma fila circular de até 16 pixels
+type pixelFIFO struct {
+	pixels [16]fifoPixel
+	head   int
+	size   int
+}
+
+// push adiciona um pixel ao final da fila
+func (f *pixelFIFO) push(p fifoPixel) {
+	f.pixels[(f.head+f.size)&15] = p
+	f.size++
+}
+
+// pop remove o primeiro pixel da fila
+func (f *pixelFIFO) pop() fifoPixel {
+	p := f.pixels[f.head]
+	f.head = (f.head + 1) & 15
+	f.size--
+	return p
+}
+
+// at retorna o i-ésimo pixel da fila
+func (f *pixelFIFO) at(i int) *fifoPixel {
+	return &f.pixels[(f.head+i)&15]
+}
+
+// clear esvazia a fila
+func (f *pixelFIFO) clear() {
+	f.head = 0
+	f.size = 0
+}
+
+// fetcher busca os tiles de BG/window, 2 ciclos por etapa
+type fetcher struct {
+	step       int
+	dots       int
+	tileX      uint8 // Coluna do próximo tile (relativa ao SCX ou à window)
+	window     bool
+	mapOffset  uint16
+	tileIndex  uint8
+	attributes uint8
+	row        uint8
+	low        uint8
+	high       uint8
+}
+
+// spriteEntry é um sprite selecionado na varredura da OAM
+type spriteEntry struct {
+	index int // Índice na OAM
+	x     int // Coordenada X da OAM (tela + 8)
+	y     int // Coordenada Y da OAM (tela + 16)
+}
+
+// fifoState guarda o estado do renderizador pixel FIFO
+type fifoState struct {
+	dot  int // Ciclo dentro da linha (0-455)
+	line int // Linha interna (LY difere apenas no fim da linha 153)
+
+	// Modo 3
+	lcdX    int // Próximo pixel a ser enviado à tela
+	discard int // Pixels a descartar (SCX fino ou WX < 7)
+	startup int // Ciclos restantes da busca inicial
+	bg      pixelFIFO
+	obj     pixelFIFO
+	fetch   fetcher
+
+	// Sprites da linha
+	sprites      [maxLineSprites]spriteEntry
+	spriteCount  int
+	nextSprite   int
+	spriteDots   int // Ciclos restantes da busca do sprite atual
+	spriteActive bool
+	penaltyTile  int // Último tile de BG/window que atrasou uma busca de sprite
+
+	// Window
+	windowActive    bool // Fetcher buscando tiles da window nesta linha
+	windowDrawn     bool // Window apareceu na linha atual
+	windowTriggered bool // WY == LY ocorreu neste frame
+}
+
+// stateFields lista os campos da fila para o save state
+func (f *pixelFIFO) stateFields() []any {
+	fields := []any{&f.head, &f.size}
+	for i := range f.pixels {
+		p := &f.pixels[i]
+		fields = append(fields, &p.color, &p.palette, &p.priority, &p.oam)
+	}
+	return fields
+}
+
+// stateFields lista os campos do renderizador para o save state
+func (s *fifoState) stateFields() []any {
+	fields := []any{&s.dot, &s.line, &s.lcdX, &s.discard, &s.startup}
+	fields = append(fields, s.bg.stateFields()...)
+	fields = append(fields, s.obj.stateFields()...)
+
+	f := &s.fetch
+	fields = append(fields, &f.step, &f.dots, &f.tileX, &f.window, &f.mapOffset,
+		&f.tileIndex, &f.attributes, &f.row, &f.low, &f.high)
+
+	for i := range s.sprites {
+		sprite := &s.sprites[i]
+		fields = append(fields, &sprite.index, &sprite.x, &sprite.y)
+	}
+	return append(fields, &s.spriteCount, &s.nextSprite, &s.spriteDots, &s.spriteActive,
+		&s.penaltyTile, &s.windowActive, &s.windowDrawn, &s.windowTriggered)
+}
+
+// SetRenderer seleciona o renderizador do LCD. Se a troca ocorrer com o
+// display ligado, a linha atual é reiniciada no modo 2.
+func (lcd *LCD) SetRenderer(renderer Renderer) {
+	if renderer == lcd.renderer {
+		return
+	}
+	lcd.renderer = renderer
+	lcd.restartLine()
+}
+
+// restartLine reinicia a linha atual no modo 2, descartando o progresso do
+// renderizador
+func (lcd *LCD) restartLine() {
+	lcd.cycles = 0
+	lcd.fifo = fifoState{line: int(lcd.ly)}
+	if lcd.IsDisplayEnabled() && lcd.ly < ScreenHeight {
+		lcd.setMode(ModeOAM)
+	}
+}
+
+// GetRenderer retorna o renderizador do LCD
+func (lcd *LCD) GetRenderer() Renderer {
+	return lcd.renderer
+}
+
+// stepFIFO avança o renderizador pixel FIFO ciclo a ciclo
+func (lcd *LCD) stepFIFO(cycles int) {
+	for i := 0; i < cycles; i++ {
+		lcd.tickFIFO()
+	}
+}
+
+// tickFIFO executa um ciclo (dot) do LCD
+func (lcd *LCD) tickFIFO() {
+	s := &lcd.fifo
+
+	if lcd.mode == ModeVRAM {
+		lcd.tickMode3()
+	}
+
+	s.dot++
+
+	switch {
+	case s.dot == CyclesLine:
+		s.dot = 0
+		lcd.nextLineFIFO()
+	case lcd.mode == ModeOAM && s.dot == CyclesOAM:
+		lcd.startMode3()
+	case s.line == 153 && s.dot == line153ZeroDot:
+		// Na linha 153, LY passa a ler 0 poucos ciclos após o início
+		lcd.ly = 0
+		lcd.checkLYC()
+	}
+}
+
+// nextLineFIFO avança para a próxima linha
+func (lcd *LCD) nextLineFIFO() {
+	s := &lcd.fifo
+
+	s.line++
+	if s.line > 153 {
+		s.line = 0
+		lcd.windowLine = 0
+		s.windowTriggered = false
+	}
+	lcd.ly = uint8(s.line)
+	lcd.checkLYC()
+
+	switch {
+	case s.line < ScreenHeight:
+		lcd.setMode(ModeOAM)
+	case s.line == ScreenHeight:
+		lcd.enterVBlank()
+	}
+}
+
+// startMode3 seleciona os sprites da linha e prepara o fetcher
+func (lcd *LCD) startMode3() {
+	s := &lcd.fifo
+
+	if lcd.ly == lcd.wy {
+		s.windowTriggered = true
+	}
+	lcd.scanOAM()
+
+	s.lcdX = 0
+	s.discard = int(lcd.scx & 0x07)
+	s.startup = fifoStartupDots
+	s.bg.clear()
+	s.obj.clear()
+	s.fetch = fetcher{}
+	s.nextSprite = 0
+	s.spriteActive = false
+	s.penaltyTile = -1
+	s.windowActive = false
+	s.windowDrawn = false
+
+	lcd.setMode(ModeVRAM)
+}
+
+// scanOAM seleciona até 10 sprites que cobrem a linha atual, ordenados
+// pela ordem em que serão buscados (X crescente, depois ordem da OAM)
+func (lcd *LCD) scanOAM() {
+	s := &lcd.fifo
+
+	spriteHeight := 8
+	if lcd.lcdc&LCDCOBJSize != 0 {
+		spriteHeight = 16
+	}
+
+	s.spriteCount = 0
+	for i := 0; i < 40 && s.spriteCount < maxLineSprites; i++ {
+		y := int(lcd.oam[i*4])
+		if int(lcd.ly)+16 < y || int(lcd.ly)+16 >= y+spriteHeight {
+			continue
+		}
+		s.sprites[s.spriteCount] = spriteEntry{index: i, x: int(lcd.oam[i*4+1]), y: y}
+		s.spriteCount++
+	}
+
+	sort.SliceStable(s.sprites[:s.spriteCount], func(a, b int) bool {
+		return s.sprites[a].x < s.sprites[b].x
+	})
+}
+
+// tickMode3 executa um ciclo do modo 3: fetcher, sprites e shifter
+func (lcd *LCD) tickMode3() {
+	s := &lcd.fifo
+
+	// Busca inicial, descartada pelo hardware
+	if s.startup > 0 {
+		s.startup--
+		return
+	}
+
+	// Durante a busca de um sprite, fetcher e shifter ficam parados
+	if s.spriteActive {
+		s.spriteDots--
+		if s.spriteDots == 0 {
+			lcd.fetchSprite(s.sprites[s.nextSprite])
+			s.nextSprite++
+			s.spriteActive = false
+		}
+		return
+	}
+
+	// A window reinicia o fetcher quando o pixel atual alcança WX-7
+	if !s.windowActive && lcd.windowStartsAt(s.lcdX) {
+		s.windowActive = true
+		s.windowDrawn = true
+		s.bg.clear()
+		s.fetch = fetcher{window: true}
+		s.penaltyTile = -1
+		if s.lcdX == 0 && lcd.wx < 7 {
+			s.discard = int(7 - lcd.wx)
+		}
+	}
+
+	// Um sprite na posição atual interrompe o shifter assim que houver
+	// pixels de BG para combinar
+	if lcd.spritePending() {
+		if s.bg.size == 0 {
+			lcd.tickFetcher()
+		}
+		if s.bg.size > 0 {
+			// Este ciclo já conta como o primeiro da busca
+			s.spriteActive = true
+			s.spriteDots = spriteFetchDots + lcd.spriteFetchDelay() - 1
+		}
+		return
+	}
+
+	lcd.tickFetcher()
+
+	if s.bg.size == 0 {
+		return
+	}
+
+	bg := s.bg.pop()
+	if s.discard > 0 {
+		s.discard--
+		return
+	}
+
+	var obj fifoPixel
+	if s.obj.size > 0 {
+		obj = s.obj.pop()
+	}
+
+	lcd.outputPixel(s.lcdX, bg, obj)
+	s.lcdX++
+
+	if s.lcdX == ScreenWidth {
+		if s.windowDrawn {
+			lcd.windowLine++
+		}
+		lcd.setMode(ModeHBlank)
+	}
+}
+
+// windowStartsAt retorna se a window começa no pixel x da linha atual
+func (lcd *LCD) windowStartsAt(x int) bool {
+	if lcd.lcdc&LCDCWindowEnable == 0 || !lcd.fifo.windowTriggered || lcd.fifo.discard > 0 {
+		return false
+	}
+	// No DMG o bit 0 do LCDC também desliga a window
+	if !lcd.cgbMode && lcd.lcdc&LCDCBGEnable == 0 {
+		return false
+	}
+	return lcd.wx <= 166 && int(lcd.wx) <= x+7
+}
+
+// spritePending retorna se o próximo sprite da linha começa no pixel atual
+func (lcd *LCD) spritePending() bool {
+	s := &lcd.fifo
+	if lcd.lcdc&LCDCOBJEnable == 0 || s.nextSprite >= s.spriteCount {
+		return false
+	}
+	return s.sprites[s.nextSprite].x <= s.lcdX+8
+}
+
+// spriteFetchDelay retorna os ciclos que o fetcher de BG ainda precisa
+// para concluir o tile sob o pixel atual (0-5). Apenas o primeiro sprite
+// de cada tile paga esse custo.
+func (lcd *LCD) spriteFetchDelay() int {
+	s := &lcd.fifo
+
+	offset := s.lcdX + int(lcd.scx&0x07)
+	if s.windowActive {
+		offset = s.lcdX + 7 - int(lcd.wx)
+	}
+
+	if offset/TileSize == s.penaltyTile {
+		return 0
+	}
+	s.penaltyTile = offset / TileSize
+
+	if delay := 5 - offset%TileSize; delay > 0 {
+		return delay
+	}
+	return 0
+}
+
+// tickFetcher avança o fetcher de BG/window em um ciclo
+func (lcd *LCD) tickFetcher() {
+	f := &lcd.fifo.fetch
+
+	if f.step == fetchPush {
+		if lcd.fifo.bg.size == 0 {
+			lcd.pushTile()
+			f.tileX++
+			f.step = fetchTile
+		}
+		return
+	}
+
+	// Cada etapa de leitura leva 2 ciclos
+	f.dots++
+	if f.dots < 2 {
+		return
+	}
+	f.dots = 0
+
+	switch f.step {
+	case fetchTile:
+		lcd.fetchTileIndex()
+	case fetchLow:
+		f.low = lcd.vram[(f.attributes&AttrBank)>>3][lcd.bgTileOffset(f.tileIndex)+uint16(f.row)*2]
+	case fetchHigh:
+		f.high = lcd.vram[(f.attributes&AttrBank)>>3][lcd.bgTileOffset(f.tileIndex)+uint16(f.row)*2+1]
+	}
+	f.step++
+}
+
+// fetchTileIndex lê o índice e os atributos do próximo tile, usando os
+// valores atuais de LCDC, SCX e SCY
+func (lcd *LCD) fetchTileIndex() {
+	f := &lcd.fifo.fetch
+
+	var tileMapBase uint16
+	var mapX, mapY uint8
+	if f.window {
+		tileMapBase = TileMap0
+		if lcd.lcdc&LCDCWindowTileMap != 0 {
+			tileMapBase = TileMap1
+		}
+		mapX = f.tileX * TileSize
+		mapY = uint8(lcd.windowLine)
+	} else {
+		tileMapBase = TileMap0
+		if lcd.lcdc&LCDCBGTileMap != 0 {
+			tileMapBase = TileMap1
+		}
+		mapX = lcd.scx + f.tileX*TileSize
+		mapY = lcd.ly + lcd.scy
+	}
+
+	f.mapOffset = tileMapBase - VRAMBase + uint16(mapY/TileSize)*TileMapSize + uint16(mapX/TileSize)
+	f.tileIndex = lcd.vram[0][f.mapOffset]
+	f.attributes = 0
+	if lcd.cgbMode {
+		f.attributes = lcd.vram[1][f.mapOffset]
+	}
+
+	f.row = mapY % TileSize
+	if f.attributes&AttrFlipY != 0 {
+		f.row = 7 - f.row
+	}
+}
+
+// pushTile empilha os 8 pixels do tile buscado no FIFO de BG
+func (lcd *LCD) pushTile() {
+	f := &lcd.fifo.fetch
+
+	for col := uint8(0); col < 8; col++ {
+		bit := 7 - col
+		if f.attributes&AttrFlipX != 0 {
+			bit = col
+		}
+		lcd.fifo.bg.push(fifoPixel{
+			color:    (f.high>>bit)&1<<1 | (f.low>>bit)&1,
+			palette:  f.attributes & AttrPalette,
+			priority: f.attributes&AttrPriority != 0,
+		})
+	}
+}
+
+// fetchSprite lê os dados do sprite e os combina com o FIFO de objetos
+func (lcd *LCD) fetchSprite(sprite spriteEntry) {
+	s := &lcd.fifo
+
+	spriteHeight := 8
+	if lcd.lcdc&LCDCOBJSize != 0 {
+		spriteHeight = 16
+	}
+
+	tileIndex := lcd.oam[sprite.index*4+2]
+	attributes := lcd.oam[sprite.index*4+3]
+	if spriteHeight == 16 {
+		tileIndex &= 0xFE
+	}
+
+	row := uint8(int(lcd.ly) + 16 - sprite.y)
+	if attributes&AttrFlipY != 0 {
+		row = uint8(spriteHeight-1) - row
+	}
+
+	var bank, palette uint8
+	if lcd.cgbMode {
+		bank = (attributes & AttrBank) >> 3
+		palette = attributes & AttrPalette
+	} else if attributes&AttrDMGPal != 0 {
+		palette = 1
+	}
+
+	// Completa o FIFO de objetos com pixels transparentes
+	for s.obj.size < 8 {
+		s.obj.push(fifoPixel{})
+	}
+
+	// Sprites parcialmente à esquerda da tela perdem as primeiras colunas
+	skip := s.lcdX + 8 - sprite.x
+	for col := skip; col < 8; col++ {
+		pixelCol := uint8(col)
+		if attributes&AttrFlipX != 0 {
+			pixelCol = 7 - pixelCol
+		}
+
+		color := lcd.tilePixel(bank, uint16(tileIndex)*16, row, pixelCol)
+		if color == 0 {
+			continue
+		}
+
+		// No DMG o primeiro sprite buscado vence; no CGB vale o menor índice da OAM
+		slot := s.obj.at(col - skip)
+		if slot.color != 0 && !(lcd.cgbMode && uint8(sprite.index) < slot.oam) {
+			continue
+		}
+		*slot = fifoPixel{
+			color:    color,
+			palette:  palette,
+			priority: attributes&AttrPriority != 0,
+			oam:      uint8(sprite.index),
+		}
+	}
+}
+
+// outputPixel combina os pixels de BG e objeto e escreve na tela, usando
+// as paletas e o LCDC no momento em que o pixel sai do FIFO
+func (lcd *LCD) outputPixel(x int, bg, obj fifoPixel) {
+	y := lcd.ly
+
+	bgColor := bg.color
+	if !lcd.cgbMode && lcd.lcdc&LCDCBGEnable == 0 {
+		bgColor = 0
+	}
+
+	shade := (lcd.bgp >> (bgColor * 2)) & 0x03
+	lcd.bgBuffer[y][x] = shade
+	lcd.objBuffer[y][x] = 0
+
+	color := dmgColors[shade]
+	if lcd.cgbMode {
+		color = paletteColor(&lcd.bgPaletteRAM, bg.palette, bgColor)
+	}
+
+	if obj.color != 0 && lcd.lcdc&LCDCOBJEnable != 0 && !lcd.bgCovers(bgColor, bg.priority, obj.priority) {
+		if lcd.cgbMode {
+			color = paletteColor(&lcd.objPaletteRAM, obj.palette, obj.color)
+		} else {
+			paletteReg := lcd.obp0
+			if obj.palette != 0 {
+				paletteReg = lcd.obp1
+			}
+			shade = (paletteReg >> (obj.color * 2)) & 0x03
+			lcd.objBuffer[y][x] = shade
+			color = dmgColors[shade]
+		}
+	}
+
+	if lcd.cgbMode {
+		shade = colorToShade(color)
+	}
+	lcd.frameBuffer[y][x] = shade
+	lcd.colorBuffer[y][x] = color
+}
+
+// bgCovers retorna se o pixel de BG/window cobre o pixel do sprite
+func (lcd *LCD) bgCovers(bgColor uint8, bgPriority, objPriority bool) bool {
+	if bgColor == 0 {
+		return false
+	}
+	if lcd.cgbMode {
+		// LCDC bit 0 desligado dá prioridade total aos sprites
+		if lcd.lcdc&LCDCBGEnable == 0 {
+			return false
+		}
+		return objPriority || bgPriority
+	}
+	return objPriority
+}
@@ -0,0 +1,166 @@
+package video
+
+import "testing"
+
+// mockInterrupts conta as interrupções solicitadas
+type mockInterrupts struct {
+	counts map[uint8]int
+}
+
+func (m *mockInterrupts) RequestInterrupt(interrupt uint8) {
+	m.counts[interrupt]++
+}
+
+// newFIFOLCD cria um LCD com o renderizador FIFO, no início da linha 0
+func newFIFOLCD() (*LCD, *mockInterrupts) {
+	ic := &mockInterrupts{counts: make(map[uint8]int)}
+	lcd := NewLCD(ic)
+	lcd.Reset()
+	lcd.SetRenderer(RendererFIFO)
+	return lcd, ic
+}
+
+// mode3Length mede a duração do modo 3 da linha atual
+func mode3Length(lcd *LCD) int {
+	for lcd.GetMode() != ModeVRAM {
+		lcd.Step(1)
+	}
+	dots := 0
+	for lcd.GetMode() == ModeVRAM {
+		lcd.Step(1)
+		dots++
+	}
+	return dots
+}
+
+func TestFIFOMode3Length(t *testing.T) {
+	lcd, _ := newFIFOLCD()
+	if got := mode3Length(lcd); got != CyclesVRAM {
+		t.Errorf("modo 3 sem scroll: esperado %d ciclos, obtido %d", CyclesVRAM, got)
+	}
+
+	// O scroll fino descarta SCX&7 pixels no início da linha
+	lcd.Step(CyclesLine)
+	lcd.WriteRegister(RegSCX, 0x05)
+	if got := mode3Length(lcd); got != CyclesVRAM+5 {
+		t.Errorf("modo 3 com SCX=5: esperado %d ciclos, obtido %d", CyclesVRAM+5, got)
+	}
+}
+
+func TestFIFOSpritePenalty(t *testing.T) {
+	lcd, _ := newFIFOLCD()
+	lcd.WriteRegister(RegLCDC, 0x93) // LCD, BG e sprites
+
+	// Sprite na linha 0, coluna 0
+	lcd.oam[0] = 16
+	lcd.oam[1] = 8
+
+	got := mode3Length(lcd)
+	if got != CyclesVRAM+11 {
+		t.Errorf("sprite em X=0 deveria custar 11 ciclos, modo 3 durou %d", got)
+	}
+
+	// Um segundo sprite no mesmo tile custa apenas 6 ciclos
+	lcd.Step(CyclesLine - lcd.fifo.dot)
+	lcd.oam[0] = 17
+	lcd.oam[4] = 17
+	lcd.oam[5] = 10
+	if got := mode3Length(lcd); got != CyclesVRAM+11+6 {
+		t.Errorf("dois sprites no mesmo tile: esperado %d ciclos, obtido %d", CyclesVRAM+17, got)
+	}
+}
+
+func TestFIFOLineTiming(t *testing.T) {
+	lcd, ic := newFIFOLCD()
+
+	lcd.Step(CyclesLine * ScreenHeight)
+	if lcd.ReadRegister(RegLY) != ScreenHeight || lcd.GetMode() != ModeVBlank {
+		t.Fatalf("esperado V-Blank na linha 144, obtido LY=%d modo %d", lcd.ReadRegister(RegLY), lcd.GetMode())
+	}
+	if ic.counts[0x01] != 1 || !lcd.IsFrameReady() {
+		t.Error("V-Blank deveria solicitar interrupção e concluir o frame")
+	}
+
+	// Na linha 153, LY lê 0 após poucos ciclos
+	lcd.Step(CyclesLine*9 + line153ZeroDot)
+	if lcd.ReadRegister(RegLY) != 0 || lcd.GetMode() != ModeVBlank {
+		t.Errorf("linha 153: esperado LY=0 ainda em V-Blank, obtido LY=%d modo %d", lcd.ReadRegister(RegLY), lcd.GetMode())
+	}
+
+	lcd.Step(CyclesLine - line153ZeroDot)
+	if lcd.ReadRegister(RegLY) != 0 || lcd.GetMode() != ModeOAM {
+		t.Errorf("novo frame: esperado LY=0 em modo 2, obtido LY=%d modo %d", lcd.ReadRegister(RegLY), lcd.GetMode())
+	}
+}
+
+func TestFIFOMidScanlinePaletteChange(t *testing.T) {
+	lcd, _ := newFIFOLCD()
+
+	// Tile 0 inteiro com índice de cor 3
+	for i := 0; i < 16; i++ {
+		lcd.vram[0][i] = 0xFF
+	}
+	lcd.WriteRegister(RegBGP, 0xFF)
+
+	for lcd.GetMode() != ModeVRAM {
+		lcd.Step(1)
+	}
+	// Troca a paleta no meio do modo 3
+	lcd.Step(CyclesVRAM / 2)
+	lcd.WriteRegister(RegBGP, 0x00)
+	for lcd.GetMode() == ModeVRAM {
+		lcd.Step(1)
+	}
+
+	if lcd.frameBuffer[0][0] != 3 {
+		t.Errorf("início da linha: esperado tom 3, obtido %d", lcd.frameBuffer[0][0])
+	}
+	if lcd.frameBuffer[0][ScreenWidth-1] != 0 {
+		t.Errorf("fim da linha: esperado tom 0, obtido %d", lcd.frameBuffer[0][ScreenWidth-1])
+	}
+}
+
+func TestFIFOWindowLineCounter(t *testing.T) {
+	lcd, _ := newFIFOLCD()
+	lcd.WriteRegister(RegLCDC, 0xF1) // LCD, BG e window (mapa 0x9C00)
+	lcd.WriteRegister(RegWY, 0)
+	lcd.WriteRegister(RegWX, 87)
+
+	// A window no meio da linha descarta o FIFO de BG e reinicia o fetcher
+	if got := mode3Length(lcd); got <= CyclesVRAM {
+		t.Errorf("window deveria alongar o modo 3, obtido %d", got)
+	}
+
+	// Desliga a window por uma linha: o contador não avança
+	lcd.Step(CyclesLine - lcd.fifo.dot)
+	lcd.WriteRegister(RegLCDC, 0xD1)
+	lcd.Step(CyclesLine)
+	lcd.WriteRegister(RegLCDC, 0xF1)
+	mode3Length(lcd)
+
+	if lcd.windowLine != 2 {
+		t.Errorf("contador de linhas da window: esperado 2, obtido %d", lcd.windowLine)
+	}
+}
+
+func TestSTATInterruptBlocking(t *testing.T) {
+	for _, renderer := range []Renderer{RendererScanline, RendererFIFO} {
+		ic := &mockInterrupts{counts: make(map[uint8]int)}
+		lcd := NewLCD(ic)
+		lcd.Reset()
+		lcd.SetRenderer(renderer)
+
+		// LYC=1 e H-Blank habilitados: o H-Blank da linha 0 mantém a linha
+		// STAT ativa até o início da linha 1, onde LY=LYC a mantém ativa
+		lcd.WriteRegister(RegLYC, 1)
+		lcd.WriteRegister(RegSTAT, STATHBlankInt|STATLYCInt)
+
+		for i := 0; i < CyclesLine+CyclesOAM/2; i += 4 {
+			lcd.Step(4)
+		}
+
+		if got := ic.counts[0x02]; got != 1 {
+			t.Errorf("%s: esperada 1 interrupção STAT, obtidas %d", renderer, got)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package video
 
 import (
 	"fmt"
+	"sort"
+
+	"github.com/hobbiee/visualboy-go/internal/core/gb/savestate"
 )
 
 // Constantes do LCD
@@ -25,6 +28,13 @@ const (
 	RegWY   = 0xFF4A // Window Y Position
 	RegWX   = 0xFF4B // Window X Position
 
+	// Registradores LCD do Game Boy Color
+	RegVBK  = 0xFF4F // VRAM Bank
+	RegBCPS = 0xFF68 // Background Color Palette Specification
+	RegBCPD = 0xFF69 // Background Color Palette Data
+	RegOCPS = 0xFF6A // Object Color Palette Specification
+	RegOCPD = 0xFF6B // Object Color Palette Data
+
 	// Endereços de memória
 	VRAMBase  = 0x8000
 	VRAMSize  = 0x2000
@@ -35,6 +45,9 @@ const (
 	TileMap0  = 0x9800
 	TileMap1  = 0x9C00
 
+	// Memória de paletas do CGB (8 paletas x 4 cores x 2 bytes)
+	PaletteRAMSize = 64
+
 	// Modos LCD
 	ModeHBlank = 0
 	ModeVBlank = 1
@@ -71,6 +84,19 @@ const (
 	STATLYCInt    = 1 << 6 // LYC=LY Coincidence Interrupt
 )
 
+// Atributos de tile do CGB (VRAM banco 1) e de sprites
+const (
+	AttrPalette  = 0x07   // Paleta CGB (0-7)
+	AttrBank     = 1 << 3 // Banco de VRAM do tile
+	AttrDMGPal   = 1 << 4 // Paleta DMG do sprite (OBP0/OBP1)
+	AttrFlipX    = 1 << 5 // Espelhamento horizontal
+	AttrFlipY    = 1 << 6 // Espelhamento vertical
+	AttrPriority = 1 << 7 // Prioridade do BG sobre sprites
+)
+
+// dmgColors converte os tons DMG para RGB555 na saída colorida
+var dmgColors = [4]uint16{0x7FFF, 0x56B5, 0x294A, 0x0000}
+
 // LCD representa o controlador LCD do Game Boy
 type LCD struct {
 	// Registradores
@@ -90,15 +116,36 @@ type LCD struct {
 	mode       uint8 // Modo atual do LCD
 	cycles     int   // Ciclos acumulados
 	frameReady bool  // Frame pronto para renderização
+	windowLine int   // Contador interno de linhas da window
+	statLine   bool  // Linha de interrupção STAT (OU de todas as fontes)
+
+	// Renderizador
+	renderer Renderer
+	fifo     fifoState
 
 	// Buffers
-	frameBuffer [ScreenHeight][ScreenWidth]uint8 // Buffer do frame atual
-	bgBuffer    [ScreenHeight][ScreenWidth]uint8 // Buffer do background
-	objBuffer   [ScreenHeight][ScreenWidth]uint8 // Buffer dos objetos
+	frameBuffer [ScreenHeight][ScreenWidth]uint8  // Buffer do frame atual
+	bgBuffer    [ScreenHeight][ScreenWidth]uint8  // Buffer do background
+	objBuffer   [ScreenHeight][ScreenWidth]uint8  // Buffer dos objetos
+	colorBuffer [ScreenHeight][ScreenWidth]uint16 // Frame atual em RGB555
+
+	// Estado da linha atual
+	lineColor      [ScreenWidth]uint8 // Índice de cor (0-3) do BG/window
+	linePriority   [ScreenWidth]bool  // Atributo de prioridade do BG (CGB)
+	lineObj        [ScreenWidth]bool  // Pixel ocupado por um sprite
+	lineObjVisible [ScreenWidth]bool  // Sprite visível (não coberto pelo BG)
 
 	// Memória
-	vram [VRAMSize]uint8 // Video RAM
-	oam  [OAMSize]uint8  // Object Attribute Memory
+	vram [2][VRAMSize]uint8 // Video RAM (banco 1 apenas no CGB)
+	oam  [OAMSize]uint8     // Object Attribute Memory
+
+	// Game Boy Color
+	cgbMode       bool
+	vramBank      uint8
+	bcps          uint8
+	ocps          uint8
+	bgPaletteRAM  [PaletteRAMSize]uint8
+	objPaletteRAM [PaletteRAMSize]uint8
 
 	// Interface de interrupções
 	interruptHandler InterruptHandler
@@ -134,6 +181,9 @@ func (lcd *LCD) Reset() {
 	lcd.mode = ModeOAM
 	lcd.cycles = 0
 	lcd.frameReady = false
+	lcd.windowLine = 0
+	lcd.statLine = false
+	lcd.fifo = fifoState{}
 
 	// Limpa buffers
 	for y := 0; y < ScreenHeight; y++ {
@@ -141,16 +191,46 @@ func (lcd *LCD) Reset() {
 			lcd.frameBuffer[y][x] = 0
 			lcd.bgBuffer[y][x] = 0
 			lcd.objBuffer[y][x] = 0
+			lcd.colorBuffer[y][x] = dmgColors[0]
 		}
 	}
 
 	// Limpa memória
-	for i := range lcd.vram {
-		lcd.vram[i] = 0
+	for bank := range lcd.vram {
+		for i := range lcd.vram[bank] {
+			lcd.vram[bank][i] = 0
+		}
 	}
 	for i := range lcd.oam {
 		lcd.oam[i] = 0
 	}
+
+	// Paletas CGB iniciam em branco, como deixadas pela boot ROM
+	lcd.vramBank = 0
+	lcd.bcps = 0
+	lcd.ocps = 0
+	for i := range lcd.bgPaletteRAM {
+		lcd.bgPaletteRAM[i] = 0xFF
+		lcd.objPaletteRAM[i] = 0xFF
+	}
+}
+
+// SetCGBMode habilita ou desabilita o modo Game Boy Color
+func (lcd *LCD) SetCGBMode(enabled bool) {
+	lcd.cgbMode = enabled
+	if !enabled {
+		lcd.vramBank = 0
+	}
+}
+
+// IsCGBMode retorna se o LCD está em modo Game Boy Color
+func (lcd *LCD) IsCGBMode() bool {
+	return lcd.cgbMode
+}
+
+// GetMode retorna o modo atual do LCD
+func (lcd *LCD) GetMode() uint8 {
+	return lcd.mode
 }
 
 // Step executa um ciclo do LCD
@@ -159,6 +239,11 @@ func (lcd *LCD) Step(cycles int) {
 		return
 	}
 
+	if lcd.renderer == RendererFIFO {
+		lcd.stepFIFO(cycles)
+		return
+	}
+
 	lcd.cycles += cycles
 
 	switch lcd.mode {
@@ -179,18 +264,13 @@ func (lcd *LCD) Step(cycles int) {
 		if lcd.cycles >= CyclesHBlank {
 			lcd.cycles -= CyclesHBlank
 			lcd.ly++
+			lcd.checkLYC()
 
 			if lcd.ly == ScreenHeight {
-				lcd.setMode(ModeVBlank)
-				lcd.frameReady = true
-				if lcd.stat&STATVBlankInt != 0 {
-					lcd.interruptHandler.RequestInterrupt(0x01) // V-Blank interrupt
-				}
+				lcd.enterVBlank()
 			} else {
 				lcd.setMode(ModeOAM)
 			}
-
-			lcd.checkLYC()
 		}
 
 	case ModeVBlank:
@@ -200,42 +280,66 @@ func (lcd *LCD) Step(cycles int) {
 
 			if lcd.ly > 153 {
 				lcd.ly = 0
-				lcd.setMode(ModeOAM)
+				lcd.windowLine = 0
 			}
-
 			lcd.checkLYC()
+
+			if lcd.ly == 0 {
+				lcd.setMode(ModeOAM)
+			}
 		}
 	}
 }
 
+// enterVBlank inicia o V-Blank e marca o frame como pronto
+func (lcd *LCD) enterVBlank() {
+	lcd.setMode(ModeVBlank)
+	lcd.frameReady = true
+	lcd.interruptHandler.RequestInterrupt(0x01) // V-Blank interrupt
+}
+
 // setMode define o modo do LCD e atualiza o registrador STAT
 func (lcd *LCD) setMode(mode uint8) {
 	lcd.mode = mode
 	lcd.stat = (lcd.stat & ^uint8(STATMode)) | mode
-
-	// Verifica interrupções baseadas no modo
-	switch mode {
-	case ModeHBlank:
-		if lcd.stat&STATHBlankInt != 0 {
-			lcd.interruptHandler.RequestInterrupt(0x02) // LCD STAT interrupt
-		}
-	case ModeOAM:
-		if lcd.stat&STATOAMInt != 0 {
-			lcd.interruptHandler.RequestInterrupt(0x02) // LCD STAT interrupt
-		}
-	}
+	lcd.updateSTAT()
 }
 
 // checkLYC verifica se LY == LYC e atualiza flags/interrupções
 func (lcd *LCD) checkLYC() {
 	if lcd.ly == lcd.lyc {
 		lcd.stat |= STATLYCFlag
-		if lcd.stat&STATLYCInt != 0 {
-			lcd.interruptHandler.RequestInterrupt(0x02) // LCD STAT interrupt
-		}
 	} else {
 		lcd.stat &= ^uint8(STATLYCFlag)
 	}
+	lcd.updateSTAT()
+}
+
+// updateSTAT recalcula a linha de interrupção STAT. As fontes habilitadas
+// são combinadas por OU e a interrupção só é pedida na borda de subida:
+// enquanto uma fonte mantém a linha ativa, as demais não geram novas
+// interrupções ("STAT blocking").
+func (lcd *LCD) updateSTAT() {
+	line := false
+	if lcd.IsDisplayEnabled() {
+		line = lcd.stat&STATLYCInt != 0 && lcd.stat&STATLYCFlag != 0
+
+		switch lcd.mode {
+		case ModeHBlank:
+			line = line || lcd.stat&STATHBlankInt != 0
+		case ModeOAM:
+			line = line || lcd.stat&STATOAMInt != 0
+		case ModeVBlank:
+			line = line || lcd.stat&STATVBlankInt != 0
+			// No início da linha 144 a fonte do modo 2 também é ativada
+			line = line || (lcd.ly == ScreenHeight && lcd.stat&STATOAMInt != 0)
+		}
+	}
+
+	if line && !lcd.statLine {
+		lcd.interruptHandler.RequestInterrupt(0x02) // LCD STAT interrupt
+	}
+	lcd.statLine = line
 }
 
 // renderScanline renderiza uma linha da tela
@@ -244,14 +348,24 @@ func (lcd *LCD) renderScanline() {
 		return
 	}
 
-	// Renderiza background
-	if lcd.lcdc&LCDCBGEnable != 0 {
-		lcd.renderBackground()
+	for x := 0; x < ScreenWidth; x++ {
+		lcd.lineColor[x] = 0
+		lcd.linePriority[x] = false
+		lcd.lineObj[x] = false
+		lcd.lineObjVisible[x] = false
+		lcd.bgBuffer[lcd.ly][x] = 0
+		lcd.colorBuffer[lcd.ly][x] = dmgColors[0]
 	}
 
-	// Renderiza window
-	if lcd.lcdc&LCDCWindowEnable != 0 {
-		lcd.renderWindow()
+	// No CGB o bit 0 do LCDC não desliga o BG, apenas remove sua prioridade
+	if lcd.lcdc&LCDCBGEnable != 0 || lcd.cgbMode {
+		// Renderiza background
+		lcd.renderBackground()
+
+		// Renderiza window
+		if lcd.lcdc&LCDCWindowEnable != 0 {
+			lcd.renderWindow()
+		}
 	}
 
 	// Renderiza sprites
@@ -279,6 +393,17 @@ func (lcd *LCD) GetFrameBuffer() [ScreenHeight][ScreenWidth]uint8 {
 	return lcd.frameBuffer
 }
 
+// ClearFrameReady marca o frame atual como consumido
+func (lcd *LCD) ClearFrameReady() {
+	lcd.frameReady = false
+}
+
+// GetColorFrameBuffer retorna o frame atual em RGB555 (bits 0-4 vermelho,
+// 5-9 verde, 10-14 azul). Em modo DMG os tons são convertidos para cinza.
+func (lcd *LCD) GetColorFrameBuffer() [ScreenHeight][ScreenWidth]uint16 {
+	return lcd.colorBuffer
+}
+
 // ReadRegister lê um registrador do LCD
 func (lcd *LCD) ReadRegister(addr uint16) uint8 {
 	switch addr {
@@ -304,6 +429,31 @@ func (lcd *LCD) ReadRegister(addr uint16) uint8 {
 		return lcd.wy
 	case RegWX:
 		return lcd.wx
+	case RegVBK:
+		if lcd.cgbMode {
+			return lcd.vramBank | 0xFE
+		}
+		return 0xFF
+	case RegBCPS:
+		if lcd.cgbMode {
+			return lcd.bcps | 0x40
+		}
+		return 0xFF
+	case RegBCPD:
+		if lcd.cgbMode {
+			return lcd.bgPaletteRAM[lcd.bcps&0x3F]
+		}
+		return 0xFF
+	case RegOCPS:
+		if lcd.cgbMode {
+			return lcd.ocps | 0x40
+		}
+		return 0xFF
+	case RegOCPD:
+		if lcd.cgbMode {
+			return lcd.objPaletteRAM[lcd.ocps&0x3F]
+		}
+		return 0xFF
 	default:
 		return 0xFF
 	}
@@ -313,14 +463,26 @@ func (lcd *LCD) ReadRegister(addr uint16) uint8 {
 func (lcd *LCD) WriteRegister(addr uint16, value uint8) {
 	switch addr {
 	case RegLCDC:
+		wasEnabled := lcd.IsDisplayEnabled()
 		lcd.lcdc = value
 		if !lcd.IsDisplayEnabled() {
 			lcd.ly = 0
 			lcd.cycles = 0
+			lcd.fifo = fifoState{}
 			lcd.setMode(ModeHBlank)
+		} else if !wasEnabled {
+			// Ao religar, o LCD recomeça na linha 0
+			lcd.ly = 0
+			lcd.cycles = 0
+			lcd.fifo = fifoState{}
+			lcd.windowLine = 0
+			lcd.mode = ModeOAM
+			lcd.stat = (lcd.stat & ^uint8(STATMode)) | ModeOAM
+			lcd.checkLYC()
 		}
 	case RegSTAT:
 		lcd.stat = (lcd.stat & 0x07) | (value & 0x78) // Bits 0-2 são read-only
+		lcd.updateSTAT()
 	case RegSCY:
 		lcd.scy = value
 	case RegSCX:
@@ -340,13 +502,43 @@ func (lcd *LCD) WriteRegister(addr uint16, value uint8) {
 		lcd.wy = value
 	case RegWX:
 		lcd.wx = value
+	case RegVBK:
+		if lcd.cgbMode {
+			lcd.vramBank = value & 0x01
+		}
+	case RegBCPS:
+		if lcd.cgbMode {
+			lcd.bcps = value & 0xBF
+		}
+	case RegBCPD:
+		if lcd.cgbMode {
+			lcd.bgPaletteRAM[lcd.bcps&0x3F] = value
+			lcd.bcps = autoIncrement(lcd.bcps)
+		}
+	case RegOCPS:
+		if lcd.cgbMode {
+			lcd.ocps = value & 0xBF
+		}
+	case RegOCPD:
+		if lcd.cgbMode {
+			lcd.objPaletteRAM[lcd.ocps&0x3F] = value
+			lcd.ocps = autoIncrement(lcd.ocps)
+		}
 	}
 }
 
+// autoIncrement avança o índice de BCPS/OCPS quando o bit 7 está ativo
+func autoIncrement(spec uint8) uint8 {
+	if spec&0x80 == 0 {
+		return spec
+	}
+	return 0x80 | ((spec + 1) & 0x3F)
+}
+
 // ReadVRAM lê da Video RAM
 func (lcd *LCD) ReadVRAM(addr uint16) uint8 {
 	if addr >= VRAMBase && addr < VRAMBase+VRAMSize {
-		return lcd.vram[addr-VRAMBase]
+		return lcd.vram[lcd.vramBank][addr-VRAMBase]
 	}
 	return 0xFF
 }
@@ -356,7 +548,7 @@ func (lcd *LCD) WriteVRAM(addr uint16, value uint8) {
 	if addr >= VRAMBase && addr < VRAMBase+VRAMSize {
 		// Só pode escrever na VRAM quando não está no modo VRAM
 		if lcd.mode != ModeVRAM {
-			lcd.vram[addr-VRAMBase] = value
+			lcd.vram[lcd.vramBank][addr-VRAMBase] = value
 		}
 	}
 }
@@ -379,6 +571,14 @@ func (lcd *LCD) WriteOAM(addr uint16, value uint8) {
 	}
 }
 
+// DMAWriteOAM escreve o byte index da OAM pela OAM DMA, que tem acesso à
+// OAM independente do modo do PPU
+func (lcd *LCD) DMAWriteOAM(index int, value uint8) {
+	if index >= 0 && index < OAMSize {
+		lcd.oam[index] = value
+	}
+}
+
 // renderBackground renderiza o background da linha atual
 func (lcd *LCD) renderBackground() {
 	// Determina qual tile map usar
@@ -389,62 +589,20 @@ func (lcd *LCD) renderBackground() {
 		tileMapBase = TileMap0
 	}
 
-	// Determina qual tile data usar
-	var tileDataBase uint16
-	var signedTileIndex bool
-	if lcd.lcdc&LCDCBGTileData != 0 {
-		tileDataBase = TileData0
-		signedTileIndex = false
-	} else {
-		tileDataBase = TileData1
-		signedTileIndex = true
-	}
-
 	// Calcula a linha do background considerando scroll
-	bgY := (lcd.ly + lcd.scy) & 0xFF
-	tileY := bgY / TileSize
-	pixelY := bgY % TileSize
+	bgY := lcd.ly + lcd.scy
 
 	// Renderiza cada pixel da linha
 	for x := 0; x < ScreenWidth; x++ {
-		bgX := (uint8(x) + lcd.scx) & 0xFF
-		tileX := bgX / TileSize
-		pixelX := bgX % TileSize
-
-		// Obtém o índice do tile
-		tileMapAddr := tileMapBase + uint16(tileY)*TileMapSize + uint16(tileX)
-		tileIndex := lcd.vram[tileMapAddr-VRAMBase]
-
-		// Calcula o endereço do tile data
-		var tileAddr uint16
-		if signedTileIndex {
-			signedIndex := int8(tileIndex)
-			tileAddr = tileDataBase + uint16(int16(signedIndex)+128)*16
-		} else {
-			tileAddr = tileDataBase + uint16(tileIndex)*16
-		}
-
-		// Obtém os dados do pixel
-		tileDataAddr := tileAddr + uint16(pixelY)*2
-		lowByte := lcd.vram[tileDataAddr-VRAMBase]
-		highByte := lcd.vram[tileDataAddr+1-VRAMBase]
-
-		// Extrai o valor do pixel (2 bits)
-		bitPos := 7 - pixelX
-		colorBit0 := (lowByte >> bitPos) & 1
-		colorBit1 := (highByte >> bitPos) & 1
-		colorIndex := colorBit1<<1 | colorBit0
-
-		// Aplica a paleta
-		paletteColor := (lcd.bgp >> (colorIndex * 2)) & 0x03
-		lcd.bgBuffer[lcd.ly][x] = paletteColor
+		bgX := uint8(x) + lcd.scx
+		lcd.renderTilePixel(x, tileMapBase, bgX, bgY)
 	}
 }
 
 // renderWindow renderiza a window da linha atual
 func (lcd *LCD) renderWindow() {
 	// Verifica se a window está visível nesta linha
-	if lcd.ly < lcd.wy {
+	if lcd.ly < lcd.wy || lcd.wx > 166 {
 		return
 	}
 
@@ -456,21 +614,10 @@ func (lcd *LCD) renderWindow() {
 		tileMapBase = TileMap0
 	}
 
-	// Determina qual tile data usar
-	var tileDataBase uint16
-	var signedTileIndex bool
-	if lcd.lcdc&LCDCBGTileData != 0 {
-		tileDataBase = TileData0
-		signedTileIndex = false
-	} else {
-		tileDataBase = TileData1
-		signedTileIndex = true
-	}
-
-	// Calcula a linha da window
-	windowY := lcd.ly - lcd.wy
-	tileY := windowY / TileSize
-	pixelY := windowY % TileSize
+	// A window usa um contador próprio de linhas, que só avança
+	// nas linhas em que ela foi desenhada
+	windowY := uint8(lcd.windowLine)
+	lcd.windowLine++
 
 	// Renderiza cada pixel da linha da window
 	windowX := int(lcd.wx) - 7 // WX é offset por 7
@@ -478,44 +625,69 @@ func (lcd *LCD) renderWindow() {
 		if x < windowX {
 			continue
 		}
+		lcd.renderTilePixel(x, tileMapBase, uint8(x-windowX), windowY)
+	}
+}
 
-		tileX := uint8(x-windowX) / TileSize
-		pixelX := uint8(x-windowX) % TileSize
+// renderTilePixel desenha um pixel do BG/window na posição x da linha
+// atual, a partir da coordenada (mapX, mapY) no tile map
+func (lcd *LCD) renderTilePixel(x int, tileMapBase uint16, mapX, mapY uint8) {
+	// Obtém o índice do tile e, no CGB, seus atributos (banco 1)
+	mapOffset := tileMapBase - VRAMBase + uint16(mapY/TileSize)*TileMapSize + uint16(mapX/TileSize)
+	tileIndex := lcd.vram[0][mapOffset]
 
-		// Obtém o índice do tile
-		tileMapAddr := tileMapBase + uint16(tileY)*TileMapSize + uint16(tileX)
-		if tileMapAddr >= VRAMBase+VRAMSize {
-			continue
-		}
-		tileIndex := lcd.vram[tileMapAddr-VRAMBase]
+	var attributes uint8
+	if lcd.cgbMode {
+		attributes = lcd.vram[1][mapOffset]
+	}
 
-		// Calcula o endereço do tile data
-		var tileAddr uint16
-		if signedTileIndex {
-			signedIndex := int8(tileIndex)
-			tileAddr = tileDataBase + uint16(int16(signedIndex)+128)*16
-		} else {
-			tileAddr = tileDataBase + uint16(tileIndex)*16
-		}
+	row := mapY % TileSize
+	col := mapX % TileSize
+	if attributes&AttrFlipY != 0 {
+		row = 7 - row
+	}
+	if attributes&AttrFlipX != 0 {
+		col = 7 - col
+	}
 
-		// Obtém os dados do pixel
-		tileDataAddr := tileAddr + uint16(pixelY)*2
-		if tileDataAddr >= VRAMBase+VRAMSize {
-			continue
-		}
-		lowByte := lcd.vram[tileDataAddr-VRAMBase]
-		highByte := lcd.vram[tileDataAddr+1-VRAMBase]
+	bank := (attributes & AttrBank) >> 3
+	colorIndex := lcd.tilePixel(bank, lcd.bgTileOffset(tileIndex), row, col)
 
-		// Extrai o valor do pixel (2 bits)
-		bitPos := 7 - pixelX
-		colorBit0 := (lowByte >> bitPos) & 1
-		colorBit1 := (highByte >> bitPos) & 1
-		colorIndex := colorBit1<<1 | colorBit0
+	lcd.lineColor[x] = colorIndex
+	lcd.linePriority[x] = attributes&AttrPriority != 0
 
-		// Aplica a paleta
-		paletteColor := (lcd.bgp >> (colorIndex * 2)) & 0x03
-		lcd.bgBuffer[lcd.ly][x] = paletteColor
+	// Aplica a paleta
+	lcd.bgBuffer[lcd.ly][x] = (lcd.bgp >> (colorIndex * 2)) & 0x03
+	if lcd.cgbMode {
+		lcd.colorBuffer[lcd.ly][x] = paletteColor(&lcd.bgPaletteRAM, attributes&AttrPalette, colorIndex)
+	}
+}
+
+// bgTileOffset calcula o offset na VRAM dos dados de um tile de BG/window
+func (lcd *LCD) bgTileOffset(tileIndex uint8) uint16 {
+	if lcd.lcdc&LCDCBGTileData != 0 {
+		return TileData0 - VRAMBase + uint16(tileIndex)*16
 	}
+	// Endereçamento com sinal a partir de 0x9000
+	return uint16(0x1000 + int(int8(tileIndex))*16)
+}
+
+// tilePixel extrai o índice de cor (2 bits) de um pixel de tile
+func (lcd *LCD) tilePixel(bank uint8, tileOffset uint16, row, col uint8) uint8 {
+	dataOffset := tileOffset + uint16(row)*2
+	lowByte := lcd.vram[bank][dataOffset]
+	highByte := lcd.vram[bank][dataOffset+1]
+
+	bitPos := 7 - col
+	colorBit0 := (lowByte >> bitPos) & 1
+	colorBit1 := (highByte >> bitPos) & 1
+	return colorBit1<<1 | colorBit0
+}
+
+// paletteColor lê uma cor RGB555 da memória de paletas do CGB
+func paletteColor(ram *[PaletteRAMSize]uint8, palette, colorIndex uint8) uint16 {
+	offset := int(palette)*8 + int(colorIndex)*2
+	return (uint16(ram[offset]) | uint16(ram[offset+1])<<8) & 0x7FFF
 }
 
 // renderSprites renderiza os sprites da linha atual
@@ -530,33 +702,37 @@ func (lcd *LCD) renderSprites() {
 		lcd.objBuffer[lcd.ly][x] = 0
 	}
 
-	// Processa até 10 sprites por linha
-	spritesOnLine := 0
-	for i := 0; i < 40 && spritesOnLine < 10; i++ {
+	// Seleciona até 10 sprites por linha, na ordem da OAM
+	var selected [10]int
+	count := 0
+	for i := 0; i < 40 && count < 10; i++ {
+		spriteY := int(lcd.oam[i*4]) - 16
+		if int(lcd.ly) < spriteY || int(lcd.ly) >= spriteY+spriteHeight {
+			continue
+		}
+		selected[count] = i
+		count++
+	}
+
+	// No DMG o sprite de menor X tem prioridade; no CGB vale a ordem da OAM
+	if !lcd.cgbMode {
+		sort.SliceStable(selected[:count], func(a, b int) bool {
+			return lcd.oam[selected[a]*4+1] < lcd.oam[selected[b]*4+1]
+		})
+	}
+
+	for _, i := range selected[:count] {
 		spriteAddr := i * 4
 
 		// Lê os atributos do sprite
-		spriteY := lcd.oam[spriteAddr] - 16
-		spriteX := lcd.oam[spriteAddr+1] - 8
+		spriteY := int(lcd.oam[spriteAddr]) - 16
+		spriteX := int(lcd.oam[spriteAddr+1]) - 8
 		tileIndex := lcd.oam[spriteAddr+2]
 		attributes := lcd.oam[spriteAddr+3]
 
-		// Verifica se o sprite está visível nesta linha
-		if lcd.ly < spriteY || lcd.ly >= spriteY+uint8(spriteHeight) {
-			continue
-		}
-
-		spritesOnLine++
-
-		// Extrai atributos
-		priority := (attributes & 0x80) != 0
-		flipY := (attributes & 0x40) != 0
-		flipX := (attributes & 0x20) != 0
-		palette := (attributes & 0x10) != 0
-
 		// Calcula a linha do sprite
-		spriteLineY := lcd.ly - spriteY
-		if flipY {
+		spriteLineY := uint8(int(lcd.ly) - spriteY)
+		if attributes&AttrFlipY != 0 {
 			spriteLineY = uint8(spriteHeight-1) - spriteLineY
 		}
 
@@ -565,65 +741,151 @@ func (lcd *LCD) renderSprites() {
 			tileIndex &= 0xFE
 		}
 
-		// Calcula o endereço dos dados do tile
-		tileAddr := TileData0 + uint16(tileIndex)*16 + uint16(spriteLineY)*2
-		lowByte := lcd.vram[tileAddr-VRAMBase]
-		highByte := lcd.vram[tileAddr+1-VRAMBase]
+		var bank uint8
+		if lcd.cgbMode {
+			bank = (attributes & AttrBank) >> 3
+		}
 
 		// Renderiza cada pixel do sprite
 		for pixelX := uint8(0); pixelX < 8; pixelX++ {
-			screenX := int(spriteX) + int(pixelX)
-			if screenX < 0 || screenX >= ScreenWidth {
+			screenX := spriteX + int(pixelX)
+			if screenX < 0 || screenX >= ScreenWidth || lcd.lineObj[screenX] {
 				continue
 			}
 
-			// Calcula a posição do bit
-			bitPos := pixelX
-			if flipX {
-				bitPos = 7 - pixelX
+			col := pixelX
+			if attributes&AttrFlipX != 0 {
+				col = 7 - pixelX
 			}
 
-			// Extrai o valor do pixel
-			colorBit0 := (lowByte >> (7 - bitPos)) & 1
-			colorBit1 := (highByte >> (7 - bitPos)) & 1
-			colorIndex := colorBit1<<1 | colorBit0
+			colorIndex := lcd.tilePixel(bank, uint16(tileIndex)*16, spriteLineY, col)
 
 			// Cor 0 é transparente
 			if colorIndex == 0 {
 				continue
 			}
 
+			// Mesmo escondido pelo BG, o pixel bloqueia sprites de menor prioridade
+			lcd.lineObj[screenX] = true
+			if lcd.bgHasPriority(screenX, attributes) {
+				continue
+			}
+
 			// Aplica a paleta
-			var paletteReg uint8
-			if palette {
+			paletteReg := lcd.obp0
+			if attributes&AttrDMGPal != 0 {
 				paletteReg = lcd.obp1
-			} else {
-				paletteReg = lcd.obp0
 			}
-			paletteColor := (paletteReg >> (colorIndex * 2)) & 0x03
+			lcd.objBuffer[lcd.ly][screenX] = (paletteReg >> (colorIndex * 2)) & 0x03
 
-			// Verifica prioridade
-			if !priority || lcd.bgBuffer[lcd.ly][screenX] == 0 {
-				lcd.objBuffer[lcd.ly][screenX] = paletteColor
+			if lcd.cgbMode {
+				lcd.colorBuffer[lcd.ly][screenX] = paletteColor(&lcd.objPaletteRAM, attributes&AttrPalette, colorIndex)
 			}
+			lcd.lineObjVisible[screenX] = true
+		}
+	}
+}
+
+// bgHasPriority retorna se o BG/window cobre o sprite na coluna x
+func (lcd *LCD) bgHasPriority(x int, attributes uint8) bool {
+	if lcd.lineColor[x] == 0 {
+		return false
+	}
+	if lcd.cgbMode {
+		// LCDC bit 0 desligado dá prioridade total aos sprites
+		if lcd.lcdc&LCDCBGEnable == 0 {
+			return false
 		}
+		return attributes&AttrPriority != 0 || lcd.linePriority[x]
 	}
+	return attributes&AttrPriority != 0
 }
 
 // combineBuffers combina os buffers de background e objetos no frame buffer final
 func (lcd *LCD) combineBuffers() {
 	for x := 0; x < ScreenWidth; x++ {
+		if lcd.cgbMode {
+			// Aproxima o tom DMG a partir da luminância, para frontends monocromáticos
+			lcd.frameBuffer[lcd.ly][x] = colorToShade(lcd.colorBuffer[lcd.ly][x])
+			continue
+		}
+
 		// Se há um sprite visível, usa ele; senão usa o background
-		if lcd.objBuffer[lcd.ly][x] != 0 {
+		if lcd.lineObjVisible[x] {
 			lcd.frameBuffer[lcd.ly][x] = lcd.objBuffer[lcd.ly][x]
 		} else {
 			lcd.frameBuffer[lcd.ly][x] = lcd.bgBuffer[lcd.ly][x]
 		}
+		lcd.colorBuffer[lcd.ly][x] = dmgColors[lcd.frameBuffer[lcd.ly][x]]
+	}
+}
+
+// colorToShade converte uma cor RGB555 no tom DMG (0-3) mais próximo
+func colorToShade(color uint16) uint8 {
+	r := int(color & 0x1F)
+	g := int((color >> 5) & 0x1F)
+	b := int((color >> 10) & 0x1F)
+	luma := (r*2 + g*5 + b) / 8 // 0-31
+	return uint8(3 - luma*4/32)
+}
+
+// ColorToRGBA converte uma cor RGB555 em componentes RGBA de 8 bits
+func ColorToRGBA(color uint16) (r, g, b, a uint8) {
+	r = uint8(color&0x1F) << 3
+	g = uint8((color>>5)&0x1F) << 3
+	b = uint8((color>>10)&0x1F) << 3
+	return r | r>>5, g | g>>5, b | b>>5, 0xFF
+}
+
+// ColorFrameToRGBA converte um frame RGB555 em um buffer RGBA (4 bytes por pixel)
+func ColorFrameToRGBA(frame [ScreenHeight][ScreenWidth]uint16) []uint8 {
+	rgba := make([]uint8, ScreenWidth*ScreenHeight*4)
+	for y := 0; y < ScreenHeight; y++ {
+		for x := 0; x < ScreenWidth; x++ {
+			offset := (y*ScreenWidth + x) * 4
+			rgba[offset], rgba[offset+1], rgba[offset+2], rgba[offset+3] = ColorToRGBA(frame[y][x])
+		}
+	}
+	return rgba
+}
+
+// stateFields lista os campos gravados no save state. Os buffers de BG e
+// de objetos são refeitos a cada linha e ficam de fora.
+func (lcd *LCD) stateFields() []any {
+	fields := []any{
+		&lcd.lcdc, &lcd.stat, &lcd.scy, &lcd.scx, &lcd.ly, &lcd.lyc,
+		&lcd.bgp, &lcd.obp0, &lcd.obp1, &lcd.wy, &lcd.wx,
+		&lcd.mode, &lcd.cycles, &lcd.frameReady, &lcd.windowLine, &lcd.statLine,
+		&lcd.frameBuffer, &lcd.colorBuffer,
+		&lcd.lineColor, &lcd.linePriority, &lcd.lineObj, &lcd.lineObjVisible,
+		&lcd.vram, &lcd.oam,
+		&lcd.cgbMode, &lcd.vramBank, &lcd.bcps, &lcd.ocps, &lcd.bgPaletteRAM, &lcd.objPaletteRAM,
+	}
+	return append(fields, lcd.fifo.stateFields()...)
+}
+
+// SaveState serializa registradores, VRAM, OAM, paletas, o frame em
+// construção e o estado do renderizador
+func (lcd *LCD) SaveState() ([]byte, error) {
+	renderer := int(lcd.renderer)
+	return savestate.Marshal(append([]any{&renderer}, lcd.stateFields()...)...)
+}
+
+// LoadState restaura o estado gerado por SaveState. Se o estado veio de
+// outro renderizador, a linha atual recomeça no modo 2.
+func (lcd *LCD) LoadState(data []byte) error {
+	var renderer int
+	if err := savestate.Unmarshal(data, append([]any{&renderer}, lcd.stateFields()...)...); err != nil {
+		return fmt.Errorf("estado do LCD inválido: %w", err)
+	}
+	if Renderer(renderer) != lcd.renderer {
+		lcd.restartLine()
 	}
+	return nil
 }
 
 // String retorna uma representação em string do estado do LCD
 func (lcd *LCD) String() string {
-	return fmt.Sprintf("LCD: Mode=%d LY=%d LCDC=0x%02X STAT=0x%02X",
-		lcd.mode, lcd.ly, lcd.lcdc, lcd.stat)
+	return fmt.Sprintf("LCD: Mode=%d LY=%d LCDC=0x%02X STAT=0x%02X Renderer=%s",
+		lcd.mode, lcd.ly, lcd.lcdc, lcd.stat, lcd.renderer)
 }
@@ -0,0 +1,117 @@
+package memory
+
+// Registradores da porta GPIO do cartucho, mapeados sobre a ROM
+const (
+	GPIOData      uint32 = 0x080000C4 // Pinos 0-3
+	GPIODirection uint32 = 0x080000C6 // 1 = saída (GBA -> cartucho)
+	GPIOControl   uint32 = 0x080000C8 // Bit 0: registradores legíveis
+
+	// Pino ligado ao motor de vibração (Drill Dozer, WarioWare: Twisted!)
+	GPIORumblePin = 1 << 3
+)
+
+// rumbleGameCodes são os códigos de jogo (0xAC no header) de cartuchos com
+// motor de vibração na porta GPIO
+var rumbleGameCodes = map[string]bool{
+	"V49E": true, "V49J": true, "V49P": true, // Drill Dozer
+	"RZWE": true, "RZWJ": true, "RZWP": true, // WarioWare: Twisted!
+}
+
+// GPIO representa a porta de 4 pinos usada por cartuchos com RTC, sensores
+// ou motor de vibração. Somente o motor é emulado.
+type GPIO struct {
+	enabled   bool
+	data      byte
+	direction byte
+	readable  bool
+}
+
+// NewGPIO cria uma porta GPIO desabilitada
+func NewGPIO() *GPIO {
+	return &GPIO{}
+}
+
+// SetEnabled liga/desliga o mapeamento da porta sobre a ROM
+func (g *GPIO) SetEnabled(enabled bool) {
+	g.enabled = enabled
+	g.Reset()
+}
+
+// IsEnabled retorna se a porta está mapeada
+func (g *GPIO) IsEnabled() bool {
+	return g.enabled
+}
+
+// Reset reinicia os registradores da porta
+func (g *GPIO) Reset() {
+	g.data = 0
+	g.direction = 0
+	g.readable = false
+}
+
+// handles retorna se o acesso ao endereço vai para a porta. Leituras só são
+// desviadas com o bit 0 do controle ligado; caso contrário leem a ROM.
+func (g *GPIO) handles(addr uint32, isWrite bool) bool {
+	if !g.enabled || addr < GPIOData || addr > GPIOControl+1 {
+		return false
+	}
+	return isWrite || g.readable
+}
+
+// Read lê um registrador da porta
+func (g *GPIO) Read(addr uint32) byte {
+	switch addr {
+	case GPIOData:
+		return g.data
+	case GPIODirection:
+		return g.direction
+	case GPIOControl:
+		if g.readable {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Write escreve em um registrador da porta. Somente pinos configurados
+// como saída são alterados pelo GBA.
+func (g *GPIO) Write(addr uint32, value byte) {
+	switch addr {
+	case GPIOData:
+		g.data = (g.data &^ g.direction) | (value & g.direction & 0x0F)
+	case GPIODirection:
+		g.direction = value & 0x0F
+	case GPIOControl:
+		g.readable = value&0x01 != 0
+	}
+}
+
+// GetState retorna os registradores da porta (dados, direção e controle)
+func (g *GPIO) GetState() [3]byte {
+	state := [3]byte{g.data, g.direction, 0}
+	if g.readable {
+		state[2] = 1
+	}
+	return state
+}
+
+// SetState restaura os registradores gerados por GetState
+func (g *GPIO) SetState(state [3]byte) {
+	g.data = state[0] & 0x0F
+	g.direction = state[1] & 0x0F
+	g.readable = state[2]&0x01 != 0
+}
+
+// MotorOn retorna se o motor de vibração está ligado
+func (g *GPIO) MotorOn() bool {
+	return g.enabled && g.direction&GPIORumblePin != 0 && g.data&GPIORumblePin != 0
+}
+
+// HasRumbleGameCode retorna se o header da ROM identifica um jogo com motor
+// de vibração
+func HasRumbleGameCode(romData []byte) bool {
+	if len(romData) < 0xB0 {
+		return false
+	}
+	return rumbleGameCodes[string(romData[0xAC:0xB0])]
+}
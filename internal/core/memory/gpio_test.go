@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"testing"
+)
+
+// newRumbleROM cria uma ROM com o código de jogo do Drill Dozer
+func newRumbleROM() []byte {
+	rom := make([]byte, 0x200)
+	copy(rom[0xAC:], "V49E")
+	rom[GPIOData-ROMStart] = 0xAA
+	return rom
+}
+
+func TestGPIODetection(t *testing.T) {
+	ms := NewMemorySystem()
+	if err := ms.LoadROM(make([]byte, 0x200)); err != nil {
+		t.Fatalf("Erro ao carregar ROM: %v", err)
+	}
+	if ms.GetGPIO().IsEnabled() {
+		t.Error("GPIO não deveria estar ativa sem código de jogo com rumble")
+	}
+
+	if err := ms.LoadROM(newRumbleROM()); err != nil {
+		t.Fatalf("Erro ao carregar ROM: %v", err)
+	}
+	if !ms.GetGPIO().IsEnabled() {
+		t.Error("GPIO deveria estar ativa para V49E")
+	}
+}
+
+func TestGPIORumble(t *testing.T) {
+	ms := NewMemorySystem()
+	if err := ms.LoadROM(newRumbleROM()); err != nil {
+		t.Fatalf("Erro ao carregar ROM: %v", err)
+	}
+	gpio := ms.GetGPIO()
+
+	// Pino de entrada não é alterado pelo GBA
+	ms.Write16(GPIOData, GPIORumblePin)
+	if gpio.MotorOn() {
+		t.Error("Motor não deveria ligar com o pino configurado como entrada")
+	}
+
+	ms.Write16(GPIODirection, GPIORumblePin)
+	ms.Write16(GPIOData, GPIORumblePin)
+	if !gpio.MotorOn() {
+		t.Error("Motor deveria estar ligado")
+	}
+
+	// Sem o bit de leitura, o endereço lê a ROM
+	if got := ms.Read8(GPIOData); got != 0xAA {
+		t.Errorf("Leitura com GPIO ilegível: got 0x%02X, want 0xAA", got)
+	}
+	ms.Write16(GPIOControl, 1)
+	if got := ms.Read8(GPIOData); got != GPIORumblePin {
+		t.Errorf("Leitura do registrador de dados: got 0x%02X, want 0x%02X", got, GPIORumblePin)
+	}
+
+	ms.Write16(GPIOData, 0)
+	if gpio.MotorOn() {
+		t.Error("Motor deveria estar desligado")
+	}
+}
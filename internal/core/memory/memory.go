@@ -24,6 +24,7 @@ const (
 type MemorySystem struct {
 	bus    *MemoryBus
 	timers *timer.TimerSystem
+	gpio   *GPIO
 }
 
 // NewMemorySystem cria uma nova instância do sistema de memória
@@ -104,6 +105,7 @@ func NewMemorySystem() *MemorySystem {
 			IO:         make(map[uint32]*IORegister),
 			IOHandlers: make(map[uint32]IOHandler),
 		},
+		gpio: NewGPIO(),
 	}
 
 	// Inicializa registradores de I/O
@@ -117,6 +119,11 @@ func (m *MemorySystem) SetTimerSystem(timers *timer.TimerSystem) {
 	m.timers = timers
 }
 
+// GetGPIO retorna a porta GPIO do cartucho
+func (m *MemorySystem) GetGPIO() *GPIO {
+	return m.gpio
+}
+
 // initIORegisters inicializa os registradores de I/O com seus valores padrão
 func (m *MemorySystem) initIORegisters() {
 	// LCD Control
@@ -161,6 +168,9 @@ func (m *MemorySystem) LoadROM(romData []byte) error {
 		return fmt.Errorf("ROM muito grande: %d (máximo %d)", len(romData), ROMSize)
 	}
 
+	// Cartuchos com motor de vibração usam a porta GPIO
+	m.gpio.SetEnabled(HasRumbleGameCode(romData))
+
 	// Encontra a região da ROM
 	for i := range m.bus.Map.Regions {
 		if m.bus.Map.Regions[i].Start == ROMStart {
@@ -353,6 +363,11 @@ func (m *MemorySystem) Read8(addr uint32) byte {
 		return 0
 	}
 
+	// Porta GPIO do cartucho
+	if m.gpio.handles(addr, false) {
+		return m.gpio.Read(addr)
+	}
+
 	// Procura a região de memória
 	region := m.findRegion(addr)
 	if region == nil {
@@ -384,6 +399,12 @@ func (m *MemorySystem) Write8(addr uint32, value byte) {
 		return
 	}
 
+	// Porta GPIO do cartucho
+	if m.gpio.handles(addr, true) {
+		m.gpio.Write(addr, value)
+		return
+	}
+
 	// Procura a região de memória
 	region := m.findRegion(addr)
 	if region == nil {
@@ -460,6 +481,36 @@ func (m *MemorySystem) GetRegion(start uint32) *MemoryRegion {
 	return m.findRegion(start)
 }
 
+// GetBackup retorna a memória de backup (save) do cartucho
+func (m *MemorySystem) GetBackup() *BackupMemory {
+	return m.bus.Backup
+}
+
+// GetIOState retorna os valores dos registradores de I/O sem handler,
+// indexados pelo endereço a partir de IOStart
+func (m *MemorySystem) GetIOState() []byte {
+	state := make([]byte, IOSize)
+	for addr, reg := range m.bus.IO {
+		if addr >= IOStart && addr <= IOEnd {
+			state[addr-IOStart] = reg.Value
+		}
+	}
+	return state
+}
+
+// SetIOState restaura os registradores gravados por GetIOState
+func (m *MemorySystem) SetIOState(state []byte) error {
+	if len(state) != int(IOSize) {
+		return fmt.Errorf("estado de I/O com %d bytes (esperado %d)", len(state), IOSize)
+	}
+	for addr, reg := range m.bus.IO {
+		if addr >= IOStart && addr <= IOEnd {
+			reg.Value = state[addr-IOStart]
+		}
+	}
+	return nil
+}
+
 // DumpMemory retorna um dump de uma região de memória específica
 func (m *MemorySystem) DumpMemory(start, size uint32) []byte {
 	region := m.findRegion(start)
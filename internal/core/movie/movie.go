@@ -0,0 +1,312 @@
+// Package movie implementa a gravação e a reprodução determinística de
+// input. Um movie guarda o estado dos botões em cada frame, a partir do
+// power-on ou de um save state embutido, e uma soma de verificação do
+// framebuffer e da RAM ao fim de cada frame. Na reprodução, a primeira soma
+// diferente indica o frame em que a emulação deixou de seguir a gravação.
+//
+// O arquivo usa o contêiner de statefile, com as seções:
+//
+//	HEAD  header (sistema, versão do núcleo e configurações)
+//	HASH  SHA-1 da ROM, em hexadecimal
+//	STAT  save state inicial (ausente = power-on)
+//	INPT  botões de cada frame (uint16)
+//	CSUM  soma de verificação de cada frame (uint32)
+package movie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hobbiee/visualboy-go/internal/core/statefile"
+)
+
+// Formato do arquivo
+const (
+	Magic   = "VBMV"
+	Version = 1
+)
+
+// Sistemas gravados no header
+const (
+	SystemGB  = "GB"
+	SystemGBA = "GBA"
+)
+
+// Tags das seções
+const (
+	tagHeader    = "HEAD"
+	tagState     = "STAT"
+	tagInput     = "INPT"
+	tagChecksums = "CSUM"
+)
+
+var format = statefile.NewFormat(Magic, Version)
+
+// ErrDesync é a causa dos erros de reprodução que divergiu da gravação
+var ErrDesync = errors.New("movie dessincronizado")
+
+// ErrSystemMismatch indica um movie gravado no outro núcleo
+var ErrSystemMismatch = errors.New("movie de outro sistema")
+
+// DesyncError informa o primeiro frame cuja soma de verificação não
+// corresponde à gravada
+type DesyncError struct {
+	Frame    int    // Frame, a partir de 1
+	Expected uint32 // Soma gravada
+	Actual   uint32 // Soma obtida na reprodução
+}
+
+// Error implementa a interface error
+func (e *DesyncError) Error() string {
+	return fmt.Sprintf("%v no frame %d: esperado %08X, obtido %08X", ErrDesync, e.Frame, e.Expected, e.Actual)
+}
+
+// Unwrap permite comparar com errors.Is(err, ErrDesync)
+func (e *DesyncError) Unwrap() error {
+	return ErrDesync
+}
+
+// Header descreve as condições da gravação
+type Header struct {
+	System      string            // SystemGB ou SystemGBA
+	CoreVersion string            // Versão do núcleo que gravou
+	ROMHash     string            // SHA-1 da ROM, em hexadecimal
+	Settings    map[string]string // Configurações que afetam a emulação
+	StartState  []byte            // Save state inicial (nil = power-on)
+}
+
+// Setting retorna uma configuração do header (vazio se ausente)
+func (h *Header) Setting(name string) string {
+	return h.Settings[name]
+}
+
+// CheckROM confere o hash da ROM carregada com o da gravação.
+// Retorna *statefile.ROMMismatchError se forem diferentes.
+func (h *Header) CheckROM(system, romHash string) error {
+	if h.System != system {
+		return fmt.Errorf("%w: gravado em %s, núcleo %s", ErrSystemMismatch, h.System, system)
+	}
+	if h.ROMHash != "" && !strings.EqualFold(h.ROMHash, romHash) {
+		return &statefile.ROMMismatchError{Saved: h.ROMHash, Loaded: romHash}
+	}
+	return nil
+}
+
+// Frame é o registro de um frame
+type Frame struct {
+	Input    uint16 // Botões pressionados durante o frame
+	Checksum uint32 // Soma do framebuffer e da RAM ao fim do frame
+}
+
+// Movie é uma gravação completa
+type Movie struct {
+	Header
+	Frames []Frame
+}
+
+// Checksum calcula a soma de verificação (CRC-32) de um frame a partir do
+// framebuffer e das regiões de RAM
+func Checksum(parts ...[]byte) uint32 {
+	var sum uint32
+	for _, part := range parts {
+		sum = crc32.Update(sum, crc32.IEEETable, part)
+	}
+	return sum
+}
+
+// headerInfo é a parte do header gravada na seção HEAD
+type headerInfo struct {
+	System      string
+	CoreVersion string
+	Settings    map[string]string
+}
+
+// Encode codifica o movie
+func (m *Movie) Encode() ([]byte, error) {
+	var head bytes.Buffer
+	info := headerInfo{System: m.System, CoreVersion: m.CoreVersion, Settings: m.Settings}
+	if err := gob.NewEncoder(&head).Encode(info); err != nil {
+		return nil, fmt.Errorf("erro ao codificar header do movie: %w", err)
+	}
+
+	inputs := make([]byte, 2*len(m.Frames))
+	checksums := make([]byte, 4*len(m.Frames))
+	for i, frame := range m.Frames {
+		binary.LittleEndian.PutUint16(inputs[2*i:], frame.Input)
+		binary.LittleEndian.PutUint32(checksums[4*i:], frame.Checksum)
+	}
+
+	file := &statefile.File{}
+	file.Add(tagHeader, head.Bytes())
+	file.Add(statefile.TagROMHash, []byte(m.ROMHash))
+	if m.StartState != nil {
+		file.Add(tagState, m.StartState)
+	}
+	file.Add(tagInput, inputs)
+	file.Add(tagChecksums, checksums)
+	return format.Encode(file), nil
+}
+
+// Decode lê um movie gerado por Encode
+func Decode(data []byte) (*Movie, error) {
+	file, err := format.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var info headerInfo
+	if err := gob.NewDecoder(bytes.NewReader(file.Get(tagHeader))).Decode(&info); err != nil {
+		return nil, fmt.Errorf("header do movie inválido: %w", err)
+	}
+
+	inputs, checksums := file.Get(tagInput), file.Get(tagChecksums)
+	if len(inputs)%2 != 0 || len(checksums) != 2*len(inputs) {
+		return nil, fmt.Errorf("movie inválido: %d bytes de input e %d de somas", len(inputs), len(checksums))
+	}
+
+	m := &Movie{
+		Header: Header{
+			System:      info.System,
+			CoreVersion: info.CoreVersion,
+			ROMHash:     string(file.Get(statefile.TagROMHash)),
+			Settings:    info.Settings,
+			StartState:  file.Get(tagState),
+		},
+		Frames: make([]Frame, len(inputs)/2),
+	}
+	for i := range m.Frames {
+		m.Frames[i].Input = binary.LittleEndian.Uint16(inputs[2*i:])
+		m.Frames[i].Checksum = binary.LittleEndian.Uint32(checksums[4*i:])
+	}
+	return m, nil
+}
+
+// Save grava o movie no arquivo
+func (m *Movie) Save(path string) error {
+	data, err := m.Encode()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Load lê um movie do arquivo
+func Load(path string) (*Movie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler movie: %w", err)
+	}
+	m, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler movie %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// String resume o header do movie
+func (m *Movie) String() string {
+	start := "power-on"
+	if m.StartState != nil {
+		start = "save state"
+	}
+	names := make([]string, 0, len(m.Settings))
+	for name := range m.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	settings := make([]string, len(names))
+	for i, name := range names {
+		settings[i] = name + "=" + m.Settings[name]
+	}
+	return fmt.Sprintf("Movie %s (%s): %d frames desde %s, ROM %s [%s]",
+		m.System, m.CoreVersion, len(m.Frames), start, m.ROMHash, strings.Join(settings, " "))
+}
+
+// Recorder acumula os frames de uma gravação
+type Recorder struct {
+	movie *Movie
+}
+
+// NewRecorder inicia uma gravação com o header indicado
+func NewRecorder(header Header) *Recorder {
+	return &Recorder{movie: &Movie{Header: header}}
+}
+
+// Record acrescenta um frame
+func (r *Recorder) Record(input uint16, checksum uint32) {
+	r.movie.Frames = append(r.movie.Frames, Frame{Input: input, Checksum: checksum})
+}
+
+// Frames retorna o número de frames gravados
+func (r *Recorder) Frames() int {
+	return len(r.movie.Frames)
+}
+
+// Movie retorna a gravação
+func (r *Recorder) Movie() *Movie {
+	return r.movie
+}
+
+// Player percorre os frames de um movie
+type Player struct {
+	movie *Movie
+	frame int // Próximo frame (a partir de 0)
+	err   error
+}
+
+// NewPlayer inicia a reprodução de um movie
+func NewPlayer(m *Movie) *Player {
+	return &Player{movie: m}
+}
+
+// Input retorna os botões do frame atual. ok é false ao fim do movie.
+func (p *Player) Input() (input uint16, ok bool) {
+	if p.Done() {
+		return 0, false
+	}
+	return p.movie.Frames[p.frame].Input, true
+}
+
+// Verify confere a soma do frame atual e avança para o seguinte. A
+// primeira divergência retorna *DesyncError e encerra a reprodução.
+func (p *Player) Verify(checksum uint32) error {
+	if p.Done() {
+		return p.err
+	}
+
+	expected := p.movie.Frames[p.frame].Checksum
+	p.frame++
+	if checksum != expected {
+		p.err = &DesyncError{Frame: p.frame, Expected: expected, Actual: checksum}
+		return p.err
+	}
+	return nil
+}
+
+// Frame retorna o número de frames já reproduzidos
+func (p *Player) Frame() int {
+	return p.frame
+}
+
+// Done retorna se a reprodução terminou, pelo fim do movie ou por
+// dessincronização
+func (p *Player) Done() bool {
+	return p.err != nil || p.frame >= len(p.movie.Frames)
+}
+
+// Err retorna a dessincronização encontrada (nil se nenhuma)
+func (p *Player) Err() error {
+	return p.err
+}
+
+// Movie retorna o movie reproduzido
+func (p *Player) Movie() *Movie {
+	return p.movie
+}
@@ -0,0 +1,102 @@
+package movie
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hobbiee/visualboy-go/internal/core/statefile"
+)
+
+func newMovie() *Movie {
+	r := NewRecorder(Header{
+		System:      SystemGB,
+		CoreVersion: "gb-1",
+		ROMHash:     "abcdef",
+		Settings:    map[string]string{"model": "DMG"},
+	})
+	for i := 0; i < 10; i++ {
+		r.Record(uint16(i), Checksum([]byte{byte(i)}, []byte{1, 2}))
+	}
+	return r.Movie()
+}
+
+func TestEncodeDecode(t *testing.T) {
+	m := newMovie()
+	path := filepath.Join(t.TempDir(), "test.vbm")
+	if err := m.Save(path); err != nil {
+		t.Fatalf("erro ao gravar movie: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("erro ao ler movie: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("movie diferente após a leitura:\n%v\n%v", loaded, m)
+	}
+
+	// Estado inicial embutido
+	m.StartState = []byte{1, 2, 3}
+	data, _ := m.Encode()
+	if loaded, _ := Decode(data); !reflect.DeepEqual(loaded.StartState, m.StartState) {
+		t.Errorf("estado inicial diferente: %v", loaded.StartState)
+	}
+
+	if _, err := Decode(data[:len(data)-1]); err == nil {
+		t.Error("movie truncado deveria retornar erro")
+	}
+	if _, err := Decode([]byte("VBGA\x01\x00\x00\x00")); !errors.Is(err, statefile.ErrInvalidMagic) {
+		t.Errorf("esperado ErrInvalidMagic, obtido %v", err)
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	m := newMovie()
+	p := NewPlayer(m)
+	for i := 0; i < 3; i++ {
+		input, ok := p.Input()
+		if !ok || input != uint16(i) {
+			t.Fatalf("frame %d: esperado input %d, obtido %d (ok=%v)", i, i, input, ok)
+		}
+		if err := p.Verify(m.Frames[i].Checksum); err != nil {
+			t.Fatalf("frame %d: erro inesperado: %v", i, err)
+		}
+	}
+
+	// A primeira soma diferente encerra a reprodução com o número do frame
+	err := p.Verify(0)
+	var desync *DesyncError
+	if !errors.As(err, &desync) || !errors.Is(err, ErrDesync) || desync.Frame != 4 {
+		t.Fatalf("esperado DesyncError no frame 4, obtido %v", err)
+	}
+	if !p.Done() || p.Err() != err {
+		t.Error("reprodução deveria terminar na dessincronização")
+	}
+	if _, ok := p.Input(); ok {
+		t.Error("não deveria haver input após a dessincronização")
+	}
+
+	// Reprodução completa
+	p = NewPlayer(m)
+	for _, frame := range m.Frames {
+		p.Verify(frame.Checksum)
+	}
+	if !p.Done() || p.Err() != nil || p.Frame() != len(m.Frames) {
+		t.Errorf("esperado fim sem erro após %d frames, obtido %d (%v)", len(m.Frames), p.Frame(), p.Err())
+	}
+}
+
+func TestCheckROM(t *testing.T) {
+	m := newMovie()
+	if err := m.CheckROM(SystemGB, "ABCDEF"); err != nil {
+		t.Errorf("hash igual não deveria retornar erro: %v", err)
+	}
+	var mismatch *statefile.ROMMismatchError
+	if err := m.CheckROM(SystemGB, "123456"); !errors.As(err, &mismatch) || mismatch.Saved != "abcdef" {
+		t.Errorf("esperado ROMMismatchError, obtido %v", err)
+	}
+	if err := m.CheckROM(SystemGBA, "abcdef"); !errors.Is(err, ErrSystemMismatch) {
+		t.Errorf("esperado ErrSystemMismatch, obtido %v", err)
+	}
+}
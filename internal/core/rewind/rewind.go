@@ -0,0 +1,247 @@
+// Package rewind guarda o histórico de estados usado para voltar a
+// emulação no tempo. Os núcleos gravam um save state a cada Interval
+// frames; o buffer mantém o último estado completo e, para os anteriores,
+// apenas o XOR com o estado seguinte, comprimido. Como frames próximos
+// diferem em poucos bytes, o XOR é quase todo zero e comprime muito bem.
+package rewind
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+)
+
+// Valores padrão
+const (
+	DefaultInterval = 2        // Frames entre snapshots
+	DefaultBudget   = 32 << 20 // 32MB
+	DefaultCapacity = 3600     // Snapshots no ring (2 minutos com intervalo 2)
+)
+
+// Config define a frequência dos snapshots e os limites do histórico
+type Config struct {
+	Interval int // Frames entre snapshots
+	Budget   int // Memória máxima, em bytes, dos snapshots guardados
+	Capacity int // Número máximo de snapshots
+}
+
+// DefaultConfig retorna a configuração padrão
+func DefaultConfig() Config {
+	return Config{Interval: DefaultInterval, Budget: DefaultBudget, Capacity: DefaultCapacity}
+}
+
+// snapshot é um estado anterior guardado como diferença
+type snapshot struct {
+	frame uint64
+	size  int    // Tamanho do estado
+	diff  []byte // Estado XOR estado seguinte, comprimido
+}
+
+// Buffer é o ring de snapshots
+type Buffer struct {
+	config Config
+
+	// Snapshot mais recente, completo
+	latest      []byte
+	latestFrame uint64
+	hasLatest   bool
+
+	// Snapshots anteriores, do mais antigo (head) ao mais novo
+	ring  []snapshot
+	head  int
+	count int
+	used  int // Bytes comprimidos no ring
+
+	// Buffers reaproveitados entre snapshots
+	xor        []byte
+	compressed bytes.Buffer
+	writer     *flate.Writer
+	reader     io.ReadCloser
+}
+
+// NewBuffer cria um buffer vazio. Valores não positivos usam os padrões.
+func NewBuffer(config Config) *Buffer {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Budget <= 0 {
+		config.Budget = DefaultBudget
+	}
+	if config.Capacity <= 0 {
+		config.Capacity = DefaultCapacity
+	}
+	writer, _ := flate.NewWriter(nil, flate.BestSpeed)
+	return &Buffer{
+		config: config,
+		ring:   make([]snapshot, config.Capacity),
+		writer: writer,
+	}
+}
+
+// GetConfig retorna a configuração do buffer
+func (b *Buffer) GetConfig() Config {
+	return b.config
+}
+
+// ShouldCapture retorna se o frame deve ser gravado
+func (b *Buffer) ShouldCapture(frame uint64) bool {
+	return frame%uint64(b.config.Interval) == 0
+}
+
+// Push grava o estado do frame. Um frame que não é posterior ao último
+// gravado (reset, save state carregado) descarta o histórico.
+func (b *Buffer) Push(frame uint64, state []byte) error {
+	if b.hasLatest && frame <= b.latestFrame {
+		b.Clear()
+	}
+
+	if b.hasLatest {
+		diff, err := b.compress(b.latest, state)
+		if err != nil {
+			return fmt.Errorf("erro ao comprimir snapshot: %w", err)
+		}
+		b.push(snapshot{frame: b.latestFrame, size: len(b.latest), diff: diff})
+	}
+
+	b.latest = append(b.latest[:0], state...)
+	b.latestFrame = frame
+	b.hasLatest = true
+
+	// Descarta os snapshots mais antigos até caber no orçamento
+	for b.count > 0 && b.used+len(b.latest) > b.config.Budget {
+		b.dropOldest()
+	}
+	return nil
+}
+
+// Rewind volta ao snapshot mais recente gravado até o frame indicado,
+// descartando os posteriores. Se o histórico não chega tão longe, volta
+// ao snapshot mais antigo. O estado retornado pertence ao buffer e só é
+// válido até a próxima chamada.
+func (b *Buffer) Rewind(frame uint64) ([]byte, uint64, bool) {
+	if !b.hasLatest {
+		return nil, 0, false
+	}
+
+	for b.latestFrame > frame && b.count > 0 {
+		newest := b.ring[(b.head+b.count-1)%len(b.ring)]
+		if err := b.restore(newest); err != nil {
+			// Snapshot ilegível: o histórico anterior a ele é perdido
+			b.count = 0
+			b.used = 0
+			break
+		}
+		b.ring[(b.head+b.count-1)%len(b.ring)] = snapshot{}
+		b.count--
+		b.used -= len(newest.diff)
+	}
+
+	return b.latest, b.latestFrame, true
+}
+
+// Clear descarta todo o histórico
+func (b *Buffer) Clear() {
+	for i := range b.ring {
+		b.ring[i] = snapshot{}
+	}
+	b.head = 0
+	b.count = 0
+	b.used = 0
+	b.latest = b.latest[:0]
+	b.hasLatest = false
+}
+
+// Len retorna o número de snapshots disponíveis, incluindo o mais recente
+func (b *Buffer) Len() int {
+	if !b.hasLatest {
+		return 0
+	}
+	return b.count + 1
+}
+
+// Size retorna a memória usada pelos snapshots, em bytes
+func (b *Buffer) Size() int {
+	return b.used + len(b.latest)
+}
+
+// push acrescenta um snapshot ao ring, descartando o mais antigo se cheio
+func (b *Buffer) push(s snapshot) {
+	if b.count == len(b.ring) {
+		b.dropOldest()
+	}
+	b.ring[(b.head+b.count)%len(b.ring)] = s
+	b.count++
+	b.used += len(s.diff)
+}
+
+// dropOldest descarta o snapshot mais antigo
+func (b *Buffer) dropOldest() {
+	b.used -= len(b.ring[b.head].diff)
+	b.ring[b.head] = snapshot{}
+	b.head = (b.head + 1) % len(b.ring)
+	b.count--
+}
+
+// xorInto grava a XOR b em dst, completando o menor com zeros
+func xorInto(dst, a, b []byte) []byte {
+	size := len(a)
+	if len(b) > size {
+		size = len(b)
+	}
+	if cap(dst) < size {
+		dst = make([]byte, size)
+	}
+	dst = dst[:size]
+	copy(dst, a)
+	for i := len(a); i < size; i++ {
+		dst[i] = 0
+	}
+	for i, v := range b {
+		dst[i] ^= v
+	}
+	return dst
+}
+
+// compress retorna o XOR de older com newer, comprimido
+func (b *Buffer) compress(older, newer []byte) ([]byte, error) {
+	b.xor = xorInto(b.xor, older, newer)
+
+	b.compressed.Reset()
+	b.writer.Reset(&b.compressed)
+	if _, err := b.writer.Write(b.xor); err != nil {
+		return nil, err
+	}
+	if err := b.writer.Close(); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(b.compressed.Bytes()), nil
+}
+
+// restore reconstrói o estado do snapshot a partir do estado seguinte
+func (b *Buffer) restore(s snapshot) error {
+	if b.reader == nil {
+		b.reader = flate.NewReader(bytes.NewReader(s.diff))
+	} else if err := b.reader.(flate.Resetter).Reset(bytes.NewReader(s.diff), nil); err != nil {
+		return err
+	}
+
+	size := len(b.latest)
+	if s.size > size {
+		size = s.size
+	}
+	if cap(b.xor) < size {
+		b.xor = make([]byte, size)
+	}
+	b.xor = b.xor[:size]
+	if _, err := io.ReadFull(b.reader, b.xor); err != nil {
+		return err
+	}
+
+	for i, v := range b.latest {
+		b.xor[i] ^= v
+	}
+	b.latest = append(b.latest[:0], b.xor[:s.size]...)
+	b.latestFrame = s.frame
+	return nil
+}
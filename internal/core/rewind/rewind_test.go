@@ -0,0 +1,124 @@
+package rewind
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// states gera estados que mudam poucos bytes por frame, como um save state
+// real; alguns mudam de tamanho
+func states(n, size int) [][]byte {
+	rng := rand.New(rand.NewSource(1))
+	result := make([][]byte, n)
+	current := make([]byte, size)
+	rng.Read(current)
+	for i := range result {
+		for j := 0; j < 64; j++ {
+			current[rng.Intn(len(current))] = byte(rng.Intn(256))
+		}
+		if i%7 == 3 {
+			current = append(current, byte(i))
+		}
+		result[i] = bytes.Clone(current)
+	}
+	return result
+}
+
+func TestRewind(t *testing.T) {
+	b := NewBuffer(Config{Interval: 1})
+	history := states(50, 4096)
+	for i, state := range history {
+		if err := b.Push(uint64(i+1), state); err != nil {
+			t.Fatalf("erro ao gravar snapshot %d: %v", i, err)
+		}
+	}
+	if b.Len() != 50 {
+		t.Fatalf("esperado 50 snapshots, obtido %d", b.Len())
+	}
+
+	// Volta de 5 em 5 frames, conferindo cada estado reconstruído
+	for frame := uint64(45); frame >= 5; frame -= 5 {
+		state, got, ok := b.Rewind(frame)
+		if !ok || got != frame {
+			t.Fatalf("rewind para o frame %d: obtido frame %d (ok=%v)", frame, got, ok)
+		}
+		if !bytes.Equal(state, history[frame-1]) {
+			t.Fatalf("estado do frame %d reconstruído incorretamente", frame)
+		}
+	}
+
+	// Além do início volta ao snapshot mais antigo
+	state, got, _ := b.Rewind(0)
+	if got != 1 || !bytes.Equal(state, history[0]) {
+		t.Errorf("esperado o snapshot mais antigo (frame 1), obtido frame %d", got)
+	}
+
+	// Gravar um frame anterior ao último descarta o histórico
+	b.Push(10, history[9])
+	b.Push(3, history[2])
+	if b.Len() != 1 {
+		t.Errorf("histórico deveria ser descartado, obtido %d snapshots", b.Len())
+	}
+}
+
+func TestBudgetAndCapacity(t *testing.T) {
+	history := states(200, 8192)
+
+	// Capacidade do ring
+	b := NewBuffer(Config{Interval: 1, Capacity: 10})
+	for i, state := range history {
+		b.Push(uint64(i+1), state)
+	}
+	if b.Len() != 11 {
+		t.Errorf("esperado 10 snapshots no ring e o atual, obtido %d", b.Len())
+	}
+	if _, frame, _ := b.Rewind(0); frame != 190 {
+		t.Errorf("snapshot mais antigo deveria ser o frame 190, obtido %d", frame)
+	}
+
+	// Orçamento de memória
+	b = NewBuffer(Config{Interval: 1, Budget: 8192 + 4096})
+	for i, state := range history {
+		b.Push(uint64(i+1), state)
+		if b.Size() > 8192+4096 && b.Len() > 1 {
+			t.Fatalf("frame %d: %d bytes excede o orçamento", i+1, b.Size())
+		}
+	}
+	if b.Len() < 2 {
+		t.Errorf("diferenças comprimidas deveriam caber no orçamento, obtido %d snapshots", b.Len())
+	}
+	state, frame, _ := b.Rewind(0)
+	if !bytes.Equal(state, history[frame-1]) {
+		t.Errorf("estado do frame %d reconstruído incorretamente", frame)
+	}
+}
+
+func TestShouldCapture(t *testing.T) {
+	b := NewBuffer(Config{Interval: 3})
+	var captured []uint64
+	for frame := uint64(1); frame <= 9; frame++ {
+		if b.ShouldCapture(frame) {
+			captured = append(captured, frame)
+		}
+	}
+	if len(captured) != 3 || captured[0] != 3 || captured[2] != 9 {
+		t.Errorf("esperado frames 3, 6 e 9, obtido %v", captured)
+	}
+
+	if _, _, ok := NewBuffer(Config{}).Rewind(0); ok {
+		t.Error("buffer vazio não deveria ter snapshot")
+	}
+}
+
+// BenchmarkPush mede o custo de gravar um snapshot do tamanho de um save
+// state do Game Boy
+func BenchmarkPush(b *testing.B) {
+	history := states(64, 128*1024)
+	buffer := NewBuffer(Config{Interval: 1})
+	b.SetBytes(128 * 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buffer.Push(uint64(i+1), history[i%len(history)])
+	}
+}
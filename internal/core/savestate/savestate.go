@@ -9,8 +9,28 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/hobbiee/visualboy-go/internal/core/statefile"
+)
+
+// Constantes do arquivo de estado
+const (
+	// SaveStateVersion é a versão do contêiner. A versão 1 era o
+	// SaveState inteiro codificado com gob, sem header.
+	SaveStateVersion = 2
+	SaveStateMagic   = "VBGA" // VisualBoy Go (GBA)
 )
 
+// format é o contêiner dos estados do GBA: cada parte do SaveState vai em
+// uma seção própria codificada com gob, e o arquivo é comprimido com gzip
+var format = newFormat()
+
+func newFormat() *statefile.Format {
+	f := statefile.NewFormat(SaveStateMagic, SaveStateVersion)
+	f.Register(1, migrateV1)
+	return f
+}
+
 // SaveState representa um estado salvo do emulador
 type SaveState struct {
 	// Metadados
@@ -28,18 +48,28 @@ type SaveState struct {
 		ThumbMode bool       `json:"thumb_mode"`
 		Halted    bool       `json:"halted"`
 		Cycles    uint64     `json:"cycles"`
+
+		BankedR    [5][7]uint32 `json:"banked_r"`
+		BankedSPSR [5]uint32    `json:"banked_spsr"`
+		Pipeline   [3]uint32    `json:"pipeline"` // Fetch, decode e execute
+
+		// Controlador de interrupções
+		IE  uint16 `json:"ie"`
+		IF  uint16 `json:"if"`
+		IME bool   `json:"ime"`
 	} `json:"cpu"`
 
 	Memory struct {
-		BIOS    []byte `json:"bios"`
-		EWRAM   []byte `json:"ewram"`
-		IWRAM   []byte `json:"iwram"`
-		IO      []byte `json:"io"`
-		Palette []byte `json:"palette"`
-		VRAM    []byte `json:"vram"`
-		OAM     []byte `json:"oam"`
-		ROM     []byte `json:"rom"`
-		Save    []byte `json:"save"`
+		BIOS    []byte  `json:"bios"`
+		EWRAM   []byte  `json:"ewram"`
+		IWRAM   []byte  `json:"iwram"`
+		IO      []byte  `json:"io"`
+		Palette []byte  `json:"palette"`
+		VRAM    []byte  `json:"vram"`
+		OAM     []byte  `json:"oam"`
+		ROM     []byte  `json:"rom"`
+		Save    []byte  `json:"save"`
+		GPIO    [3]byte `json:"gpio"`
 	} `json:"memory"`
 
 	GPU struct {
@@ -77,8 +107,22 @@ type SaveState struct {
 			Reload   uint16 `json:"reload"`
 			Control  uint16 `json:"control"`
 			Overflow bool   `json:"overflow"`
+
+			Prescaler uint32 `json:"prescaler"`
+			LastValue uint16 `json:"last_value"`
 		} `json:"channels"`
 	} `json:"timer"`
+
+	Input struct {
+		KeyControl uint16 `json:"key_control"`
+	} `json:"input"`
+
+	// Contadores do emulador
+	Emulator struct {
+		FrameCount       uint64 `json:"frame_count"`
+		RumbleCycles     uint64 `json:"rumble_cycles"`
+		RumbleFrameStart uint64 `json:"rumble_frame_start"`
+	} `json:"emulator"`
 }
 
 // SaveStateManager gerencia os estados salvos do emulador
@@ -115,13 +159,7 @@ func (sm *SaveStateManager) SaveToSlot(slot int, state *SaveState) error {
 	}
 	defer file.Close()
 
-	// Comprime os dados
-	gzw := gzip.NewWriter(file)
-	defer gzw.Close()
-
-	// Serializa o estado
-	enc := gob.NewEncoder(gzw)
-	if err := enc.Encode(state); err != nil {
+	if err := encodeState(file, state); err != nil {
 		return err
 	}
 
@@ -143,22 +181,13 @@ func (sm *SaveStateManager) LoadFromSlot(slot int) (*SaveState, error) {
 	}
 	defer file.Close()
 
-	// Descomprime os dados
-	gzr, err := gzip.NewReader(file)
+	state, err := decodeState(file)
 	if err != nil {
 		return nil, err
 	}
-	defer gzr.Close()
-
-	// Deserializa o estado
-	var state SaveState
-	dec := gob.NewDecoder(gzr)
-	if err := dec.Decode(&state); err != nil {
-		return nil, err
-	}
 
-	sm.current = &state
-	return &state, nil
+	sm.current = state
+	return state, nil
 }
 
 // GetSlotInfo retorna informações sobre um slot específico
@@ -181,18 +210,10 @@ func (sm *SaveStateManager) GetSlotInfo(slot int) (*SaveStateInfo, error) {
 	}
 	defer file.Close()
 
-	// Lê apenas os metadados
-	gzr, err := gzip.NewReader(file)
+	state, err := decodeState(file)
 	if err != nil {
 		return nil, err
 	}
-	defer gzr.Close()
-
-	var state SaveState
-	dec := gob.NewDecoder(gzr)
-	if err := dec.Decode(&state); err != nil {
-		return nil, err
-	}
 
 	return &SaveStateInfo{
 		Slot:        slot,
@@ -239,13 +260,7 @@ func (sm *SaveStateManager) SaveToFile(filename string, state *SaveState) error
 	}
 	defer file.Close()
 
-	// Comprime os dados
-	gzw := gzip.NewWriter(file)
-	defer gzw.Close()
-
-	// Serializa o estado
-	enc := gob.NewEncoder(gzw)
-	if err := enc.Encode(state); err != nil {
+	if err := encodeState(file, state); err != nil {
 		return err
 	}
 
@@ -262,38 +277,19 @@ func (sm *SaveStateManager) LoadFromFile(filename string) (*SaveState, error) {
 	}
 	defer file.Close()
 
-	// Descomprime os dados
-	gzr, err := gzip.NewReader(file)
+	state, err := decodeState(file)
 	if err != nil {
 		return nil, err
 	}
-	defer gzr.Close()
 
-	// Deserializa o estado
-	var state SaveState
-	dec := gob.NewDecoder(gzr)
-	if err := dec.Decode(&state); err != nil {
-		return nil, err
-	}
-
-	sm.current = &state
-	return &state, nil
+	sm.current = state
+	return state, nil
 }
 
 // SaveToBuffer salva o estado atual em um buffer de memória
 func (sm *SaveStateManager) SaveToBuffer(state *SaveState) ([]byte, error) {
 	var buf bytes.Buffer
-
-	// Comprime os dados
-	gzw := gzip.NewWriter(&buf)
-
-	// Serializa o estado
-	enc := gob.NewEncoder(gzw)
-	if err := enc.Encode(state); err != nil {
-		return nil, err
-	}
-
-	if err := gzw.Close(); err != nil {
+	if err := encodeState(&buf, state); err != nil {
 		return nil, err
 	}
 
@@ -302,24 +298,13 @@ func (sm *SaveStateManager) SaveToBuffer(state *SaveState) ([]byte, error) {
 
 // LoadFromBuffer carrega um estado de um buffer de memória
 func (sm *SaveStateManager) LoadFromBuffer(data []byte) (*SaveState, error) {
-	buf := bytes.NewReader(data)
-
-	// Descomprime os dados
-	gzr, err := gzip.NewReader(buf)
+	state, err := decodeState(bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
-	defer gzr.Close()
 
-	// Deserializa o estado
-	var state SaveState
-	dec := gob.NewDecoder(gzr)
-	if err := dec.Decode(&state); err != nil {
-		return nil, err
-	}
-
-	sm.current = &state
-	return &state, nil
+	sm.current = state
+	return state, nil
 }
 
 // DeleteSlot remove um estado salvo de um slot específico
@@ -352,6 +337,15 @@ func (sm *SaveStateManager) ValidateState(state *SaveState, romName, romHash str
 	return state.ROMName == romName && state.ROMHash == romHash
 }
 
+// CheckROM retorna *statefile.ROMMismatchError se o estado foi salvo com
+// outra ROM. Estados sem hash são aceitos.
+func (sm *SaveStateManager) CheckROM(state *SaveState, romHash string) error {
+	if state.ROMHash == "" || state.ROMHash == romHash {
+		return nil
+	}
+	return &statefile.ROMMismatchError{Saved: state.ROMHash, Loaded: romHash}
+}
+
 // CopyState cria uma cópia profunda de um estado
 func (sm *SaveStateManager) CopyState(state *SaveState) (*SaveState, error) {
 	var buf bytes.Buffer
@@ -372,14 +366,7 @@ func (sm *SaveStateManager) CopyState(state *SaveState) (*SaveState, error) {
 // GetStateSize retorna o tamanho em bytes de um estado salvo
 func (sm *SaveStateManager) GetStateSize(state *SaveState) (int64, error) {
 	var buf bytes.Buffer
-	gzw := gzip.NewWriter(&buf)
-
-	enc := gob.NewEncoder(gzw)
-	if err := enc.Encode(state); err != nil {
-		return 0, err
-	}
-
-	if err := gzw.Close(); err != nil {
+	if err := encodeState(&buf, state); err != nil {
 		return 0, err
 	}
 
@@ -446,3 +433,127 @@ func (sm *SaveStateManager) CopySlot(srcSlot, dstSlot int) error {
 	_, err = io.Copy(dst, src)
 	return err
 }
+
+// metadata é a seção com os metadados do estado
+type metadata struct {
+	Version     int
+	Timestamp   time.Time
+	Description string
+	ROMName     string
+}
+
+// stateSections associa as tags do arquivo às partes do estado
+func stateSections(state *SaveState) map[string]any {
+	return map[string]any{
+		"CPU ": &state.CPU,
+		"MEM ": &state.Memory,
+		"GPU ": &state.GPU,
+		"APU ": &state.APU,
+		"DMA ": &state.DMA,
+		"TMR ": &state.Timer,
+		"KEY ": &state.Input,
+		"EMU ": &state.Emulator,
+	}
+}
+
+// sectionOrder é a ordem das seções no arquivo
+var sectionOrder = []string{"CPU ", "MEM ", "GPU ", "APU ", "DMA ", "TMR ", "KEY ", "EMU "}
+
+// encodeState grava o estado comprimido no formato atual
+func encodeState(w io.Writer, state *SaveState) error {
+	payload, err := statePayload(state)
+	if err != nil {
+		return err
+	}
+
+	gzw := gzip.NewWriter(w)
+	if _, err := gzw.Write(append(format.Header(SaveStateVersion), payload...)); err != nil {
+		return err
+	}
+	return gzw.Close()
+}
+
+// statePayload codifica as seções de um estado
+func statePayload(state *SaveState) ([]byte, error) {
+	file := &statefile.File{}
+	add := func(tag string, value any) error {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+			return fmt.Errorf("erro ao salvar seção %q: %w", tag, err)
+		}
+		file.Add(tag, buf.Bytes())
+		return nil
+	}
+
+	meta := metadata{state.Version, state.Timestamp, state.Description, state.ROMName}
+	if err := add("META", &meta); err != nil {
+		return nil, err
+	}
+	if state.ROMHash != "" {
+		file.Add(statefile.TagROMHash, []byte(state.ROMHash))
+	}
+	sections := stateSections(state)
+	for _, tag := range sectionOrder {
+		if err := add(tag, sections[tag]); err != nil {
+			return nil, err
+		}
+	}
+	return file.Payload(), nil
+}
+
+// decodeState lê um estado comprimido, migrando versões anteriores.
+// Arquivos sem header são da versão 1.
+func decodeState(r io.Reader) (*SaveState, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gzr.Close()
+
+	data, err := io.ReadAll(gzr)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte(SaveStateMagic)) {
+		data = append(format.Header(1), data...)
+	}
+
+	file, err := format.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &SaveState{ROMHash: string(file.Get(statefile.TagROMHash))}
+	get := func(tag string, value any) error {
+		data := file.Get(tag)
+		if data == nil {
+			return nil // Seção ausente mantém o valor zero
+		}
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(value); err != nil {
+			return fmt.Errorf("erro ao carregar seção %q: %w", tag, err)
+		}
+		return nil
+	}
+
+	var meta metadata
+	if err := get("META", &meta); err != nil {
+		return nil, err
+	}
+	state.Version, state.Timestamp = meta.Version, meta.Timestamp
+	state.Description, state.ROMName = meta.Description, meta.ROMName
+	for tag, value := range stateSections(state) {
+		if err := get(tag, value); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// migrateV1 converte o SaveState codificado com gob nas seções da versão 2
+func migrateV1(payload []byte) ([]byte, error) {
+	var state SaveState
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&state); err != nil {
+		return nil, err
+	}
+	return statePayload(&state)
+}
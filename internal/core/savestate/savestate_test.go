@@ -1,10 +1,18 @@
 package savestate
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/hobbiee/visualboy-go/internal/core/statefile"
 )
 
 func TestSaveStateManager(t *testing.T) {
@@ -246,3 +254,49 @@ func TestSaveStateManager(t *testing.T) {
 		}
 	})
 }
+
+func TestSaveStateFormat(t *testing.T) {
+	sm := NewSaveStateManager(t.TempDir(), 1)
+
+	state := &SaveState{Version: 1, Description: "formato", ROMName: "test.gba", ROMHash: "abc"}
+	state.CPU.Registers[15] = 0x08000000
+	state.Memory.IWRAM = []byte{1, 2, 3}
+
+	// Arquivo da versão 1: o SaveState inteiro em gob, sem header
+	var legacy bytes.Buffer
+	gzw := gzip.NewWriter(&legacy)
+	if err := gob.NewEncoder(gzw).Encode(state); err != nil {
+		t.Fatalf("Erro ao codificar estado antigo: %v", err)
+	}
+	gzw.Close()
+
+	loaded, err := sm.LoadFromBuffer(legacy.Bytes())
+	if err != nil {
+		t.Fatalf("Erro ao migrar estado da versão 1: %v", err)
+	}
+	if loaded.CPU.Registers[15] != 0x08000000 || !bytes.Equal(loaded.Memory.IWRAM, state.Memory.IWRAM) || loaded.ROMHash != "abc" {
+		t.Errorf("Estado migrado incorreto: %+v", loaded.CPU)
+	}
+
+	// Estado de uma versão mais nova
+	data, _ := sm.SaveToBuffer(state)
+	gzr, _ := gzip.NewReader(bytes.NewReader(data))
+	raw, _ := io.ReadAll(gzr)
+	binary.LittleEndian.PutUint32(raw[4:], SaveStateVersion+1)
+	var newer bytes.Buffer
+	gzw = gzip.NewWriter(&newer)
+	gzw.Write(raw)
+	gzw.Close()
+	if _, err := sm.LoadFromBuffer(newer.Bytes()); !errors.Is(err, statefile.ErrNewerVersion) {
+		t.Errorf("Esperado ErrNewerVersion, obtido %v", err)
+	}
+
+	// Estado de outra ROM
+	var mismatch *statefile.ROMMismatchError
+	if err := sm.CheckROM(loaded, "def"); !errors.As(err, &mismatch) || mismatch.Saved != "abc" {
+		t.Errorf("Esperado ROMMismatchError, obtido %v", err)
+	}
+	if err := sm.CheckROM(loaded, "abc"); err != nil {
+		t.Errorf("Mesma ROM deveria ser aceita: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+// Package statefile implementa o contêiner versionado dos save states dos
+// dois núcleos: um header com magic e versão seguido de seções (chunks)
+// identificadas por uma tag de 4 bytes e precedidas do seu tamanho.
+// Seções desconhecidas são ignoradas na leitura, e arquivos de versões
+// anteriores passam pelas migrações registradas no formato.
+package statefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// HeaderSize é o tamanho do header: magic (4 bytes) e versão (uint32)
+const HeaderSize = 8
+
+// TagROMHash identifica a seção com o hash da ROM usada ao salvar
+const TagROMHash = "HASH"
+
+// ErrInvalidMagic indica dados que não são um save state do formato
+var ErrInvalidMagic = errors.New("magic inválido no save state")
+
+// ErrNewerVersion é a causa dos erros de save states gravados por uma
+// versão mais nova do emulador
+var ErrNewerVersion = errors.New("save state de uma versão mais nova")
+
+// ErrUnsupportedVersion é a causa dos erros de versões antigas sem
+// migração registrada
+var ErrUnsupportedVersion = errors.New("versão de save state sem migração")
+
+// ErrROMMismatch é a causa dos erros de save states de outra ROM
+var ErrROMMismatch = errors.New("save state de outra ROM")
+
+// VersionError informa a versão de um save state que não pode ser lida
+type VersionError struct {
+	Version uint32 // Versão gravada no arquivo
+	Current uint32 // Versão atual do formato
+}
+
+// Error implementa a interface error
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%v: versão %d (atual %d)", e.Unwrap(), e.Version, e.Current)
+}
+
+// Unwrap permite comparar com errors.Is(err, ErrNewerVersion) ou
+// errors.Is(err, ErrUnsupportedVersion)
+func (e *VersionError) Unwrap() error {
+	if e.Version > e.Current {
+		return ErrNewerVersion
+	}
+	return ErrUnsupportedVersion
+}
+
+// ROMMismatchError informa os hashes da ROM do save state e da carregada
+type ROMMismatchError struct {
+	Saved  string
+	Loaded string
+}
+
+// Error implementa a interface error
+func (e *ROMMismatchError) Error() string {
+	return fmt.Sprintf("%v: salvo com %s, ROM carregada %s", ErrROMMismatch, e.Saved, e.Loaded)
+}
+
+// Unwrap permite comparar com errors.Is(err, ErrROMMismatch)
+func (e *ROMMismatchError) Unwrap() error {
+	return ErrROMMismatch
+}
+
+// Chunk é uma seção do arquivo
+type Chunk struct {
+	Tag  [4]byte
+	Data []byte
+}
+
+// File é o conteúdo de um save state na versão atual do formato
+type File struct {
+	Version uint32
+	Chunks  []Chunk
+}
+
+// Add acrescenta uma seção. A tag deve ter 4 caracteres.
+func (f *File) Add(tag string, data []byte) {
+	var chunk Chunk
+	copy(chunk.Tag[:], tag)
+	chunk.Data = data
+	f.Chunks = append(f.Chunks, chunk)
+}
+
+// Get retorna os dados da primeira seção com a tag (nil se ausente)
+func (f *File) Get(tag string) []byte {
+	for _, chunk := range f.Chunks {
+		if string(chunk.Tag[:]) == tag {
+			return chunk.Data
+		}
+	}
+	return nil
+}
+
+// Payload codifica as seções, sem o header
+func (f *File) Payload() []byte {
+	var buf bytes.Buffer
+	for _, chunk := range f.Chunks {
+		buf.Write(chunk.Tag[:])
+		binary.Write(&buf, binary.LittleEndian, uint32(len(chunk.Data)))
+		buf.Write(chunk.Data)
+	}
+	return buf.Bytes()
+}
+
+// ParsePayload lê as seções geradas por Payload. Os dados de cada seção
+// são copiados e não compartilham memória com payload.
+func ParsePayload(payload []byte) ([]Chunk, error) {
+	var chunks []Chunk
+	for len(payload) > 0 {
+		if len(payload) < 8 {
+			return nil, fmt.Errorf("seção truncada no save state")
+		}
+		var chunk Chunk
+		copy(chunk.Tag[:], payload)
+		size := binary.LittleEndian.Uint32(payload[4:])
+		payload = payload[8:]
+		if int64(size) > int64(len(payload)) {
+			return nil, fmt.Errorf("seção %q truncada no save state", chunk.Tag[:])
+		}
+		chunk.Data = append([]byte(nil), payload[:size]...)
+		payload = payload[size:]
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// Migration converte o payload de uma versão no payload da versão
+// seguinte
+type Migration func(payload []byte) ([]byte, error)
+
+// Format descreve um formato de save state: magic, versão atual e as
+// migrações das versões anteriores
+type Format struct {
+	magic      [4]byte
+	version    uint32
+	migrations map[uint32]Migration
+}
+
+// NewFormat cria um formato com o magic e a versão atual
+func NewFormat(magic string, version uint32) *Format {
+	f := &Format{version: version, migrations: make(map[uint32]Migration)}
+	copy(f.magic[:], magic)
+	return f
+}
+
+// Version retorna a versão atual do formato
+func (f *Format) Version() uint32 {
+	return f.version
+}
+
+// Register registra a migração da versão from para from+1
+func (f *Format) Register(from uint32, migration Migration) {
+	f.migrations[from] = migration
+}
+
+// Header codifica o header de um arquivo com a versão indicada
+func (f *Format) Header(version uint32) []byte {
+	header := make([]byte, HeaderSize)
+	copy(header, f.magic[:])
+	binary.LittleEndian.PutUint32(header[4:], version)
+	return header
+}
+
+// Encode codifica o arquivo na versão atual
+func (f *Format) Encode(file *File) []byte {
+	return append(f.Header(f.version), file.Payload()...)
+}
+
+// Decode lê um arquivo, aplicando em ordem as migrações das versões
+// anteriores. Versões mais novas retornam *VersionError.
+func (f *Format) Decode(data []byte) (*File, error) {
+	if len(data) < HeaderSize || !bytes.Equal(data[:4], f.magic[:]) {
+		return nil, ErrInvalidMagic
+	}
+
+	version := binary.LittleEndian.Uint32(data[4:])
+	payload := data[HeaderSize:]
+	if version > f.version {
+		return nil, &VersionError{Version: version, Current: f.version}
+	}
+	for ; version < f.version; version++ {
+		migration, ok := f.migrations[version]
+		if !ok {
+			return nil, &VersionError{Version: version, Current: f.version}
+		}
+		var err error
+		if payload, err = migration(payload); err != nil {
+			return nil, fmt.Errorf("erro ao migrar save state da versão %d: %w", version, err)
+		}
+	}
+
+	chunks, err := ParsePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &File{Version: version, Chunks: chunks}, nil
+}
@@ -0,0 +1,88 @@
+package statefile
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	f := NewFormat("TEST", 1)
+
+	file := &File{}
+	file.Add("AAAA", []byte{1, 2, 3})
+	file.Add("XTRA", []byte{9}) // Seção que o leitor não conhece
+	file.Add("BBBB", nil)
+	data := f.Encode(file)
+
+	loaded, err := f.Decode(data)
+	if err != nil {
+		t.Fatalf("erro ao decodificar: %v", err)
+	}
+	if loaded.Version != 1 || len(loaded.Chunks) != 3 {
+		t.Fatalf("esperado versão 1 com 3 seções, obtido %d e %d", loaded.Version, len(loaded.Chunks))
+	}
+	if !bytes.Equal(loaded.Get("AAAA"), []byte{1, 2, 3}) || loaded.Get("CCCC") != nil {
+		t.Errorf("seções diferentes: %v", loaded.Chunks)
+	}
+
+	// Os dados decodificados não compartilham memória com o arquivo
+	loaded.Get("AAAA")[0] = 0xFF
+	if data[HeaderSize+8] != 1 {
+		t.Error("seção decodificada não deveria alterar o arquivo")
+	}
+
+	if _, err := f.Decode(data[:len(data)-1]); err == nil {
+		t.Error("seção truncada deveria retornar erro")
+	}
+	if _, err := NewFormat("OUTR", 1).Decode(data); !errors.Is(err, ErrInvalidMagic) {
+		t.Errorf("esperado ErrInvalidMagic, obtido %v", err)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	f := NewFormat("TEST", 3)
+
+	// Versão 1: payload cru; versão 2: seção única; versão 3: duas seções
+	f.Register(1, func(payload []byte) ([]byte, error) {
+		file := &File{}
+		file.Add("OLD ", payload)
+		return file.Payload(), nil
+	})
+	f.Register(2, func(payload []byte) ([]byte, error) {
+		chunks, err := ParsePayload(payload)
+		if err != nil {
+			return nil, err
+		}
+		file := &File{Chunks: chunks}
+		file.Add("NEW ", []byte{0x42})
+		return file.Payload(), nil
+	})
+
+	old := append(f.Header(1), 7, 8)
+	file, err := f.Decode(old)
+	if err != nil {
+		t.Fatalf("erro ao migrar: %v", err)
+	}
+	if file.Version != 3 || !bytes.Equal(file.Get("OLD "), []byte{7, 8}) || !bytes.Equal(file.Get("NEW "), []byte{0x42}) {
+		t.Errorf("migração incorreta: versão %d, seções %v", file.Version, file.Chunks)
+	}
+
+	// Versão mais nova e versão sem migração
+	var versionErr *VersionError
+	_, err = f.Decode(f.Header(4))
+	if !errors.Is(err, ErrNewerVersion) || !errors.As(err, &versionErr) || versionErr.Version != 4 {
+		t.Errorf("esperado ErrNewerVersion da versão 4, obtido %v", err)
+	}
+	if _, err := f.Decode(f.Header(0)); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("esperado ErrUnsupportedVersion, obtido %v", err)
+	}
+
+	// Erro na migração interrompe a leitura
+	f.Register(1, func(payload []byte) ([]byte, error) {
+		return nil, errors.New("falha")
+	})
+	if _, err := f.Decode(old); err == nil {
+		t.Error("falha na migração deveria retornar erro")
+	}
+}
@@ -247,6 +247,45 @@ func (ts *TimerSystem) IsTimerEnabled(timerID int) bool {
 	return ts.timers[timerID].enabled
 }
 
+// TimerState é o estado de um timer gravado nos save states
+type TimerState struct {
+	Counter   uint16
+	Reload    uint16
+	Control   uint16
+	Prescaler uint32
+	LastValue uint16
+}
+
+// GetState retorna o estado dos 4 timers
+func (ts *TimerSystem) GetState() [4]TimerState {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	var state [4]TimerState
+	for i, timer := range ts.timers {
+		state[i] = TimerState{timer.counter, timer.reload, timer.control, timer.prescaler, timer.lastValue}
+	}
+	return state
+}
+
+// SetState restaura o estado gerado por GetState
+func (ts *TimerSystem) SetState(state [4]TimerState) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for i, timer := range ts.timers {
+		timer.counter = state[i].Counter
+		timer.reload = state[i].Reload
+		timer.control = state[i].Control
+		timer.enabled = (timer.control & TIMER_ENABLE) != 0
+		timer.cascade = (timer.control & TIMER_CASCADE) != 0
+		timer.irqEnable = (timer.control & TIMER_IRQ) != 0
+		timer.frequency = uint8(timer.control & TIMER_FREQUENCY)
+		timer.prescaler = state[i].Prescaler
+		timer.lastValue = state[i].LastValue
+	}
+}
+
 // Reset reinicia todos os timers
 func (ts *TimerSystem) Reset() {
 	ts.mu.Lock()
@@ -1,12 +1,17 @@
 package gba
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 
 	"github.com/hobbiee/visualboy-go/internal/core/cpu"
 	"github.com/hobbiee/visualboy-go/internal/core/input"
 	"github.com/hobbiee/visualboy-go/internal/core/memory"
+	"github.com/hobbiee/visualboy-go/internal/core/movie"
+	"github.com/hobbiee/visualboy-go/internal/core/rewind"
 	"github.com/hobbiee/visualboy-go/internal/core/timer"
 )
 
@@ -33,6 +38,23 @@ type Emulator struct {
 
 	// Buffer de vídeo
 	videoBuffer []uint32
+
+	// ROM carregada, usada para associar os save states
+	romName string
+	romHash string // SHA-1 em hexadecimal
+
+	// Histórico de rewind (nil = desligado)
+	rewind *rewind.Buffer
+
+	// Movie sendo gravado ou reproduzido
+	movieRecorder *movie.Recorder
+	moviePlayer   *movie.Player
+
+	// Motor de vibração (GPIO do cartucho)
+	rumbleListener   func(on bool, duty float64)
+	rumbleActive     bool
+	rumbleCycles     uint64 // Ciclos com o motor ligado no frame atual
+	rumbleFrameStart uint64 // Ciclo do início do frame atual
 }
 
 // NewEmulator cria uma nova instância do emulador
@@ -98,6 +120,11 @@ func (e *Emulator) LoadROM(path string) error {
 		return fmt.Errorf("erro ao carregar ROM na memória: %v", err)
 	}
 
+	hash := sha1.Sum(romData)
+	e.romName = filepath.Base(path)
+	e.romHash = hex.EncodeToString(hash[:])
+	e.clearRewind()
+
 	return nil
 }
 
@@ -135,8 +162,7 @@ func (e *Emulator) Run() error {
 
 		// Verifica se precisa renderizar um novo frame
 		if e.ShouldRenderFrame() {
-			e.RenderFrame()
-			e.frameCount++
+			e.endFrame()
 		}
 
 		// Processa entrada do usuário
@@ -149,7 +175,11 @@ func (e *Emulator) Run() error {
 // Step executa um ciclo do emulador
 func (e *Emulator) Step() error {
 	// Executa um ciclo do CPU
+	start := e.cpu.Cycles
 	e.cpu.Step()
+	if e.memory.GetGPIO().MotorOn() {
+		e.rumbleCycles += e.cpu.Cycles - start
+	}
 
 	// Atualiza timers
 	e.timers.Step()
@@ -159,6 +189,16 @@ func (e *Emulator) Step() error {
 	return nil
 }
 
+// endFrame conclui um frame: vídeo, movie, motor de vibração e o
+// snapshot de rewind
+func (e *Emulator) endFrame() {
+	e.RenderFrame()
+	e.updateMovie()
+	e.reportRumble()
+	e.frameCount++
+	e.captureRewind()
+}
+
 // Stop para a execução do emulador
 func (e *Emulator) Stop() {
 	e.running = false
@@ -175,6 +215,40 @@ func (e *Emulator) RenderFrame() {
 	// TODO: Implementar renderização do frame
 }
 
+// SetRumbleListener define o callback do motor de vibração, chamado a cada
+// frame com o estado do motor e a fração do frame em que ele esteve ligado.
+// Ele é chamado enquanto o motor estiver ativo e uma última vez ao desligar.
+func (e *Emulator) SetRumbleListener(listener func(on bool, duty float64)) {
+	e.rumbleListener = listener
+	e.rumbleActive = false
+}
+
+// HasRumble retorna se o cartucho possui motor de vibração
+func (e *Emulator) HasRumble() bool {
+	return e.memory.GetGPIO().IsEnabled()
+}
+
+// reportRumble informa o estado do motor no fim de um frame
+func (e *Emulator) reportRumble() {
+	var duty float64
+	if elapsed := e.cpu.Cycles - e.rumbleFrameStart; elapsed > 0 {
+		duty = float64(e.rumbleCycles) / float64(elapsed)
+	}
+	e.rumbleCycles = 0
+	e.rumbleFrameStart = e.cpu.Cycles
+
+	if e.rumbleListener == nil || !e.HasRumble() {
+		return
+	}
+
+	on := e.memory.GetGPIO().MotorOn()
+	active := on || duty > 0
+	if active || e.rumbleActive {
+		e.rumbleListener(on, duty)
+	}
+	e.rumbleActive = active
+}
+
 // HandleInput processa entrada do usuário
 func (e *Emulator) HandleInput() {
 	// O processamento real é feito pelo InputSystem
@@ -239,21 +313,13 @@ func (e *Emulator) Reset() {
 	e.running = false
 	e.debugMode = false
 	e.frameCount = 0
+	e.rumbleActive = false
+	e.rumbleCycles = 0
+	e.rumbleFrameStart = e.cpu.Cycles
 	e.timers.Reset()
 	e.input.Reset()
 	for i := range e.videoBuffer {
 		e.videoBuffer[i] = 0
 	}
-}
-
-// SaveState salva o estado atual do emulador
-func (e *Emulator) SaveState(path string) error {
-	// TODO: Implementar salvamento de estado
-	return nil
-}
-
-// LoadState carrega um estado salvo do emulador
-func (e *Emulator) LoadState(path string) error {
-	// TODO: Implementar carregamento de estado
-	return nil
+	e.clearRewind()
 }
@@ -1,11 +1,19 @@
 package gba
 
 import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/hobbiee/visualboy-go/internal/core/cpu"
 	"github.com/hobbiee/visualboy-go/internal/core/input"
 	"github.com/hobbiee/visualboy-go/internal/core/memory"
+	"github.com/hobbiee/visualboy-go/internal/core/movie"
+	"github.com/hobbiee/visualboy-go/internal/core/rewind"
+	"github.com/hobbiee/visualboy-go/internal/core/statefile"
 )
 
 func TestEmulatorInput(t *testing.T) {
@@ -106,3 +114,200 @@ func TestEmulatorInputMemoryIO(t *testing.T) {
 		t.Errorf("Controle incorreto via memória: got %04X, want %04X", readControl, controlValue)
 	}
 }
+
+func TestEmulatorRumble(t *testing.T) {
+	mem := memory.NewMemorySystem()
+	cpu := cpu.NewCPU(mem)
+	emulator := NewEmulator(cpu, mem)
+
+	rom := make([]byte, 0x200)
+	copy(rom[0xAC:], "RZWE")
+	path := filepath.Join(t.TempDir(), "rumble.gba")
+	if err := os.WriteFile(path, rom, 0644); err != nil {
+		t.Fatalf("Erro ao criar ROM: %v", err)
+	}
+	if err := emulator.LoadROM(path); err != nil {
+		t.Fatalf("Erro ao carregar ROM: %v", err)
+	}
+	if !emulator.HasRumble() {
+		t.Fatal("Cartucho RZWE deveria ter motor de vibração")
+	}
+
+	var reports []float64
+	emulator.SetRumbleListener(func(on bool, duty float64) {
+		reports = append(reports, duty)
+	})
+
+	// Metade do frame com o motor ligado
+	mem.Write16(memory.GPIODirection, memory.GPIORumblePin)
+	mem.Write16(memory.GPIOData, memory.GPIORumblePin)
+	for i := 0; i < 10; i++ {
+		emulator.Step()
+	}
+	mem.Write16(memory.GPIOData, 0)
+	for i := 0; i < 10; i++ {
+		emulator.Step()
+	}
+	emulator.reportRumble()
+
+	if len(reports) != 1 || reports[0] <= 0 || reports[0] >= 1 {
+		t.Fatalf("Esperado um aviso com fração entre 0 e 1, obtido %v", reports)
+	}
+
+	// Um frame desligado gera um último aviso; o seguinte, nenhum
+	emulator.Step()
+	emulator.reportRumble()
+	emulator.Step()
+	emulator.reportRumble()
+	if len(reports) != 2 || reports[1] != 0 {
+		t.Errorf("Esperado um único aviso de desligamento, obtido %v", reports)
+	}
+}
+
+// newStateEmulator cria um emulador com uma ROM de teste carregada
+func newStateEmulator(t *testing.T, rom []byte) (*Emulator, *memory.MemorySystem) {
+	mem := memory.NewMemorySystem()
+	emulator := NewEmulator(cpu.NewCPU(mem), mem)
+	path := filepath.Join(t.TempDir(), "test.gba")
+	if err := os.WriteFile(path, rom, 0644); err != nil {
+		t.Fatalf("Erro ao criar ROM: %v", err)
+	}
+	if err := emulator.LoadROM(path); err != nil {
+		t.Fatalf("Erro ao carregar ROM: %v", err)
+	}
+	return emulator, mem
+}
+
+func TestEmulatorSaveState(t *testing.T) {
+	emulator, mem := newStateEmulator(t, make([]byte, 0x200))
+	emulator.cpu.R[3] = 0x1234
+	mem.Write8(memory.EWRAMStart+10, 0xAB)
+	emulator.timers.WriteCounter(1, 0xFF00)
+	emulator.timers.WriteControl(1, 0x0080)
+
+	path := filepath.Join(t.TempDir(), "state.sav")
+	if err := emulator.SaveState(path); err != nil {
+		t.Fatalf("Erro ao salvar estado: %v", err)
+	}
+
+	emulator.cpu.R[3] = 0
+	mem.Write8(memory.EWRAMStart+10, 0)
+	emulator.timers.Reset()
+	if err := emulator.LoadState(path); err != nil {
+		t.Fatalf("Erro ao carregar estado: %v", err)
+	}
+	if emulator.cpu.R[3] != 0x1234 || mem.Read8(memory.EWRAMStart+10) != 0xAB {
+		t.Errorf("Estado restaurado incorreto: R3=%04X, EWRAM=%02X", emulator.cpu.R[3], mem.Read8(memory.EWRAMStart+10))
+	}
+	if !emulator.timers.IsTimerEnabled(1) || emulator.timers.GetTimerValue(1) != 0xFF00 {
+		t.Error("Timer 1 não foi restaurado")
+	}
+
+	// Estado de outra ROM
+	other, _ := newStateEmulator(t, append(make([]byte, 0x1FF), 1))
+	var mismatch *statefile.ROMMismatchError
+	if err := other.LoadState(path); !errors.As(err, &mismatch) {
+		t.Errorf("Esperado ROMMismatchError, obtido %v", err)
+	}
+}
+
+func TestEmulatorRewind(t *testing.T) {
+	emulator, mem := newStateEmulator(t, make([]byte, 0x200))
+	emulator.SetRewindConfig(rewind.Config{Interval: 1})
+
+	// Snapshots do mesmo estado são idênticos
+	first, _ := emulator.snapshot()
+	time.Sleep(time.Millisecond)
+	if second, _ := emulator.snapshot(); !bytes.Equal(first, second) {
+		t.Error("Snapshots do mesmo estado deveriam ser idênticos")
+	}
+
+	// Cada frame grava o seu número na IWRAM
+	for frame := 1; frame <= 20; frame++ {
+		mem.Write8(memory.IWRAMStart, byte(frame))
+		emulator.cpu.R[0] = uint32(frame)
+		emulator.endFrame()
+	}
+
+	if err := emulator.Rewind(5); err != nil {
+		t.Fatalf("Erro no rewind: %v", err)
+	}
+	if emulator.frameCount != 15 || mem.Read8(memory.IWRAMStart) != 15 || emulator.cpu.R[0] != 15 {
+		t.Errorf("Esperado o frame 15, obtido frame %d, IWRAM %d e R0 %d",
+			emulator.frameCount, mem.Read8(memory.IWRAMStart), emulator.cpu.R[0])
+	}
+
+	// Além do início volta ao snapshot mais antigo
+	emulator.Rewind(100)
+	if emulator.frameCount != 1 || mem.Read8(memory.IWRAMStart) != 1 {
+		t.Errorf("Esperado o frame 1, obtido %d", emulator.frameCount)
+	}
+
+	emulator.DisableRewind()
+	if err := emulator.Rewind(1); err == nil {
+		t.Error("Rewind desligado deveria retornar erro")
+	}
+}
+
+// runMovieFrames simula um jogo que, a cada frame, grava na IWRAM os
+// botões pressionados
+func runMovieFrames(emulator *Emulator, mem *memory.MemorySystem, frames int, press func(frame int)) {
+	for frame := 0; frame < frames; frame++ {
+		if press != nil {
+			press(frame)
+		}
+		keys := emulator.input.GetKeyState()
+		mem.Write8(memory.IWRAMStart+uint32(frame), byte(keys))
+		mem.Write8(memory.IWRAMStart+0x100+uint32(frame), byte(keys>>8))
+		emulator.endFrame()
+	}
+}
+
+func TestEmulatorMovie(t *testing.T) {
+	rom := make([]byte, 0x200)
+	emulator, mem := newStateEmulator(t, rom)
+	mem.Write8(memory.EWRAMStart, 0x55)
+	if err := emulator.StartRecording(true); err != nil {
+		t.Fatalf("Erro ao iniciar gravação: %v", err)
+	}
+	runMovieFrames(emulator, mem, 10, func(frame int) {
+		if frame == 3 {
+			emulator.ProcessButtonDown(input.KEY_A)
+		}
+		if frame == 6 {
+			emulator.ProcessButtonDown(input.KEY_L)
+			emulator.ProcessButtonUp(input.KEY_A)
+		}
+	})
+	m := emulator.StopRecording()
+	if len(m.Frames) != 10 || m.Frames[3].Input != input.KEY_A || m.Frames[7].Input != input.KEY_L {
+		t.Fatalf("Input gravado incorreto: %v", m.Frames)
+	}
+
+	// A reprodução a partir do estado embutido segue a gravação
+	player, playerMem := newStateEmulator(t, rom)
+	if err := player.PlayMovie(m); err != nil {
+		t.Fatalf("Erro ao reproduzir movie: %v", err)
+	}
+	if playerMem.Read8(memory.EWRAMStart) != 0x55 {
+		t.Error("Estado inicial do movie não foi restaurado")
+	}
+	runMovieFrames(player, playerMem, 10, nil)
+	if player.IsPlayingMovie() || player.MovieError() != nil {
+		t.Fatalf("Esperado fim da reprodução sem erro, obtido %v", player.MovieError())
+	}
+
+	// Input alterado dessincroniza no frame em que foi lido
+	m.Frames[5].Input = input.KEY_B
+	player.PlayMovie(m)
+	runMovieFrames(player, playerMem, 10, nil)
+	var desync *movie.DesyncError
+	if err := player.MovieError(); !errors.As(err, &desync) || desync.Frame != 6 {
+		t.Errorf("Esperado DesyncError no frame 6, obtido %v", err)
+	}
+
+	other, _ := newStateEmulator(t, make([]byte, 0x400))
+	if err := other.PlayMovie(m); !errors.Is(err, statefile.ErrROMMismatch) {
+		t.Errorf("Esperado ErrROMMismatch, obtido %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package gba
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/hobbiee/visualboy-go/internal/core/input"
+	"github.com/hobbiee/visualboy-go/internal/core/memory"
+	"github.com/hobbiee/visualboy-go/internal/core/movie"
+	"github.com/hobbiee/visualboy-go/internal/core/savestate"
+)
+
+// CoreVersion identifica a revisão do núcleo gravada nos movies. Deve mudar
+// quando uma alteração da emulação torna gravações antigas incompatíveis.
+const CoreVersion = "gba-1"
+
+// movieButtons são os botões gravados nos movies, na ordem dos bits de
+// KEYINPUT
+var movieButtons = []uint16{
+	input.KEY_A, input.KEY_B, input.KEY_SELECT, input.KEY_START, input.KEY_RIGHT,
+	input.KEY_LEFT, input.KEY_UP, input.KEY_DOWN, input.KEY_R, input.KEY_L,
+}
+
+// StartRecording inicia a gravação de um movie. Com fromState o estado
+// atual é embutido no movie; sem ele o emulador volta ao power-on.
+func (e *Emulator) StartRecording(fromState bool) error {
+	if e.romHash == "" {
+		return fmt.Errorf("nenhuma ROM carregada")
+	}
+
+	header := movie.Header{
+		System:      movie.SystemGBA,
+		CoreVersion: CoreVersion,
+		ROMHash:     e.romHash,
+		Settings:    map[string]string{"rom": e.romName},
+	}
+	if fromState {
+		state, err := e.snapshot()
+		if err != nil {
+			return fmt.Errorf("erro ao gravar estado inicial do movie: %w", err)
+		}
+		header.StartState = state
+	} else {
+		e.powerOn()
+	}
+
+	e.moviePlayer = nil
+	e.movieRecorder = movie.NewRecorder(header)
+	return nil
+}
+
+// StopRecording encerra a gravação e retorna o movie (nil se não havia
+// gravação)
+func (e *Emulator) StopRecording() *movie.Movie {
+	if e.movieRecorder == nil {
+		return nil
+	}
+	m := e.movieRecorder.Movie()
+	e.movieRecorder = nil
+	return m
+}
+
+// PlayMovie volta ao estado inicial do movie e passa a controlar os botões
+// a cada frame. A reprodução termina ao fim do movie ou no primeiro frame
+// dessincronizado, informado por MovieError. Movies de outra ROM retornam
+// *statefile.ROMMismatchError.
+func (e *Emulator) PlayMovie(m *movie.Movie) error {
+	if err := m.CheckROM(movie.SystemGBA, e.romHash); err != nil {
+		return err
+	}
+
+	if m.StartState != nil {
+		if err := e.restoreSnapshot(m.StartState); err != nil {
+			return fmt.Errorf("erro ao carregar estado inicial do movie: %w", err)
+		}
+		e.clearRewind()
+	} else {
+		e.powerOn()
+	}
+
+	e.movieRecorder = nil
+	e.moviePlayer = movie.NewPlayer(m)
+	e.applyMovieInput()
+	return nil
+}
+
+// StopMovie encerra a reprodução
+func (e *Emulator) StopMovie() {
+	e.moviePlayer = nil
+}
+
+// IsPlayingMovie retorna se a reprodução ainda controla os botões
+func (e *Emulator) IsPlayingMovie() bool {
+	return e.moviePlayer != nil && !e.moviePlayer.Done()
+}
+
+// MovieError retorna a dessincronização encontrada na reprodução, como
+// *movie.DesyncError (nil se nenhuma)
+func (e *Emulator) MovieError() error {
+	if e.moviePlayer == nil {
+		return nil
+	}
+	return e.moviePlayer.Err()
+}
+
+// powerOn reinicia CPU, timers e input e zera a memória gravável
+func (e *Emulator) powerOn() {
+	e.cpu.Reset()
+	e.Reset()
+	for start := range stateRegions(&savestate.SaveState{}) {
+		clear(e.memory.GetRegion(start).Data)
+	}
+}
+
+// applyMovieInput define os botões do frame que vai começar
+func (e *Emulator) applyMovieInput() {
+	if e.moviePlayer == nil {
+		return
+	}
+	buttons, ok := e.moviePlayer.Input()
+	if !ok {
+		return
+	}
+
+	for _, button := range movieButtons {
+		if buttons&button != 0 {
+			e.input.ButtonDown(button)
+		} else {
+			e.input.ButtonUp(button)
+		}
+	}
+}
+
+// updateMovie grava ou confere o frame que terminou e aplica os botões do
+// frame seguinte
+func (e *Emulator) updateMovie() {
+	if e.movieRecorder == nil && !e.IsPlayingMovie() {
+		return
+	}
+
+	checksum := e.frameChecksum()
+	if e.movieRecorder != nil {
+		e.movieRecorder.Record(^e.input.GetKeyState()&input.KEY_ALL, checksum)
+		return
+	}
+	if e.moviePlayer.Verify(checksum) == nil {
+		e.applyMovieInput()
+	}
+}
+
+// frameChecksum calcula a soma do buffer de vídeo, da EWRAM e da IWRAM
+func (e *Emulator) frameChecksum() uint32 {
+	pixels := make([]byte, 0, 4*len(e.videoBuffer))
+	for _, pixel := range e.videoBuffer {
+		pixels = binary.LittleEndian.AppendUint32(pixels, pixel)
+	}
+	return movie.Checksum(pixels,
+		e.memory.GetRegion(memory.EWRAMStart).Data,
+		e.memory.GetRegion(memory.IWRAMStart).Data)
+}
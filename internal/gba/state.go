@@ -0,0 +1,224 @@
+package gba
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/hobbiee/visualboy-go/internal/core/memory"
+	"github.com/hobbiee/visualboy-go/internal/core/rewind"
+	"github.com/hobbiee/visualboy-go/internal/core/savestate"
+	"github.com/hobbiee/visualboy-go/internal/core/timer"
+)
+
+// stateRegions são as regiões graváveis copiadas para o save state. BIOS
+// e ROM não são gravados: vêm dos arquivos carregados.
+func stateRegions(state *savestate.SaveState) map[uint32]*[]byte {
+	return map[uint32]*[]byte{
+		memory.EWRAMStart:   &state.Memory.EWRAM,
+		memory.IWRAMStart:   &state.Memory.IWRAM,
+		memory.PaletteStart: &state.Memory.Palette,
+		memory.VRAMStart:    &state.Memory.VRAM,
+		memory.OAMStart:     &state.Memory.OAM,
+	}
+}
+
+// captureState copia o estado da máquina para um SaveState
+func (e *Emulator) captureState() *savestate.SaveState {
+	state := &savestate.SaveState{
+		Version:   savestate.SaveStateVersion,
+		Timestamp: time.Now(),
+		ROMName:   e.romName,
+		ROMHash:   e.romHash,
+	}
+
+	// CPU e interrupções
+	c := e.cpu
+	state.CPU.Registers = c.R
+	state.CPU.CPSR, state.CPU.SPSR = c.CPSR, c.SPSR
+	state.CPU.ThumbMode, state.CPU.Halted, state.CPU.Cycles = c.ThumbMode, c.Halted, c.Cycles
+	state.CPU.BankedR, state.CPU.BankedSPSR = c.BankedR, c.BankedSPSR
+	state.CPU.Pipeline = [3]uint32{c.Pipeline.Fetch, c.Pipeline.Decode, c.Pipeline.Execute}
+	state.CPU.IE = c.InterruptController.GetIE()
+	state.CPU.IF = c.InterruptController.GetIF()
+	state.CPU.IME = c.InterruptController.GetIME()
+
+	// Memória
+	for start, data := range stateRegions(state) {
+		*data = bytes.Clone(e.memory.GetRegion(start).Data)
+	}
+	state.Memory.IO = e.memory.GetIOState()
+	state.Memory.Save = bytes.Clone(e.memory.GetBackup().Data)
+	state.Memory.GPIO = e.memory.GetGPIO().GetState()
+
+	// Timers e input
+	for i, t := range e.timers.GetState() {
+		channel := &state.Timer.Channel[i]
+		channel.Counter, channel.Reload, channel.Control = t.Counter, t.Reload, t.Control
+		channel.Prescaler, channel.LastValue = t.Prescaler, t.LastValue
+	}
+	state.Input.KeyControl = e.input.GetKeyControl()
+
+	state.Emulator.FrameCount = e.frameCount
+	state.Emulator.RumbleCycles = e.rumbleCycles
+	state.Emulator.RumbleFrameStart = e.rumbleFrameStart
+	return state
+}
+
+// restoreState restaura um estado gerado por captureState. O tamanho das
+// regiões é conferido antes de qualquer componente ser alterado.
+func (e *Emulator) restoreState(state *savestate.SaveState) error {
+	regions := stateRegions(state)
+	for start, data := range regions {
+		if len(*data) != len(e.memory.GetRegion(start).Data) {
+			return fmt.Errorf("save state inválido: região 0x%08X com %d bytes", start, len(*data))
+		}
+	}
+	if len(state.Memory.Save) != len(e.memory.GetBackup().Data) {
+		return fmt.Errorf("save state inválido: memória de backup com %d bytes", len(state.Memory.Save))
+	}
+	if err := e.memory.SetIOState(state.Memory.IO); err != nil {
+		return fmt.Errorf("save state inválido: %w", err)
+	}
+
+	c := e.cpu
+	c.R = state.CPU.Registers
+	c.CPSR, c.SPSR = state.CPU.CPSR, state.CPU.SPSR
+	c.ThumbMode, c.Halted, c.Cycles = state.CPU.ThumbMode, state.CPU.Halted, state.CPU.Cycles
+	c.BankedR, c.BankedSPSR = state.CPU.BankedR, state.CPU.BankedSPSR
+	c.Pipeline.Fetch, c.Pipeline.Decode, c.Pipeline.Execute = state.CPU.Pipeline[0], state.CPU.Pipeline[1], state.CPU.Pipeline[2]
+	c.InterruptController.SetIE(state.CPU.IE)
+	c.InterruptController.SetIF(state.CPU.IF)
+	c.InterruptController.SetIME(state.CPU.IME)
+
+	for start, data := range regions {
+		copy(e.memory.GetRegion(start).Data, *data)
+	}
+	copy(e.memory.GetBackup().Data, state.Memory.Save)
+	e.memory.GetGPIO().SetState(state.Memory.GPIO)
+
+	var timers [4]timer.TimerState
+	for i, channel := range state.Timer.Channel {
+		timers[i] = timer.TimerState{
+			Counter:   channel.Counter,
+			Reload:    channel.Reload,
+			Control:   channel.Control,
+			Prescaler: channel.Prescaler,
+			LastValue: channel.LastValue,
+		}
+	}
+	e.timers.SetState(timers)
+	e.input.SetKeyControl(state.Input.KeyControl)
+
+	e.frameCount = state.Emulator.FrameCount
+	e.rumbleCycles = state.Emulator.RumbleCycles
+	e.rumbleFrameStart = state.Emulator.RumbleFrameStart
+	return nil
+}
+
+// SaveState salva o estado atual do emulador no arquivo
+func (e *Emulator) SaveState(path string) error {
+	manager := savestate.NewSaveStateManager(filepath.Dir(path), 1)
+	return manager.SaveToFile(path, e.captureState())
+}
+
+// LoadState carrega um estado salvo do emulador. Estados de uma versão
+// mais nova ou de outra ROM retornam *statefile.VersionError e
+// *statefile.ROMMismatchError.
+func (e *Emulator) LoadState(path string) error {
+	manager := savestate.NewSaveStateManager(filepath.Dir(path), 1)
+	state, err := manager.LoadFromFile(path)
+	if err != nil {
+		return fmt.Errorf("erro ao carregar save state: %w", err)
+	}
+	if err := manager.CheckROM(state, e.romHash); err != nil {
+		return err
+	}
+	if err := e.restoreState(state); err != nil {
+		return err
+	}
+
+	e.clearRewind()
+	return nil
+}
+
+// SetRewindConfig liga o rewind com a configuração indicada, descartando
+// o histórico atual. O rewind começa desligado.
+func (e *Emulator) SetRewindConfig(config rewind.Config) {
+	e.rewind = rewind.NewBuffer(config)
+}
+
+// DisableRewind desliga o rewind e libera o histórico
+func (e *Emulator) DisableRewind() {
+	e.rewind = nil
+}
+
+// snapshot codifica o estado atual em memória, para o rewind e os movies.
+// O horário fica de fora: o snapshot depende só da máquina, e o delta XOR
+// entre frames do rewind não muda a cada gravação.
+func (e *Emulator) snapshot() ([]byte, error) {
+	state := e.captureState()
+	state.Timestamp = time.Time{}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// restoreSnapshot restaura um estado gerado por snapshot
+func (e *Emulator) restoreSnapshot(data []byte) error {
+	var state savestate.SaveState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return fmt.Errorf("snapshot inválido: %w", err)
+	}
+	return e.restoreState(&state)
+}
+
+// captureRewind grava o snapshot do frame no histórico de rewind
+func (e *Emulator) captureRewind() {
+	if e.rewind == nil || !e.rewind.ShouldCapture(e.frameCount) {
+		return
+	}
+
+	data, err := e.snapshot()
+	if err != nil {
+		return
+	}
+	e.rewind.Push(e.frameCount, data)
+}
+
+// clearRewind descarta o histórico de rewind
+func (e *Emulator) clearRewind() {
+	if e.rewind != nil {
+		e.rewind.Clear()
+	}
+}
+
+// Rewind volta a emulação frames quadros, até o snapshot mais antigo
+// disponível
+func (e *Emulator) Rewind(frames int) error {
+	if e.rewind == nil {
+		return fmt.Errorf("rewind desativado")
+	}
+	if frames <= 0 {
+		return nil
+	}
+
+	target := uint64(0)
+	if uint64(frames) < e.frameCount {
+		target = e.frameCount - uint64(frames)
+	}
+	data, _, ok := e.rewind.Rewind(target)
+	if !ok {
+		return fmt.Errorf("histórico de rewind vazio")
+	}
+
+	if err := e.restoreSnapshot(data); err != nil {
+		return fmt.Errorf("erro ao restaurar snapshot de rewind: %w", err)
+	}
+	return nil
+}
@@ -108,18 +108,36 @@ func (a *AudioSystem) Destroy() {
 	a.initialized = false
 }
 
-// QueueSamples adiciona amostras à fila de áudio
+// QueueSamples adiciona amostras estéreo intercaladas (L, R, L, R...) à fila de áudio
 func (a *AudioSystem) QueueSamples(samples []int16) {
+	if !a.initialized || !a.enabled || len(samples) < Channels {
+		return
+	}
+
+	// Descarta uma amostra final sem par para não inverter os canais
+	frames := len(samples) / Channels * Channels
+
+	// Copia para não alterar o buffer de quem chamou ao aplicar o volume
+	stereoSamples := make([]int16, frames)
+	copy(stereoSamples, samples[:frames])
+
+	a.queue(stereoSamples)
+}
+
+// QueueMonoSamples adiciona amostras mono à fila, duplicando-as nos dois canais
+func (a *AudioSystem) QueueMonoSamples(samples []int16) {
 	if !a.initialized || !a.enabled || len(samples) == 0 {
 		return
 	}
 
+	a.queue(monoToStereo(samples))
+}
+
+// queue aplica o volume e envia amostras estéreo para o SDL
+func (a *AudioSystem) queue(stereoSamples []int16) {
 	a.bufferMutex.Lock()
 	defer a.bufferMutex.Unlock()
 
-	// Converte mono para stereo se necessário
-	stereoSamples := a.convertToStereo(samples)
-
 	// Aplica volume
 	a.applyVolume(stereoSamples)
 
@@ -136,17 +154,8 @@ func (a *AudioSystem) QueueSamples(samples []int16) {
 	}
 }
 
-// convertToStereo converte amostras mono para stereo
-func (a *AudioSystem) convertToStereo(samples []int16) []int16 {
-	if len(samples) == 0 {
-		return samples
-	}
-
-	// Se já é stereo, retorna como está
-	if len(samples)%2 == 0 {
-		return samples
-	}
-
+// monoToStereo converte amostras mono para stereo
+func monoToStereo(samples []int16) []int16 {
 	// Converte mono para stereo duplicando cada amostra
 	stereo := make([]int16, len(samples)*2)
 	for i, sample := range samples {
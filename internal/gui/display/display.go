@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"unsafe"
 	
+	"github.com/hobbiee/visualboy-go/internal/core/gb/sgb"
+	"github.com/hobbiee/visualboy-go/internal/core/gb/video"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
@@ -39,11 +41,29 @@ type Display struct {
 	// Buffer de pixels
 	pixelBuffer []uint8
 	
+	// Imagem de 256x224 do Super Game Boy (criada no primeiro frame SGB)
+	sgbTexture *sdl.Texture
+	sgbBuffer  []uint8
+	
 	// Estado
 	initialized bool
 	running     bool
+	
+	// Inclinação analógica (mouse arrastado ou analógico do gamepad)
+	controller *sdl.GameController
+	tiltX      float64
+	tiltY      float64
+	mouseTilt  bool
+	stickTilt  bool
 }
 
+// Zona morta do analógico do gamepad
+const stickDeadZone = 0.15
+
+// Duração de cada pulso de vibração; maior que um frame para não haver
+// intervalos enquanto o motor continua ligado
+const rumblePulseMS = 100
+
 // NewDisplay cria uma nova instância do display
 func NewDisplay(scale int) *Display {
 	if scale <= 0 {
@@ -65,9 +85,10 @@ func (d *Display) Initialize() error {
 	}
 	
 	// Inicializa SDL2
-	if err := sdl.Init(sdl.INIT_VIDEO); err != nil {
+	if err := sdl.Init(sdl.INIT_VIDEO | sdl.INIT_GAMECONTROLLER); err != nil {
 		return fmt.Errorf("failed to initialize SDL2: %w", err)
 	}
+	d.openController()
 	
 	// Cria janela
 	window, err := sdl.CreateWindow(
@@ -123,9 +144,17 @@ func (d *Display) Destroy() {
 		return
 	}
 	
+	if d.controller != nil {
+		d.controller.Close()
+		d.controller = nil
+	}
 	if d.texture != nil {
 		d.texture.Destroy()
 	}
+	if d.sgbTexture != nil {
+		d.sgbTexture.Destroy()
+		d.sgbTexture = nil
+	}
 	if d.renderer != nil {
 		d.renderer.Destroy()
 	}
@@ -147,6 +176,79 @@ func (d *Display) UpdateFrame(frame [GameBoyHeight][GameBoyWidth]uint8) error {
 	// Converte frame Game Boy para RGBA
 	d.convertFrameToRGBA(frame)
 	
+	return d.present()
+}
+
+// UpdateColorFrame atualiza o display com um frame RGB555 (Game Boy Color)
+func (d *Display) UpdateColorFrame(frame [GameBoyHeight][GameBoyWidth]uint16) error {
+	if !d.initialized {
+		return fmt.Errorf("display not initialized")
+	}
+	
+	for y := 0; y < GameBoyHeight; y++ {
+		for x := 0; x < GameBoyWidth; x++ {
+			offset := (y*GameBoyWidth + x) * 4
+			r, g, b, a := video.ColorToRGBA(frame[y][x])
+			d.pixelBuffer[offset+0] = r
+			d.pixelBuffer[offset+1] = g
+			d.pixelBuffer[offset+2] = b
+			d.pixelBuffer[offset+3] = a
+		}
+	}
+	
+	return d.present()
+}
+
+// UpdateSGBFrame atualiza o display com a imagem do Super Game Boy (tela e
+// moldura, RGB555). A janela passa a ter a proporção de 256x224.
+func (d *Display) UpdateSGBFrame(frame [sgb.Height][sgb.Width]uint16) error {
+	if !d.initialized {
+		return fmt.Errorf("display not initialized")
+	}
+	
+	if d.sgbTexture == nil {
+		texture, err := d.renderer.CreateTexture(
+			sdl.PIXELFORMAT_RGBA8888,
+			sdl.TEXTUREACCESS_STREAMING,
+			sgb.Width,
+			sgb.Height,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create SGB texture: %w", err)
+		}
+		d.sgbTexture = texture
+		d.sgbBuffer = make([]uint8, sgb.Width*sgb.Height*4)
+		
+		d.width = int32(sgb.Width * d.scale)
+		d.height = int32(sgb.Height * d.scale)
+		if !d.fullscreen {
+			d.window.SetSize(d.width, d.height)
+		}
+	}
+	
+	for y := 0; y < sgb.Height; y++ {
+		for x := 0; x < sgb.Width; x++ {
+			offset := (y*sgb.Width + x) * 4
+			r, g, b, a := video.ColorToRGBA(frame[y][x])
+			d.sgbBuffer[offset+0] = r
+			d.sgbBuffer[offset+1] = g
+			d.sgbBuffer[offset+2] = b
+			d.sgbBuffer[offset+3] = a
+		}
+	}
+	
+	if err := d.sgbTexture.Update(nil, unsafe.Pointer(&d.sgbBuffer[0]), sgb.Width*4); err != nil {
+		return fmt.Errorf("failed to update SGB texture: %w", err)
+	}
+	d.renderer.Clear()
+	d.renderer.Copy(d.sgbTexture, nil, nil)
+	d.renderer.Present()
+	
+	return nil
+}
+
+// present envia o buffer de pixels para a tela
+func (d *Display) present() error {
 	// Atualiza texture
 	err := d.texture.Update(nil, unsafe.Pointer(&d.pixelBuffer[0]), GameBoyWidth*4)
 	if err != nil {
@@ -217,6 +319,8 @@ func (d *Display) HandleEvents() (map[string]bool, bool) {
 					keys["Left"] = true
 				case sdl.K_RIGHT:
 					keys["Right"] = true
+				case sdl.K_BACKSPACE:
+					keys["Rewind"] = true
 				}
 			} else if e.Type == sdl.KEYUP {
 				switch e.Keysym.Sym {
@@ -235,6 +339,8 @@ func (d *Display) HandleEvents() (map[string]bool, bool) {
 					keys["Left"] = false
 				case sdl.K_RIGHT:
 					keys["Right"] = false
+				case sdl.K_BACKSPACE:
+					keys["Rewind"] = false
 				}
 			}
 			
@@ -242,12 +348,92 @@ func (d *Display) HandleEvents() (map[string]bool, bool) {
 			if e.Event == sdl.WINDOWEVENT_RESIZED {
 				d.handleResize(e.Data1, e.Data2)
 			}
+			
+		case *sdl.MouseButtonEvent:
+			if e.Button == sdl.BUTTON_LEFT {
+				d.mouseTilt = e.State == sdl.PRESSED
+				d.updateMouseTilt(e.X, e.Y)
+			}
+			
+		case *sdl.MouseMotionEvent:
+			if d.mouseTilt {
+				d.updateMouseTilt(e.X, e.Y)
+			}
+			
+		case *sdl.ControllerAxisEvent:
+			value := float64(e.Value) / 32767
+			switch e.Axis {
+			case sdl.CONTROLLER_AXIS_LEFTX:
+				d.tiltX = value
+			case sdl.CONTROLLER_AXIS_LEFTY:
+				d.tiltY = value
+			}
+			d.stickTilt = d.tiltX*d.tiltX+d.tiltY*d.tiltY > stickDeadZone*stickDeadZone
+			
+		case *sdl.ControllerDeviceEvent:
+			if e.Type == sdl.CONTROLLERDEVICEADDED && d.controller == nil {
+				d.openController()
+			}
 		}
 	}
 	
 	return keys, true
 }
 
+// openController abre o primeiro gamepad conectado
+func (d *Display) openController() {
+	for i := 0; i < sdl.NumJoysticks(); i++ {
+		if sdl.IsGameController(i) {
+			d.controller = sdl.GameControllerOpen(i)
+			return
+		}
+	}
+}
+
+// updateMouseTilt converte a posição do mouse, relativa ao centro da
+// janela, em inclinação de -1 a 1
+func (d *Display) updateMouseTilt(x, y int32) {
+	if !d.mouseTilt || d.window == nil {
+		d.tiltX, d.tiltY = 0, 0
+		return
+	}
+	
+	width, height := d.window.GetSize()
+	d.tiltX = (float64(x)/float64(width))*2 - 1
+	d.tiltY = (float64(y)/float64(height))*2 - 1
+}
+
+// GetTilt retorna a inclinação analógica e se o mouse ou o analógico
+// estão em uso; caso contrário o frontend usa as setas
+func (d *Display) GetTilt() (float64, float64, bool) {
+	if !d.mouseTilt && !d.stickTilt {
+		return 0, 0, false
+	}
+	return d.tiltX, d.tiltY, true
+}
+
+// Rumble repassa o motor de vibração do cartucho ao gamepad. A intensidade
+// é a fração do frame em que o motor ficou ligado.
+func (d *Display) Rumble(on bool, duty float64) {
+	if d.controller == nil {
+		return
+	}
+	
+	if !on && duty <= 0 {
+		d.controller.Rumble(0, 0, 0)
+		return
+	}
+	if on && duty <= 0 {
+		duty = 1
+	}
+	if duty > 1 {
+		duty = 1
+	}
+	
+	strength := uint16(duty * 0xFFFF)
+	d.controller.Rumble(strength, strength, rumblePulseMS)
+}
+
 // ToggleFullscreen alterna entre fullscreen e janela
 func (d *Display) ToggleFullscreen() {
 	if !d.initialized {
@@ -270,8 +456,11 @@ func (d *Display) handleResize(width, height int32) {
 		return
 	}
 	
-	// Mantém proporção
+	// Mantém proporção (256x224 com a moldura do Super Game Boy)
 	aspectRatio := float32(GameBoyWidth) / float32(GameBoyHeight)
+	if d.sgbTexture != nil {
+		aspectRatio = float32(sgb.Width) / float32(sgb.Height)
+	}
 	
 	newWidth := width
 	newHeight := int32(float32(width) / aspectRatio)
@@ -322,6 +511,10 @@ func (d *Display) SetScale(scale int) {
 	d.scale = scale
 	d.width = int32(GameBoyWidth * scale)
 	d.height = int32(GameBoyHeight * scale)
+	if d.sgbTexture != nil {
+		d.width = int32(sgb.Width * scale)
+		d.height = int32(sgb.Height * scale)
+	}
 	
 	if d.initialized && d.window != nil && !d.fullscreen {
 		d.window.SetSize(d.width, d.height)
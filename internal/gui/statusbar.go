@@ -16,6 +16,7 @@ type StatusBar struct {
 	romName    string
 	romSize    int64
 	saveType   string
+	romDetails string // Resumo do header (mapeador, CGB/SGB, licenciado)
 	isModified bool
 
 	// Estado do emulador
@@ -58,6 +59,11 @@ func (sb *StatusBar) SetROMInfo(name string, size int64, saveType string) {
 	sb.isModified = false
 }
 
+// SetROMDetails define o resumo do header exibido junto à ROM
+func (sb *StatusBar) SetROMDetails(details string) {
+	sb.romDetails = details
+}
+
 // SetPaused define o estado de pausa
 func (sb *StatusBar) SetPaused(paused bool) {
 	sb.isPaused = paused
@@ -89,6 +95,9 @@ func (sb *StatusBar) GetStatusText() string {
 		if sb.saveType != "" {
 			status += fmt.Sprintf(", %s", sb.saveType)
 		}
+		if sb.romDetails != "" {
+			status += fmt.Sprintf(", %s", sb.romDetails)
+		}
 		if sb.isModified {
 			status += "*"
 		}
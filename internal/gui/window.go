@@ -349,6 +349,11 @@ func (mw *MainWindow) SetROMInfo(name string, size int64, saveType string) {
 	mw.statusBar.SetROMInfo(name, size, saveType)
 }
 
+// SetROMDetails define o resumo do header na barra de status
+func (mw *MainWindow) SetROMDetails(details string) {
+	mw.statusBar.SetROMDetails(details)
+}
+
 // SetSpeed define a velocidade de emulação
 func (mw *MainWindow) SetSpeed(speed float64) {
 	mw.statusBar.SetSpeed(speed)